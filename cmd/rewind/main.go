@@ -1,22 +1,108 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/bhavanki/rewind/internal/config"
+	"github.com/bhavanki/rewind/internal/ingest"
+	"github.com/bhavanki/rewind/internal/integrity"
+	"github.com/bhavanki/rewind/internal/purge"
 	"github.com/bhavanki/rewind/internal/routes"
-	"github.com/bhavanki/rewind/internal/store"
+	"github.com/bhavanki/rewind/pkg/store"
 	"github.com/gin-gonic/gin"
 )
 
+// purgeInterval is how often deleted entities past their retention period
+// are purged.
+const purgeInterval = time.Hour
+
+// danglingRefsCommand is the command that reports dangling refs instead of
+// serving, as in "rewind dangling-refs -database rewind.db".
+const danglingRefsCommand = "dangling-refs"
+
 func main() {
+	args := os.Args[1:]
+	command := ""
+	if len(args) > 0 && args[0] == danglingRefsCommand {
+		command, args = args[0], args[1:]
+	}
+
+	cfg, err := config.Load(args, os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err.Error())
+		os.Exit(2)
+	}
+
+	if command == danglingRefsCommand {
+		os.Exit(reportDanglingRefs(cfg, os.Stdout))
+	}
+
 	r := gin.Default()
 	_ = r.SetTrustedProxies(nil)
 
-	// store, err := store.NewSqliteStore("file::memory:?cache=shared")
-	store, err := store.NewSqliteStore("file::memory:")
+	st, err := openStore(cfg)
 	if err != nil {
 		panic(err)
 	}
+	st = store.WithTimeouts(st, store.Timeouts{Read: cfg.ReadTimeout, Write: cfg.WriteTimeout})
+
+	go ingest.NewProcessor(st, cfg.IngestInterval, cfg.IngestRoots).Run(context.Background())
+	go purge.NewPurger(st, cfg.TombstoneRetention, purgeInterval).Run(context.Background())
+
+	routes.SetupRoutes(r, st,
+		routes.WithIntegrityMode(cfg.Integrity),
+		routes.WithTrustedActorHeader(cfg.TrustActorHeader))
+
+	_ = r.Run(cfg.Listen)
+}
+
+func openStore(cfg config.Config) (store.Store, error) {
+	switch cfg.Driver {
+	case config.DriverPostgres:
+		return store.NewPostgresStore(cfg.Database)
+	default:
+		return store.NewSqliteStore(cfg.Database)
+	}
+}
 
-	routes.SetupRoutes(r, store)
+// reportDanglingRefs writes the dangling refs in the store, grouped by owner,
+// and returns the exit status: 1 if there are any, and 2 if they could not be
+// found.
+func reportDanglingRefs(cfg config.Config, w io.Writer) int {
+	st, err := openStore(cfg)
+	if err != nil {
+		slog.Error("failed to open store", "error", err.Error())
+		return 2
+	}
+	st = store.WithTimeouts(st, store.Timeouts{Read: cfg.ReadTimeout, Write: cfg.WriteTimeout})
 
-	_ = r.Run() // listen and serve on 0.0.0.0:8080 (for windows "localhost:8080")
+	report, err := integrity.Report(context.Background(), st)
+	if err != nil {
+		slog.Error("failed to report dangling refs", "error", err.Error())
+		return 2
+	}
+	for _, owner := range report.Owners {
+		if owner.Owner.Empty() {
+			fmt.Fprintln(w, "(no owner)")
+		} else {
+			fmt.Fprintln(w, owner.Owner)
+		}
+		for _, ref := range owner.Refs {
+			fmt.Fprintf(w, "  %s %s %s\n", ref.EntityRef, ref.Field, ref.TargetRef)
+		}
+	}
+	if report.Total > 0 {
+		return 1
+	}
+	return 0
 }
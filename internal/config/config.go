@@ -0,0 +1,271 @@
+// Package config gathers the server's settings from command line flags,
+// environment variables and an optional YAML config file, in that order of
+// precedence.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/bhavanki/rewind/internal/integrity"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DriverSqlite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+const (
+	DefaultDriver             = DriverSqlite
+	DefaultDatabase           = "rewind.db"
+	DefaultListen             = ":8080"
+	DefaultIngestInterval     = time.Minute
+	DefaultTombstoneRetention = 30 * 24 * time.Hour
+	DefaultIntegrity          = integrity.ModeOff
+)
+
+const (
+	envConfig             = "REWIND_CONFIG"
+	envDriver             = "REWIND_DRIVER"
+	envDatabase           = "REWIND_DATABASE"
+	envListen             = "REWIND_LISTEN"
+	envIngestInterval     = "REWIND_INGEST_INTERVAL"
+	envIngestRoots        = "REWIND_INGEST_ROOTS"
+	envQueryTimeout       = "REWIND_QUERY_TIMEOUT"
+	envReadTimeout        = "REWIND_READ_TIMEOUT"
+	envWriteTimeout       = "REWIND_WRITE_TIMEOUT"
+	envTombstoneRetention = "REWIND_TOMBSTONE_RETENTION"
+	envIntegrity          = "REWIND_INTEGRITY"
+	envTrustActorHeader   = "REWIND_TRUST_ACTOR_HEADER"
+)
+
+type Config struct {
+	// Driver selects the database that backs the store, either "sqlite" or
+	// "postgres".
+	Driver string `yaml:"driver"`
+	// Database is, for SQLite, the path of the database file or a
+	// go-sqlite3 DSN such as "file:rewind.db?cache=shared". For PostgreSQL,
+	// it is a lib/pq connection string or URL, and must be given.
+	Database string `yaml:"database"`
+	// Listen is the address that the HTTP server listens on.
+	Listen string `yaml:"listen"`
+	// IngestInterval is how often locations are ingested.
+	IngestInterval time.Duration `yaml:"ingestInterval"`
+	// IngestRoots are the directories that location targets must lie under
+	// to be ingested. With none, no targets are ingested. In the environment
+	// and on the command line, they are separated as in PATH.
+	IngestRoots []string `yaml:"ingestRoots"`
+	// QueryTimeout limits how long each store operation may run. Zero means
+	// no limit. It is the default for ReadTimeout and WriteTimeout.
+	QueryTimeout time.Duration `yaml:"queryTimeout"`
+	// ReadTimeout limits how long each store operation that only reads, such
+	// as a list, search or report, may run.
+	ReadTimeout time.Duration `yaml:"readTimeout"`
+	// WriteTimeout limits how long each store operation that changes
+	// entities may run.
+	WriteTimeout time.Duration `yaml:"writeTimeout"`
+	// TombstoneRetention is how long deleted entities are kept, and may be
+	// restored, before they are purged for good.
+	TombstoneRetention time.Duration `yaml:"tombstoneRetention"`
+	// Integrity is how strictly the entity refs in written entities are
+	// checked: "off", "warn" or "strict".
+	Integrity integrity.Mode `yaml:"integrity"`
+	// TrustActorHeader is whether the actor named in the X-Rewind-Actor
+	// request header is recorded in entity history. The header is not
+	// authenticated, so only trust it behind a proxy that sets it.
+	TrustActorHeader bool `yaml:"trustActorHeader"`
+}
+
+// Load builds a configuration from command line arguments (excluding the
+// program name), the environment, and the config file named by the -config
+// flag or the REWIND_CONFIG environment variable. Settings missing from all
+// of them take their defaults.
+func Load(args []string, getenv func(string) string) (Config, error) {
+	fs := flag.NewFlagSet("rewind", flag.ContinueOnError)
+	configFile := fs.String("config", "", "path of a YAML config file")
+	driver := fs.String("driver", "", "database driver, sqlite or postgres")
+	database := fs.String("database", "", "path or DSN of the database")
+	listen := fs.String("listen", "", "address to listen on")
+	ingestInterval := fs.Duration("ingest-interval", 0, "how often to ingest locations")
+	ingestRoots := fs.String("ingest-roots", "", "directories that location targets must lie under, separated as in PATH")
+	queryTimeout := fs.Duration("query-timeout", 0, "how long each store operation may run, or 0 for no limit")
+	readTimeout := fs.Duration("read-timeout", 0, "how long each store read may run, if not the query timeout")
+	writeTimeout := fs.Duration("write-timeout", 0, "how long each store write may run, if not the query timeout")
+	tombstoneRetention := fs.Duration("tombstone-retention", 0, "how long to keep deleted entities before purging them")
+	integrityMode := fs.String("integrity", "", "how strictly to check entity refs, off, warn or strict")
+	trustActorHeader := fs.Bool("trust-actor-header", false, "record the actor named in the X-Rewind-Actor request header")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	cfg := Config{}
+	if *configFile == "" {
+		*configFile = getenv(envConfig)
+	}
+	if *configFile != "" {
+		fileCfg, err := readFile(*configFile)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg = fileCfg
+	}
+
+	if v := getenv(envDriver); v != "" {
+		cfg.Driver = v
+	}
+	if v := getenv(envDatabase); v != "" {
+		cfg.Database = v
+	}
+	if v := getenv(envListen); v != "" {
+		cfg.Listen = v
+	}
+	if v := getenv(envIngestInterval); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %s: %w", envIngestInterval, v, err)
+		}
+		cfg.IngestInterval = d
+	}
+	if v := getenv(envIngestRoots); v != "" {
+		cfg.IngestRoots = filepath.SplitList(v)
+	}
+	if v := getenv(envQueryTimeout); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %s: %w", envQueryTimeout, v, err)
+		}
+		cfg.QueryTimeout = d
+	}
+	if v := getenv(envReadTimeout); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %s: %w", envReadTimeout, v, err)
+		}
+		cfg.ReadTimeout = d
+	}
+	if v := getenv(envWriteTimeout); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %s: %w", envWriteTimeout, v, err)
+		}
+		cfg.WriteTimeout = d
+	}
+	if v := getenv(envTombstoneRetention); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %s: %w", envTombstoneRetention, v, err)
+		}
+		cfg.TombstoneRetention = d
+	}
+	if v := getenv(envIntegrity); v != "" {
+		cfg.Integrity = integrity.Mode(v)
+	}
+	if v := getenv(envTrustActorHeader); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %s: %w", envTrustActorHeader, v, err)
+		}
+		cfg.TrustActorHeader = b
+	}
+
+	// Only the flags given on the command line override the other settings,
+	// so that a flag can set a setting to its zero value, such as
+	// -query-timeout 0 for no limit.
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "driver":
+			cfg.Driver = *driver
+		case "database":
+			cfg.Database = *database
+		case "listen":
+			cfg.Listen = *listen
+		case "ingest-interval":
+			cfg.IngestInterval = *ingestInterval
+		case "ingest-roots":
+			cfg.IngestRoots = filepath.SplitList(*ingestRoots)
+		case "query-timeout":
+			cfg.QueryTimeout = *queryTimeout
+		case "read-timeout":
+			cfg.ReadTimeout = *readTimeout
+		case "write-timeout":
+			cfg.WriteTimeout = *writeTimeout
+		case "tombstone-retention":
+			cfg.TombstoneRetention = *tombstoneRetention
+		case "integrity":
+			cfg.Integrity = integrity.Mode(*integrityMode)
+		case "trust-actor-header":
+			cfg.TrustActorHeader = *trustActorHeader
+		}
+	})
+	if cfg.Driver == "" {
+		cfg.Driver = DefaultDriver
+	}
+	switch cfg.Driver {
+	case DriverSqlite:
+		if cfg.Database == "" {
+			cfg.Database = DefaultDatabase
+		}
+	case DriverPostgres:
+		if cfg.Database == "" {
+			return Config{}, errors.New("a database connection string is required for postgres")
+		}
+	default:
+		return Config{}, fmt.Errorf("unsupported driver %s", cfg.Driver)
+	}
+	if cfg.Listen == "" {
+		cfg.Listen = DefaultListen
+	}
+	if cfg.IngestInterval == 0 {
+		cfg.IngestInterval = DefaultIngestInterval
+	}
+	if cfg.IngestInterval < 0 {
+		return Config{}, errors.New("ingest interval must be positive")
+	}
+	if cfg.QueryTimeout < 0 {
+		return Config{}, errors.New("query timeout must not be negative")
+	}
+	if cfg.ReadTimeout == 0 {
+		cfg.ReadTimeout = cfg.QueryTimeout
+	}
+	if cfg.ReadTimeout < 0 {
+		return Config{}, errors.New("read timeout must not be negative")
+	}
+	if cfg.WriteTimeout == 0 {
+		cfg.WriteTimeout = cfg.QueryTimeout
+	}
+	if cfg.WriteTimeout < 0 {
+		return Config{}, errors.New("write timeout must not be negative")
+	}
+	if cfg.TombstoneRetention == 0 {
+		cfg.TombstoneRetention = DefaultTombstoneRetention
+	}
+	if cfg.TombstoneRetention < 0 {
+		return Config{}, errors.New("tombstone retention must not be negative")
+	}
+	if cfg.Integrity == "" {
+		cfg.Integrity = DefaultIntegrity
+	}
+	if _, err := integrity.ParseMode(string(cfg.Integrity)); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func readFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
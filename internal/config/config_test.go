@@ -0,0 +1,268 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bhavanki/rewind/internal/integrity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "rewind.yaml")
+	err := os.WriteFile(configFile, []byte(`
+database: /var/lib/rewind/file.db
+listen: ":9000"
+ingestInterval: 5m
+ingestRoots:
+  - /srv/catalog
+queryTimeout: 10s
+writeTimeout: 30s
+tombstoneRetention: 168h
+integrity: warn
+trustActorHeader: true
+`), 0o644)
+	require.NoError(t, err)
+
+	type testCase struct {
+		args        []string
+		env         map[string]string
+		expected    Config
+		description string
+	}
+	tcs := []testCase{
+		{
+			expected: Config{
+				Driver:             DefaultDriver,
+				Database:           DefaultDatabase,
+				Listen:             DefaultListen,
+				IngestInterval:     DefaultIngestInterval,
+				TombstoneRetention: DefaultTombstoneRetention,
+				Integrity:          DefaultIntegrity,
+			},
+			description: "defaults",
+		},
+		{
+			args: []string{"-config", configFile},
+			expected: Config{
+				Driver:             DriverSqlite,
+				Database:           "/var/lib/rewind/file.db",
+				Listen:             ":9000",
+				IngestInterval:     5 * time.Minute,
+				IngestRoots:        []string{"/srv/catalog"},
+				QueryTimeout:       10 * time.Second,
+				ReadTimeout:        10 * time.Second,
+				WriteTimeout:       30 * time.Second,
+				TombstoneRetention: 7 * 24 * time.Hour,
+				Integrity:          integrity.ModeWarn,
+				TrustActorHeader:   true,
+			},
+			description: "config file",
+		},
+		{
+			env: map[string]string{
+				"REWIND_CONFIG":              configFile,
+				"REWIND_DATABASE":            "/var/lib/rewind/env.db",
+				"REWIND_INGEST_INTERVAL":     "30s",
+				"REWIND_INGEST_ROOTS":        "/srv/a:/srv/b",
+				"REWIND_QUERY_TIMEOUT":       "2s",
+				"REWIND_READ_TIMEOUT":        "1s",
+				"REWIND_TOMBSTONE_RETENTION": "24h",
+				"REWIND_INTEGRITY":           "strict",
+			},
+			expected: Config{
+				Driver:             DriverSqlite,
+				Database:           "/var/lib/rewind/env.db",
+				Listen:             ":9000",
+				IngestInterval:     30 * time.Second,
+				IngestRoots:        []string{"/srv/a", "/srv/b"},
+				QueryTimeout:       2 * time.Second,
+				ReadTimeout:        time.Second,
+				WriteTimeout:       30 * time.Second,
+				TombstoneRetention: 24 * time.Hour,
+				Integrity:          integrity.ModeStrict,
+				TrustActorHeader:   true,
+			},
+			description: "environment over config file",
+		},
+		{
+			args: []string{"-database", "/var/lib/rewind/flag.db", "-listen", "localhost:8081", "-query-timeout", "500ms", "-write-timeout", "5s", "-tombstone-retention", "1h", "-integrity", "off", "-ingest-roots", "/srv/flag"},
+			env: map[string]string{
+				"REWIND_CONFIG":              configFile,
+				"REWIND_DATABASE":            "/var/lib/rewind/env.db",
+				"REWIND_QUERY_TIMEOUT":       "2s",
+				"REWIND_TOMBSTONE_RETENTION": "24h",
+				"REWIND_INTEGRITY":           "strict",
+			},
+			expected: Config{
+				Driver:             DriverSqlite,
+				Database:           "/var/lib/rewind/flag.db",
+				Listen:             "localhost:8081",
+				IngestInterval:     5 * time.Minute,
+				IngestRoots:        []string{"/srv/flag"},
+				QueryTimeout:       500 * time.Millisecond,
+				ReadTimeout:        500 * time.Millisecond,
+				WriteTimeout:       5 * time.Second,
+				TombstoneRetention: time.Hour,
+				Integrity:          integrity.ModeOff,
+				TrustActorHeader:   true,
+			},
+			description: "flags over environment",
+		},
+		{
+			args: []string{"-driver", "postgres"},
+			env: map[string]string{
+				"REWIND_DATABASE": "postgres://rewind@localhost/rewind",
+			},
+			expected: Config{
+				Driver:             DriverPostgres,
+				Database:           "postgres://rewind@localhost/rewind",
+				Listen:             DefaultListen,
+				IngestInterval:     DefaultIngestInterval,
+				TombstoneRetention: DefaultTombstoneRetention,
+				Integrity:          DefaultIntegrity,
+			},
+			description: "postgres",
+		},
+		{
+			args: []string{"-query-timeout", "0", "-ingest-roots", "", "-trust-actor-header=false"},
+			env: map[string]string{
+				"REWIND_CONFIG":              configFile,
+				"REWIND_QUERY_TIMEOUT":       "2s",
+				"REWIND_TRUST_ACTOR_HEADER":  "true",
+				"REWIND_TOMBSTONE_RETENTION": "24h",
+			},
+			expected: Config{
+				Driver:             DriverSqlite,
+				Database:           "/var/lib/rewind/file.db",
+				Listen:             ":9000",
+				IngestInterval:     5 * time.Minute,
+				IngestRoots:        []string{},
+				WriteTimeout:       30 * time.Second,
+				TombstoneRetention: 24 * time.Hour,
+				Integrity:          integrity.ModeWarn,
+			},
+			description: "zero flags over environment",
+		},
+		{
+			args: []string{"-trust-actor-header"},
+			expected: Config{
+				Driver:             DefaultDriver,
+				Database:           DefaultDatabase,
+				Listen:             DefaultListen,
+				IngestInterval:     DefaultIngestInterval,
+				TombstoneRetention: DefaultTombstoneRetention,
+				Integrity:          DefaultIntegrity,
+				TrustActorHeader:   true,
+			},
+			description: "trusted actor header flag",
+		},
+		{
+			env: map[string]string{
+				"REWIND_TRUST_ACTOR_HEADER": "true",
+			},
+			expected: Config{
+				Driver:             DefaultDriver,
+				Database:           DefaultDatabase,
+				Listen:             DefaultListen,
+				IngestInterval:     DefaultIngestInterval,
+				TombstoneRetention: DefaultTombstoneRetention,
+				Integrity:          DefaultIntegrity,
+				TrustActorHeader:   true,
+			},
+			description: "trusted actor header in environment",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg, err := Load(tc.args, func(key string) string { return tc.env[key] })
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, cfg)
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	type testCase struct {
+		args        []string
+		env         map[string]string
+		description string
+	}
+	tcs := []testCase{
+		{
+			args:        []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")},
+			description: "missing config file",
+		},
+		{
+			env:         map[string]string{"REWIND_INGEST_INTERVAL": "often"},
+			description: "bad interval in environment",
+		},
+		{
+			args:        []string{"-ingest-interval", "-1m"},
+			description: "negative interval",
+		},
+		{
+			env:         map[string]string{"REWIND_QUERY_TIMEOUT": "soon"},
+			description: "bad query timeout in environment",
+		},
+		{
+			args:        []string{"-query-timeout", "-1s"},
+			description: "negative query timeout",
+		},
+		{
+			env:         map[string]string{"REWIND_READ_TIMEOUT": "soon"},
+			description: "bad read timeout in environment",
+		},
+		{
+			args:        []string{"-read-timeout", "-1s"},
+			description: "negative read timeout",
+		},
+		{
+			env:         map[string]string{"REWIND_WRITE_TIMEOUT": "soon"},
+			description: "bad write timeout in environment",
+		},
+		{
+			args:        []string{"-write-timeout", "-1s"},
+			description: "negative write timeout",
+		},
+		{
+			env:         map[string]string{"REWIND_TOMBSTONE_RETENTION": "forever"},
+			description: "bad tombstone retention in environment",
+		},
+		{
+			args:        []string{"-tombstone-retention", "-1h"},
+			description: "negative tombstone retention",
+		},
+		{
+			args:        []string{"-integrity", "lenient"},
+			description: "unsupported integrity mode",
+		},
+		{
+			env:         map[string]string{"REWIND_TRUST_ACTOR_HEADER": "sometimes"},
+			description: "bad trusted actor header in environment",
+		},
+		{
+			args:        []string{"-driver", "postgres"},
+			description: "postgres without database",
+		},
+		{
+			args:        []string{"-driver", "mysql"},
+			description: "unsupported driver",
+		},
+		{
+			args:        []string{"-unknown"},
+			description: "unknown flag",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := Load(tc.args, func(key string) string { return tc.env[key] })
+			assert.Error(t, err)
+		})
+	}
+}
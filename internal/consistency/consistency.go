@@ -0,0 +1,211 @@
+// Package consistency checks that pairs of inverse spec fields, such as the
+// dependsOn and dependencyOf of components, agree with each other, and can
+// write the missing side of a pair.
+package consistency
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+)
+
+// fieldPair is a spec field whose refs should each be matched by a ref back
+// in the inverse field of the target. Only targets of the given kinds have
+// the inverse field.
+type fieldPair struct {
+	field   string
+	inverse string
+	kinds   []string
+}
+
+var fieldPairs = []fieldPair{
+	{"spec.dependsOn", "spec.dependencyOf", []string{model.KindComponent, model.KindResource}},
+	{"spec.dependencyOf", "spec.dependsOn", []string{model.KindComponent, model.KindResource}},
+	{"spec.children", "spec.parent", []string{model.KindGroup}},
+	{"spec.parent", "spec.children", []string{model.KindGroup}},
+}
+
+// Asymmetries finds the refs in paired spec fields that the targets do not
+// give back: a component that depends on another which does not list it as
+// a dependency, or a group that lists a child whose parent is missing or is
+// another group. A ref to a missing entity is not an asymmetry; it is left to
+// integrity checks.
+//
+// Asymmetries are found from the refs in specs, not from relations, because
+// relations cannot show them: store.ListRelations merges the relations that an
+// entity's own spec makes with the inverses of the refs to it from other
+// specs. So an asymmetry never leaves a relation missing. A group listed as a
+// child of one group, but with another as its parent, has a childOf relation
+// to both, and the report is what tells them apart.
+func Asymmetries(ctx context.Context, st store.Store) ([]model.Asymmetry, error) {
+	var fields []string
+	for _, pair := range fieldPairs {
+		fields = append(fields, pair.field)
+	}
+	refs, err := st.ListSpecRefs(ctx, fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spec refs: %w", err)
+	}
+
+	byEntity := map[model.EntityRef]map[string][]model.EntityRef{}
+	for _, ref := range refs {
+		if byEntity[ref.EntityRef] == nil {
+			byEntity[ref.EntityRef] = map[string][]model.EntityRef{}
+		}
+		byEntity[ref.EntityRef][ref.Field] = append(byEntity[ref.EntityRef][ref.Field], ref.Ref)
+	}
+
+	// A target with no refs of its own in the paired fields may not exist,
+	// so those targets are all looked up at once.
+	var unlisted []model.EntityRef
+	for _, ref := range refs {
+		if _, ok := byEntity[ref.Ref]; !ok {
+			unlisted = append(unlisted, ref.Ref)
+		}
+	}
+	existing, err := st.ListExistingRefs(ctx, unlisted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up refs: %w", err)
+	}
+
+	asymmetries := []model.Asymmetry{}
+	for _, ref := range refs {
+		i := slices.IndexFunc(fieldPairs, func(pair fieldPair) bool { return pair.field == ref.Field })
+		pair := fieldPairs[i]
+		if !slices.Contains(pair.kinds, ref.Ref.Kind) {
+			continue
+		}
+		inverseRefs, ok := byEntity[ref.Ref]
+		if slices.Contains(inverseRefs[pair.inverse], ref.EntityRef) {
+			continue
+		}
+		if !ok && !slices.Contains(existing, ref.Ref) {
+			continue
+		}
+		asymmetries = append(asymmetries, model.Asymmetry{
+			EntityRef:    ref.EntityRef,
+			Field:        ref.Field,
+			TargetRef:    ref.Ref,
+			InverseField: pair.inverse,
+			InverseRefs:  append([]model.EntityRef{}, inverseRefs[pair.inverse]...),
+		})
+	}
+	return asymmetries, nil
+}
+
+// Synthesize writes the missing inverse ref of each asymmetry into the spec
+// of its target, so that the pair agrees: a resource that a component depends
+// on comes to list the component in its dependencyOf, and a group listed as a
+// child with no parent of its own comes to have the listing group as parent.
+// A group's parent is never replaced, so a child listed by more than one
+// group, or that already has another parent, is skipped and left for the
+// report. Each target is updated at the version it was read at, so that a
+// concurrent change to it makes Synthesize fail rather than be lost.
+func Synthesize(ctx context.Context, st store.Store) (model.SynthesisReport, error) {
+	asymmetries, err := Asymmetries(ctx, st)
+	if err != nil {
+		return model.SynthesisReport{}, err
+	}
+
+	var targets []model.EntityRef
+	byTarget := map[model.EntityRef][]model.Asymmetry{}
+	for _, asymmetry := range asymmetries {
+		if _, ok := byTarget[asymmetry.TargetRef]; !ok {
+			targets = append(targets, asymmetry.TargetRef)
+		}
+		byTarget[asymmetry.TargetRef] = append(byTarget[asymmetry.TargetRef], asymmetry)
+	}
+
+	report := model.SynthesisReport{
+		Synthesized: []model.Asymmetry{},
+		Skipped:     []model.Asymmetry{},
+	}
+	for _, target := range targets {
+		synthesized, skipped, err := synthesizeTarget(ctx, st, target, byTarget[target])
+		if err != nil {
+			return model.SynthesisReport{}, fmt.Errorf("failed to synthesize inverse refs in %s: %w", target, err)
+		}
+		report.Synthesized = append(report.Synthesized, synthesized...)
+		report.Skipped = append(report.Skipped, skipped...)
+	}
+	return report, nil
+}
+
+// synthesizeTarget writes the missing inverse refs of the asymmetries with a
+// target into its spec.
+func synthesizeTarget(ctx context.Context, st store.Store, target model.EntityRef, asymmetries []model.Asymmetry) ([]model.Asymmetry, []model.Asymmetry, error) {
+	switch target.Kind {
+	case model.KindComponent:
+		c, err := st.ReadComponent(ctx, target)
+		if err != nil {
+			return nil, nil, err
+		}
+		synthesizeDependencies(&c.Spec.DependsOn, &c.Spec.DependencyOf, asymmetries)
+		if _, err := st.UpdateComponent(ctx, c); err != nil {
+			return nil, nil, err
+		}
+		return asymmetries, nil, nil
+	case model.KindResource:
+		r, err := st.ReadResource(ctx, target)
+		if err != nil {
+			return nil, nil, err
+		}
+		synthesizeDependencies(&r.Spec.DependsOn, &r.Spec.DependencyOf, asymmetries)
+		if _, err := st.UpdateResource(ctx, r); err != nil {
+			return nil, nil, err
+		}
+		return asymmetries, nil, nil
+	case model.KindGroup:
+		g, err := st.ReadGroup(ctx, target)
+		if err != nil {
+			return nil, nil, err
+		}
+		var synthesized, skipped []model.Asymmetry
+		var parents []model.Asymmetry
+		for _, asymmetry := range asymmetries {
+			switch asymmetry.InverseField {
+			case "spec.children":
+				addRef(&g.Spec.Children, asymmetry.EntityRef)
+				synthesized = append(synthesized, asymmetry)
+			case "spec.parent":
+				parents = append(parents, asymmetry)
+			}
+		}
+		if len(parents) == 1 && g.Spec.Parent.Empty() {
+			g.Spec.Parent = parents[0].EntityRef
+			synthesized = append(synthesized, parents[0])
+		} else {
+			skipped = append(skipped, parents...)
+		}
+		if len(synthesized) > 0 {
+			if _, err := st.UpdateGroup(ctx, g); err != nil {
+				return nil, nil, err
+			}
+		}
+		return synthesized, skipped, nil
+	default:
+		return nil, asymmetries, nil
+	}
+}
+
+// synthesizeDependencies adds the missing inverse refs of asymmetries to the
+// dependsOn and dependencyOf of a component or resource.
+func synthesizeDependencies(dependsOn *[]model.EntityRef, dependencyOf *[]model.EntityRef, asymmetries []model.Asymmetry) {
+	for _, asymmetry := range asymmetries {
+		switch asymmetry.InverseField {
+		case "spec.dependsOn":
+			addRef(dependsOn, asymmetry.EntityRef)
+		case "spec.dependencyOf":
+			addRef(dependencyOf, asymmetry.EntityRef)
+		}
+	}
+}
+
+func addRef(refs *[]model.EntityRef, ref model.EntityRef) {
+	if !slices.Contains(*refs, ref) {
+		*refs = append(*refs, ref)
+	}
+}
@@ -0,0 +1,200 @@
+package consistency
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func groupRef(name string) model.EntityRef {
+	return model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: name}
+}
+
+func componentRef(name string) model.EntityRef {
+	return model.EntityRef{Kind: model.KindComponent, Namespace: "default", Name: name}
+}
+
+func entity(ref model.EntityRef) model.Entity {
+	return model.Entity{
+		APIVersion: "backstage.io/v1alpha1",
+		Kind:       ref.Kind,
+		Metadata:   model.Metadata{Namespace: ref.Namespace, Name: ref.Name},
+	}
+}
+
+func TestAsymmetries_Groups(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemoryStore()
+	a, b, c, d := groupRef("a"), groupRef("b"), groupRef("c"), groupRef("d")
+	groups := []model.Group{
+		{Entity: entity(a), Spec: model.GroupSpec{Type: "team", Children: []model.EntityRef{b, d, groupRef("missing")}}},
+		{Entity: entity(b), Spec: model.GroupSpec{Type: "team", Parent: c}},
+		{Entity: entity(c), Spec: model.GroupSpec{Type: "team", Children: []model.EntityRef{b}}},
+		{Entity: entity(d), Spec: model.GroupSpec{Type: "team"}},
+	}
+	for _, group := range groups {
+		_, err := st.CreateGroup(ctx, group)
+		require.NoError(t, err)
+	}
+
+	asymmetries, err := Asymmetries(ctx, st)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Asymmetry{
+		{EntityRef: a, Field: "spec.children", TargetRef: b, InverseField: "spec.parent", InverseRefs: []model.EntityRef{c}},
+		{EntityRef: a, Field: "spec.children", TargetRef: d, InverseField: "spec.parent", InverseRefs: []model.EntityRef{}},
+	}, asymmetries, "a child with another parent, and a child with none")
+
+	relations, err := st.ListRelations(ctx, b)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Relation{
+		{Type: model.RelationChildOf, TargetRef: a},
+		{Type: model.RelationChildOf, TargetRef: c},
+	}, relations, "relations include the inverse of each ref")
+}
+
+func TestAsymmetries_Dependencies(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemoryStore()
+	web, api, db := componentRef("web"), componentRef("api"), model.EntityRef{Kind: model.KindResource, Namespace: "default", Name: "db"}
+	spec := func(dependsOn []model.EntityRef, dependencyOf []model.EntityRef) model.ComponentSpec {
+		return model.ComponentSpec{
+			Type:         model.ComponentTypeService,
+			Lifecycle:    model.ComponentLifecycleProduction,
+			DependsOn:    dependsOn,
+			DependencyOf: dependencyOf,
+		}
+	}
+	_, err := st.CreateComponent(ctx, model.Component{Entity: entity(web), Spec: spec([]model.EntityRef{api}, nil)})
+	require.NoError(t, err)
+	_, err = st.CreateComponent(ctx, model.Component{Entity: entity(api), Spec: spec([]model.EntityRef{db}, []model.EntityRef{web})})
+	require.NoError(t, err)
+	_, err = st.CreateResource(ctx, model.Resource{
+		Entity: entity(db),
+		Spec: model.ResourceSpec{
+			Type:         "database",
+			DependencyOf: []model.EntityRef{web},
+		},
+	})
+	require.NoError(t, err)
+
+	asymmetries, err := Asymmetries(ctx, st)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Asymmetry{
+		{EntityRef: api, Field: "spec.dependsOn", TargetRef: db, InverseField: "spec.dependencyOf", InverseRefs: []model.EntityRef{web}},
+		{EntityRef: db, Field: "spec.dependencyOf", TargetRef: web, InverseField: "spec.dependsOn", InverseRefs: []model.EntityRef{api}},
+	}, asymmetries)
+
+	relations, err := st.ListRelations(ctx, web)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Relation{
+		{Type: model.RelationDependsOn, TargetRef: api},
+		{Type: model.RelationDependsOn, TargetRef: db},
+	}, relations, "relations include the inverse of each ref")
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Asymmetries(ctx, st)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSynthesize_Groups(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemoryStore()
+	a, b, c, d, e, f := groupRef("a"), groupRef("b"), groupRef("c"), groupRef("d"), groupRef("e"), groupRef("f")
+	groups := []model.Group{
+		{Entity: entity(a), Spec: model.GroupSpec{Type: "team", Children: []model.EntityRef{b, d, f}}},
+		{Entity: entity(b), Spec: model.GroupSpec{Type: "team", Parent: c}},
+		{Entity: entity(c), Spec: model.GroupSpec{Type: "team", Children: []model.EntityRef{b, f}}},
+		{Entity: entity(d), Spec: model.GroupSpec{Type: "team"}},
+		{Entity: entity(e), Spec: model.GroupSpec{Type: "team", Parent: a}},
+		{Entity: entity(f), Spec: model.GroupSpec{Type: "team"}},
+	}
+	for _, group := range groups {
+		_, err := st.CreateGroup(ctx, group)
+		require.NoError(t, err)
+	}
+
+	report, err := Synthesize(ctx, st)
+	require.NoError(t, err)
+	assert.Equal(t, model.SynthesisReport{
+		Synthesized: []model.Asymmetry{
+			{EntityRef: a, Field: "spec.children", TargetRef: d, InverseField: "spec.parent", InverseRefs: []model.EntityRef{}},
+			{EntityRef: e, Field: "spec.parent", TargetRef: a, InverseField: "spec.children", InverseRefs: []model.EntityRef{b, d, f}},
+		},
+		Skipped: []model.Asymmetry{
+			{EntityRef: a, Field: "spec.children", TargetRef: b, InverseField: "spec.parent", InverseRefs: []model.EntityRef{c}},
+			{EntityRef: a, Field: "spec.children", TargetRef: f, InverseField: "spec.parent", InverseRefs: []model.EntityRef{}},
+			{EntityRef: c, Field: "spec.children", TargetRef: f, InverseField: "spec.parent", InverseRefs: []model.EntityRef{}},
+		},
+	}, report)
+
+	group, err := st.ReadGroup(ctx, d)
+	require.NoError(t, err)
+	assert.Equal(t, a, group.Spec.Parent, "a child with no parent")
+	group, err = st.ReadGroup(ctx, a)
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{b, d, f, e}, group.Spec.Children, "a parent missing a child")
+	group, err = st.ReadGroup(ctx, b)
+	require.NoError(t, err)
+	assert.Equal(t, c, group.Spec.Parent, "a child with another parent is not changed")
+
+	asymmetries, err := Asymmetries(ctx, st)
+	require.NoError(t, err)
+	assert.Equal(t, report.Skipped, asymmetries, "only the skipped asymmetries are left")
+}
+
+func TestSynthesize_Dependencies(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemoryStore()
+	web, api, db := componentRef("web"), componentRef("api"), model.EntityRef{Kind: model.KindResource, Namespace: "default", Name: "db"}
+	spec := func(dependsOn []model.EntityRef, dependencyOf []model.EntityRef) model.ComponentSpec {
+		return model.ComponentSpec{
+			Type:         model.ComponentTypeService,
+			Lifecycle:    model.ComponentLifecycleProduction,
+			DependsOn:    dependsOn,
+			DependencyOf: dependencyOf,
+		}
+	}
+	_, err := st.CreateComponent(ctx, model.Component{Entity: entity(web), Spec: spec([]model.EntityRef{api}, nil)})
+	require.NoError(t, err)
+	_, err = st.CreateComponent(ctx, model.Component{Entity: entity(api), Spec: spec([]model.EntityRef{db}, []model.EntityRef{web})})
+	require.NoError(t, err)
+	_, err = st.CreateResource(ctx, model.Resource{
+		Entity: entity(db),
+		Spec: model.ResourceSpec{
+			Type:         "database",
+			DependencyOf: []model.EntityRef{web},
+		},
+	})
+	require.NoError(t, err)
+
+	report, err := Synthesize(ctx, st)
+	require.NoError(t, err)
+	assert.Equal(t, model.SynthesisReport{
+		Synthesized: []model.Asymmetry{
+			{EntityRef: api, Field: "spec.dependsOn", TargetRef: db, InverseField: "spec.dependencyOf", InverseRefs: []model.EntityRef{web}},
+			{EntityRef: db, Field: "spec.dependencyOf", TargetRef: web, InverseField: "spec.dependsOn", InverseRefs: []model.EntityRef{api}},
+		},
+		Skipped: []model.Asymmetry{},
+	}, report)
+
+	resource, err := st.ReadResource(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{web, api}, resource.Spec.DependencyOf)
+	assert.Equal(t, int64(2), resource.Version, "the resource is updated")
+	component, err := st.ReadComponent(ctx, web)
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{api, db}, component.Spec.DependsOn)
+
+	asymmetries, err := Asymmetries(ctx, st)
+	require.NoError(t, err)
+	assert.Empty(t, asymmetries)
+
+	report, err = Synthesize(ctx, st)
+	require.NoError(t, err)
+	assert.Empty(t, report.Synthesized, "nothing is left to synthesize")
+}
@@ -0,0 +1,443 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultNamespace = "default"
+	listPageSize     = 100
+)
+
+// Processor periodically reads the targets of every Location entity in a
+// store, and creates or updates the entities that the targets describe. Since
+// anyone who can write a location can name any target, only targets under
+// one of the processor's root directories are read.
+type Processor struct {
+	store    store.Store
+	interval time.Duration
+	roots    []string
+}
+
+func NewProcessor(st store.Store, interval time.Duration, roots []string) *Processor {
+	return &Processor{
+		store:    st,
+		interval: interval,
+		roots:    roots,
+	}
+}
+
+// Run processes all locations immediately and then once every interval,
+// until the context is done.
+func (p *Processor) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.ProcessAll(ctx); err != nil {
+			slog.Error("failed to process locations", "error", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessAll processes every location in the store. Failures for individual
+// locations do not stop the others from being processed; they are returned
+// together.
+func (p *Processor) ProcessAll(ctx context.Context) error {
+	var errs []error
+	pagination := store.Pagination{
+		Limit: listPageSize,
+	}
+	for {
+		refs, nextPagination, err := p.store.ListLocations(ctx, nil, store.Ordering{OrderBy: store.OrderByName}, pagination)
+		if err != nil {
+			return fmt.Errorf("failed to list locations: %w", err)
+		}
+		for _, ref := range refs {
+			location, err := p.store.ReadLocation(ctx, ref)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to read location %s: %w", ref, err))
+				continue
+			}
+			if err := p.ProcessLocation(ctx, location); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(refs) < pagination.Limit {
+			break
+		}
+		pagination = nextPagination
+	}
+	return errors.Join(errs...)
+}
+
+// ProcessLocation reads each target of a location and stores the entities
+// found there. Entities that an earlier read of a target stored, but that are
+// no longer in it, are deleted. Changes are recorded in entity history as made
+// by the location, unless the context already names an actor.
+func (p *Processor) ProcessLocation(ctx context.Context, location model.Location) error {
+	if store.ActorFromContext(ctx) == "" {
+		ctx = store.WithActor(ctx, location.EntityRef().String())
+	}
+	var errs []error
+	for _, target := range location.Spec.AllTargets() {
+		target, err := resolveTarget(location, target)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("refused target %s of location %s: %w", target, location.EntityRef(), err))
+			continue
+		}
+		source := fmt.Sprintf("%s:%s", locationType(location), target)
+
+		if err := p.checkTarget(target); err != nil {
+			errs = append(errs, fmt.Errorf("refused target %s of location %s: %w", source, location.EntityRef(), err))
+			continue
+		}
+		data, err := readTarget(locationType(location), target)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read target %s of location %s: %w", source, location.EntityRef(), err))
+			continue
+		}
+		refs, err := p.ingest(ctx, data, source)
+		for _, ref := range refs {
+			slog.Debug("ingested entity", "entityRef", ref.String(), "location", source)
+		}
+		if err != nil {
+			// Entities that could not be stored this time are still in the
+			// target, so nothing is pruned.
+			errs = append(errs, fmt.Errorf("failed to ingest target %s of location %s: %w", source, location.EntityRef(), err))
+			continue
+		}
+		if err := p.prune(ctx, source, refs); err != nil {
+			errs = append(errs, fmt.Errorf("failed to prune target %s of location %s: %w", source, location.EntityRef(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func locationType(location model.Location) string {
+	if location.Spec.Type == "" {
+		return model.LocationTypeFile
+	}
+	return location.Spec.Type
+}
+
+// resolveTarget makes a relative target relative to the file that the
+// location itself was ingested from. A relative target of a location that was
+// not ingested has nothing to be relative to, and is an error.
+func resolveTarget(location model.Location, target string) (string, error) {
+	if filepath.IsAbs(target) {
+		return target, nil
+	}
+	managedBy := location.Metadata.Annotations[model.AnnotationManagedByLocation]
+	_, parent, found := strings.Cut(managedBy, ":")
+	if !found || parent == "" {
+		return target, errors.New("a relative target is only allowed in a location that was itself ingested")
+	}
+	return filepath.Join(filepath.Dir(parent), target), nil
+}
+
+// checkTarget checks that a target lies under one of the root directories,
+// once symbolic links and ".." elements are resolved.
+func (p *Processor) checkTarget(target string) error {
+	if len(p.roots) == 0 {
+		return errors.New("no ingest roots are configured")
+	}
+	path, err := filepath.Abs(target)
+	if err != nil {
+		return err
+	}
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+	for _, root := range p.roots {
+		root, err := filepath.Abs(root)
+		if err != nil {
+			return err
+		}
+		root, err = filepath.EvalSymlinks(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not under an ingest root", target)
+}
+
+func readTarget(targetType string, target string) ([]byte, error) {
+	switch targetType {
+	case model.LocationTypeFile:
+		return os.ReadFile(target)
+	case model.LocationTypeGit:
+		return readGitTarget(target)
+	default:
+		return nil, fmt.Errorf("unsupported location type %s", targetType)
+	}
+}
+
+// readGitTarget reads a file as committed at HEAD in the local git checkout
+// that contains it, ignoring any uncommitted changes.
+func readGitTarget(target string) ([]byte, error) {
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(absTarget)
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find git checkout for %s: %w", target, err)
+	}
+	root, err := filepath.EvalSymlinks(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, err
+	}
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, err
+	}
+	relDir, err := filepath.Rel(root, resolvedDir)
+	if err != nil {
+		return nil, err
+	}
+	relPath := filepath.ToSlash(filepath.Join(relDir, filepath.Base(absTarget)))
+	data, err := exec.Command("git", "-C", root, "show", "HEAD:"+relPath).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from HEAD: %w", relPath, err)
+	}
+	return data, nil
+}
+
+// prune deletes the entities that were ingested from a source, but that are
+// not among those just ingested from it. They are soft-deleted, so they can
+// still be restored.
+func (p *Processor) prune(ctx context.Context, source string, refs []model.EntityRef) error {
+	filters := []store.Filter{
+		{Key: store.AnnotationFilterKeyPrefix + model.AnnotationManagedByLocation, Value: source},
+	}
+	pagination := store.Pagination{
+		Limit: listPageSize,
+	}
+	// All of the stale refs are found before any are deleted, since deleting
+	// them would shift the pages.
+	var stale []model.EntityRef
+	for {
+		managed, nextPagination, err := p.store.ListEntities(ctx, nil, filters, store.Ordering{}, pagination)
+		if err != nil {
+			return fmt.Errorf("failed to list entities managed by %s: %w", source, err)
+		}
+		for _, ref := range managed {
+			if !slices.Contains(refs, ref) {
+				stale = append(stale, ref)
+			}
+		}
+		if len(managed) < pagination.Limit {
+			break
+		}
+		pagination = nextPagination
+	}
+
+	var errs []error
+	for _, ref := range stale {
+		if err := p.delete(ctx, ref); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %s: %w", ref, err))
+			continue
+		}
+		slog.Debug("deleted entity no longer in location", "entityRef", ref.String(), "location", source)
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Processor) delete(ctx context.Context, ref model.EntityRef) error {
+	var err error
+	switch ref.Kind {
+	case model.KindComponent:
+		_, err = p.store.DeleteComponent(ctx, ref, 0)
+	case model.KindAPI:
+		_, err = p.store.DeleteAPI(ctx, ref, 0)
+	case model.KindUser:
+		_, err = p.store.DeleteUser(ctx, ref, 0)
+	case model.KindGroup:
+		_, err = p.store.DeleteGroup(ctx, ref, 0)
+	case model.KindSystem:
+		_, err = p.store.DeleteSystem(ctx, ref, 0)
+	case model.KindResource:
+		_, err = p.store.DeleteResource(ctx, ref, 0)
+	case model.KindDomain:
+		_, err = p.store.DeleteDomain(ctx, ref, 0)
+	case model.KindLocation:
+		_, err = p.store.DeleteLocation(ctx, ref, 0)
+	case model.KindTemplate:
+		_, err = p.store.DeleteTemplate(ctx, ref, 0)
+	default:
+		err = fmt.Errorf("unsupported kind %s", ref.Kind)
+	}
+	return err
+}
+
+// ingest creates or updates every entity in a (possibly multi-document) YAML
+// stream, returning the refs of the entities that were stored.
+func (p *Processor) ingest(ctx context.Context, data []byte, source string) ([]model.EntityRef, error) {
+	var refs []model.EntityRef
+	var errs []error
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse YAML: %w", err))
+			break
+		}
+		ref, err := p.ingestDocument(ctx, &node, source)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs, errors.Join(errs...)
+}
+
+func (p *Processor) ingestDocument(ctx context.Context, node *yaml.Node, source string) (model.EntityRef, error) {
+	var header struct {
+		Kind string `yaml:"kind"`
+	}
+	if err := node.Decode(&header); err != nil {
+		return model.EntityRef{}, fmt.Errorf("failed to parse entity: %w", err)
+	}
+
+	st := p.store
+	switch kind := strings.ToLower(header.Kind); kind {
+	case model.KindComponent:
+		return upsert(ctx, node, source, func(c *model.Component) *model.Entity { return &c.Entity },
+			st.ReadComponent, st.CreateComponent, st.UpdateComponent)
+	case model.KindAPI:
+		return upsert(ctx, node, source, func(a *model.API) *model.Entity { return &a.Entity },
+			st.ReadAPI, st.CreateAPI, st.UpdateAPI)
+	case model.KindUser:
+		return upsert(ctx, node, source, func(u *model.User) *model.Entity { return &u.Entity },
+			st.ReadUser, st.CreateUser, st.UpdateUser)
+	case model.KindGroup:
+		return upsert(ctx, node, source, func(g *model.Group) *model.Entity { return &g.Entity },
+			st.ReadGroup, st.CreateGroup, st.UpdateGroup)
+	case model.KindSystem:
+		return upsert(ctx, node, source, func(s *model.System) *model.Entity { return &s.Entity },
+			st.ReadSystem, st.CreateSystem, st.UpdateSystem)
+	case model.KindResource:
+		return upsert(ctx, node, source, func(r *model.Resource) *model.Entity { return &r.Entity },
+			st.ReadResource, st.CreateResource, st.UpdateResource)
+	case model.KindDomain:
+		return upsert(ctx, node, source, func(d *model.Domain) *model.Entity { return &d.Entity },
+			st.ReadDomain, st.CreateDomain, st.UpdateDomain)
+	case model.KindLocation:
+		return upsert(ctx, node, source, func(l *model.Location) *model.Entity { return &l.Entity },
+			st.ReadLocation, st.CreateLocation, st.UpdateLocation)
+	case model.KindTemplate:
+		return upsert(ctx, node, source, func(t *model.Template) *model.Entity { return &t.Entity },
+			st.ReadTemplate, st.CreateTemplate, st.UpdateTemplate)
+	default:
+		return model.EntityRef{}, fmt.Errorf("unsupported kind %s", header.Kind)
+	}
+}
+
+// upsert decodes an entity of type T, records where it came from, and either
+// updates the stored entity with the same ref or creates a new one. A stored
+// entity that is managed by another location, and not by this source, is
+// left alone and reported as a conflict.
+func upsert[T any](ctx context.Context, node *yaml.Node, source string, entityOf func(*T) *model.Entity,
+	read func(context.Context, model.EntityRef) (T, error), create func(context.Context, T) (T, error), update func(context.Context, T) (T, error)) (model.EntityRef, error) {
+	var t T
+	if err := node.Decode(&t); err != nil {
+		return model.EntityRef{}, fmt.Errorf("failed to parse entity: %w", err)
+	}
+
+	e := entityOf(&t)
+	e.Kind = strings.ToLower(e.Kind)
+	if e.Metadata.Namespace == "" {
+		e.Metadata.Namespace = defaultNamespace
+	}
+	if e.Metadata.Annotations == nil {
+		e.Metadata.Annotations = make(map[string]string)
+	}
+	e.Metadata.Annotations[model.AnnotationManagedByLocation] = source
+	ref := e.EntityRef()
+
+	existing, err := read(ctx, ref)
+	if errors.Is(err, store.ErrNotFound) {
+		if _, err := create(ctx, t); err != nil {
+			return ref, fmt.Errorf("failed to create %s: %w", ref, err)
+		}
+		return ref, nil
+	}
+	if err != nil {
+		return ref, fmt.Errorf("failed to read %s: %w", ref, err)
+	}
+	if managedBy := entityOf(&existing).Metadata.Annotations[model.AnnotationManagedByLocation]; managedBy != "" && managedBy != source {
+		return ref, fmt.Errorf("%s is managed by %s: %w", ref, managedBy, store.ErrConflict)
+	}
+
+	same, err := unchanged(t, existing, entityOf)
+	if err != nil {
+		return ref, fmt.Errorf("failed to compare %s: %w", ref, err)
+	}
+	if same {
+		return ref, nil
+	}
+
+	// Updating the version that was read means that a change made by anyone
+	// else in the meantime fails the update, rather than being overwritten.
+	e.ID = entityOf(&existing).ID
+	e.Version = entityOf(&existing).Version
+	if _, err := update(ctx, t); err != nil {
+		return ref, fmt.Errorf("failed to update %s: %w", ref, err)
+	}
+	return ref, nil
+}
+
+// unchanged reports whether an ingested entity is the same as the stored one,
+// apart from the fields that the store keeps, so that ingesting a target that
+// has not changed does not make a new version of every entity in it.
+func unchanged[T any](ingested T, existing T, entityOf func(*T) *model.Entity) (bool, error) {
+	var docs [][]byte
+	for _, t := range []T{ingested, existing} {
+		e := entityOf(&t)
+		e.ID = 0
+		e.Version = 0
+		e.Relations = nil
+		e.Metadata.UID = ""
+		e.Metadata.Etag = ""
+		e.Metadata.CreatedAt = time.Time{}
+		e.Metadata.UpdatedAt = time.Time{}
+		doc, err := yaml.Marshal(t)
+		if err != nil {
+			return false, err
+		}
+		docs = append(docs, doc)
+	}
+	return bytes.Equal(docs[0], docs[1]), nil
+}
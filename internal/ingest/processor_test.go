@@ -0,0 +1,334 @@
+package ingest
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const componentsYAML = `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: orders
+spec:
+  type: service
+  lifecycle: production
+  owner: group:default/payments
+  dependsOn:
+    - resource:default/orders-db
+---
+apiVersion: backstage.io/v1alpha1
+kind: Resource
+metadata:
+  name: orders-db
+  namespace: default
+spec:
+  type: database
+  owner: group:default/payments
+`
+
+const nestedLocationYAML = `apiVersion: backstage.io/v1alpha1
+kind: Location
+metadata:
+  name: nested
+spec:
+  type: file
+  target: ./components.yaml
+`
+
+func testStore(t *testing.T) store.Store {
+	st, err := store.NewSqliteStore("file::memory:")
+	require.NoError(t, err)
+	return st
+}
+
+func writeFile(t *testing.T, path string, contents string) {
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+}
+
+func testLocation(name string, locationType string, targets ...string) model.Location {
+	return model.Location{
+		Entity: model.Entity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       model.KindLocation,
+			Metadata: model.Metadata{
+				Namespace: "default",
+				Name:      name,
+			},
+		},
+		Spec: model.LocationSpec{
+			Type:    locationType,
+			Targets: targets,
+		},
+	}
+}
+
+func TestProcessAll_File(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	componentsPath := filepath.Join(dir, "components.yaml")
+	writeFile(t, componentsPath, componentsYAML)
+
+	st := testStore(t)
+	_, err := st.CreateLocation(ctx, testLocation("root", model.LocationTypeFile, componentsPath))
+	require.NoError(t, err)
+
+	p := NewProcessor(st, 0, []string{dir})
+	require.NoError(t, p.ProcessAll(ctx))
+
+	component, err := st.ReadComponent(ctx, model.EntityRef{Kind: model.KindComponent, Namespace: "default", Name: "orders"})
+	require.NoError(t, err)
+	assert.Equal(t, model.ComponentLifecycleProduction, component.Spec.Lifecycle)
+	assert.Equal(t, "file:"+componentsPath, component.Metadata.Annotations[model.AnnotationManagedByLocation])
+	revisions, _, err := st.ListRevisions(ctx, component.EntityRef(), store.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	assert.Equal(t, "location:default/root", revisions[0].Actor)
+
+	resource, err := st.ReadResource(ctx, model.EntityRef{Kind: model.KindResource, Namespace: "default", Name: "orders-db"})
+	require.NoError(t, err)
+	assert.Equal(t, "database", resource.Spec.Type)
+
+	// a second pass updates rather than duplicates
+	writeFile(t, componentsPath, componentsYAML[:len(componentsYAML)-1]+"\n  system: system:default/orders\n")
+	require.NoError(t, p.ProcessAll(ctx))
+
+	resource, err = st.ReadResource(ctx, resource.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, model.KindSystem, resource.Spec.System.Kind)
+	assert.Equal(t, "orders", resource.Spec.System.Name)
+}
+
+func TestProcessAll_Unchanged(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	componentsPath := filepath.Join(dir, "components.yaml")
+	writeFile(t, componentsPath, componentsYAML)
+
+	st := testStore(t)
+	_, err := st.CreateLocation(ctx, testLocation("root", model.LocationTypeFile, componentsPath))
+	require.NoError(t, err)
+
+	p := NewProcessor(st, 0, []string{dir})
+	require.NoError(t, p.ProcessAll(ctx))
+	require.NoError(t, p.ProcessAll(ctx))
+
+	ref := model.EntityRef{Kind: model.KindComponent, Namespace: "default", Name: "orders"}
+	component, err := st.ReadComponent(ctx, ref)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), component.Version, "ingesting an unchanged file makes no new version")
+	revisions, _, err := st.ListRevisions(ctx, ref, store.Pagination{})
+	require.NoError(t, err)
+	assert.Len(t, revisions, 1)
+}
+
+func TestProcessAll_UnchangedKeepsETag(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	componentsPath := filepath.Join(dir, "components.yaml")
+	writeFile(t, componentsPath, componentsYAML)
+
+	st := testStore(t)
+	_, err := st.CreateLocation(ctx, testLocation("root", model.LocationTypeFile, componentsPath))
+	require.NoError(t, err)
+	p := NewProcessor(st, 0, []string{dir})
+	require.NoError(t, p.ProcessAll(ctx))
+
+	ref := model.EntityRef{Kind: model.KindComponent, Namespace: "default", Name: "orders"}
+	component, err := st.ReadComponent(ctx, ref)
+	require.NoError(t, err)
+	etag := component.Metadata.Etag
+
+	require.NoError(t, p.ProcessAll(ctx))
+
+	current, err := st.ReadComponent(ctx, ref)
+	require.NoError(t, err)
+	assert.Equal(t, etag, current.Metadata.Etag)
+	// an update made with the version read before the pass still applies,
+	// as one made with If-Match would
+	component.Metadata.Title = "Orders"
+	_, err = st.UpdateComponent(ctx, component)
+	assert.NoError(t, err)
+}
+
+func TestProcessAll_NestedLocation(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "components.yaml"), componentsYAML)
+	rootPath := filepath.Join(dir, "catalog-info.yaml")
+	writeFile(t, rootPath, nestedLocationYAML)
+
+	st := testStore(t)
+	_, err := st.CreateLocation(ctx, testLocation("root", model.LocationTypeFile, rootPath))
+	require.NoError(t, err)
+
+	p := NewProcessor(st, 0, []string{dir})
+	require.NoError(t, p.ProcessAll(ctx))
+	nested, err := st.ReadLocation(ctx, model.EntityRef{Kind: model.KindLocation, Namespace: "default", Name: "nested"})
+	require.NoError(t, err)
+	assert.Equal(t, "file:"+rootPath, nested.Metadata.Annotations[model.AnnotationManagedByLocation])
+
+	// the nested location is picked up on the next pass, relative to its file
+	require.NoError(t, p.ProcessAll(ctx))
+	_, err = st.ReadComponent(ctx, model.EntityRef{Kind: model.KindComponent, Namespace: "default", Name: "orders"})
+	assert.NoError(t, err)
+}
+
+func TestProcessAll_Errors(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	badPath := filepath.Join(dir, "bad.yaml")
+	writeFile(t, badPath, "kind: Widget\nmetadata:\n  name: w\n")
+
+	st := testStore(t)
+	_, err := st.CreateLocation(ctx, testLocation("root", model.LocationTypeFile, filepath.Join(dir, "missing.yaml"), badPath))
+	require.NoError(t, err)
+
+	err = NewProcessor(st, 0, []string{dir}).ProcessAll(ctx)
+	assert.ErrorContains(t, err, "missing.yaml")
+	assert.ErrorContains(t, err, "unsupported kind Widget")
+}
+
+func TestProcessAll_OutsideRoots(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	require.NoError(t, os.Mkdir(root, 0o755))
+	outsidePath := filepath.Join(dir, "components.yaml")
+	writeFile(t, outsidePath, componentsYAML)
+
+	st := testStore(t)
+	_, err := st.CreateLocation(ctx, testLocation("root", model.LocationTypeFile,
+		outsidePath, filepath.Join(root, "..", "components.yaml")))
+	require.NoError(t, err)
+
+	err = NewProcessor(st, 0, []string{root}).ProcessAll(ctx)
+	assert.ErrorContains(t, err, "not under an ingest root")
+	_, err = st.ReadComponent(ctx, model.EntityRef{Kind: model.KindComponent, Namespace: "default", Name: "orders"})
+	assert.ErrorIs(t, err, store.ErrNotFound, "targets outside the roots are not read")
+
+	err = NewProcessor(st, 0, nil).ProcessAll(ctx)
+	assert.ErrorContains(t, err, "no ingest roots")
+}
+
+func TestProcessAll_ManagedElsewhere(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	componentsPath := filepath.Join(dir, "components.yaml")
+	writeFile(t, componentsPath, componentsYAML)
+
+	st := testStore(t)
+	owned := model.Component{
+		Entity: model.Entity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       model.KindComponent,
+			Metadata: model.Metadata{
+				Namespace:   "default",
+				Name:        "orders",
+				Annotations: map[string]string{model.AnnotationManagedByLocation: "file:/elsewhere.yaml"},
+			},
+		},
+		Spec: model.ComponentSpec{Type: "service", Lifecycle: model.ComponentLifecycleExperimental, Owner: model.EntityRef{Name: "payments"}},
+	}
+	_, err := st.CreateComponent(ctx, owned)
+	require.NoError(t, err)
+	_, err = st.CreateLocation(ctx, testLocation("root", model.LocationTypeFile, componentsPath))
+	require.NoError(t, err)
+
+	err = NewProcessor(st, 0, []string{dir}).ProcessAll(ctx)
+	assert.ErrorIs(t, err, store.ErrConflict)
+	assert.ErrorContains(t, err, "file:/elsewhere.yaml")
+
+	component, err := st.ReadComponent(ctx, owned.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, model.ComponentLifecycleExperimental, component.Spec.Lifecycle, "the entity is left alone")
+	_, err = st.ReadResource(ctx, model.EntityRef{Kind: model.KindResource, Namespace: "default", Name: "orders-db"})
+	assert.NoError(t, err, "other entities in the target are still ingested")
+}
+
+func TestProcessAll_Prune(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	componentsPath := filepath.Join(dir, "components.yaml")
+	writeFile(t, componentsPath, componentsYAML)
+
+	st := testStore(t)
+	_, err := st.CreateLocation(ctx, testLocation("root", model.LocationTypeFile, componentsPath))
+	require.NoError(t, err)
+	p := NewProcessor(st, 0, []string{dir})
+	require.NoError(t, p.ProcessAll(ctx))
+
+	componentRef := model.EntityRef{Kind: model.KindComponent, Namespace: "default", Name: "orders"}
+	resourceRef := model.EntityRef{Kind: model.KindResource, Namespace: "default", Name: "orders-db"}
+	componentYAML, _, _ := strings.Cut(componentsYAML, "---\n")
+
+	// a target that fails to parse prunes nothing
+	writeFile(t, componentsPath, componentYAML+"---\n: bad\n")
+	assert.Error(t, p.ProcessAll(ctx))
+	_, err = st.ReadResource(ctx, resourceRef)
+	assert.NoError(t, err)
+
+	writeFile(t, componentsPath, componentYAML)
+	require.NoError(t, p.ProcessAll(ctx))
+	_, err = st.ReadComponent(ctx, componentRef)
+	assert.NoError(t, err)
+	_, err = st.ReadResource(ctx, resourceRef)
+	assert.ErrorIs(t, err, store.ErrNotFound, "an entity removed from the target is deleted")
+	deleted, _, err := st.ListDeletedEntities(ctx, nil, store.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{resourceRef}, deleted, "and can be restored")
+}
+
+func TestProcessAll_RelativeTarget(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "components.yaml"), componentsYAML)
+
+	st := testStore(t)
+	_, err := st.CreateLocation(ctx, testLocation("root", model.LocationTypeFile, "components.yaml"))
+	require.NoError(t, err)
+
+	err = NewProcessor(st, 0, []string{dir}).ProcessAll(ctx)
+	assert.ErrorContains(t, err, "relative target")
+	_, err = st.ReadComponent(ctx, model.EntityRef{Kind: model.KindComponent, Namespace: "default", Name: "orders"})
+	assert.ErrorIs(t, err, store.ErrNotFound, "the target is not read relative to the working directory")
+}
+
+func TestProcessAll_Git(t *testing.T) {
+	ctx := context.Background()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "catalog"), 0o755))
+	componentsPath := filepath.Join(dir, "catalog", "components.yaml")
+	writeFile(t, componentsPath, componentsYAML)
+	git("add", ".")
+	git("commit", "-q", "-m", "catalog")
+
+	// uncommitted changes are ignored
+	writeFile(t, componentsPath, "not: [valid")
+
+	st := testStore(t)
+	_, err := st.CreateLocation(ctx, testLocation("root", model.LocationTypeGit, componentsPath))
+	require.NoError(t, err)
+
+	require.NoError(t, NewProcessor(st, 0, []string{dir}).ProcessAll(ctx))
+	component, err := st.ReadComponent(ctx, model.EntityRef{Kind: model.KindComponent, Namespace: "default", Name: "orders"})
+	require.NoError(t, err)
+	assert.Equal(t, "git:"+componentsPath, component.Metadata.Annotations[model.AnnotationManagedByLocation])
+}
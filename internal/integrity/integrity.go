@@ -0,0 +1,107 @@
+// Package integrity checks that the entity refs in entity specs resolve to
+// stored entities.
+package integrity
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+)
+
+// Mode is how strictly entity refs are checked when entities are written.
+type Mode string
+
+const (
+	// ModeOff stores entities without checking their refs.
+	ModeOff = Mode("off")
+	// ModeWarn stores entities with unresolved refs, but warns about them.
+	ModeWarn = Mode("warn")
+	// ModeStrict rejects entities with unresolved refs, and blocks the
+	// deletion of entities that others still refer to. Unresolved refs in
+	// mutual fields are only warned about; see Mutual.
+	ModeStrict = Mode("strict")
+)
+
+// mutualFields are the spec fields that are paired with an inverse field in
+// the entities that they refer to, so that two entities refer to each other.
+var mutualFields = []string{"spec.dependsOn", "spec.dependencyOf", "spec.parent", "spec.children"}
+
+// ParseMode parses the name of a mode.
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(s); mode {
+	case ModeOff, ModeWarn, ModeStrict:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported integrity mode %s", s)
+	}
+}
+
+// Unresolved returns the refs in the spec of an entity of any kind that name
+// no stored entity. A ref with no kind, which some fields allow, cannot be
+// resolved. The refs are all looked up at once.
+func Unresolved(ctx context.Context, st store.Store, entity any) ([]model.SpecRef, error) {
+	specRefs := model.SpecRefs(entity)
+	refs := make([]model.EntityRef, 0, len(specRefs))
+	for _, specRef := range specRefs {
+		refs = append(refs, specRef.Ref)
+	}
+	existing, err := st.ListExistingRefs(ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up refs: %w", err)
+	}
+
+	var unresolved []model.SpecRef
+	for _, specRef := range specRefs {
+		if specRef.Ref.Kind == "" || !slices.Contains(existing, specRef.Ref) {
+			unresolved = append(unresolved, specRef)
+		}
+	}
+	return unresolved, nil
+}
+
+// Mutual reports whether a ref is in a spec field paired with an inverse
+// field in the entity it refers to, as dependsOn is with dependencyOf and
+// parent is with children. Two entities that refer to each other this way
+// cannot both be written after the other, so strict mode lets the first one
+// written refer to the second before it exists.
+func Mutual(specRef model.SpecRef) bool {
+	return slices.Contains(mutualFields, specRef.Field)
+}
+
+// Describe describes an unresolved ref, such as for a warning.
+func Describe(specRef model.SpecRef) string {
+	return fmt.Sprintf("%s: %s does not exist", specRef.Field, specRef.Ref)
+}
+
+// Report lists every dangling ref in a store, that is, every ref in the spec
+// of an entity that names no stored entity. The refs are grouped by the owner
+// of the entity with the ref; refs from entities with no owner come first.
+func Report(ctx context.Context, st store.Store) (model.DanglingRefsReport, error) {
+	refs, err := st.ListDanglingRefs(ctx)
+	if err != nil {
+		return model.DanglingRefsReport{}, fmt.Errorf("failed to list dangling refs: %w", err)
+	}
+
+	report := model.DanglingRefsReport{
+		Owners: []model.OwnerDanglingRefs{},
+		Total:  len(refs),
+	}
+	byOwner := map[model.EntityRef][]model.DanglingRef{}
+	for _, ref := range refs {
+		byOwner[ref.Owner] = append(byOwner[ref.Owner], ref)
+	}
+	for owner, refs := range byOwner {
+		report.Owners = append(report.Owners, model.OwnerDanglingRefs{
+			Owner: owner,
+			Refs:  refs,
+		})
+	}
+	slices.SortFunc(report.Owners, func(a, b model.OwnerDanglingRefs) int {
+		return cmp.Compare(a.Owner.String(), b.Owner.String())
+	})
+	return report, nil
+}
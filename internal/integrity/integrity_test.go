@@ -0,0 +1,135 @@
+package integrity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMode(t *testing.T) {
+	for _, mode := range []Mode{ModeOff, ModeWarn, ModeStrict} {
+		parsed, err := ParseMode(string(mode))
+		assert.NoError(t, err)
+		assert.Equal(t, mode, parsed)
+	}
+	_, err := ParseMode("lenient")
+	assert.Error(t, err)
+}
+
+func TestUnresolved(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemoryStore()
+	team := model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "team"}
+	_, err := st.CreateGroup(ctx, model.Group{
+		Entity: model.Entity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       model.KindGroup,
+			Metadata:   model.Metadata{Namespace: team.Namespace, Name: team.Name},
+		},
+		Spec: model.GroupSpec{Type: "team"},
+	})
+	require.NoError(t, err)
+
+	component := model.Component{
+		Entity: model.Entity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       model.KindComponent,
+			Metadata:   model.Metadata{Namespace: "default", Name: "service"},
+		},
+		Spec: model.ComponentSpec{
+			Owner:        model.EntityRef{Name: "team"},
+			ProvidesAPIs: []model.EntityRef{model.TestAPI1EntityRef},
+			DependsOn:    []model.EntityRef{{Name: "db"}},
+		},
+	}
+	unresolved, err := Unresolved(ctx, st, component)
+	require.NoError(t, err)
+	assert.Equal(t, []model.SpecRef{
+		{Field: "spec.providesApis", Relation: model.RelationProvidesAPI, Ref: model.TestAPI1EntityRef},
+		{Field: "spec.dependsOn", Relation: model.RelationDependsOn, Ref: model.EntityRef{Namespace: "default", Name: "db"}},
+	}, unresolved)
+	assert.Equal(t, "spec.providesApis: api:default/api1 does not exist", Describe(unresolved[0]))
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Unresolved(ctx, st, component)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestUnresolved_OneLookup(t *testing.T) {
+	ctx := context.Background()
+	st := &store.StoreMock{
+		ListExistingRefsFunc: func(ctx context.Context, refs []model.EntityRef) ([]model.EntityRef, error) {
+			return []model.EntityRef{model.TestAPI1EntityRef}, nil
+		},
+	}
+	component := model.Component{
+		Entity: model.Entity{Kind: model.KindComponent, Metadata: model.Metadata{Namespace: "default", Name: "service"}},
+		Spec: model.ComponentSpec{
+			ProvidesAPIs: []model.EntityRef{model.TestAPI1EntityRef},
+			ConsumesAPIs: []model.EntityRef{model.TestAPI2EntityRef},
+		},
+	}
+
+	unresolved, err := Unresolved(ctx, st, component)
+	require.NoError(t, err)
+	assert.Equal(t, []model.SpecRef{
+		{Field: "spec.consumesApis", Relation: model.RelationConsumesAPI, Ref: model.TestAPI2EntityRef},
+	}, unresolved)
+	calls := st.ListExistingRefsCalls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, []model.EntityRef{model.TestAPI1EntityRef, model.TestAPI2EntityRef}, calls[0].Refs)
+}
+
+func TestMutual(t *testing.T) {
+	for _, field := range []string{"spec.dependsOn", "spec.dependencyOf", "spec.parent", "spec.children"} {
+		assert.True(t, Mutual(model.SpecRef{Field: field}), field)
+	}
+	for _, field := range []string{"spec.owner", "spec.members", "spec.memberOf", "spec.providesApis"} {
+		assert.False(t, Mutual(model.SpecRef{Field: field}), field)
+	}
+}
+
+func TestReport(t *testing.T) {
+	ctx := context.Background()
+	team := model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "team"}
+	st := &store.StoreMock{
+		ListDanglingRefsFunc: func(ctx context.Context) ([]model.DanglingRef, error) {
+			return []model.DanglingRef{
+				{EntityRef: model.TestComponentEntityRef, Owner: team, Field: "spec.providesApis", TargetRef: model.TestAPI1EntityRef},
+				{EntityRef: model.TestGroupEntityRef, Field: "spec.parent", TargetRef: model.TestGroup2EntityRef},
+				{EntityRef: model.TestComponent2EntityRef, Owner: team, Field: "spec.system", TargetRef: model.TestSystemEntityRef},
+			}, nil
+		},
+	}
+
+	report, err := Report(ctx, st)
+	require.NoError(t, err)
+	assert.Equal(t, model.DanglingRefsReport{
+		Owners: []model.OwnerDanglingRefs{
+			{
+				Refs: []model.DanglingRef{
+					{EntityRef: model.TestGroupEntityRef, Field: "spec.parent", TargetRef: model.TestGroup2EntityRef},
+				},
+			},
+			{
+				Owner: team,
+				Refs: []model.DanglingRef{
+					{EntityRef: model.TestComponentEntityRef, Owner: team, Field: "spec.providesApis", TargetRef: model.TestAPI1EntityRef},
+					{EntityRef: model.TestComponent2EntityRef, Owner: team, Field: "spec.system", TargetRef: model.TestSystemEntityRef},
+				},
+			},
+		},
+		Total: 3,
+	}, report)
+
+	st.ListDanglingRefsFunc = func(ctx context.Context) ([]model.DanglingRef, error) {
+		return nil, store.ErrTimeout
+	}
+	_, err = Report(ctx, st)
+	assert.ErrorIs(t, err, store.ErrTimeout)
+}
@@ -0,0 +1,59 @@
+package purge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bhavanki/rewind/pkg/store"
+)
+
+// Purger periodically removes for good the entities in a store that were
+// deleted longer ago than a retention period. Until then, deleted entities
+// may be restored.
+type Purger struct {
+	store     store.Store
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+}
+
+func NewPurger(st store.Store, retention time.Duration, interval time.Duration) *Purger {
+	return &Purger{
+		store:     st,
+		retention: retention,
+		interval:  interval,
+		now:       time.Now,
+	}
+}
+
+// Run purges immediately and then once every interval, until the context is
+// done.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := p.PurgeOnce(ctx); err != nil {
+			slog.Error("failed to purge deleted entities", "error", err.Error())
+		} else if n > 0 {
+			slog.Info("purged deleted entities", "count", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeOnce purges the entities deleted before the retention period, and
+// returns how many were purged.
+func (p *Purger) PurgeOnce(ctx context.Context) (int64, error) {
+	n, err := p.store.PurgeEntities(ctx, p.now().Add(-p.retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge entities: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,68 @@
+package purge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeOnce(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemoryStore()
+	_, err := st.CreateComponent(ctx, model.TestFullComponent)
+	require.NoError(t, err)
+	_, err = st.CreateAPI(ctx, model.TestFullAPI)
+	require.NoError(t, err)
+	_, err = st.DeleteComponent(ctx, model.TestFullComponent.EntityRef(), 0)
+	require.NoError(t, err)
+
+	p := NewPurger(st, time.Hour, time.Minute)
+
+	n, err := p.PurgeOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "purging within the retention period")
+	refs, _, err := st.ListDeletedEntities(ctx, nil, store.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{model.TestFullComponent.EntityRef()}, refs)
+
+	p.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	n, err = p.PurgeOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "purging after the retention period")
+	refs, _, err = st.ListDeletedEntities(ctx, nil, store.Pagination{})
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+	err = st.RestoreEntity(ctx, model.TestFullComponent.EntityRef())
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	_, err = st.ReadAPI(ctx, model.TestFullAPI.EntityRef())
+	assert.NoError(t, err, "live entities are kept")
+}
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	st := store.NewMemoryStore()
+	_, err := st.CreateComponent(ctx, model.TestFullComponent)
+	require.NoError(t, err)
+	_, err = st.DeleteComponent(ctx, model.TestFullComponent.EntityRef(), 0)
+	require.NoError(t, err)
+
+	p := NewPurger(st, 0, time.Hour)
+	p.now = func() time.Time { return time.Now().Add(time.Minute) }
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		refs, _, err := st.ListDeletedEntities(context.Background(), nil, store.Pagination{})
+		return err == nil && len(refs) == 0
+	}, time.Second, 10*time.Millisecond, "purging when run")
+	cancel()
+	<-done
+}
@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/gin-gonic/gin"
+)
+
+// ListDomainSystems lists the systems that reference a domain through their
+// spec.domain field.
+func ListDomainSystems(c *gin.Context, st store.Store) {
+	domainRef := expectedEntityRef(c)
+	if domainRef.Kind != model.KindDomain {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported kind %s", domainRef.Kind)})
+		return
+	}
+
+	filters, ordering, pagination, err := processListParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("bad list parameter: %s", err)})
+		return
+	}
+
+	if err := store.ValidateFilters([]string{model.KindSystem}, filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("bad list parameter: %s", err)})
+		return
+	}
+
+	domain, err := st.ReadDomain(c.Request.Context(), domainRef)
+	if err != nil {
+		respondStoreError(c, err, "failed to read domain", domainRef)
+		return
+	}
+
+	filters = append(filters, store.Filter{
+		Key:   "spec.domain",
+		Value: domain.EntityRef().String(),
+	})
+	refs, nextPagination, err := st.ListSystems(c.Request.Context(), filters, ordering, pagination)
+	if err != nil {
+		respondStoreError(c, err, "failed to list systems for domain", domainRef)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SearchResults{
+		Results:    refs,
+		Limit:      nextPagination.Limit,
+		NextOffset: nextPagination.Offset,
+	})
+}
@@ -0,0 +1,77 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListDomainSystems(t *testing.T) {
+	refs := []model.EntityRef{
+		model.TestSystemEntityRef,
+	}
+	r := gin.Default()
+	s := &store.StoreMock{
+		ReadDomainFunc: func(ctx context.Context, ref model.EntityRef) (model.Domain, error) {
+			d := model.TestFullDomain
+			d.Metadata.Namespace = ref.Namespace
+			d.Metadata.Name = ref.Name
+			return d, nil
+		},
+		ListSystemsFunc: func(ctx context.Context, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return refs, store.Pagination{
+				Limit:  50,
+				Offset: 1,
+			}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/domain/default/domain/systems", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results model.SearchResults
+	err = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Equal(t, refs, results.Results)
+	assert.Equal(t, 1, results.NextOffset)
+
+	readCalls := s.ReadDomainCalls()
+	assert.Equal(t, 1, len(readCalls))
+	assert.Equal(t, model.TestDomainEntityRef, readCalls[0].Ref)
+
+	calls := s.ListSystemsCalls()
+	assert.Equal(t, 1, len(calls))
+	assert.Equal(t, []store.Filter{
+		{
+			Key:   "spec.domain",
+			Value: "domain:default/domain",
+		},
+	}, calls[0].Filters)
+}
+
+func TestListDomainSystems_WrongKind(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/component/default/component/systems", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -2,18 +2,20 @@ package routes
 
 import (
 	"fmt"
-	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
-	"github.com/bhavanki/rewind/internal/store"
 	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
 	"github.com/gin-gonic/gin"
 )
 
 func CreateEntity(c *gin.Context, store store.Store) {
 	expectedEntityRef := expectedEntityRef(c)
 	kind := expectedEntityRef.Kind
+	var warnings []string
+	var ok bool
 
 	switch kind {
 	case model.KindComponent:
@@ -25,11 +27,15 @@ func CreateEntity(c *gin.Context, store store.Store) {
 		if !verifyEntityRef(c, component.Entity.EntityRef(), expectedEntityRef) {
 			return
 		}
-		if _, err := store.CreateComponent(component); err != nil {
-			slog.Error("failed to store component", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store component"})
+		if warnings, ok = checkRefs(c, store, component); !ok {
 			return
 		}
+		created, err := store.CreateComponent(c.Request.Context(), component)
+		if err != nil {
+			respondStoreError(c, err, "failed to store component", expectedEntityRef)
+			return
+		}
+		c.Header("ETag", entityTag(created.Version))
 	case model.KindAPI:
 		var api model.API
 		if err := c.ShouldBindYAML(&api); err != nil {
@@ -39,11 +45,15 @@ func CreateEntity(c *gin.Context, store store.Store) {
 		if !verifyEntityRef(c, api.Entity.EntityRef(), expectedEntityRef) {
 			return
 		}
-		if _, err := store.CreateAPI(api); err != nil {
-			slog.Error("failed to store API", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store API"})
+		if warnings, ok = checkRefs(c, store, api); !ok {
 			return
 		}
+		created, err := store.CreateAPI(c.Request.Context(), api)
+		if err != nil {
+			respondStoreError(c, err, "failed to store API", expectedEntityRef)
+			return
+		}
+		c.Header("ETag", entityTag(created.Version))
 	case model.KindUser:
 		var user model.User
 		if err := c.ShouldBindYAML(&user); err != nil {
@@ -53,11 +63,15 @@ func CreateEntity(c *gin.Context, store store.Store) {
 		if !verifyEntityRef(c, user.Entity.EntityRef(), expectedEntityRef) {
 			return
 		}
-		if _, err := store.CreateUser(user); err != nil {
-			slog.Error("failed to store user", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store user"})
+		if warnings, ok = checkRefs(c, store, user); !ok {
+			return
+		}
+		created, err := store.CreateUser(c.Request.Context(), user)
+		if err != nil {
+			respondStoreError(c, err, "failed to store user", expectedEntityRef)
 			return
 		}
+		c.Header("ETag", entityTag(created.Version))
 	case model.KindGroup:
 		var group model.Group
 		if err := c.ShouldBindYAML(&group); err != nil {
@@ -67,65 +81,228 @@ func CreateEntity(c *gin.Context, store store.Store) {
 		if !verifyEntityRef(c, group.Entity.EntityRef(), expectedEntityRef) {
 			return
 		}
-		if _, err := store.CreateGroup(group); err != nil {
-			slog.Error("failed to store group", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store group"})
+		if warnings, ok = checkRefs(c, store, group); !ok {
+			return
+		}
+		created, err := store.CreateGroup(c.Request.Context(), group)
+		if err != nil {
+			respondStoreError(c, err, "failed to store group", expectedEntityRef)
+			return
+		}
+		c.Header("ETag", entityTag(created.Version))
+	case model.KindSystem:
+		var system model.System
+		if err := c.ShouldBindYAML(&system); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !verifyEntityRef(c, system.Entity.EntityRef(), expectedEntityRef) {
+			return
+		}
+		if warnings, ok = checkRefs(c, store, system); !ok {
+			return
+		}
+		created, err := store.CreateSystem(c.Request.Context(), system)
+		if err != nil {
+			respondStoreError(c, err, "failed to store system", expectedEntityRef)
+			return
+		}
+		c.Header("ETag", entityTag(created.Version))
+	case model.KindResource:
+		var resource model.Resource
+		if err := c.ShouldBindYAML(&resource); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !verifyEntityRef(c, resource.Entity.EntityRef(), expectedEntityRef) {
+			return
+		}
+		if warnings, ok = checkRefs(c, store, resource); !ok {
+			return
+		}
+		created, err := store.CreateResource(c.Request.Context(), resource)
+		if err != nil {
+			respondStoreError(c, err, "failed to store resource", expectedEntityRef)
+			return
+		}
+		c.Header("ETag", entityTag(created.Version))
+	case model.KindDomain:
+		var domain model.Domain
+		if err := c.ShouldBindYAML(&domain); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !verifyEntityRef(c, domain.Entity.EntityRef(), expectedEntityRef) {
+			return
+		}
+		if warnings, ok = checkRefs(c, store, domain); !ok {
+			return
+		}
+		created, err := store.CreateDomain(c.Request.Context(), domain)
+		if err != nil {
+			respondStoreError(c, err, "failed to store domain", expectedEntityRef)
 			return
 		}
+		c.Header("ETag", entityTag(created.Version))
+	case model.KindLocation:
+		var location model.Location
+		if err := c.ShouldBindYAML(&location); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !verifyEntityRef(c, location.Entity.EntityRef(), expectedEntityRef) {
+			return
+		}
+		if warnings, ok = checkRefs(c, store, location); !ok {
+			return
+		}
+		created, err := store.CreateLocation(c.Request.Context(), location)
+		if err != nil {
+			respondStoreError(c, err, "failed to store location", expectedEntityRef)
+			return
+		}
+		c.Header("ETag", entityTag(created.Version))
+	case model.KindTemplate:
+		var template model.Template
+		if err := c.ShouldBindYAML(&template); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !verifyEntityRef(c, template.Entity.EntityRef(), expectedEntityRef) {
+			return
+		}
+		if warnings, ok = checkRefs(c, store, template); !ok {
+			return
+		}
+		created, err := store.CreateTemplate(c.Request.Context(), template)
+		if err != nil {
+			respondStoreError(c, err, "failed to store template", expectedEntityRef)
+			return
+		}
+		c.Header("ETag", entityTag(created.Version))
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported kind %s", kind)})
 		return
 	}
 
-	c.Status(http.StatusCreated)
+	respondWritten(c, http.StatusCreated, warnings)
 }
 
 func ReadEntity(c *gin.Context, store store.Store) {
-	expectedEntityRef := expectedEntityRef(c)
+	readEntity(c, store, expectedEntityRef(c))
+}
+
+// ReadEntityByUID reads the entity with the UID in the path, under whatever
+// ref it has now.
+func ReadEntityByUID(c *gin.Context, store store.Store) {
+	ref, err := store.ResolveUID(c.Request.Context(), c.Param("uid"))
+	if err != nil {
+		respondStoreError(c, err, "failed to resolve entity UID", model.EntityRef{})
+		return
+	}
+	readEntity(c, store, ref)
+}
+
+// readEntity responds with an entity, or with a past version of it if the
+// version query parameter is given.
+func readEntity(c *gin.Context, store store.Store, expectedEntityRef model.EntityRef) {
+	if c.Query("version") != "" {
+		readEntityRevision(c, store, expectedEntityRef)
+		return
+	}
 	kind := expectedEntityRef.Kind
 
 	switch kind {
 	case model.KindComponent:
-		component, err := store.ReadComponent(expectedEntityRef)
+		component, err := store.ReadComponent(c.Request.Context(), expectedEntityRef)
 		if err != nil {
-			slog.Error("failed to read component", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read component"})
+			respondStoreError(c, err, "failed to read component", expectedEntityRef)
 			return
 		}
-		c.YAML(http.StatusOK, component)
+		respondEntity(c, store, &component.Entity, &component)
 	case model.KindAPI:
-		api, err := store.ReadAPI(expectedEntityRef)
+		api, err := store.ReadAPI(c.Request.Context(), expectedEntityRef)
 		if err != nil {
-			slog.Error("failed to read API", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read API"})
+			respondStoreError(c, err, "failed to read API", expectedEntityRef)
 			return
 		}
-		c.YAML(http.StatusOK, api)
+		respondEntity(c, store, &api.Entity, &api)
 	case model.KindUser:
-		user, err := store.ReadUser(expectedEntityRef)
+		user, err := store.ReadUser(c.Request.Context(), expectedEntityRef)
 		if err != nil {
-			slog.Error("failed to read user", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read user"})
+			respondStoreError(c, err, "failed to read user", expectedEntityRef)
 			return
 		}
-		c.YAML(http.StatusOK, user)
+		respondEntity(c, store, &user.Entity, &user)
 	case model.KindGroup:
-		group, err := store.ReadGroup(expectedEntityRef)
+		group, err := store.ReadGroup(c.Request.Context(), expectedEntityRef)
 		if err != nil {
-			slog.Error("failed to read group", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read group"})
+			respondStoreError(c, err, "failed to read group", expectedEntityRef)
 			return
 		}
-		c.YAML(http.StatusOK, group)
+		respondEntity(c, store, &group.Entity, &group)
+	case model.KindSystem:
+		system, err := store.ReadSystem(c.Request.Context(), expectedEntityRef)
+		if err != nil {
+			respondStoreError(c, err, "failed to read system", expectedEntityRef)
+			return
+		}
+		respondEntity(c, store, &system.Entity, &system)
+	case model.KindResource:
+		resource, err := store.ReadResource(c.Request.Context(), expectedEntityRef)
+		if err != nil {
+			respondStoreError(c, err, "failed to read resource", expectedEntityRef)
+			return
+		}
+		respondEntity(c, store, &resource.Entity, &resource)
+	case model.KindDomain:
+		domain, err := store.ReadDomain(c.Request.Context(), expectedEntityRef)
+		if err != nil {
+			respondStoreError(c, err, "failed to read domain", expectedEntityRef)
+			return
+		}
+		respondEntity(c, store, &domain.Entity, &domain)
+	case model.KindLocation:
+		location, err := store.ReadLocation(c.Request.Context(), expectedEntityRef)
+		if err != nil {
+			respondStoreError(c, err, "failed to read location", expectedEntityRef)
+			return
+		}
+		respondEntity(c, store, &location.Entity, &location)
+	case model.KindTemplate:
+		template, err := store.ReadTemplate(c.Request.Context(), expectedEntityRef)
+		if err != nil {
+			respondStoreError(c, err, "failed to read template", expectedEntityRef)
+			return
+		}
+		respondEntity(c, store, &template.Entity, &template)
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported kind %s", kind)})
 		return
 	}
 }
 
+// respondEntity responds with an entity that has been read, along with its
+// relations.
+func respondEntity(c *gin.Context, store store.Store, e *model.Entity, value any) {
+	relations, err := store.ListRelations(c.Request.Context(), e.EntityRef())
+	if err != nil {
+		respondStoreError(c, err, "failed to list relations", e.EntityRef())
+		return
+	}
+	e.Relations = relations
+	c.Header("ETag", entityTag(e.Version))
+	c.YAML(http.StatusOK, value)
+}
+
 func UpdateEntity(c *gin.Context, store store.Store) {
 	expectedEntityRef := expectedEntityRef(c)
 	kind := expectedEntityRef.Kind
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	var warnings []string
 
 	switch kind {
 	case model.KindComponent:
@@ -137,11 +314,16 @@ func UpdateEntity(c *gin.Context, store store.Store) {
 		if !verifyEntityRef(c, component.Entity.EntityRef(), expectedEntityRef) {
 			return
 		}
-		if _, err := store.UpdateComponent(component); err != nil {
-			slog.Error("failed to update component", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update component"})
+		if warnings, ok = checkRefs(c, store, component); !ok {
+			return
+		}
+		component.Version = version
+		updated, err := store.UpdateComponent(c.Request.Context(), component)
+		if err != nil {
+			respondStoreError(c, err, "failed to update component", expectedEntityRef)
 			return
 		}
+		c.Header("ETag", entityTag(updated.Version))
 	case model.KindAPI:
 		var api model.API
 		if err := c.ShouldBindYAML(&api); err != nil {
@@ -151,11 +333,16 @@ func UpdateEntity(c *gin.Context, store store.Store) {
 		if !verifyEntityRef(c, api.Entity.EntityRef(), expectedEntityRef) {
 			return
 		}
-		if _, err := store.UpdateAPI(api); err != nil {
-			slog.Error("failed to update API", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update API"})
+		if warnings, ok = checkRefs(c, store, api); !ok {
+			return
+		}
+		api.Version = version
+		updated, err := store.UpdateAPI(c.Request.Context(), api)
+		if err != nil {
+			respondStoreError(c, err, "failed to update API", expectedEntityRef)
 			return
 		}
+		c.Header("ETag", entityTag(updated.Version))
 	case model.KindUser:
 		var user model.User
 		if err := c.ShouldBindYAML(&user); err != nil {
@@ -165,11 +352,16 @@ func UpdateEntity(c *gin.Context, store store.Store) {
 		if !verifyEntityRef(c, user.Entity.EntityRef(), expectedEntityRef) {
 			return
 		}
-		if _, err := store.UpdateUser(user); err != nil {
-			slog.Error("failed to update user", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user"})
+		if warnings, ok = checkRefs(c, store, user); !ok {
 			return
 		}
+		user.Version = version
+		updated, err := store.UpdateUser(c.Request.Context(), user)
+		if err != nil {
+			respondStoreError(c, err, "failed to update user", expectedEntityRef)
+			return
+		}
+		c.Header("ETag", entityTag(updated.Version))
 	case model.KindGroup:
 		var group model.Group
 		if err := c.ShouldBindYAML(&group); err != nil {
@@ -179,62 +371,217 @@ func UpdateEntity(c *gin.Context, store store.Store) {
 		if !verifyEntityRef(c, group.Entity.EntityRef(), expectedEntityRef) {
 			return
 		}
-		if _, err := store.UpdateGroup(group); err != nil {
-			slog.Error("failed to update group", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update group"})
+		if warnings, ok = checkRefs(c, store, group); !ok {
 			return
 		}
+		group.Version = version
+		updated, err := store.UpdateGroup(c.Request.Context(), group)
+		if err != nil {
+			respondStoreError(c, err, "failed to update group", expectedEntityRef)
+			return
+		}
+		c.Header("ETag", entityTag(updated.Version))
+	case model.KindSystem:
+		var system model.System
+		if err := c.ShouldBindYAML(&system); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !verifyEntityRef(c, system.Entity.EntityRef(), expectedEntityRef) {
+			return
+		}
+		if warnings, ok = checkRefs(c, store, system); !ok {
+			return
+		}
+		system.Version = version
+		updated, err := store.UpdateSystem(c.Request.Context(), system)
+		if err != nil {
+			respondStoreError(c, err, "failed to update system", expectedEntityRef)
+			return
+		}
+		c.Header("ETag", entityTag(updated.Version))
+	case model.KindResource:
+		var resource model.Resource
+		if err := c.ShouldBindYAML(&resource); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !verifyEntityRef(c, resource.Entity.EntityRef(), expectedEntityRef) {
+			return
+		}
+		if warnings, ok = checkRefs(c, store, resource); !ok {
+			return
+		}
+		resource.Version = version
+		updated, err := store.UpdateResource(c.Request.Context(), resource)
+		if err != nil {
+			respondStoreError(c, err, "failed to update resource", expectedEntityRef)
+			return
+		}
+		c.Header("ETag", entityTag(updated.Version))
+	case model.KindDomain:
+		var domain model.Domain
+		if err := c.ShouldBindYAML(&domain); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !verifyEntityRef(c, domain.Entity.EntityRef(), expectedEntityRef) {
+			return
+		}
+		if warnings, ok = checkRefs(c, store, domain); !ok {
+			return
+		}
+		domain.Version = version
+		updated, err := store.UpdateDomain(c.Request.Context(), domain)
+		if err != nil {
+			respondStoreError(c, err, "failed to update domain", expectedEntityRef)
+			return
+		}
+		c.Header("ETag", entityTag(updated.Version))
+	case model.KindLocation:
+		var location model.Location
+		if err := c.ShouldBindYAML(&location); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !verifyEntityRef(c, location.Entity.EntityRef(), expectedEntityRef) {
+			return
+		}
+		if warnings, ok = checkRefs(c, store, location); !ok {
+			return
+		}
+		location.Version = version
+		updated, err := store.UpdateLocation(c.Request.Context(), location)
+		if err != nil {
+			respondStoreError(c, err, "failed to update location", expectedEntityRef)
+			return
+		}
+		c.Header("ETag", entityTag(updated.Version))
+	case model.KindTemplate:
+		var template model.Template
+		if err := c.ShouldBindYAML(&template); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !verifyEntityRef(c, template.Entity.EntityRef(), expectedEntityRef) {
+			return
+		}
+		if warnings, ok = checkRefs(c, store, template); !ok {
+			return
+		}
+		template.Version = version
+		updated, err := store.UpdateTemplate(c.Request.Context(), template)
+		if err != nil {
+			respondStoreError(c, err, "failed to update template", expectedEntityRef)
+			return
+		}
+		c.Header("ETag", entityTag(updated.Version))
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported kind %s", kind)})
 		return
 	}
 
-	c.Status(http.StatusAccepted)
+	respondWritten(c, http.StatusAccepted, warnings)
 }
 
 func DeleteEntity(c *gin.Context, store store.Store) {
 	expectedEntityRef := expectedEntityRef(c)
 	kind := expectedEntityRef.Kind
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	if !checkReferrers(c, store, expectedEntityRef) {
+		return
+	}
 
 	switch kind {
 	case model.KindComponent:
-		component, err := store.DeleteComponent(expectedEntityRef)
+		component, err := store.DeleteComponent(c.Request.Context(), expectedEntityRef, version)
 		if err != nil {
-			slog.Error("failed to delete component", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete component"})
+			respondStoreError(c, err, "failed to delete component", expectedEntityRef)
 			return
 		}
 		c.YAML(http.StatusOK, component)
 	case model.KindAPI:
-		api, err := store.DeleteAPI(expectedEntityRef)
+		api, err := store.DeleteAPI(c.Request.Context(), expectedEntityRef, version)
 		if err != nil {
-			slog.Error("failed to delete API", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete API"})
+			respondStoreError(c, err, "failed to delete API", expectedEntityRef)
 			return
 		}
 		c.YAML(http.StatusOK, api)
 	case model.KindUser:
-		user, err := store.DeleteUser(expectedEntityRef)
+		user, err := store.DeleteUser(c.Request.Context(), expectedEntityRef, version)
 		if err != nil {
-			slog.Error("failed to delete user", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete user"})
+			respondStoreError(c, err, "failed to delete user", expectedEntityRef)
 			return
 		}
 		c.YAML(http.StatusOK, user)
 	case model.KindGroup:
-		group, err := store.DeleteGroup(expectedEntityRef)
+		group, err := store.DeleteGroup(c.Request.Context(), expectedEntityRef, version)
 		if err != nil {
-			slog.Error("failed to delete group", "entityRef", expectedEntityRef.String(), "error", err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete group"})
+			respondStoreError(c, err, "failed to delete group", expectedEntityRef)
 			return
 		}
 		c.YAML(http.StatusOK, group)
+	case model.KindSystem:
+		system, err := store.DeleteSystem(c.Request.Context(), expectedEntityRef, version)
+		if err != nil {
+			respondStoreError(c, err, "failed to delete system", expectedEntityRef)
+			return
+		}
+		c.YAML(http.StatusOK, system)
+	case model.KindResource:
+		resource, err := store.DeleteResource(c.Request.Context(), expectedEntityRef, version)
+		if err != nil {
+			respondStoreError(c, err, "failed to delete resource", expectedEntityRef)
+			return
+		}
+		c.YAML(http.StatusOK, resource)
+	case model.KindDomain:
+		domain, err := store.DeleteDomain(c.Request.Context(), expectedEntityRef, version)
+		if err != nil {
+			respondStoreError(c, err, "failed to delete domain", expectedEntityRef)
+			return
+		}
+		c.YAML(http.StatusOK, domain)
+	case model.KindLocation:
+		location, err := store.DeleteLocation(c.Request.Context(), expectedEntityRef, version)
+		if err != nil {
+			respondStoreError(c, err, "failed to delete location", expectedEntityRef)
+			return
+		}
+		c.YAML(http.StatusOK, location)
+	case model.KindTemplate:
+		template, err := store.DeleteTemplate(c.Request.Context(), expectedEntityRef, version)
+		if err != nil {
+			respondStoreError(c, err, "failed to delete template", expectedEntityRef)
+			return
+		}
+		c.YAML(http.StatusOK, template)
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported kind %s", kind)})
 		return
 	}
 }
 
+// RestoreEntity brings back the entity most recently deleted with the ref in
+// the path, as a new version, and responds with it. In strict integrity mode,
+// the refs in its spec must resolve, as when it is written.
+func RestoreEntity(c *gin.Context, store store.Store) {
+	expectedEntityRef := expectedEntityRef(c)
+
+	if !checkRestoredRefs(c, store, expectedEntityRef) {
+		return
+	}
+
+	if err := store.RestoreEntity(c.Request.Context(), expectedEntityRef); err != nil {
+		respondStoreError(c, err, "failed to restore entity", expectedEntityRef)
+		return
+	}
+	readEntity(c, store, expectedEntityRef)
+}
+
 func ListEntities(c *gin.Context, st store.Store) {
 	filters, ordering, pagination, err := processListParams(c)
 	if err != nil {
@@ -242,19 +589,101 @@ func ListEntities(c *gin.Context, st store.Store) {
 		return
 	}
 
+	kind := c.Param("kind")
+	if err := store.ValidateFilters([]string{kind}, filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("bad list parameter: %s", err)})
+		return
+	}
+
 	var refs []model.EntityRef
 	var nextPagination store.Pagination
-	kind := c.Param("kind")
 	switch kind {
 	case model.KindComponent:
-		refs, nextPagination, err = st.ListComponents(filters, ordering, pagination)
+		refs, nextPagination, err = st.ListComponents(c.Request.Context(), filters, ordering, pagination)
+	case model.KindAPI:
+		refs, nextPagination, err = st.ListAPIs(c.Request.Context(), filters, ordering, pagination)
+	case model.KindUser:
+		refs, nextPagination, err = st.ListUsers(c.Request.Context(), filters, ordering, pagination)
+	case model.KindGroup:
+		refs, nextPagination, err = st.ListGroups(c.Request.Context(), filters, ordering, pagination)
+	case model.KindSystem:
+		refs, nextPagination, err = st.ListSystems(c.Request.Context(), filters, ordering, pagination)
+	case model.KindResource:
+		refs, nextPagination, err = st.ListResources(c.Request.Context(), filters, ordering, pagination)
+	case model.KindDomain:
+		refs, nextPagination, err = st.ListDomains(c.Request.Context(), filters, ordering, pagination)
+	case model.KindLocation:
+		refs, nextPagination, err = st.ListLocations(c.Request.Context(), filters, ordering, pagination)
+	case model.KindTemplate:
+		filters = append(filters, templateListFilters(c)...)
+		refs, nextPagination, err = st.ListTemplates(c.Request.Context(), filters, ordering, pagination)
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported kind %s", kind)})
 		return
 	}
 	if err != nil {
-		slog.Error("failed to list entities", "error", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list entities"})
+		respondStoreError(c, err, "failed to list entities", model.EntityRef{})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SearchResults{
+		Results:    refs,
+		Limit:      nextPagination.Limit,
+		NextOffset: nextPagination.Offset,
+	})
+}
+
+// ListAllEntities lists entities of every kind, or of the kinds given in one
+// or more kind parameters. Each kind parameter may hold a comma-separated list
+// of kinds.
+func ListAllEntities(c *gin.Context, st store.Store) {
+	filters, ordering, pagination, err := processListParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("bad list parameter: %s", err)})
+		return
+	}
+
+	kinds := []string{}
+	for _, kindParam := range c.QueryArray("kind") {
+		for _, kind := range strings.Split(kindParam, ",") {
+			kind = strings.TrimSpace(kind)
+			if kind != "" {
+				kinds = append(kinds, kind)
+			}
+		}
+	}
+	if c.Query("deleted") == "true" {
+		if len(filters) > 0 || ordering.OrderBy != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad list parameter: deleted entities cannot be filtered or ordered"})
+			return
+		}
+		listDeletedEntities(c, st, kinds, pagination)
+		return
+	}
+	if err := store.ValidateFilters(kinds, filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("bad list parameter: %s", err)})
+		return
+	}
+
+	refs, nextPagination, err := st.ListEntities(c.Request.Context(), kinds, filters, ordering, pagination)
+	if err != nil {
+		respondStoreError(c, err, "failed to list entities", model.EntityRef{})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SearchResults{
+		Results:    refs,
+		Limit:      nextPagination.Limit,
+		NextOffset: nextPagination.Offset,
+	})
+}
+
+// listDeletedEntities lists the refs of deleted entities that may still be
+// restored.
+func listDeletedEntities(c *gin.Context, st store.Store, kinds []string, pagination store.Pagination) {
+	refs, nextPagination, err := st.ListDeletedEntities(c.Request.Context(), kinds, pagination)
+	if err != nil {
+		respondStoreError(c, err, "failed to list deleted entities", model.EntityRef{})
 		return
 	}
 
@@ -269,6 +698,25 @@ const (
 	defaultLimit = "50"
 )
 
+func templateListFilters(c *gin.Context) []store.Filter {
+	filters := []store.Filter{}
+	templateType := c.Query("type")
+	if templateType != "" {
+		filters = append(filters, store.Filter{
+			Key:   "spec.type",
+			Value: templateType,
+		})
+	}
+	owner := c.Query("owner")
+	if owner != "" {
+		filters = append(filters, store.Filter{
+			Key:   "spec.owner",
+			Value: owner,
+		})
+	}
+	return filters
+}
+
 func processListParams(c *gin.Context) ([]store.Filter, store.Ordering, store.Pagination, error) {
 	filters := []store.Filter{}
 	namespace := c.Query("namespace")
@@ -287,17 +735,35 @@ func processListParams(c *gin.Context) ([]store.Filter, store.Ordering, store.Pa
 	}
 
 	ordering := store.Ordering{}
-	pagination := store.Pagination{}
+
+	labelSelector := c.Query("labelSelector")
+	if labelSelector != "" {
+		labelFilters, err := parseLabelSelector(labelSelector)
+		if err != nil {
+			return filters, ordering, store.Pagination{}, fmt.Errorf("invalid labelSelector: %w", err)
+		}
+		filters = append(filters, labelFilters...)
+	}
+
+	for _, fieldSelector := range c.QueryArray("filter") {
+		fieldFilters, err := parseFieldSelector(fieldSelector)
+		if err != nil {
+			return filters, ordering, store.Pagination{}, fmt.Errorf("invalid filter: %w", err)
+		}
+		filters = append(filters, fieldFilters...)
+	}
 
 	orderBy := c.Query("orderBy")
 	if orderBy != "" {
 		switch orderBy {
+		case "kind":
+			ordering.OrderBy = store.OrderByKind
 		case "namespace":
 			ordering.OrderBy = store.OrderByNamespace
 		case "name":
 			ordering.OrderBy = store.OrderByName
 		default:
-			return filters, ordering, pagination, fmt.Errorf("invalid orderBy %s", orderBy)
+			return filters, ordering, store.Pagination{}, fmt.Errorf("invalid orderBy %s", orderBy)
 		}
 		descending := c.Query("descending")
 		if descending == "true" {
@@ -305,6 +771,17 @@ func processListParams(c *gin.Context) ([]store.Filter, store.Ordering, store.Pa
 		}
 	}
 
+	pagination, err := processPaginationParams(c)
+	if err != nil {
+		return filters, ordering, pagination, err
+	}
+
+	return filters, ordering, pagination, nil
+}
+
+func processPaginationParams(c *gin.Context) (store.Pagination, error) {
+	pagination := store.Pagination{}
+
 	limit := c.Query("limit")
 	if limit == "" {
 		limit = defaultLimit
@@ -312,18 +789,18 @@ func processListParams(c *gin.Context) ([]store.Filter, store.Ordering, store.Pa
 	if limit != "" {
 		limitInt, err := strconv.Atoi(limit)
 		if err != nil || limitInt <= 0 {
-			return filters, ordering, pagination, fmt.Errorf("invalid limit %s", limit)
+			return pagination, fmt.Errorf("invalid limit %s", limit)
 		}
 		pagination.Limit = limitInt
 		offset := c.Query("offset")
 		if offset != "" {
 			offsetInt, err := strconv.Atoi(offset)
 			if err != nil || offsetInt < 0 {
-				return filters, ordering, pagination, fmt.Errorf("invalid offset %s", offset)
+				return pagination, fmt.Errorf("invalid offset %s", offset)
 			}
 			pagination.Offset = offsetInt
 		}
 	}
 
-	return filters, ordering, pagination, nil
+	return pagination, nil
 }
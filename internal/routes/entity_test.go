@@ -1,26 +1,35 @@
 package routes
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
-	"github.com/bhavanki/rewind/internal/store"
 	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 )
 
+// noRelations stands in for ListRelations where relations do not matter.
+func noRelations(ctx context.Context, ref model.EntityRef) ([]model.Relation, error) {
+	return nil, nil
+}
+
 func TestCreateEntity_Component(t *testing.T) {
 	r := gin.Default()
 	var component model.Component
 	s := &store.StoreMock{
-		CreateComponentFunc: func(c model.Component) (model.Component, error) {
+		CreateComponentFunc: func(ctx context.Context, c model.Component) (model.Component, error) {
 			component = c
 			return c, nil
 		},
@@ -42,7 +51,8 @@ func TestCreateEntity_Component(t *testing.T) {
 func TestReadEntity_Component(t *testing.T) {
 	r := gin.Default()
 	s := &store.StoreMock{
-		ReadComponentFunc: func(ref model.EntityRef) (model.Component, error) {
+		ListRelationsFunc: noRelations,
+		ReadComponentFunc: func(ctx context.Context, ref model.EntityRef) (model.Component, error) {
 			return model.TestFullComponent, nil
 		},
 	}
@@ -65,7 +75,7 @@ func TestUpdateEntity_Component(t *testing.T) {
 	r := gin.Default()
 	var component model.Component
 	s := &store.StoreMock{
-		UpdateComponentFunc: func(c model.Component) (model.Component, error) {
+		UpdateComponentFunc: func(ctx context.Context, c model.Component) (model.Component, error) {
 			component = c
 			return c, nil
 		},
@@ -87,7 +97,7 @@ func TestUpdateEntity_Component(t *testing.T) {
 func TestDeleteEntity_Component(t *testing.T) {
 	r := gin.Default()
 	s := &store.StoreMock{
-		DeleteComponentFunc: func(ref model.EntityRef) (model.Component, error) {
+		DeleteComponentFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Component, error) {
 			return model.TestFullComponent, nil
 		},
 	}
@@ -113,7 +123,7 @@ func TestListEntity_Component(t *testing.T) {
 	}
 	r := gin.Default()
 	s := &store.StoreMock{
-		ListComponentsFunc: func(filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+		ListComponentsFunc: func(ctx context.Context, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
 			return refs, store.Pagination{
 				Limit:  50,
 				Offset: 2,
@@ -152,7 +162,7 @@ func TestListEntity_Component_Params(t *testing.T) {
 	}
 	r := gin.Default()
 	s := &store.StoreMock{
-		ListComponentsFunc: func(filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+		ListComponentsFunc: func(ctx context.Context, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
 			return refs, store.Pagination{
 				Limit:  20,
 				Offset: 2,
@@ -213,7 +223,7 @@ func TestCreateEntity_API(t *testing.T) {
 	r := gin.Default()
 	var api model.API
 	s := &store.StoreMock{
-		CreateAPIFunc: func(a model.API) (model.API, error) {
+		CreateAPIFunc: func(ctx context.Context, a model.API) (model.API, error) {
 			api = a
 			return a, nil
 		},
@@ -235,7 +245,8 @@ func TestCreateEntity_API(t *testing.T) {
 func TestReadEntity_API(t *testing.T) {
 	r := gin.Default()
 	s := &store.StoreMock{
-		ReadAPIFunc: func(ref model.EntityRef) (model.API, error) {
+		ListRelationsFunc: noRelations,
+		ReadAPIFunc: func(ctx context.Context, ref model.EntityRef) (model.API, error) {
 			return model.TestFullAPI, nil
 		},
 	}
@@ -258,7 +269,7 @@ func TestUpdateEntity_API(t *testing.T) {
 	r := gin.Default()
 	var api model.API
 	s := &store.StoreMock{
-		UpdateAPIFunc: func(a model.API) (model.API, error) {
+		UpdateAPIFunc: func(ctx context.Context, a model.API) (model.API, error) {
 			api = a
 			return a, nil
 		},
@@ -280,7 +291,7 @@ func TestUpdateEntity_API(t *testing.T) {
 func TestDeleteEntity_API(t *testing.T) {
 	r := gin.Default()
 	s := &store.StoreMock{
-		DeleteAPIFunc: func(ref model.EntityRef) (model.API, error) {
+		DeleteAPIFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.API, error) {
 			return model.TestFullAPI, nil
 		},
 	}
@@ -303,7 +314,7 @@ func TestCreateEntity_User(t *testing.T) {
 	r := gin.Default()
 	var user model.User
 	s := &store.StoreMock{
-		CreateUserFunc: func(u model.User) (model.User, error) {
+		CreateUserFunc: func(ctx context.Context, u model.User) (model.User, error) {
 			user = u
 			return u, nil
 		},
@@ -325,7 +336,8 @@ func TestCreateEntity_User(t *testing.T) {
 func TestReadEntity_User(t *testing.T) {
 	r := gin.Default()
 	s := &store.StoreMock{
-		ReadUserFunc: func(ref model.EntityRef) (model.User, error) {
+		ListRelationsFunc: noRelations,
+		ReadUserFunc: func(ctx context.Context, ref model.EntityRef) (model.User, error) {
 			return model.TestFullUser, nil
 		},
 	}
@@ -348,7 +360,7 @@ func TestUpdateEntity_User(t *testing.T) {
 	r := gin.Default()
 	var user model.User
 	s := &store.StoreMock{
-		UpdateUserFunc: func(u model.User) (model.User, error) {
+		UpdateUserFunc: func(ctx context.Context, u model.User) (model.User, error) {
 			user = u
 			return u, nil
 		},
@@ -370,7 +382,7 @@ func TestUpdateEntity_User(t *testing.T) {
 func TestDeleteEntity_User(t *testing.T) {
 	r := gin.Default()
 	s := &store.StoreMock{
-		DeleteUserFunc: func(ref model.EntityRef) (model.User, error) {
+		DeleteUserFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.User, error) {
 			return model.TestFullUser, nil
 		},
 	}
@@ -393,7 +405,7 @@ func TestCreateEntity_Group(t *testing.T) {
 	r := gin.Default()
 	var group model.Group
 	s := &store.StoreMock{
-		CreateGroupFunc: func(g model.Group) (model.Group, error) {
+		CreateGroupFunc: func(ctx context.Context, g model.Group) (model.Group, error) {
 			group = g
 			return g, nil
 		},
@@ -415,7 +427,8 @@ func TestCreateEntity_Group(t *testing.T) {
 func TestReadEntity_Group(t *testing.T) {
 	r := gin.Default()
 	s := &store.StoreMock{
-		ReadGroupFunc: func(ref model.EntityRef) (model.Group, error) {
+		ListRelationsFunc: noRelations,
+		ReadGroupFunc: func(ctx context.Context, ref model.EntityRef) (model.Group, error) {
 			return model.TestFullGroup, nil
 		},
 	}
@@ -438,7 +451,7 @@ func TestUpdateEntity_Group(t *testing.T) {
 	r := gin.Default()
 	var group model.Group
 	s := &store.StoreMock{
-		UpdateGroupFunc: func(g model.Group) (model.Group, error) {
+		UpdateGroupFunc: func(ctx context.Context, g model.Group) (model.Group, error) {
 			group = g
 			return g, nil
 		},
@@ -460,7 +473,7 @@ func TestUpdateEntity_Group(t *testing.T) {
 func TestDeleteEntity_Group(t *testing.T) {
 	r := gin.Default()
 	s := &store.StoreMock{
-		DeleteGroupFunc: func(ref model.EntityRef) (model.Group, error) {
+		DeleteGroupFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Group, error) {
 			return model.TestFullGroup, nil
 		},
 	}
@@ -478,3 +491,1416 @@ func TestDeleteEntity_Group(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, model.TestFullGroup, group)
 }
+
+func TestCreateEntity_System(t *testing.T) {
+	r := gin.Default()
+	var system model.System
+	s := &store.StoreMock{
+		CreateSystemFunc: func(ctx context.Context, sy model.System) (model.System, error) {
+			system = sy
+			return sy, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	systemYAML, err := yaml.Marshal(model.TestFullSystem)
+	require.NoError(t, err)
+	req, err := http.NewRequest("POST", "/api/v1/system/my-namespace/my-service", strings.NewReader(string(systemYAML)))
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, model.TestFullSystem, system)
+}
+
+func TestReadEntity_System(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListRelationsFunc: noRelations,
+		ReadSystemFunc: func(ctx context.Context, ref model.EntityRef) (model.System, error) {
+			return model.TestFullSystem, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/system/my-namespace/my-service", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var system model.System
+	err = yaml.Unmarshal(w.Body.Bytes(), &system)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestFullSystem, system)
+}
+
+func TestUpdateEntity_System(t *testing.T) {
+	r := gin.Default()
+	var system model.System
+	s := &store.StoreMock{
+		UpdateSystemFunc: func(ctx context.Context, sy model.System) (model.System, error) {
+			system = sy
+			return sy, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	systemYAML, err := yaml.Marshal(model.TestFullSystem)
+	require.NoError(t, err)
+	req, err := http.NewRequest("PUT", "/api/v1/system/my-namespace/my-service", strings.NewReader(string(systemYAML)))
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, model.TestFullSystem, system)
+}
+
+func TestDeleteEntity_System(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		DeleteSystemFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.System, error) {
+			return model.TestFullSystem, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("DELETE", "/api/v1/system/my-namespace/my-service", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var system model.System
+	err = yaml.Unmarshal(w.Body.Bytes(), &system)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestFullSystem, system)
+}
+
+func TestListEntity_System(t *testing.T) {
+	refs := []model.EntityRef{
+		model.TestSystemEntityRef,
+		model.TestSystem2EntityRef,
+	}
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListSystemsFunc: func(ctx context.Context, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return refs, store.Pagination{
+				Limit:  50,
+				Offset: 2,
+			}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/system", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results model.SearchResults
+	err = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Equal(t, refs, results.Results)
+	assert.Equal(t, 1, len(s.ListSystemsCalls()))
+}
+
+func TestCreateEntity_Resource(t *testing.T) {
+	r := gin.Default()
+	var resource model.Resource
+	s := &store.StoreMock{
+		CreateResourceFunc: func(ctx context.Context, x model.Resource) (model.Resource, error) {
+			resource = x
+			return x, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	resourceYAML, err := yaml.Marshal(model.TestFullResource)
+	require.NoError(t, err)
+	req, err := http.NewRequest("POST", "/api/v1/resource/my-namespace/my-service", strings.NewReader(string(resourceYAML)))
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, model.TestFullResource, resource)
+}
+
+func TestReadEntity_Resource(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListRelationsFunc: noRelations,
+		ReadResourceFunc: func(ctx context.Context, ref model.EntityRef) (model.Resource, error) {
+			return model.TestFullResource, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/resource/my-namespace/my-service", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resource model.Resource
+	err = yaml.Unmarshal(w.Body.Bytes(), &resource)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestFullResource, resource)
+}
+
+func TestUpdateEntity_Resource(t *testing.T) {
+	r := gin.Default()
+	var resource model.Resource
+	s := &store.StoreMock{
+		UpdateResourceFunc: func(ctx context.Context, x model.Resource) (model.Resource, error) {
+			resource = x
+			return x, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	resourceYAML, err := yaml.Marshal(model.TestFullResource)
+	require.NoError(t, err)
+	req, err := http.NewRequest("PUT", "/api/v1/resource/my-namespace/my-service", strings.NewReader(string(resourceYAML)))
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, model.TestFullResource, resource)
+}
+
+func TestDeleteEntity_Resource(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		DeleteResourceFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Resource, error) {
+			return model.TestFullResource, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("DELETE", "/api/v1/resource/my-namespace/my-service", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resource model.Resource
+	err = yaml.Unmarshal(w.Body.Bytes(), &resource)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestFullResource, resource)
+}
+
+func TestListEntity_Resource(t *testing.T) {
+	refs := []model.EntityRef{
+		model.TestResource1EntityRef,
+		model.TestResource2EntityRef,
+	}
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListResourcesFunc: func(ctx context.Context, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return refs, store.Pagination{
+				Limit:  50,
+				Offset: 2,
+			}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/resource", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results model.SearchResults
+	err = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Equal(t, refs, results.Results)
+	assert.Equal(t, 1, len(s.ListResourcesCalls()))
+}
+
+func TestCreateEntity_Domain(t *testing.T) {
+	r := gin.Default()
+	var domain model.Domain
+	s := &store.StoreMock{
+		CreateDomainFunc: func(ctx context.Context, x model.Domain) (model.Domain, error) {
+			domain = x
+			return x, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	domainYAML, err := yaml.Marshal(model.TestFullDomain)
+	require.NoError(t, err)
+	req, err := http.NewRequest("POST", "/api/v1/domain/my-namespace/my-service", strings.NewReader(string(domainYAML)))
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, model.TestFullDomain, domain)
+}
+
+func TestReadEntity_Domain(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListRelationsFunc: noRelations,
+		ReadDomainFunc: func(ctx context.Context, ref model.EntityRef) (model.Domain, error) {
+			return model.TestFullDomain, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/domain/my-namespace/my-service", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var domain model.Domain
+	err = yaml.Unmarshal(w.Body.Bytes(), &domain)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestFullDomain, domain)
+}
+
+func TestUpdateEntity_Domain(t *testing.T) {
+	r := gin.Default()
+	var domain model.Domain
+	s := &store.StoreMock{
+		UpdateDomainFunc: func(ctx context.Context, x model.Domain) (model.Domain, error) {
+			domain = x
+			return x, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	domainYAML, err := yaml.Marshal(model.TestFullDomain)
+	require.NoError(t, err)
+	req, err := http.NewRequest("PUT", "/api/v1/domain/my-namespace/my-service", strings.NewReader(string(domainYAML)))
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, model.TestFullDomain, domain)
+}
+
+func TestDeleteEntity_Domain(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		DeleteDomainFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Domain, error) {
+			return model.TestFullDomain, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("DELETE", "/api/v1/domain/my-namespace/my-service", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var domain model.Domain
+	err = yaml.Unmarshal(w.Body.Bytes(), &domain)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestFullDomain, domain)
+}
+
+func TestListEntity_Domain(t *testing.T) {
+	refs := []model.EntityRef{
+		model.TestDomainEntityRef,
+		model.TestDomain2EntityRef,
+	}
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListDomainsFunc: func(ctx context.Context, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return refs, store.Pagination{
+				Limit:  50,
+				Offset: 2,
+			}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/domain", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results model.SearchResults
+	err = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Equal(t, refs, results.Results)
+	assert.Equal(t, 1, len(s.ListDomainsCalls()))
+}
+
+func TestCreateEntity_Location(t *testing.T) {
+	r := gin.Default()
+	var location model.Location
+	s := &store.StoreMock{
+		CreateLocationFunc: func(ctx context.Context, x model.Location) (model.Location, error) {
+			location = x
+			return x, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	locationYAML, err := yaml.Marshal(model.TestFullLocation)
+	require.NoError(t, err)
+	req, err := http.NewRequest("POST", "/api/v1/location/my-namespace/my-service", strings.NewReader(string(locationYAML)))
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, model.TestFullLocation, location)
+}
+
+func TestReadEntity_Location(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListRelationsFunc: noRelations,
+		ReadLocationFunc: func(ctx context.Context, ref model.EntityRef) (model.Location, error) {
+			return model.TestFullLocation, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/location/my-namespace/my-service", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var location model.Location
+	err = yaml.Unmarshal(w.Body.Bytes(), &location)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestFullLocation, location)
+}
+
+func TestUpdateEntity_Location(t *testing.T) {
+	r := gin.Default()
+	var location model.Location
+	s := &store.StoreMock{
+		UpdateLocationFunc: func(ctx context.Context, x model.Location) (model.Location, error) {
+			location = x
+			return x, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	locationYAML, err := yaml.Marshal(model.TestFullLocation)
+	require.NoError(t, err)
+	req, err := http.NewRequest("PUT", "/api/v1/location/my-namespace/my-service", strings.NewReader(string(locationYAML)))
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, model.TestFullLocation, location)
+}
+
+func TestDeleteEntity_Location(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		DeleteLocationFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Location, error) {
+			return model.TestFullLocation, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("DELETE", "/api/v1/location/my-namespace/my-service", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var location model.Location
+	err = yaml.Unmarshal(w.Body.Bytes(), &location)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestFullLocation, location)
+}
+
+func TestListEntity_Location(t *testing.T) {
+	refs := []model.EntityRef{
+		model.TestLocationEntityRef,
+		model.TestLocation2EntityRef,
+	}
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListLocationsFunc: func(ctx context.Context, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return refs, store.Pagination{
+				Limit:  50,
+				Offset: 2,
+			}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/location", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results model.SearchResults
+	err = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Equal(t, refs, results.Results)
+	assert.Equal(t, 1, len(s.ListLocationsCalls()))
+}
+
+func TestCreateEntity_Template(t *testing.T) {
+	r := gin.Default()
+	var template model.Template
+	s := &store.StoreMock{
+		CreateTemplateFunc: func(ctx context.Context, x model.Template) (model.Template, error) {
+			template = x
+			return x, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	templateYAML, err := yaml.Marshal(model.TestFullTemplate)
+	require.NoError(t, err)
+	req, err := http.NewRequest("POST", "/api/v1/template/my-namespace/my-service", strings.NewReader(string(templateYAML)))
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, model.TestFullTemplate, template)
+}
+
+func TestReadEntity_Template(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListRelationsFunc: noRelations,
+		ReadTemplateFunc: func(ctx context.Context, ref model.EntityRef) (model.Template, error) {
+			return model.TestFullTemplate, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/template/my-namespace/my-service", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var template model.Template
+	err = yaml.Unmarshal(w.Body.Bytes(), &template)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestFullTemplate, template)
+}
+
+func TestUpdateEntity_Template(t *testing.T) {
+	r := gin.Default()
+	var template model.Template
+	s := &store.StoreMock{
+		UpdateTemplateFunc: func(ctx context.Context, x model.Template) (model.Template, error) {
+			template = x
+			return x, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	templateYAML, err := yaml.Marshal(model.TestFullTemplate)
+	require.NoError(t, err)
+	req, err := http.NewRequest("PUT", "/api/v1/template/my-namespace/my-service", strings.NewReader(string(templateYAML)))
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, model.TestFullTemplate, template)
+}
+
+func TestDeleteEntity_Template(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		DeleteTemplateFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Template, error) {
+			return model.TestFullTemplate, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("DELETE", "/api/v1/template/my-namespace/my-service", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var template model.Template
+	err = yaml.Unmarshal(w.Body.Bytes(), &template)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestFullTemplate, template)
+}
+
+func TestListEntity_Template(t *testing.T) {
+	refs := []model.EntityRef{
+		model.TestTemplateEntityRef,
+		model.TestTemplate2EntityRef,
+	}
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListTemplatesFunc: func(ctx context.Context, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return refs, store.Pagination{
+				Limit:  50,
+				Offset: 2,
+			}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/template", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results model.SearchResults
+	err = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Equal(t, refs, results.Results)
+	assert.Equal(t, 1, len(s.ListTemplatesCalls()))
+}
+
+func TestListEntity_Template_Params(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListTemplatesFunc: func(ctx context.Context, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return []model.EntityRef{model.TestTemplateEntityRef}, pagination, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	params := url.Values{
+		"namespace": []string{"default"},
+		"type":      []string{"service"},
+		"owner":     []string{"group:default/payments"},
+	}
+	url := url.URL{
+		Path:     "/api/v1/template",
+		RawQuery: params.Encode(),
+	}
+	req, err := http.NewRequest("GET", url.String(), nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	calls := s.ListTemplatesCalls()
+	assert.Equal(t, 1, len(calls))
+	assert.Equal(t, []store.Filter{
+		{
+			Key:   "entity.namespace",
+			Value: "default",
+		},
+		{
+			Key:   "spec.type",
+			Value: "service",
+		},
+		{
+			Key:   "spec.owner",
+			Value: "group:default/payments",
+		},
+	}, calls[0].Filters)
+}
+
+func TestListEntity_API(t *testing.T) {
+	refs := []model.EntityRef{
+		model.TestAPI1EntityRef,
+		model.TestAPI2EntityRef,
+	}
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListAPIsFunc: func(ctx context.Context, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return refs, store.Pagination{
+				Limit:  20,
+				Offset: 2,
+			}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	params := url.Values{
+		"namespace": []string{"default"},
+		"orderBy":   []string{"name"},
+		"limit":     []string{"20"},
+	}
+	url := url.URL{
+		Path:     "/api/v1/api",
+		RawQuery: params.Encode(),
+	}
+	req, err := http.NewRequest("GET", url.String(), nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results model.SearchResults
+	err = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Equal(t, refs, results.Results)
+	assert.Equal(t, 20, results.Limit)
+	assert.Equal(t, 2, results.NextOffset)
+
+	calls := s.ListAPIsCalls()
+	assert.Equal(t, 1, len(calls))
+	assert.Equal(t, []store.Filter{
+		{
+			Key:   "entity.namespace",
+			Value: "default",
+		},
+	}, calls[0].Filters)
+	assert.Equal(t, store.Ordering{
+		OrderBy: store.OrderByName,
+	}, calls[0].Ordering)
+	assert.Equal(t, store.Pagination{
+		Limit: 20,
+	}, calls[0].Pagination)
+}
+
+func TestListEntity_User(t *testing.T) {
+	refs := []model.EntityRef{
+		model.TestOwnerEntityRef,
+		model.TestUser2EntityRef,
+	}
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListUsersFunc: func(ctx context.Context, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return refs, store.Pagination{
+				Limit:  20,
+				Offset: 2,
+			}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	params := url.Values{
+		"namespace": []string{"default"},
+		"orderBy":   []string{"name"},
+		"limit":     []string{"20"},
+	}
+	url := url.URL{
+		Path:     "/api/v1/user",
+		RawQuery: params.Encode(),
+	}
+	req, err := http.NewRequest("GET", url.String(), nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results model.SearchResults
+	err = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Equal(t, refs, results.Results)
+	assert.Equal(t, 20, results.Limit)
+	assert.Equal(t, 2, results.NextOffset)
+
+	calls := s.ListUsersCalls()
+	assert.Equal(t, 1, len(calls))
+	assert.Equal(t, []store.Filter{
+		{
+			Key:   "entity.namespace",
+			Value: "default",
+		},
+	}, calls[0].Filters)
+	assert.Equal(t, store.Ordering{
+		OrderBy: store.OrderByName,
+	}, calls[0].Ordering)
+	assert.Equal(t, store.Pagination{
+		Limit: 20,
+	}, calls[0].Pagination)
+}
+
+func TestListEntity_Group(t *testing.T) {
+	refs := []model.EntityRef{
+		model.TestGroupEntityRef,
+		model.TestGroup2EntityRef,
+	}
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListGroupsFunc: func(ctx context.Context, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return refs, store.Pagination{
+				Limit:  20,
+				Offset: 2,
+			}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	params := url.Values{
+		"namespace": []string{"default"},
+		"orderBy":   []string{"name"},
+		"limit":     []string{"20"},
+	}
+	url := url.URL{
+		Path:     "/api/v1/group",
+		RawQuery: params.Encode(),
+	}
+	req, err := http.NewRequest("GET", url.String(), nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results model.SearchResults
+	err = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Equal(t, refs, results.Results)
+	assert.Equal(t, 20, results.Limit)
+	assert.Equal(t, 2, results.NextOffset)
+
+	calls := s.ListGroupsCalls()
+	assert.Equal(t, 1, len(calls))
+	assert.Equal(t, []store.Filter{
+		{
+			Key:   "entity.namespace",
+			Value: "default",
+		},
+	}, calls[0].Filters)
+	assert.Equal(t, store.Ordering{
+		OrderBy: store.OrderByName,
+	}, calls[0].Ordering)
+	assert.Equal(t, store.Pagination{
+		Limit: 20,
+	}, calls[0].Pagination)
+}
+
+func TestListAllEntities(t *testing.T) {
+	refs := []model.EntityRef{
+		model.TestAPI1EntityRef,
+		model.TestComponentEntityRef,
+		model.TestGroupEntityRef,
+	}
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListEntitiesFunc: func(ctx context.Context, kinds []string, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return refs, store.Pagination{
+				Limit:  10,
+				Offset: 3,
+			}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	params := url.Values{
+		"kind":      []string{"api,component", "group"},
+		"namespace": []string{"default"},
+		"orderBy":   []string{"kind"},
+		"limit":     []string{"10"},
+	}
+	url := url.URL{
+		Path:     "/api/v1/entities",
+		RawQuery: params.Encode(),
+	}
+	req, err := http.NewRequest("GET", url.String(), nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results model.SearchResults
+	err = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Equal(t, refs, results.Results)
+	assert.Equal(t, 10, results.Limit)
+	assert.Equal(t, 3, results.NextOffset)
+
+	calls := s.ListEntitiesCalls()
+	assert.Equal(t, 1, len(calls))
+	assert.Equal(t, []string{model.KindAPI, model.KindComponent, model.KindGroup}, calls[0].Kinds)
+	assert.Equal(t, []store.Filter{
+		{
+			Key:   "entity.namespace",
+			Value: "default",
+		},
+	}, calls[0].Filters)
+	assert.Equal(t, store.Ordering{
+		OrderBy: store.OrderByKind,
+	}, calls[0].Ordering)
+	assert.Equal(t, store.Pagination{
+		Limit: 10,
+	}, calls[0].Pagination)
+}
+
+func TestListAllEntities_NoKinds(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListEntitiesFunc: func(ctx context.Context, kinds []string, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return nil, pagination, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/entities", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	calls := s.ListEntitiesCalls()
+	assert.Equal(t, 1, len(calls))
+	assert.Empty(t, calls[0].Kinds)
+}
+
+func TestListAllEntities_Deleted(t *testing.T) {
+	refs := []model.EntityRef{model.TestComponentEntityRef}
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListDeletedEntitiesFunc: func(ctx context.Context, kinds []string, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return refs, store.Pagination{Limit: pagination.Limit, Offset: pagination.Offset + 1}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/entities?deleted=true&kind=component&limit=10", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results model.SearchResults
+	err = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Equal(t, refs, results.Results)
+	assert.Equal(t, 10, results.Limit)
+	assert.Equal(t, 1, results.NextOffset)
+
+	calls := s.ListDeletedEntitiesCalls()
+	require.Equal(t, 1, len(calls))
+	assert.Equal(t, []string{model.KindComponent}, calls[0].Kinds)
+	assert.Equal(t, store.Pagination{Limit: 10}, calls[0].Pagination)
+	assert.Empty(t, s.ListEntitiesCalls())
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/api/v1/entities?deleted=true&namespace=default", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, 1, len(s.ListDeletedEntitiesCalls()))
+}
+
+func TestRestoreEntity(t *testing.T) {
+	restored := model.TestFullComponent
+	restored.Version = 3
+	r := gin.Default()
+	s := &store.StoreMock{
+		RestoreEntityFunc: func(ctx context.Context, ref model.EntityRef) error {
+			if ref != model.TestComponentEntityRef {
+				return fmt.Errorf("deleted %s %w", ref, store.ErrNotFound)
+			}
+			return nil
+		},
+		ListRelationsFunc: noRelations,
+		ReadComponentFunc: func(ctx context.Context, ref model.EntityRef) (model.Component, error) {
+			return restored, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/api/v1/component/default/component/restore", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	var component model.Component
+	err = yaml.Unmarshal(w.Body.Bytes(), &component)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestFullComponent, component)
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("POST", "/api/v1/component/default/unknown/restore", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestListEntity_Component_LabelSelector(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListComponentsFunc: func(ctx context.Context, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return nil, pagination, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	params := url.Values{
+		"namespace":     []string{"default"},
+		"labelSelector": []string{"tier=backend,team in (a,b)"},
+	}
+	url := url.URL{
+		Path:     "/api/v1/component",
+		RawQuery: params.Encode(),
+	}
+	req, err := http.NewRequest("GET", url.String(), nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	calls := s.ListComponentsCalls()
+	assert.Equal(t, 1, len(calls))
+	assert.Equal(t, []store.Filter{
+		{
+			Key:   "entity.namespace",
+			Value: "default",
+		},
+		{
+			Key:   "metadata.labels.tier",
+			Value: "backend",
+		},
+		{
+			Key:      "metadata.labels.team",
+			Operator: store.FilterIn,
+			Values:   []string{"a", "b"},
+		},
+	}, calls[0].Filters)
+}
+
+func TestListEntity_Component_BadLabelSelector(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/component?labelSelector=team+in+(a", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, s.ListComponentsCalls())
+}
+
+func TestListEntity_Component_FieldFilters(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListComponentsFunc: func(ctx context.Context, filters []store.Filter, ordering store.Ordering, pagination store.Pagination) ([]model.EntityRef, store.Pagination, error) {
+			return nil, pagination, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	params := url.Values{
+		"filter": []string{"spec.lifecycle=production", "spec.owner=group:default/payments,metadata.tags^=java"},
+	}
+	url := url.URL{
+		Path:     "/api/v1/component",
+		RawQuery: params.Encode(),
+	}
+	req, err := http.NewRequest("GET", url.String(), nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	calls := s.ListComponentsCalls()
+	assert.Equal(t, 1, len(calls))
+	assert.Equal(t, []store.Filter{
+		{
+			Key:   "spec.lifecycle",
+			Value: "production",
+		},
+		{
+			Key:   "spec.owner",
+			Value: "group:default/payments",
+		},
+		{
+			Key:      "metadata.tags",
+			Value:    "java",
+			Operator: store.FilterPrefix,
+		},
+	}, calls[0].Filters)
+}
+
+func TestListEntity_UnsupportedFilterField(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/user?filter=spec.lifecycle%3Dproduction", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, s.ListUsersCalls())
+}
+
+func TestListAllEntities_SpecFilterWithSeveralKinds(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/entities?kind=component,api&filter=spec.owner%3Dgroup:default/payments", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, s.ListEntitiesCalls())
+}
+
+func TestEntity_StoreErrors(t *testing.T) {
+	componentYAML, err := yaml.Marshal(model.TestFullComponent)
+	require.NoError(t, err)
+
+	type testCase struct {
+		method         string
+		err            error
+		expectedStatus int
+		description    string
+	}
+	tcs := []testCase{
+		{
+			method:         "GET",
+			err:            fmt.Errorf("component:my-namespace/my-service %w", store.ErrNotFound),
+			expectedStatus: http.StatusNotFound,
+			description:    "read missing",
+		},
+		{
+			method:         "DELETE",
+			err:            fmt.Errorf("component:my-namespace/my-service %w", store.ErrNotFound),
+			expectedStatus: http.StatusNotFound,
+			description:    "delete missing",
+		},
+		{
+			method:         "PUT",
+			err:            fmt.Errorf("component:my-namespace/my-service %w", store.ErrNotFound),
+			expectedStatus: http.StatusNotFound,
+			description:    "update missing",
+		},
+		{
+			method:         "POST",
+			err:            fmt.Errorf("component:my-namespace/my-service %w", store.ErrAlreadyExists),
+			expectedStatus: http.StatusConflict,
+			description:    "create duplicate",
+		},
+		{
+			method:         "PUT",
+			err:            fmt.Errorf("%w: taken", store.ErrConflict),
+			expectedStatus: http.StatusConflict,
+			description:    "update conflict",
+		},
+		{
+			method:         "POST",
+			err:            fmt.Errorf("%w: no name", store.ErrInvalid),
+			expectedStatus: http.StatusUnprocessableEntity,
+			description:    "create invalid",
+		},
+		{
+			method:         "PUT",
+			err:            fmt.Errorf("%w: component:my-namespace/my-service is at version 3, not 2", store.ErrVersionMismatch),
+			expectedStatus: http.StatusPreconditionFailed,
+			description:    "update stale version",
+		},
+		{
+			method:         "GET",
+			err:            fmt.Errorf("%w after 1s: %w", store.ErrTimeout, context.DeadlineExceeded),
+			expectedStatus: http.StatusGatewayTimeout,
+			description:    "read timed out",
+		},
+		{
+			method:         "GET",
+			err:            errors.New("disk on fire"),
+			expectedStatus: http.StatusInternalServerError,
+			description:    "other failure",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			r := gin.Default()
+			s := &store.StoreMock{
+				CreateComponentFunc: func(ctx context.Context, c model.Component) (model.Component, error) {
+					return model.Component{}, tc.err
+				},
+				ListRelationsFunc: noRelations,
+				ReadComponentFunc: func(ctx context.Context, ref model.EntityRef) (model.Component, error) {
+					return model.Component{}, tc.err
+				},
+				UpdateComponentFunc: func(ctx context.Context, c model.Component) (model.Component, error) {
+					return model.Component{}, tc.err
+				},
+				DeleteComponentFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Component, error) {
+					return model.Component{}, tc.err
+				},
+			}
+			SetupRoutes(r, s)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.method, "/api/v1/component/my-namespace/my-service", strings.NewReader(string(componentYAML)))
+			require.NoError(t, err)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			var body map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			if tc.expectedStatus == http.StatusInternalServerError {
+				assert.NotContains(t, body["error"], "disk on fire")
+			} else {
+				assert.Equal(t, tc.err.Error(), body["error"])
+			}
+		})
+	}
+}
+
+func TestEntity_RequestContext(t *testing.T) {
+	type contextKey struct{}
+	r := gin.Default()
+	var storeCtx context.Context
+	s := &store.StoreMock{
+		ListRelationsFunc: noRelations,
+		ReadComponentFunc: func(ctx context.Context, ref model.EntityRef) (model.Component, error) {
+			storeCtx = ctx
+			return model.TestFullComponent, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	ctx := context.WithValue(context.Background(), contextKey{}, "request")
+	req, err := http.NewRequestWithContext(ctx, "GET", "/api/v1/component/my-namespace/my-service", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, storeCtx)
+	assert.Equal(t, "request", storeCtx.Value(contextKey{}))
+}
+
+func TestEntity_Versions(t *testing.T) {
+	componentYAML, err := yaml.Marshal(model.TestFullComponent)
+	require.NoError(t, err)
+
+	type testCase struct {
+		method          string
+		ifMatch         string
+		expectedStatus  int
+		expectedVersion int64
+		expectedETag    string
+		description     string
+	}
+	tcs := []testCase{
+		{
+			method:         "GET",
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"3"`,
+			description:    "read",
+		},
+		{
+			method:         "POST",
+			expectedStatus: http.StatusCreated,
+			expectedETag:   `"1"`,
+			description:    "create",
+		},
+		{
+			method:          "PUT",
+			ifMatch:         `"3"`,
+			expectedStatus:  http.StatusAccepted,
+			expectedVersion: 3,
+			expectedETag:    `"4"`,
+			description:     "update with If-Match",
+		},
+		{
+			method:         "PUT",
+			expectedStatus: http.StatusAccepted,
+			expectedETag:   `"4"`,
+			description:    "update without If-Match",
+		},
+		{
+			method:         "PUT",
+			ifMatch:        "*",
+			expectedStatus: http.StatusAccepted,
+			expectedETag:   `"4"`,
+			description:    "update with If-Match *",
+		},
+		{
+			method:          "DELETE",
+			ifMatch:         `"3"`,
+			expectedStatus:  http.StatusOK,
+			expectedVersion: 3,
+			description:     "delete with If-Match",
+		},
+		{
+			method:         "PUT",
+			ifMatch:        `W/"3"`,
+			expectedStatus: http.StatusPreconditionFailed,
+			description:    "weak If-Match",
+		},
+		{
+			method:         "DELETE",
+			ifMatch:        `"abc"`,
+			expectedStatus: http.StatusPreconditionFailed,
+			description:    "If-Match that is not a version",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			r := gin.Default()
+			var version int64
+			stored := model.TestFullComponent
+			stored.Version = 3
+			s := &store.StoreMock{
+				CreateComponentFunc: func(ctx context.Context, c model.Component) (model.Component, error) {
+					c.Version = 1
+					return c, nil
+				},
+				ListRelationsFunc: noRelations,
+				ReadComponentFunc: func(ctx context.Context, ref model.EntityRef) (model.Component, error) {
+					return stored, nil
+				},
+				UpdateComponentFunc: func(ctx context.Context, c model.Component) (model.Component, error) {
+					version = c.Version
+					c.Version = stored.Version + 1
+					return c, nil
+				},
+				DeleteComponentFunc: func(ctx context.Context, ref model.EntityRef, v int64) (model.Component, error) {
+					version = v
+					return stored, nil
+				},
+			}
+			SetupRoutes(r, s)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.method, "/api/v1/component/my-namespace/my-service", strings.NewReader(string(componentYAML)))
+			require.NoError(t, err)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedVersion, version)
+			assert.Equal(t, tc.expectedETag, w.Header().Get("ETag"))
+			if tc.expectedStatus == http.StatusPreconditionFailed {
+				assert.Empty(t, s.UpdateComponentCalls())
+				assert.Empty(t, s.DeleteComponentCalls())
+			}
+		})
+	}
+}
+
+func TestReadEntityByUID(t *testing.T) {
+	stored := model.TestFullComponent
+	stored.Version = 2
+	stored.Metadata.UID = "0b4a6f1e-3c1d-4e8a-9f2b-7d5c8e6a1b2c"
+	stored.Metadata.Etag = "2"
+	stored.Metadata.CreatedAt = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	stored.Metadata.UpdatedAt = time.Date(2024, time.March, 2, 12, 0, 0, 0, time.UTC)
+
+	type testCase struct {
+		uid            string
+		expectedStatus int
+		description    string
+	}
+	tcs := []testCase{
+		{
+			uid:            stored.Metadata.UID,
+			expectedStatus: http.StatusOK,
+			description:    "known UID",
+		},
+		{
+			uid:            "no-such-uid",
+			expectedStatus: http.StatusNotFound,
+			description:    "unknown UID",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			r := gin.Default()
+			var readRef model.EntityRef
+			s := &store.StoreMock{
+				ResolveUIDFunc: func(ctx context.Context, uid string) (model.EntityRef, error) {
+					if uid != stored.Metadata.UID {
+						return model.EntityRef{}, fmt.Errorf("entity with UID %s %w", uid, store.ErrNotFound)
+					}
+					return stored.EntityRef(), nil
+				},
+				ListRelationsFunc: noRelations,
+				ReadComponentFunc: func(ctx context.Context, ref model.EntityRef) (model.Component, error) {
+					readRef = ref
+					return stored, nil
+				},
+			}
+			SetupRoutes(r, s)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/api/v1/by-uid/"+tc.uid, nil)
+			require.NoError(t, err)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus != http.StatusOK {
+				assert.Empty(t, s.ReadComponentCalls())
+				return
+			}
+			assert.Equal(t, stored.EntityRef(), readRef)
+			assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+			var component model.Component
+			require.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &component))
+			expected := stored
+			expected.Version = 0
+			assert.Equal(t, expected, component)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/gin-gonic/gin"
+)
+
+// actorHeader is the request header that names the actor responsible for
+// the changes that a request makes.
+const actorHeader = "X-Rewind-Actor"
+
+// withActor puts the actor named by the request, if any, in the request
+// context, so that stores record it in entity history. The header is not
+// authenticated, and any client may send it, so it is only used when trusted
+// is true, as when the server is reached only through a proxy that sets it.
+// Otherwise, changes are recorded with no actor.
+func withActor(trusted bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if actor := c.GetHeader(actorHeader); trusted && actor != "" {
+			c.Request = c.Request.WithContext(store.WithActor(c.Request.Context(), actor))
+		}
+		c.Next()
+	}
+}
+
+// ListEntityHistory lists the changes made to an entity, newest first. The
+// history of a deleted entity can still be listed.
+func ListEntityHistory(c *gin.Context, st store.Store) {
+	ref := expectedEntityRef(c)
+
+	pagination, err := processPaginationParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("bad list parameter: %s", err)})
+		return
+	}
+
+	revisions, nextPagination, err := st.ListRevisions(c.Request.Context(), ref, pagination)
+	if err != nil {
+		respondStoreError(c, err, "failed to list entity history", ref)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.History{
+		Revisions:  revisions,
+		Limit:      nextPagination.Limit,
+		NextOffset: nextPagination.Offset,
+	})
+}
+
+// readEntityRevision responds with the YAML of a past version of an entity,
+// as given by the version query parameter.
+func readEntityRevision(c *gin.Context, st store.Store, ref model.EntityRef) {
+	version, err := strconv.ParseInt(c.Query("version"), 10, 64)
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid version %s", c.Query("version"))})
+		return
+	}
+
+	revision, err := st.ReadRevision(c.Request.Context(), ref, version)
+	if err != nil {
+		respondStoreError(c, err, "failed to read entity revision", ref)
+		return
+	}
+	c.Header("ETag", entityTag(revision.Version))
+	c.Data(http.StatusOK, "application/yaml; charset=utf-8", []byte(revision.Current))
+}
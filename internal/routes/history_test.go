@@ -0,0 +1,192 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var testRevision = model.Revision{
+	EntityRef: model.TestComponentEntityRef,
+	UID:       "0b4a6f1e-3c1d-4e8a-9f2b-7d5c8e6a1b2c",
+	Version:   2,
+	Operation: model.OperationUpdate,
+	Actor:     "alice",
+	Timestamp: time.Date(2024, time.March, 2, 12, 0, 0, 0, time.UTC),
+	Previous:  "kind: component\nmetadata:\n  title: old\n",
+	Current:   "kind: component\nmetadata:\n  title: new\n",
+}
+
+func TestListEntityHistory(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListRevisionsFunc: func(ctx context.Context, ref model.EntityRef, pagination store.Pagination) ([]model.Revision, store.Pagination, error) {
+			if ref != model.TestComponentEntityRef {
+				return nil, store.Pagination{}, fmt.Errorf("%s %w", ref, store.ErrNotFound)
+			}
+			return []model.Revision{testRevision}, store.Pagination{Limit: pagination.Limit, Offset: pagination.Offset + 1}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/component/default/component/history?limit=1&offset=1", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var history model.History
+	err = json.Unmarshal(w.Body.Bytes(), &history)
+	assert.NoError(t, err)
+	assert.Equal(t, model.History{
+		Revisions:  []model.Revision{testRevision},
+		Limit:      1,
+		NextOffset: 2,
+	}, history)
+
+	calls := s.ListRevisionsCalls()
+	require.Equal(t, 1, len(calls))
+	assert.Equal(t, store.Pagination{Limit: 1, Offset: 1}, calls[0].Pagination)
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/api/v1/component/default/unknown/history", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestReadEntity_Version(t *testing.T) {
+	type testCase struct {
+		version        string
+		expectedStatus int
+		expectedBody   string
+		description    string
+	}
+	tcs := []testCase{
+		{
+			version:        "2",
+			expectedStatus: http.StatusOK,
+			expectedBody:   testRevision.Current,
+			description:    "known version",
+		},
+		{
+			version:        "5",
+			expectedStatus: http.StatusNotFound,
+			description:    "unknown version",
+		},
+		{
+			version:        "0",
+			expectedStatus: http.StatusBadRequest,
+			description:    "zero version",
+		},
+		{
+			version:        "latest",
+			expectedStatus: http.StatusBadRequest,
+			description:    "non-numeric version",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			r := gin.Default()
+			s := &store.StoreMock{
+				ReadRevisionFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Revision, error) {
+					if version != testRevision.Version {
+						return model.Revision{}, fmt.Errorf("version %d of %s %w", version, ref, store.ErrNotFound)
+					}
+					return testRevision, nil
+				},
+			}
+			SetupRoutes(r, s)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/api/v1/component/default/component?version="+tc.version, nil)
+			require.NoError(t, err)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Empty(t, s.ReadComponentCalls())
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+			calls := s.ReadRevisionCalls()
+			require.Equal(t, 1, len(calls))
+			assert.Equal(t, model.TestComponentEntityRef, calls[0].Ref)
+		})
+	}
+}
+
+func TestWithActor(t *testing.T) {
+	componentYAML, err := yaml.Marshal(model.TestFullComponent)
+	require.NoError(t, err)
+
+	type testCase struct {
+		actor         string
+		trusted       bool
+		expectedActor string
+		description   string
+	}
+	tcs := []testCase{
+		{
+			actor:         "alice",
+			trusted:       true,
+			expectedActor: "alice",
+			description:   "trusted actor header",
+		},
+		{
+			trusted:     true,
+			description: "no trusted actor header",
+		},
+		{
+			actor:       "alice",
+			description: "untrusted actor header",
+		},
+		{
+			description: "no actor header",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			r := gin.Default()
+			var actor string
+			s := &store.StoreMock{
+				CreateComponentFunc: func(ctx context.Context, c model.Component) (model.Component, error) {
+					actor = store.ActorFromContext(ctx)
+					return c, nil
+				},
+			}
+			SetupRoutes(r, s, WithTrustedActorHeader(tc.trusted))
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/api/v1/component/my-namespace/my-service", strings.NewReader(string(componentYAML)))
+			require.NoError(t, err)
+			if tc.actor != "" {
+				req.Header.Set(actorHeader, tc.actor)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusCreated, w.Code)
+			assert.Equal(t, tc.expectedActor, actor)
+		})
+	}
+}
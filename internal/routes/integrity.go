@@ -0,0 +1,179 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bhavanki/rewind/internal/integrity"
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// integrityModeKey is the key of the integrity mode in the gin context.
+const integrityModeKey = "integrityMode"
+
+// withIntegrityMode puts the integrity mode in the gin context of each
+// request.
+func withIntegrityMode(mode integrity.Mode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(integrityModeKey, mode)
+	}
+}
+
+func integrityMode(c *gin.Context) integrity.Mode {
+	mode, ok := c.Get(integrityModeKey)
+	if !ok {
+		return integrity.ModeOff
+	}
+	return mode.(integrity.Mode)
+}
+
+// checkRefs checks that the refs in the spec of an entity about to be written
+// resolve, as the integrity mode requires. In warn mode, it returns a warning
+// for each unresolved ref. In strict mode, it responds with 422 if any ref is
+// unresolved, and returns false; unresolved refs in mutual fields are only
+// warned about, so that entities can refer to each other.
+func checkRefs(c *gin.Context, store store.Store, value any) ([]string, bool) {
+	mode := integrityMode(c)
+	if mode == integrity.ModeOff {
+		return nil, true
+	}
+
+	unresolved, err := integrity.Unresolved(c.Request.Context(), store, value)
+	if err != nil {
+		respondStoreError(c, err, "failed to check entity refs", model.EntityRef{})
+		return nil, false
+	}
+	var warnings, rejected []string
+	for _, specRef := range unresolved {
+		if mode == integrity.ModeStrict && !integrity.Mutual(specRef) {
+			rejected = append(rejected, integrity.Describe(specRef))
+			continue
+		}
+		warnings = append(warnings, integrity.Describe(specRef))
+	}
+	if len(rejected) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":      "unresolved entity refs",
+			"unresolved": rejected,
+		})
+		return nil, false
+	}
+	return warnings, true
+}
+
+// checkRestoredRefs checks, in strict mode, that the refs in the spec of the
+// entity that restoring a ref would bring back resolve, as checkRefs does. The
+// entity is read from the history that its deletion recorded. If there is no
+// such deletion, the check is left to the store, which fails the restore.
+func checkRestoredRefs(c *gin.Context, st store.Store, ref model.EntityRef) bool {
+	if integrityMode(c) != integrity.ModeStrict {
+		return true
+	}
+
+	revisions, _, err := st.ListRevisions(c.Request.Context(), ref, store.Pagination{Limit: 1})
+	if errors.Is(err, store.ErrNotFound) {
+		return true
+	}
+	if err != nil {
+		respondStoreError(c, err, "failed to read entity history", ref)
+		return false
+	}
+	if len(revisions) == 0 || revisions[0].Operation != model.OperationDelete {
+		return true
+	}
+	value, err := decodeEntity(ref.Kind, []byte(revisions[0].Previous))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to read deleted entity: %s", err)})
+		return false
+	}
+	_, ok := checkRefs(c, st, value)
+	return ok
+}
+
+// decodeEntity decodes the YAML of an entity of a kind.
+func decodeEntity(kind string, data []byte) (any, error) {
+	switch kind {
+	case model.KindComponent:
+		return decode[model.Component](data)
+	case model.KindAPI:
+		return decode[model.API](data)
+	case model.KindUser:
+		return decode[model.User](data)
+	case model.KindGroup:
+		return decode[model.Group](data)
+	case model.KindSystem:
+		return decode[model.System](data)
+	case model.KindResource:
+		return decode[model.Resource](data)
+	case model.KindDomain:
+		return decode[model.Domain](data)
+	case model.KindLocation:
+		return decode[model.Location](data)
+	case model.KindTemplate:
+		return decode[model.Template](data)
+	default:
+		return nil, fmt.Errorf("unsupported kind %s", kind)
+	}
+}
+
+func decode[T any](data []byte) (any, error) {
+	var value T
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// respondWritten responds to a write with a status, and with any warnings
+// about the entity that was written.
+func respondWritten(c *gin.Context, status int, warnings []string) {
+	if len(warnings) == 0 {
+		c.Status(status)
+		return
+	}
+	c.JSON(status, gin.H{"warnings": warnings})
+}
+
+// checkReferrers checks, in strict mode, that no other entity refers to an
+// entity about to be deleted, unless the force query parameter is true. If
+// any does, it responds with 409 and returns false.
+func checkReferrers(c *gin.Context, store store.Store, ref model.EntityRef) bool {
+	if integrityMode(c) != integrity.ModeStrict {
+		return true
+	}
+	if v := c.Query("force"); v != "" {
+		force, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid force %s", v)})
+			return false
+		}
+		if force {
+			return true
+		}
+	}
+
+	referrers, err := store.ListReferrers(c.Request.Context(), ref)
+	if err != nil {
+		respondStoreError(c, err, "failed to list referrers", ref)
+		return false
+	}
+	var others []model.Referrer
+	for _, referrer := range referrers {
+		if referrer.EntityRef != ref {
+			others = append(others, referrer)
+		}
+	}
+	if len(others) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     fmt.Sprintf("%s is referred to by other entities", ref),
+			"referrers": others,
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,308 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhavanki/rewind/internal/integrity"
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	integrityGroup = model.Group{
+		Entity: model.Entity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       model.KindGroup,
+			Metadata:   model.Metadata{Namespace: "default", Name: "team"},
+		},
+		Spec: model.GroupSpec{Type: "team"},
+	}
+	integrityComponent = model.Component{
+		Entity: model.Entity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       model.KindComponent,
+			Metadata:   model.Metadata{Namespace: "default", Name: "service"},
+		},
+		Spec: model.ComponentSpec{
+			Type:         model.ComponentTypeService,
+			Lifecycle:    model.ComponentLifecycleProduction,
+			Owner:        model.EntityRef{Name: "team"},
+			ProvidesAPIs: []model.EntityRef{{Kind: model.KindAPI, Namespace: "default", Name: "missing"}},
+		},
+	}
+)
+
+// sendEntity sends an entity as YAML to a route.
+func sendEntity(t *testing.T, r *gin.Engine, method string, path string, value any) *httptest.ResponseRecorder {
+	body, err := yaml.Marshal(value)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(method, path, bytes.NewReader(body))
+	require.NoError(t, err)
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateEntity_Integrity(t *testing.T) {
+	type testCase struct {
+		mode             integrity.Mode
+		expectedStatus   int
+		expectedWarnings []string
+		description      string
+	}
+	tcs := []testCase{
+		{
+			mode:           integrity.ModeOff,
+			expectedStatus: http.StatusCreated,
+			description:    "off",
+		},
+		{
+			mode:             integrity.ModeWarn,
+			expectedStatus:   http.StatusCreated,
+			expectedWarnings: []string{"spec.providesApis: api:default/missing does not exist"},
+			description:      "warn",
+		},
+		{
+			mode:           integrity.ModeStrict,
+			expectedStatus: http.StatusUnprocessableEntity,
+			description:    "strict",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			r := gin.Default()
+			st := store.NewMemoryStore()
+			SetupRoutes(r, st, WithIntegrityMode(tc.mode))
+
+			w := sendEntity(t, r, "POST", "/api/v1/group/default/team", integrityGroup)
+			require.Equal(t, http.StatusCreated, w.Code)
+			assert.Empty(t, w.Body.String())
+
+			w = sendEntity(t, r, "POST", "/api/v1/component/default/service", integrityComponent)
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			_, err := st.ReadComponent(context.Background(), model.EntityRef{Kind: model.KindComponent, Namespace: "default", Name: "service"})
+			if tc.expectedStatus != http.StatusCreated {
+				assert.ErrorIs(t, err, store.ErrNotFound, "the component is not stored")
+				var body struct {
+					Unresolved []string `json:"unresolved"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, []string{"spec.providesApis: api:default/missing does not exist"}, body.Unresolved)
+				return
+			}
+			assert.NoError(t, err)
+			if tc.expectedWarnings == nil {
+				assert.Empty(t, w.Body.String())
+				return
+			}
+			var body struct {
+				Warnings []string `json:"warnings"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tc.expectedWarnings, body.Warnings)
+		})
+	}
+}
+
+func TestUpdateEntity_Integrity(t *testing.T) {
+	r := gin.Default()
+	st := store.NewMemoryStore()
+	SetupRoutes(r, st, WithIntegrityMode(integrity.ModeStrict))
+
+	w := sendEntity(t, r, "POST", "/api/v1/group/default/team", integrityGroup)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	group := integrityGroup
+	group.Spec.Members = []model.EntityRef{{Name: "missing"}}
+	w = sendEntity(t, r, "PUT", "/api/v1/group/default/team", group)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	group = integrityGroup
+	group.Spec.Children = []model.EntityRef{{Name: "team"}}
+	w = sendEntity(t, r, "PUT", "/api/v1/group/default/team", group)
+	assert.Equal(t, http.StatusAccepted, w.Code, "a ref to the entity itself resolves")
+}
+
+func TestCreateEntity_IntegrityMutualRefs(t *testing.T) {
+	r := gin.Default()
+	st := store.NewMemoryStore()
+	SetupRoutes(r, st, WithIntegrityMode(integrity.ModeStrict))
+
+	w := sendEntity(t, r, "POST", "/api/v1/group/default/team", integrityGroup)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// A parent and a child that list each other, and two components that
+	// depend on each other, can be created one after the other.
+	parent := integrityGroup
+	parent.Metadata.Name = "parent"
+	parent.Spec.Children = []model.EntityRef{{Name: "child"}}
+	child := integrityGroup
+	child.Metadata.Name = "child"
+	child.Spec.Parent = model.EntityRef{Name: "parent"}
+	app := integrityComponent
+	app.Metadata.Name = "app"
+	app.Spec.ProvidesAPIs = nil
+	app.Spec.DependsOn = []model.EntityRef{{Kind: model.KindResource, Name: "db"}}
+	db := model.Resource{
+		Entity: model.Entity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       model.KindResource,
+			Metadata:   model.Metadata{Namespace: "default", Name: "db"},
+		},
+		Spec: model.ResourceSpec{
+			Type:         "database",
+			Owner:        model.EntityRef{Name: "team"},
+			DependencyOf: []model.EntityRef{{Kind: model.KindComponent, Name: "app"}},
+		},
+	}
+
+	type testCase struct {
+		path             string
+		value            any
+		expectedWarnings []string
+	}
+	for _, tc := range []testCase{
+		{"/api/v1/group/default/parent", parent, []string{"spec.children: group:default/child does not exist"}},
+		{"/api/v1/group/default/child", child, nil},
+		{"/api/v1/component/default/app", app, []string{"spec.dependsOn: resource:default/db does not exist"}},
+		{"/api/v1/resource/default/db", db, nil},
+	} {
+		w := sendEntity(t, r, "POST", tc.path, tc.value)
+		require.Equal(t, http.StatusCreated, w.Code, tc.path)
+		if tc.expectedWarnings == nil {
+			assert.Empty(t, w.Body.String(), tc.path)
+			continue
+		}
+		var body struct {
+			Warnings []string `json:"warnings"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, tc.expectedWarnings, body.Warnings, tc.path)
+	}
+
+	// Other unresolved refs are still rejected alongside mutual ones.
+	other := child
+	other.Metadata.Name = "other"
+	other.Spec.Parent = model.EntityRef{Name: "missing"}
+	other.Spec.Members = []model.EntityRef{{Name: "nobody"}}
+	w = sendEntity(t, r, "POST", "/api/v1/group/default/other", other)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var body struct {
+		Unresolved []string `json:"unresolved"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []string{"spec.members: user:default/nobody does not exist"}, body.Unresolved)
+}
+
+func TestDeleteEntity_Integrity(t *testing.T) {
+	type testCase struct {
+		mode           integrity.Mode
+		query          string
+		expectedStatus int
+		description    string
+	}
+	tcs := []testCase{
+		{
+			mode:           integrity.ModeWarn,
+			expectedStatus: http.StatusOK,
+			description:    "warn",
+		},
+		{
+			mode:           integrity.ModeStrict,
+			expectedStatus: http.StatusConflict,
+			description:    "strict",
+		},
+		{
+			mode:           integrity.ModeStrict,
+			query:          "?force=true",
+			expectedStatus: http.StatusOK,
+			description:    "strict with force",
+		},
+		{
+			mode:           integrity.ModeStrict,
+			query:          "?force=false",
+			expectedStatus: http.StatusConflict,
+			description:    "strict without force",
+		},
+		{
+			mode:           integrity.ModeStrict,
+			query:          "?force=always",
+			expectedStatus: http.StatusBadRequest,
+			description:    "strict with bad force",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			r := gin.Default()
+			st := store.NewMemoryStore()
+			SetupRoutes(r, st)
+			w := sendEntity(t, r, "POST", "/api/v1/group/default/team", integrityGroup)
+			require.Equal(t, http.StatusCreated, w.Code)
+			w = sendEntity(t, r, "POST", "/api/v1/component/default/service", integrityComponent)
+			require.Equal(t, http.StatusCreated, w.Code)
+
+			r = gin.Default()
+			SetupRoutes(r, st, WithIntegrityMode(tc.mode))
+
+			w = httptest.NewRecorder()
+			req, err := http.NewRequest("DELETE", "/api/v1/group/default/team"+tc.query, nil)
+			require.NoError(t, err)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			_, err = st.ReadGroup(context.Background(), model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "team"})
+			if tc.expectedStatus == http.StatusOK {
+				assert.ErrorIs(t, err, store.ErrNotFound)
+			} else {
+				assert.NoError(t, err, "the group is not deleted")
+			}
+		})
+	}
+}
+
+func TestRestoreEntity_Integrity(t *testing.T) {
+	r := gin.Default()
+	st := store.NewMemoryStore()
+	SetupRoutes(r, st, WithIntegrityMode(integrity.ModeStrict))
+	send := func(method string, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(method, path, nil)
+		require.NoError(t, err)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	component := integrityComponent
+	component.Spec.ProvidesAPIs = nil
+	w := sendEntity(t, r, "POST", "/api/v1/group/default/team", integrityGroup)
+	require.Equal(t, http.StatusCreated, w.Code)
+	w = sendEntity(t, r, "POST", "/api/v1/component/default/service", component)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, http.StatusOK, send("DELETE", "/api/v1/component/default/service").Code)
+	require.Equal(t, http.StatusOK, send("DELETE", "/api/v1/group/default/team").Code)
+
+	w = send("POST", "/api/v1/component/default/service/restore")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "restoring with a missing owner")
+	var body struct {
+		Unresolved []string `json:"unresolved"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []string{"spec.owner: group:default/team does not exist"}, body.Unresolved)
+	_, err := st.ReadComponent(context.Background(), component.EntityRef())
+	assert.ErrorIs(t, err, store.ErrNotFound, "the component is not restored")
+
+	assert.Equal(t, http.StatusOK, send("POST", "/api/v1/group/default/team/restore").Code)
+	assert.Equal(t, http.StatusOK, send("POST", "/api/v1/component/default/service/restore").Code, "restoring with the owner back")
+	assert.Equal(t, http.StatusNotFound, send("POST", "/api/v1/component/default/unknown/restore").Code)
+}
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/gin-gonic/gin"
+)
+
+// ListEntityRelations lists the relations of an entity, both those that its
+// spec makes and those that other entities make to it.
+func ListEntityRelations(c *gin.Context, st store.Store) {
+	ref := expectedEntityRef(c)
+
+	relations, err := st.ListRelations(c.Request.Context(), ref)
+	if err != nil {
+		respondStoreError(c, err, "failed to list relations", ref)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.EntityRelations{
+		EntityRef: ref,
+		Relations: relations,
+	})
+}
+
+// ListEntityReferrers lists the entities whose specs refer to an entity, with
+// the fields that hold the refs. The entity need not exist, so that refs to a
+// missing entity can be found.
+func ListEntityReferrers(c *gin.Context, st store.Store) {
+	ref := expectedEntityRef(c)
+
+	referrers, err := st.ListReferrers(c.Request.Context(), ref)
+	if err != nil {
+		respondStoreError(c, err, "failed to list referrers", ref)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.EntityReferrers{
+		EntityRef: ref,
+		Referrers: referrers,
+	})
+}
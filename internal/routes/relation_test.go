@@ -0,0 +1,135 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var testRelations = []model.Relation{
+	{Type: model.RelationOwnedBy, TargetRef: model.TestOwnerEntityRef},
+	{Type: model.RelationProvidesAPI, TargetRef: model.TestAPI1EntityRef},
+}
+
+func relationsMock() *store.StoreMock {
+	return &store.StoreMock{
+		ListRelationsFunc: func(ctx context.Context, ref model.EntityRef) ([]model.Relation, error) {
+			if ref != model.TestComponentEntityRef {
+				return nil, fmt.Errorf("%s %w", ref, store.ErrNotFound)
+			}
+			return testRelations, nil
+		},
+		ReadComponentFunc: func(ctx context.Context, ref model.EntityRef) (model.Component, error) {
+			c := model.TestFullComponent
+			c.Metadata.Namespace = ref.Namespace
+			c.Metadata.Name = ref.Name
+			return c, nil
+		},
+	}
+}
+
+func TestListEntityRelations(t *testing.T) {
+	r := gin.Default()
+	SetupRoutes(r, relationsMock())
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/component/default/component/relations", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var relations model.EntityRelations
+	err = json.Unmarshal(w.Body.Bytes(), &relations)
+	assert.NoError(t, err)
+	assert.Equal(t, model.EntityRelations{
+		EntityRef: model.TestComponentEntityRef,
+		Relations: testRelations,
+	}, relations)
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/api/v1/component/default/unknown/relations", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestReadEntity_Relations(t *testing.T) {
+	r := gin.Default()
+	SetupRoutes(r, relationsMock())
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/component/default/component", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var component model.Component
+	err = yaml.Unmarshal(w.Body.Bytes(), &component)
+	assert.NoError(t, err)
+	assert.Equal(t, testRelations, component.Relations)
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/api/v1/component/default/unknown", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "failing to list relations")
+}
+
+func TestListEntityReferrers(t *testing.T) {
+	referrers := []model.Referrer{
+		{EntityRef: model.TestComponentEntityRef, Field: "spec.owner"},
+		{EntityRef: model.TestAPI1EntityRef, Field: "spec.owner"},
+	}
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListReferrersFunc: func(ctx context.Context, ref model.EntityRef) ([]model.Referrer, error) {
+			if ref != model.TestOwnerEntityRef {
+				return []model.Referrer{}, nil
+			}
+			return referrers, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/user/default/owner/referrers", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body model.EntityReferrers
+	err = json.Unmarshal(w.Body.Bytes(), &body)
+	assert.NoError(t, err)
+	assert.Equal(t, model.EntityReferrers{
+		EntityRef: model.TestOwnerEntityRef,
+		Referrers: referrers,
+	}, body)
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/api/v1/group/default/unknown/referrers", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	err = json.Unmarshal(w.Body.Bytes(), &body)
+	assert.NoError(t, err)
+	assert.Empty(t, body.Referrers, "no referrers to an unknown entity")
+}
@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/bhavanki/rewind/internal/consistency"
+	"github.com/bhavanki/rewind/internal/integrity"
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/gin-gonic/gin"
+)
+
+// ReportDanglingRefs lists the refs in entity specs that name no stored
+// entity, grouped by the owners of the entities with the refs.
+func ReportDanglingRefs(c *gin.Context, st store.Store) {
+	report, err := integrity.Report(c.Request.Context(), st)
+	if err != nil {
+		respondStoreError(c, err, "failed to report dangling refs", model.EntityRef{})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ReportAsymmetries lists the refs in pairs of inverse spec fields, such as
+// dependsOn and dependencyOf, that the targets do not give back. The report
+// is read from spec refs, since relations already merge inverses.
+func ReportAsymmetries(c *gin.Context, st store.Store) {
+	asymmetries, err := consistency.Asymmetries(c.Request.Context(), st)
+	if err != nil {
+		respondStoreError(c, err, "failed to report asymmetries", model.EntityRef{})
+		return
+	}
+	c.JSON(http.StatusOK, model.AsymmetriesReport{
+		Asymmetries: asymmetries,
+		Total:       len(asymmetries),
+	})
+}
+
+// SynthesizeAsymmetries writes the missing inverse ref of each asymmetry into
+// the spec of its target, where it can do so without replacing another, and
+// responds with the asymmetries that it resolved and those that it skipped.
+func SynthesizeAsymmetries(c *gin.Context, st store.Store) {
+	report, err := consistency.Synthesize(c.Request.Context(), st)
+	if err != nil {
+		respondStoreError(c, err, "failed to synthesize inverse refs", model.EntityRef{})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
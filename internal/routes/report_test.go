@@ -0,0 +1,146 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportDanglingRefs(t *testing.T) {
+	danglingRef := model.DanglingRef{
+		EntityRef: model.TestComponentEntityRef,
+		Owner:     model.TestOwnerEntityRef,
+		Field:     "spec.providesApis",
+		TargetRef: model.TestAPI1EntityRef,
+	}
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListDanglingRefsFunc: func(ctx context.Context) ([]model.DanglingRef, error) {
+			return []model.DanglingRef{danglingRef}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/reports/dangling-refs", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var report model.DanglingRefsReport
+	err = json.Unmarshal(w.Body.Bytes(), &report)
+	assert.NoError(t, err)
+	assert.Equal(t, model.DanglingRefsReport{
+		Owners: []model.OwnerDanglingRefs{
+			{Owner: model.TestOwnerEntityRef, Refs: []model.DanglingRef{danglingRef}},
+		},
+		Total: 1,
+	}, report)
+
+	s.ListDanglingRefsFunc = func(ctx context.Context) ([]model.DanglingRef, error) {
+		return nil, store.ErrTimeout
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+}
+
+func TestReportAsymmetries(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		ListSpecRefsFunc: func(ctx context.Context, fields []string) ([]model.EntitySpecRef, error) {
+			return []model.EntitySpecRef{
+				{
+					EntityRef: model.TestGroupEntityRef,
+					SpecRef:   model.SpecRef{Field: "spec.parent", Relation: model.RelationChildOf, Ref: model.TestGroup2EntityRef},
+				},
+			}, nil
+		},
+		ListExistingRefsFunc: func(ctx context.Context, refs []model.EntityRef) ([]model.EntityRef, error) {
+			return []model.EntityRef{model.TestGroup2EntityRef}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/reports/asymmetries", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var report model.AsymmetriesReport
+	err = json.Unmarshal(w.Body.Bytes(), &report)
+	assert.NoError(t, err)
+	assert.Equal(t, model.AsymmetriesReport{
+		Asymmetries: []model.Asymmetry{
+			{
+				EntityRef:    model.TestGroupEntityRef,
+				Field:        "spec.parent",
+				TargetRef:    model.TestGroup2EntityRef,
+				InverseField: "spec.children",
+				InverseRefs:  []model.EntityRef{},
+			},
+		},
+		Total: 1,
+	}, report)
+
+	s.ListSpecRefsFunc = func(ctx context.Context, fields []string) ([]model.EntitySpecRef, error) {
+		return nil, store.ErrTimeout
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+}
+
+func TestSynthesizeAsymmetries(t *testing.T) {
+	ctx := context.Background()
+	r := gin.Default()
+	st := store.NewMemoryStore()
+	SetupRoutes(r, st)
+	parent := model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "parent"}
+	child := model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "child"}
+	for _, group := range []model.Group{
+		{
+			Entity: model.Entity{APIVersion: "backstage.io/v1alpha1", Kind: model.KindGroup, Metadata: model.Metadata{Namespace: "default", Name: "parent"}},
+			Spec:   model.GroupSpec{Type: "team", Children: []model.EntityRef{child}},
+		},
+		{
+			Entity: model.Entity{APIVersion: "backstage.io/v1alpha1", Kind: model.KindGroup, Metadata: model.Metadata{Namespace: "default", Name: "child"}},
+			Spec:   model.GroupSpec{Type: "team"},
+		},
+	} {
+		_, err := st.CreateGroup(ctx, group)
+		require.NoError(t, err)
+	}
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/api/v1/reports/asymmetries/synthesize", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var report model.SynthesisReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, model.SynthesisReport{
+		Synthesized: []model.Asymmetry{
+			{EntityRef: parent, Field: "spec.children", TargetRef: child, InverseField: "spec.parent", InverseRefs: []model.EntityRef{}},
+		},
+		Skipped: []model.Asymmetry{},
+	}, report)
+	group, err := st.ReadGroup(ctx, child)
+	require.NoError(t, err)
+	assert.Equal(t, parent, group.Spec.Parent)
+}
@@ -3,22 +3,71 @@ package routes
 import (
 	"net/http"
 
-	"github.com/bhavanki/rewind/internal/store"
+	"github.com/bhavanki/rewind/internal/integrity"
+	"github.com/bhavanki/rewind/pkg/store"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(r *gin.Engine, store store.Store) {
+// Option is an option for the routes that SetupRoutes sets up.
+type Option func(*options)
+
+type options struct {
+	integrityMode      integrity.Mode
+	trustedActorHeader bool
+}
+
+// WithIntegrityMode sets how the entity refs in written entities are
+// checked. Without it, they are not checked.
+func WithIntegrityMode(mode integrity.Mode) Option {
+	return func(o *options) {
+		o.integrityMode = mode
+	}
+}
+
+// WithTrustedActorHeader sets whether the actor named in the X-Rewind-Actor
+// header of a request is recorded in entity history. The header is not
+// authenticated, so it should only be trusted when every request comes
+// through a proxy that sets it. Without it, the header is ignored.
+func WithTrustedActorHeader(trusted bool) Option {
+	return func(o *options) {
+		o.trustedActorHeader = trusted
+	}
+}
+
+// SetupRoutes sets up the API routes.
+func SetupRoutes(r *gin.Engine, store store.Store, opts ...Option) {
+	o := options{integrityMode: integrity.ModeOff}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	r.Use(withActor(o.trustedActorHeader))
+	r.Use(withIntegrityMode(o.integrityMode))
+
 	r.GET("/api/v1/ping", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"message": "pong",
 		})
 	})
 
+	r.GET("/api/v1/entities", withStore(store, ListAllEntities))
+	r.GET("/api/v1/search", withStore(store, SearchEntities))
+	r.GET("/api/v1/by-uid/:uid", withStore(store, ReadEntityByUID))
+	r.GET("/api/v1/reports/dangling-refs", withStore(store, ReportDanglingRefs))
+	r.GET("/api/v1/reports/asymmetries", withStore(store, ReportAsymmetries))
+	r.POST("/api/v1/reports/asymmetries/synthesize", withStore(store, SynthesizeAsymmetries))
+
 	r.GET("/api/v1/:kind/:namespace/:name", withStore(store, ReadEntity))
 	r.POST("/api/v1/:kind/:namespace/:name", withStore(store, CreateEntity))
 	r.PUT("/api/v1/:kind/:namespace/:name", withStore(store, UpdateEntity))
 	r.DELETE("/api/v1/:kind/:namespace/:name", withStore(store, DeleteEntity))
 	r.GET("/api/v1/:kind", withStore(store, ListEntities))
+
+	r.GET("/api/v1/:kind/:namespace/:name/systems", withStore(store, ListDomainSystems))
+	r.GET("/api/v1/:kind/:namespace/:name/history", withStore(store, ListEntityHistory))
+	r.GET("/api/v1/:kind/:namespace/:name/relations", withStore(store, ListEntityRelations))
+	r.GET("/api/v1/:kind/:namespace/:name/referrers", withStore(store, ListEntityReferrers))
+	r.POST("/api/v1/:kind/:namespace/:name/restore", withStore(store, RestoreEntity))
 }
 
 type storeHandlerFunc func(*gin.Context, store.Store)
@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/gin-gonic/gin"
+)
+
+// SearchEntities performs a full-text search over entity names, titles,
+// descriptions, tags and annotation values, returning the best matches
+// first.
+func SearchEntities(c *gin.Context, st store.Store) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing search query q"})
+		return
+	}
+	pagination, err := processPaginationParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("bad list parameter: %s", err)})
+		return
+	}
+
+	matches, nextPagination, err := st.SearchEntities(c.Request.Context(), query, pagination)
+	if errors.Is(err, store.ErrSearchUnavailable) {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondStoreError(c, err, "failed to search entities", model.EntityRef{})
+		return
+	}
+
+	results := model.SearchResults{
+		Results:    make([]model.EntityRef, len(matches)),
+		Snippets:   make([]string, len(matches)),
+		Limit:      nextPagination.Limit,
+		NextOffset: nextPagination.Offset,
+	}
+	for i, match := range matches {
+		results.Results[i] = match.EntityRef
+		results.Snippets[i] = match.Snippet
+	}
+	c.JSON(http.StatusOK, results)
+}
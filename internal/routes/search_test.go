@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchEntities(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		SearchEntitiesFunc: func(ctx context.Context, query string, pagination store.Pagination) ([]store.SearchMatch, store.Pagination, error) {
+			return []store.SearchMatch{
+				{
+					EntityRef: model.TestComponentEntityRef,
+					Snippet:   "takes card <mark>payments</mark>",
+				},
+				{
+					EntityRef: model.TestSystemEntityRef,
+					Snippet:   "<mark>payments</mark> platform",
+				},
+			}, store.Pagination{Limit: pagination.Limit, Offset: pagination.Offset + 2}, nil
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/search?q=payments&limit=2&offset=4", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results model.SearchResults
+	err = json.Unmarshal(w.Body.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Equal(t, model.SearchResults{
+		Results:    []model.EntityRef{model.TestComponentEntityRef, model.TestSystemEntityRef},
+		Limit:      2,
+		NextOffset: 6,
+		Snippets:   []string{"takes card <mark>payments</mark>", "<mark>payments</mark> platform"},
+	}, results)
+
+	calls := s.SearchEntitiesCalls()
+	assert.Equal(t, 1, len(calls))
+	assert.Equal(t, "payments", calls[0].Query)
+	assert.Equal(t, store.Pagination{Limit: 2, Offset: 4}, calls[0].Pagination)
+}
+
+func TestSearchEntities_MissingQuery(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/search?q=+", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, s.SearchEntitiesCalls())
+}
+
+func TestSearchEntities_Unavailable(t *testing.T) {
+	r := gin.Default()
+	s := &store.StoreMock{
+		SearchEntitiesFunc: func(ctx context.Context, query string, pagination store.Pagination) ([]store.SearchMatch, store.Pagination, error) {
+			return nil, store.Pagination{}, store.ErrSearchUnavailable
+		},
+	}
+	SetupRoutes(r, s)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/search?q=payments", nil)
+	require.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
@@ -0,0 +1,136 @@
+package routes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bhavanki/rewind/pkg/store"
+)
+
+var setRequirementPattern = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+
+// parseLabelSelector parses a Kubernetes-style label selector, such as
+// "tier=backend,env!=dev,team in (a,b)", into label filters. Supported
+// requirements are "key", "!key", "key=value", "key==value", "key!=value",
+// "key in (v1,v2)" and "key notin (v1,v2)".
+func parseLabelSelector(selector string) ([]store.Filter, error) {
+	return parseSelector(selector, store.LabelFilterKeyPrefix, false)
+}
+
+// parseFieldSelector parses a selector over entity field paths, such as
+// "spec.lifecycle=production,spec.owner=group:default/payments". It accepts
+// the same requirements as a label selector, plus one extension to the
+// Kubernetes syntax: "key^=value" matches values that start with a prefix.
+// Label selectors do not accept the extension.
+func parseFieldSelector(selector string) ([]store.Filter, error) {
+	return parseSelector(selector, "", true)
+}
+
+// parseSelector parses a selector into filters on keys with a prefix. The
+// prefix requirement is only accepted if extended is true.
+func parseSelector(selector string, keyPrefix string, extended bool) ([]store.Filter, error) {
+	requirements, err := splitRequirements(selector)
+	if err != nil {
+		return nil, err
+	}
+	filters := make([]store.Filter, 0, len(requirements))
+	for _, requirement := range requirements {
+		filter, err := parseRequirement(requirement)
+		if err != nil {
+			return nil, err
+		}
+		if filter.Operator == store.FilterPrefix && !extended {
+			return nil, fmt.Errorf("unsupported operator ^= in requirement %q", requirement)
+		}
+		filter.Key = keyPrefix + filter.Key
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// splitRequirements splits a selector on the commas that are not inside a
+// parenthesized value set.
+func splitRequirements(selector string) ([]string, error) {
+	var requirements []string
+	depth := 0
+	start := 0
+	for i, r := range selector {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses in selector %q", selector)
+			}
+		case ',':
+			if depth == 0 {
+				requirements = append(requirements, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses in selector %q", selector)
+	}
+	requirements = append(requirements, selector[start:])
+
+	for i, requirement := range requirements {
+		requirements[i] = strings.TrimSpace(requirement)
+		if requirements[i] == "" {
+			return nil, fmt.Errorf("empty requirement in selector %q", selector)
+		}
+	}
+	return requirements, nil
+}
+
+func parseRequirement(requirement string) (store.Filter, error) {
+	if key, found := strings.CutPrefix(requirement, "!"); found {
+		return keyFilter(strings.TrimSpace(key), store.FilterNotExists, "", nil)
+	}
+
+	if m := setRequirementPattern.FindStringSubmatch(requirement); m != nil {
+		operator := store.FilterIn
+		if m[2] == "notin" {
+			operator = store.FilterNotIn
+		}
+		var values []string
+		for _, value := range strings.Split(m[3], ",") {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return store.Filter{}, fmt.Errorf("empty value in requirement %q", requirement)
+			}
+			values = append(values, value)
+		}
+		return keyFilter(m[1], operator, "", values)
+	}
+
+	if i := strings.Index(requirement, "="); i >= 0 {
+		key, value := requirement[:i], requirement[i+1:]
+		operator := store.FilterEquals
+		switch {
+		case strings.HasSuffix(key, "!"):
+			key, operator = strings.TrimSuffix(key, "!"), store.FilterNotEquals
+		case strings.HasSuffix(key, "^"):
+			key, operator = strings.TrimSuffix(key, "^"), store.FilterPrefix
+		default:
+			value = strings.TrimPrefix(value, "=")
+		}
+		return keyFilter(strings.TrimSpace(key), operator, strings.TrimSpace(value), nil)
+	}
+
+	return keyFilter(requirement, store.FilterExists, "", nil)
+}
+
+func keyFilter(key string, operator store.FilterOperator, value string, values []string) (store.Filter, error) {
+	if key == "" || strings.ContainsAny(key, " \t!^=(),") {
+		return store.Filter{}, fmt.Errorf("invalid key %q", key)
+	}
+	return store.Filter{
+		Key:      key,
+		Value:    value,
+		Operator: operator,
+		Values:   values,
+	}, nil
+}
@@ -0,0 +1,192 @@
+package routes
+
+import (
+	"testing"
+
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	type testCase struct {
+		selector    string
+		expected    []store.Filter
+		err         bool
+		description string
+	}
+	tcs := []testCase{
+		{
+			selector: "tier=backend",
+			expected: []store.Filter{
+				{Key: "metadata.labels.tier", Value: "backend"},
+			},
+			description: "equals",
+		},
+		{
+			selector: "tier==backend",
+			expected: []store.Filter{
+				{Key: "metadata.labels.tier", Value: "backend"},
+			},
+			description: "double equals",
+		},
+		{
+			selector: "env != dev",
+			expected: []store.Filter{
+				{Key: "metadata.labels.env", Value: "dev", Operator: store.FilterNotEquals},
+			},
+			description: "not equals",
+		},
+		{
+			selector: "team in (a, b)",
+			expected: []store.Filter{
+				{Key: "metadata.labels.team", Operator: store.FilterIn, Values: []string{"a", "b"}},
+			},
+			description: "in",
+		},
+		{
+			selector: "team notin (a)",
+			expected: []store.Filter{
+				{Key: "metadata.labels.team", Operator: store.FilterNotIn, Values: []string{"a"}},
+			},
+			description: "notin",
+		},
+		{
+			selector: "app.kubernetes.io/name",
+			expected: []store.Filter{
+				{Key: "metadata.labels.app.kubernetes.io/name", Operator: store.FilterExists},
+			},
+			description: "exists",
+		},
+		{
+			selector: "!canary",
+			expected: []store.Filter{
+				{Key: "metadata.labels.canary", Operator: store.FilterNotExists},
+			},
+			description: "not exists",
+		},
+		{
+			selector: "tier=backend,env!=dev,team in (a,b),!canary",
+			expected: []store.Filter{
+				{Key: "metadata.labels.tier", Value: "backend"},
+				{Key: "metadata.labels.env", Value: "dev", Operator: store.FilterNotEquals},
+				{Key: "metadata.labels.team", Operator: store.FilterIn, Values: []string{"a", "b"}},
+				{Key: "metadata.labels.canary", Operator: store.FilterNotExists},
+			},
+			description: "multiple requirements",
+		},
+		{
+			selector: "tier=",
+			expected: []store.Filter{
+				{Key: "metadata.labels.tier", Value: ""},
+			},
+			description: "empty value",
+		},
+		{
+			selector:    "tier=backend,",
+			err:         true,
+			description: "trailing comma",
+		},
+		{
+			selector:    "team in (a,b",
+			err:         true,
+			description: "unbalanced parentheses",
+		},
+		{
+			selector:    "team in (a,,b)",
+			err:         true,
+			description: "empty set value",
+		},
+		{
+			selector:    "=backend",
+			err:         true,
+			description: "empty key",
+		},
+		{
+			selector:    "my tier=backend",
+			err:         true,
+			description: "invalid key",
+		},
+		{
+			selector:    "tier^=back",
+			err:         true,
+			description: "prefix is not label selector syntax",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			filters, err := parseLabelSelector(tc.selector)
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, filters)
+			}
+		})
+	}
+}
+
+func TestParseFieldSelector(t *testing.T) {
+	type testCase struct {
+		selector    string
+		expected    []store.Filter
+		err         bool
+		description string
+	}
+	tcs := []testCase{
+		{
+			selector: "spec.lifecycle=production,spec.owner=group:default/payments",
+			expected: []store.Filter{
+				{Key: "spec.lifecycle", Value: "production"},
+				{Key: "spec.owner", Value: "group:default/payments"},
+			},
+			description: "equals",
+		},
+		{
+			selector: "metadata.name^=payments-",
+			expected: []store.Filter{
+				{Key: "metadata.name", Value: "payments-", Operator: store.FilterPrefix},
+			},
+			description: "prefix extension",
+		},
+		{
+			selector: "metadata.name^=payments-,metadata.tags^=",
+			expected: []store.Filter{
+				{Key: "metadata.name", Value: "payments-", Operator: store.FilterPrefix},
+				{Key: "metadata.tags", Value: "", Operator: store.FilterPrefix},
+			},
+			description: "prefix extension with other requirements",
+		},
+		{
+			selector: "metadata.annotations.backstage.io/techdocs-ref=url:https://example.com/a=b",
+			expected: []store.Filter{
+				{Key: "metadata.annotations.backstage.io/techdocs-ref", Value: "url:https://example.com/a=b"},
+			},
+			description: "value containing equals sign",
+		},
+		{
+			selector: "metadata.tags in (java,go)",
+			expected: []store.Filter{
+				{Key: "metadata.tags", Operator: store.FilterIn, Values: []string{"java", "go"}},
+			},
+			description: "in",
+		},
+		{
+			selector:    "^=x",
+			err:         true,
+			description: "empty key",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			filters, err := parseFieldSelector(tc.selector)
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, filters)
+			}
+		})
+	}
+}
@@ -1,12 +1,16 @@
 package routes
 
 import (
+	"errors"
 	"fmt"
 	// "io"
-	// "log/slog"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
 	"github.com/gin-gonic/gin"
 )
 
@@ -29,6 +33,66 @@ func verifyEntityRef(c *gin.Context, expected model.EntityRef, actual model.Enti
 	return true
 }
 
+// entityTag formats an entity version as the value of an ETag header.
+func entityTag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatchVersion finds the entity version that the If-Match header requires
+// for a change, or zero if the header is missing or is "*". Since entity tags
+// are only ever versions, a header holding anything else, including a weak
+// tag or a list of tags, can never match, and the request fails with 412.
+func ifMatchVersion(c *gin.Context) (int64, bool) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, true
+	}
+	tag, quoted := strings.CutPrefix(ifMatch, `"`)
+	tag, closed := strings.CutSuffix(tag, `"`)
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if !quoted || !closed || err != nil || version <= 0 {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": fmt.Sprintf("If-Match %s does not match any version of the entity", ifMatch)})
+		return 0, false
+	}
+	return version, true
+}
+
+// storeErrorStatus maps an error from the store to an HTTP status.
+func storeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, store.ErrAlreadyExists), errors.Is(err, store.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, store.ErrInvalid):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, store.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, store.ErrTimeout):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondStoreError responds to a failed store operation. Errors that the
+// client can act on are reported as they are; any other error is logged and
+// reported only with the given message. The entity ref is only logged if it
+// is not empty, since list and search operations have none.
+func respondStoreError(c *gin.Context, err error, message string, entityRef model.EntityRef) {
+	status := storeErrorStatus(err)
+	if status != http.StatusInternalServerError {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if entityRef.Empty() {
+		slog.Error(message, "error", err.Error())
+	} else {
+		slog.Error(message, "entityRef", entityRef.String(), "error", err.Error())
+	}
+	c.JSON(status, gin.H{"error": message})
+}
+
 // func logRequestBody(c *gin.Context) {
 // 	b, err := io.ReadAll(c.Request.Body)
 // 	if err != nil {
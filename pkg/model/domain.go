@@ -0,0 +1,12 @@
+package model
+
+type Domain struct {
+	Entity `yaml:"entity,inline"`
+	Spec   DomainSpec `yaml:"spec"`
+}
+
+type DomainSpec struct {
+	Owner       EntityRef `yaml:"owner"`
+	SubdomainOf EntityRef `yaml:"subdomainOf,omitempty"`
+	Type        string    `yaml:"type,omitempty"`
+}
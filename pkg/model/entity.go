@@ -1,13 +1,28 @@
 package model
 
+import "time"
+
+// Entity holds the fields common to every kind. ID and Version are kept by
+// the store: Version is 1 when an entity is created, and goes up by one with
+// each update. Relations are computed from the specs of this and other
+// entities; they are filled in when an entity is served, and ignored when one
+// is stored.
 type Entity struct {
-	ID         int64    `yaml:"-"`
-	APIVersion string   `yaml:"apiVersion"`
-	Kind       string   `yaml:"kind"`
-	Metadata   Metadata `yaml:"metadata"`
+	ID         int64      `yaml:"-"`
+	Version    int64      `yaml:"-"`
+	APIVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   Metadata   `yaml:"metadata"`
+	Relations  []Relation `yaml:"relations,omitempty"`
 }
 
+// Metadata describes an entity. The UID, Etag and timestamps are kept by the
+// store, which ignores any values given for them: the UID identifies an entity
+// for as long as it exists, even if it is renamed, and the Etag changes with
+// each update.
 type Metadata struct {
+	UID         string            `yaml:"uid,omitempty"`
+	Etag        string            `yaml:"etag,omitempty"`
 	Name        string            `yaml:"name"`
 	Namespace   string            `yaml:"namespace"`
 	Title       string            `yaml:"title,omitempty"`
@@ -16,6 +31,8 @@ type Metadata struct {
 	Annotations map[string]string `yaml:"annotations,omitempty"`
 	Tags        []string          `yaml:"tags,omitempty"`
 	Links       []Link            `yaml:"links,omitempty"`
+	CreatedAt   time.Time         `yaml:"createdAt,omitempty"`
+	UpdatedAt   time.Time         `yaml:"updatedAt,omitempty"`
 }
 
 type Link struct {
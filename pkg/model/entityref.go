@@ -1,10 +1,12 @@
 package model
 
 import (
+	"cmp"
 	"database/sql"
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -58,6 +60,15 @@ func (e EntityRef) Empty() bool {
 	return e.Kind == "" && e.Namespace == "" && e.Name == ""
 }
 
+// SortEntityRefs sorts entity refs by their string form, and removes
+// duplicates.
+func SortEntityRefs(refs []EntityRef) []EntityRef {
+	slices.SortFunc(refs, func(a, b EntityRef) int {
+		return cmp.Compare(a.String(), b.String())
+	})
+	return slices.Compact(refs)
+}
+
 func (e EntityRef) MarshalYAML() (any, error) {
 	return e.String(), nil
 }
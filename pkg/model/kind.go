@@ -3,8 +3,11 @@ package model
 const (
 	KindAPI       = "api"
 	KindComponent = "component"
+	KindDomain    = "domain"
 	KindGroup     = "group"
+	KindLocation  = "location"
 	KindResource  = "resource"
 	KindSystem    = "system"
+	KindTemplate  = "template"
 	KindUser      = "user"
 )
@@ -0,0 +1,31 @@
+package model
+
+const (
+	LocationTypeFile = "file"
+	LocationTypeGit  = "git"
+
+	// AnnotationManagedByLocation records the location target that an
+	// ingested entity was read from, as "<type>:<target>".
+	AnnotationManagedByLocation = "backstage.io/managed-by-location"
+)
+
+type Location struct {
+	Entity `yaml:"entity,inline"`
+	Spec   LocationSpec `yaml:"spec"`
+}
+
+type LocationSpec struct {
+	Type    string   `yaml:"type,omitempty"`
+	Target  string   `yaml:"target,omitempty"`
+	Targets []string `yaml:"targets,omitempty"`
+}
+
+// AllTargets returns the single target, if any, followed by the list of
+// targets.
+func (s LocationSpec) AllTargets() []string {
+	targets := make([]string, 0, len(s.Targets)+1)
+	if s.Target != "" {
+		targets = append(targets, s.Target)
+	}
+	return append(targets, s.Targets...)
+}
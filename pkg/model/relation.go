@@ -0,0 +1,290 @@
+package model
+
+import (
+	"cmp"
+	"slices"
+)
+
+// The types of relation between entities, after those of Backstage. Each
+// type has an inverse; see InverseRelation.
+const (
+	RelationOwnedBy       = "ownedBy"
+	RelationOwnerOf       = "ownerOf"
+	RelationPartOf        = "partOf"
+	RelationHasPart       = "hasPart"
+	RelationProvidesAPI   = "providesApi"
+	RelationAPIProvidedBy = "apiProvidedBy"
+	RelationConsumesAPI   = "consumesApi"
+	RelationAPIConsumedBy = "apiConsumedBy"
+	RelationDependsOn     = "dependsOn"
+	RelationDependencyOf  = "dependencyOf"
+	RelationMemberOf      = "memberOf"
+	RelationHasMember     = "hasMember"
+	RelationParentOf      = "parentOf"
+	RelationChildOf       = "childOf"
+)
+
+var inverseRelations = map[string]string{
+	RelationOwnedBy:       RelationOwnerOf,
+	RelationOwnerOf:       RelationOwnedBy,
+	RelationPartOf:        RelationHasPart,
+	RelationHasPart:       RelationPartOf,
+	RelationProvidesAPI:   RelationAPIProvidedBy,
+	RelationAPIProvidedBy: RelationProvidesAPI,
+	RelationConsumesAPI:   RelationAPIConsumedBy,
+	RelationAPIConsumedBy: RelationConsumesAPI,
+	RelationDependsOn:     RelationDependencyOf,
+	RelationDependencyOf:  RelationDependsOn,
+	RelationMemberOf:      RelationHasMember,
+	RelationHasMember:     RelationMemberOf,
+	RelationParentOf:      RelationChildOf,
+	RelationChildOf:       RelationParentOf,
+}
+
+// InverseRelation returns the type of relation that runs the other way from
+// one of the given type, such as ownerOf for ownedBy.
+func InverseRelation(relationType string) string {
+	return inverseRelations[relationType]
+}
+
+// Relation is a directed relation from an entity to the one named by
+// TargetRef.
+type Relation struct {
+	Type      string    `yaml:"type" json:"type"`
+	TargetRef EntityRef `yaml:"targetRef" json:"targetRef"`
+}
+
+// SortRelations sorts relations by type and then by target, and removes
+// duplicates.
+func SortRelations(relations []Relation) []Relation {
+	slices.SortFunc(relations, func(a, b Relation) int {
+		return cmp.Or(
+			cmp.Compare(a.Type, b.Type),
+			cmp.Compare(a.TargetRef.String(), b.TargetRef.String()),
+		)
+	})
+	return slices.Compact(relations)
+}
+
+// SpecRef is an entity ref given in a field of an entity's spec, along with
+// the type of relation that it makes. The ref is qualified: a kind or
+// namespace left out of the spec is filled in as Backstage would, with the
+// kind that the field expects and the namespace of the referring entity.
+type SpecRef struct {
+	// Field is the path of the spec field, as in YAML, such as
+	// "spec.owner".
+	Field    string
+	Relation string
+	Ref      EntityRef
+}
+
+// specField describes a spec field that holds entity refs.
+type specField struct {
+	name     string
+	relation string
+	// kind is the kind of the entities that the field refers to, if they
+	// are all of one kind.
+	kind string
+}
+
+var (
+	ownerField        = specField{"owner", RelationOwnedBy, KindGroup}
+	systemField       = specField{"system", RelationPartOf, KindSystem}
+	dependsOnField    = specField{"dependsOn", RelationDependsOn, ""}
+	dependencyOfField = specField{"dependencyOf", RelationDependencyOf, ""}
+)
+
+// SpecRefs returns the entity refs in the spec of an entity of any kind, in
+// the order of the spec's fields.
+func SpecRefs(entity any) []SpecRef {
+	var refs specRefs
+	switch e := entity.(type) {
+	case Component:
+		refs.namespace = e.Metadata.Namespace
+		refs.add(ownerField, e.Spec.Owner)
+		refs.add(systemField, e.Spec.System)
+		refs.add(specField{"subcomponentOf", RelationPartOf, KindComponent}, e.Spec.SubcomponentOf)
+		refs.add(specField{"providesApis", RelationProvidesAPI, KindAPI}, e.Spec.ProvidesAPIs...)
+		refs.add(specField{"consumesApis", RelationConsumesAPI, KindAPI}, e.Spec.ConsumesAPIs...)
+		refs.add(dependsOnField, e.Spec.DependsOn...)
+		refs.add(dependencyOfField, e.Spec.DependencyOf...)
+	case API:
+		refs.namespace = e.Metadata.Namespace
+		refs.add(ownerField, e.Spec.Owner)
+		refs.add(systemField, e.Spec.System)
+	case User:
+		refs.namespace = e.Metadata.Namespace
+		refs.add(specField{"memberOf", RelationMemberOf, KindGroup}, e.Spec.MemberOf...)
+	case Group:
+		refs.namespace = e.Metadata.Namespace
+		refs.add(specField{"parent", RelationChildOf, KindGroup}, e.Spec.Parent)
+		refs.add(specField{"children", RelationParentOf, KindGroup}, e.Spec.Children...)
+		refs.add(specField{"members", RelationHasMember, KindUser}, e.Spec.Members...)
+	case System:
+		refs.namespace = e.Metadata.Namespace
+		refs.add(ownerField, e.Spec.Owner)
+		refs.add(specField{"domain", RelationPartOf, KindDomain}, e.Spec.Domain)
+	case Resource:
+		refs.namespace = e.Metadata.Namespace
+		refs.add(ownerField, e.Spec.Owner)
+		refs.add(systemField, e.Spec.System)
+		refs.add(dependsOnField, e.Spec.DependsOn...)
+		refs.add(dependencyOfField, e.Spec.DependencyOf...)
+	case Domain:
+		refs.namespace = e.Metadata.Namespace
+		refs.add(ownerField, e.Spec.Owner)
+		refs.add(specField{"subdomainOf", RelationPartOf, KindDomain}, e.Spec.SubdomainOf)
+	case Template:
+		refs.namespace = e.Metadata.Namespace
+		refs.add(ownerField, e.Spec.Owner)
+	}
+	return refs.refs
+}
+
+// SpecRelations returns the relations that the spec of an entity of any kind
+// makes to other entities, sorted.
+func SpecRelations(entity any) []Relation {
+	var relations []Relation
+	for _, ref := range SpecRefs(entity) {
+		relations = append(relations, Relation{
+			Type:      ref.Relation,
+			TargetRef: ref.Ref,
+		})
+	}
+	return SortRelations(relations)
+}
+
+type specRefs struct {
+	namespace string
+	refs      []SpecRef
+}
+
+func (s *specRefs) add(field specField, refs ...EntityRef) {
+	for _, ref := range refs {
+		if ref.Empty() {
+			continue
+		}
+		if ref.Kind == "" {
+			ref.Kind = field.kind
+		}
+		if ref.Namespace == "" {
+			ref.Namespace = s.namespace
+		}
+		s.refs = append(s.refs, SpecRef{
+			Field:    "spec." + field.name,
+			Relation: field.relation,
+			Ref:      ref,
+		})
+	}
+}
+
+// EntityRelations is the list of the relations of an entity.
+type EntityRelations struct {
+	EntityRef EntityRef  `json:"entityRef"`
+	Relations []Relation `json:"relations"`
+}
+
+// Referrer is an entity whose spec refers to another entity, in the field
+// named by Field, such as "spec.owner".
+type Referrer struct {
+	EntityRef EntityRef `json:"entityRef"`
+	Field     string    `json:"field"`
+}
+
+// EntityReferrers is the list of the entities that refer to an entity.
+type EntityReferrers struct {
+	EntityRef EntityRef  `json:"entityRef"`
+	Referrers []Referrer `json:"referrers"`
+}
+
+// SortReferrers sorts referrers by entity and then by field, and removes
+// duplicates.
+func SortReferrers(referrers []Referrer) []Referrer {
+	slices.SortFunc(referrers, func(a, b Referrer) int {
+		return cmp.Or(
+			cmp.Compare(a.EntityRef.String(), b.EntityRef.String()),
+			cmp.Compare(a.Field, b.Field),
+		)
+	})
+	return slices.Compact(referrers)
+}
+
+// DanglingRef is an entity ref in the spec of a live entity that names no live
+// entity. Owner is the owner of the entity with the ref, if it has one.
+type DanglingRef struct {
+	EntityRef EntityRef `json:"entityRef"`
+	Owner     EntityRef `json:"owner"`
+	Field     string    `json:"field"`
+	TargetRef EntityRef `json:"targetRef"`
+}
+
+// SortDanglingRefs sorts dangling refs by entity, then by field and then by
+// target.
+func SortDanglingRefs(refs []DanglingRef) []DanglingRef {
+	slices.SortFunc(refs, func(a, b DanglingRef) int {
+		return cmp.Or(
+			cmp.Compare(a.EntityRef.String(), b.EntityRef.String()),
+			cmp.Compare(a.Field, b.Field),
+			cmp.Compare(a.TargetRef.String(), b.TargetRef.String()),
+		)
+	})
+	return slices.Compact(refs)
+}
+
+// OwnerDanglingRefs is the dangling refs in the specs of the entities with an
+// owner, or with none if Owner is empty.
+type OwnerDanglingRefs struct {
+	Owner EntityRef     `json:"owner"`
+	Refs  []DanglingRef `json:"refs"`
+}
+
+// DanglingRefsReport is every dangling ref in a store, grouped by owner.
+type DanglingRefsReport struct {
+	Owners []OwnerDanglingRefs `json:"owners"`
+	Total  int                 `json:"total"`
+}
+
+// EntitySpecRef is an entity ref in the spec of the entity with EntityRef.
+type EntitySpecRef struct {
+	EntityRef EntityRef
+	SpecRef
+}
+
+// SortEntitySpecRefs sorts spec refs by entity, then by field and then by
+// target.
+func SortEntitySpecRefs(refs []EntitySpecRef) []EntitySpecRef {
+	slices.SortFunc(refs, func(a, b EntitySpecRef) int {
+		return cmp.Or(
+			cmp.Compare(a.EntityRef.String(), b.EntityRef.String()),
+			cmp.Compare(a.Field, b.Field),
+			cmp.Compare(a.Ref.String(), b.Ref.String()),
+		)
+	})
+	return slices.Compact(refs)
+}
+
+// Asymmetry is a ref in one of a pair of inverse spec fields, such as
+// dependsOn and dependencyOf, that the inverse field of the target does not
+// agree with. InverseRefs is what the target gives in its inverse field
+// instead, such as another parent for a group.
+type Asymmetry struct {
+	EntityRef    EntityRef   `json:"entityRef"`
+	Field        string      `json:"field"`
+	TargetRef    EntityRef   `json:"targetRef"`
+	InverseField string      `json:"inverseField"`
+	InverseRefs  []EntityRef `json:"inverseRefs"`
+}
+
+// AsymmetriesReport is every asymmetry in a store.
+type AsymmetriesReport struct {
+	Asymmetries []Asymmetry `json:"asymmetries"`
+	Total       int         `json:"total"`
+}
+
+// SynthesisReport is the asymmetries whose missing inverse refs were written
+// into the specs of their targets, and those that were skipped because the
+// inverse ref would replace another.
+type SynthesisReport struct {
+	Synthesized []Asymmetry `json:"synthesized"`
+	Skipped     []Asymmetry `json:"skipped"`
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInverseRelation(t *testing.T) {
+	for relationType, inverse := range inverseRelations {
+		assert.Equal(t, relationType, InverseRelation(inverse), "inverse of %s", inverse)
+	}
+	assert.Empty(t, InverseRelation("unknown"))
+}
+
+func TestSpecRefs(t *testing.T) {
+	type testCase struct {
+		entity      any
+		expected    []SpecRef
+		description string
+	}
+	tcs := []testCase{
+		{
+			entity: Component{
+				Entity: Entity{Kind: KindComponent, Metadata: Metadata{Namespace: "ns", Name: "c"}},
+				Spec: ComponentSpec{
+					Owner:        EntityRef{Name: "team"},
+					System:       EntityRef{Namespace: "other", Name: "sys"},
+					ProvidesAPIs: []EntityRef{{Name: "a1"}, {}, {Kind: KindAPI, Namespace: "default", Name: "a2"}},
+					DependsOn:    []EntityRef{{Kind: KindResource, Name: "db"}, {Name: "bare"}},
+				},
+			},
+			expected: []SpecRef{
+				{Field: "spec.owner", Relation: RelationOwnedBy, Ref: EntityRef{Kind: KindGroup, Namespace: "ns", Name: "team"}},
+				{Field: "spec.system", Relation: RelationPartOf, Ref: EntityRef{Kind: KindSystem, Namespace: "other", Name: "sys"}},
+				{Field: "spec.providesApis", Relation: RelationProvidesAPI, Ref: EntityRef{Kind: KindAPI, Namespace: "ns", Name: "a1"}},
+				{Field: "spec.providesApis", Relation: RelationProvidesAPI, Ref: EntityRef{Kind: KindAPI, Namespace: "default", Name: "a2"}},
+				{Field: "spec.dependsOn", Relation: RelationDependsOn, Ref: EntityRef{Kind: KindResource, Namespace: "ns", Name: "db"}},
+				{Field: "spec.dependsOn", Relation: RelationDependsOn, Ref: EntityRef{Namespace: "ns", Name: "bare"}},
+			},
+			description: "component with unqualified and empty refs",
+		},
+		{
+			entity: TestFullGroup,
+			expected: []SpecRef{
+				{Field: "spec.parent", Relation: RelationChildOf, Ref: TestGroupEntityRef},
+				{Field: "spec.children", Relation: RelationParentOf, Ref: EntityRef{Kind: KindGroup, Namespace: "default", Name: "child"}},
+				{Field: "spec.members", Relation: RelationHasMember, Ref: TestFullGroup.Spec.Members[0]},
+			},
+			description: "group",
+		},
+		{
+			entity:      TestFullLocation,
+			description: "location",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, SpecRefs(tc.entity))
+		})
+	}
+}
+
+func TestSpecRelations(t *testing.T) {
+	user := User{
+		Entity: Entity{Kind: KindUser, Metadata: Metadata{Namespace: "default", Name: "u"}},
+		Spec: UserSpec{
+			MemberOf: []EntityRef{{Name: "b"}, {Name: "a"}, {Kind: KindGroup, Namespace: "default", Name: "b"}},
+		},
+	}
+	assert.Equal(t, []Relation{
+		{Type: RelationMemberOf, TargetRef: EntityRef{Kind: KindGroup, Namespace: "default", Name: "a"}},
+		{Type: RelationMemberOf, TargetRef: EntityRef{Kind: KindGroup, Namespace: "default", Name: "b"}},
+	}, SpecRelations(user), "sorted without duplicates")
+}
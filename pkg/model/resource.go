@@ -0,0 +1,14 @@
+package model
+
+type Resource struct {
+	Entity `yaml:"entity,inline"`
+	Spec   ResourceSpec `yaml:"spec"`
+}
+
+type ResourceSpec struct {
+	Type         string      `yaml:"type"`
+	Owner        EntityRef   `yaml:"owner"`
+	System       EntityRef   `yaml:"system,omitempty"`
+	DependsOn    []EntityRef `yaml:"dependsOn,omitempty"`
+	DependencyOf []EntityRef `yaml:"dependencyOf,omitempty"`
+}
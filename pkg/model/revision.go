@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// The operations that a revision records.
+const (
+	OperationCreate  = "create"
+	OperationUpdate  = "update"
+	OperationDelete  = "delete"
+	OperationRestore = "restore"
+)
+
+// Revision is a recorded change to an entity. Previous and Current hold the
+// entity as YAML before and after the change: Previous is empty for a create
+// or a restore, and Current is empty for a delete. Version is the version of
+// the entity that the change made, or for a delete, the version that was
+// deleted.
+type Revision struct {
+	EntityRef EntityRef `json:"entityRef"`
+	UID       string    `json:"uid"`
+	Version   int64     `json:"version"`
+	Operation string    `json:"operation"`
+	Actor     string    `json:"actor,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Previous  string    `json:"previous,omitempty"`
+	Current   string    `json:"current,omitempty"`
+}
+
+type History struct {
+	Revisions  []Revision `json:"revisions"`
+	Limit      int        `json:"limit"`
+	NextOffset int        `json:"nextOffset"`
+}
@@ -4,4 +4,8 @@ type SearchResults struct {
 	Results    []EntityRef `json:"results"`
 	Limit      int         `json:"limit"`
 	NextOffset int         `json:"nextOffset"`
+	// Snippets holds, for full-text search results only, an excerpt of the
+	// matching text for each result, in the same order as Results. The text
+	// is escaped HTML, in which matching words are wrapped in <mark> tags.
+	Snippets []string `json:"snippets,omitempty"`
 }
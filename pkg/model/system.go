@@ -0,0 +1,12 @@
+package model
+
+type System struct {
+	Entity `yaml:"entity,inline"`
+	Spec   SystemSpec `yaml:"spec"`
+}
+
+type SystemSpec struct {
+	Owner  EntityRef `yaml:"owner"`
+	Domain EntityRef `yaml:"domain,omitempty"`
+	Type   string    `yaml:"type,omitempty"`
+}
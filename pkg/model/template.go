@@ -0,0 +1,17 @@
+package model
+
+// Template describes a software template. Rewind does not run templates, so
+// the parameters, steps and output are kept as structured YAML without
+// further interpretation.
+type Template struct {
+	Entity `yaml:"entity,inline"`
+	Spec   TemplateSpec `yaml:"spec"`
+}
+
+type TemplateSpec struct {
+	Type       string           `yaml:"type"`
+	Owner      EntityRef        `yaml:"owner,omitempty"`
+	Parameters any              `yaml:"parameters,omitempty"`
+	Steps      []map[string]any `yaml:"steps,omitempty"`
+	Output     map[string]any   `yaml:"output,omitempty"`
+}
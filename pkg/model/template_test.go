@@ -0,0 +1,30 @@
+package model
+
+import (
+	_ "embed"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed testdata/template.yaml
+var templateYAMLBytes []byte
+
+func TestTemplateYAML(t *testing.T) {
+	var template Template
+	err := yaml.Unmarshal(templateYAMLBytes, &template)
+
+	assert.NoError(t, err)
+	assert.Equal(t, TestFullTemplate, template)
+
+	out, err := yaml.Marshal(template)
+
+	assert.NoError(t, err)
+
+	var template2 Template
+	err = yaml.Unmarshal(out, &template2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, TestFullTemplate, template2)
+}
@@ -21,6 +21,16 @@ var (
 		Namespace: "default",
 		Name:      "shock",
 	}
+	TestDomainEntityRef = EntityRef{
+		Kind:      KindDomain,
+		Namespace: "default",
+		Name:      "domain",
+	}
+	TestDomain2EntityRef = EntityRef{
+		Kind:      KindDomain,
+		Namespace: "default",
+		Name:      "domain2",
+	}
 	TestComponentEntityRef = EntityRef{
 		Kind:      KindComponent,
 		Namespace: "default",
@@ -66,6 +76,26 @@ var (
 		Namespace: "default",
 		Name:      "resource2",
 	}
+	TestLocationEntityRef = EntityRef{
+		Kind:      KindLocation,
+		Namespace: "default",
+		Name:      "location",
+	}
+	TestLocation2EntityRef = EntityRef{
+		Kind:      KindLocation,
+		Namespace: "default",
+		Name:      "location2",
+	}
+	TestTemplateEntityRef = EntityRef{
+		Kind:      KindTemplate,
+		Namespace: "default",
+		Name:      "template",
+	}
+	TestTemplate2EntityRef = EntityRef{
+		Kind:      KindTemplate,
+		Namespace: "default",
+		Name:      "template2",
+	}
 
 	TestFullEntity = Entity{
 		APIVersion: "backstage.io/v1alpha1",
@@ -169,6 +199,93 @@ var (
 			},
 		},
 	}
+	TestFullSystem = System{
+		Entity: TestFullEntity,
+		Spec: SystemSpec{
+			Owner:  TestOwnerEntityRef,
+			Domain: TestDomainEntityRef,
+			Type:   "product",
+		},
+	}
+	TestFullResource = Resource{
+		Entity: TestFullEntity,
+		Spec: ResourceSpec{
+			Type:   "database",
+			Owner:  TestOwnerEntityRef,
+			System: TestSystemEntityRef,
+			DependsOn: []EntityRef{
+				TestResource1EntityRef,
+			},
+			DependencyOf: []EntityRef{
+				TestComponentEntityRef,
+			},
+		},
+	}
+	TestFullDomain = Domain{
+		Entity: TestFullEntity,
+		Spec: DomainSpec{
+			Owner:       TestOwnerEntityRef,
+			SubdomainOf: TestDomain2EntityRef,
+			Type:        "product-area",
+		},
+	}
+	TestFullLocation = Location{
+		Entity: TestFullEntity,
+		Spec: LocationSpec{
+			Type:   LocationTypeFile,
+			Target: "/catalog/catalog-info.yaml",
+			Targets: []string{
+				"./components/catalog-info.yaml",
+				"./apis/catalog-info.yaml",
+			},
+		},
+	}
+	TestFullTemplate = Template{
+		Entity: TestFullEntity,
+		Spec: TemplateSpec{
+			Type:  ComponentTypeService,
+			Owner: TestOwnerEntityRef,
+			Parameters: []any{
+				map[string]any{
+					"title":    "Provide some simple information",
+					"required": []any{"component_id"},
+					"properties": map[string]any{
+						"component_id": map[string]any{
+							"title":     "Name",
+							"type":      "string",
+							"maxLength": 63,
+						},
+					},
+				},
+			},
+			Steps: []map[string]any{
+				{
+					"id":     "fetch-base",
+					"name":   "Fetch Base",
+					"action": "fetch:template",
+					"input": map[string]any{
+						"url": "./template",
+						"values": map[string]any{
+							"name": "${{ parameters.component_id }}",
+						},
+					},
+				},
+				{
+					"id":     "publish",
+					"name":   "Publish",
+					"action": "publish:github",
+				},
+			},
+			Output: map[string]any{
+				"links": []any{
+					map[string]any{
+						"title": "Repository",
+						"url":   "${{ steps['publish'].output.remoteUrl }}",
+					},
+				},
+			},
+		},
+	}
 
 	TestMinimalEntity = Entity{
 		APIVersion: "backstage.io/v1alpha1",
@@ -185,4 +302,10 @@ func init() {
 	TestFullAPI.Entity.Kind = KindAPI
 	TestFullUser.Entity.Kind = KindUser
 	TestFullGroup.Entity.Kind = KindGroup
+	TestFullSystem.Entity.Kind = KindSystem
+	TestFullResource.Entity.Kind = KindResource
+	TestFullDomain.Entity.Kind = KindDomain
+	TestFullLocation.Entity.Kind = KindLocation
+	TestFullTemplate.Entity.Kind = KindTemplate
+	TestFullTemplate.Entity.APIVersion = "scaffolder.backstage.io/v1beta3"
 }
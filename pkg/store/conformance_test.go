@@ -0,0 +1,36 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/bhavanki/rewind/pkg/store/storetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqliteStore_Conformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		s, err := store.NewSqliteStore("file::memory:")
+		require.NoError(t, err)
+		return s
+	})
+}
+
+func TestMemoryStore_Conformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return store.NewMemoryStore()
+	})
+}
+
+func TestTimeoutStore_Conformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return store.WithTimeout(store.NewMemoryStore(), time.Minute)
+	})
+}
+
+func TestPostgresStore_Conformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return store.NewTestPostgresStore(t)
+	})
+}
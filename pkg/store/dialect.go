@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// entityRefsSeparator joins entity refs in list columns that are stored as
+// text.
+const entityRefsSeparator = " "
+
+// dialect covers what differs between the databases behind a sqlStore,
+// beyond placeholder syntax.
+type dialect interface {
+	// listValue encodes a list of strings for a list column. Databases
+	// without array types join the items with the separator. An empty list
+	// is stored as NULL.
+	listValue(items []string, sep string) any
+	// newList makes a destination for scanning a list column.
+	newList(sep string) listColumn
+	// multiValueFilterClause builds the condition for a filter on a list
+	// column.
+	multiValueFilterClause(field filterField, filter Filter) (string, []any, error)
+	// isUniqueViolation reports whether an error is from a unique index,
+	// such as the one on entity refs.
+	isUniqueViolation(err error) bool
+
+	// indexEntity adds an entity to the search index, replacing any earlier
+	// entry for it, and unindexEntity removes it.
+	indexEntity(ctx context.Context, e model.Entity, tx *sqlx.Tx) error
+	unindexEntity(ctx context.Context, id int64, tx *sqlx.Tx) error
+	// searchEntities finds the entities that match a free text query, best
+	// matches first.
+	searchEntities(ctx context.Context, db *sqlx.DB, query string, pagination Pagination) ([]SearchMatch, Pagination, error)
+}
+
+// listColumn is a destination for scanning a list column.
+type listColumn interface {
+	sql.Scanner
+	Items() []string
+}
+
+func (s sqlStore) entityRefsValue(refs []model.EntityRef) any {
+	items := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if !ref.Empty() {
+			items = append(items, ref.String())
+		}
+	}
+	return s.dialect.listValue(items, entityRefsSeparator)
+}
+
+func (s sqlStore) newEntityRefs() *entityRefList {
+	return &entityRefList{
+		list: s.dialect.newList(entityRefsSeparator),
+	}
+}
+
+// entityRefList scans a list column of entity refs.
+type entityRefList struct {
+	list  listColumn
+	items []model.EntityRef
+}
+
+func (l *entityRefList) Scan(src any) error {
+	if err := l.list.Scan(src); err != nil {
+		return err
+	}
+	l.items = nil
+	for _, item := range l.list.Items() {
+		ref, err := model.MakeEntityRef(item)
+		if err != nil {
+			return fmt.Errorf("failed to make entity ref from %s: %w", item, err)
+		}
+		l.items = append(l.items, ref)
+	}
+	return nil
+}
+
+func (l *entityRefList) Items() []model.EntityRef {
+	return l.items
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"net/url"
+	"strings"
+)
+
+// sqliteDSNDefaults are the go-sqlite3 connection parameters applied to
+// every database unless the DSN sets them itself. Each parameter is listed
+// with the aliases go-sqlite3 also accepts for it.
+var sqliteDSNDefaults = []struct {
+	names []string
+	value string
+	// onDisk parameters only apply to file databases.
+	onDisk bool
+}{
+	{names: []string{"_fk", "_foreign_keys"}, value: "on"},
+	{names: []string{"_journal_mode", "_journal"}, value: "WAL", onDisk: true},
+	{names: []string{"_busy_timeout", "_timeout"}, value: "5000", onDisk: true},
+	// Write transactions take the write lock when they begin, so that they
+	// wait out the busy timeout instead of failing when upgrading later.
+	{names: []string{"_txlock"}, value: "immediate", onDisk: true},
+}
+
+// sqliteDSN adds the default connection parameters to a database path or
+// DSN. File databases use write-ahead logging and wait for locks held by
+// other connections or processes.
+func sqliteDSN(connString string) string {
+	path, rawQuery, _ := strings.Cut(connString, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		query = url.Values{}
+	}
+	inMemory := isInMemory(connString)
+
+	var params []string
+	for _, p := range sqliteDSNDefaults {
+		if p.onDisk && inMemory {
+			continue
+		}
+		set := false
+		for _, name := range p.names {
+			if query.Has(name) {
+				set = true
+			}
+		}
+		if !set {
+			params = append(params, p.names[0]+"="+p.value)
+		}
+	}
+	if len(params) == 0 {
+		return connString
+	}
+	if rawQuery == "" {
+		return path + "?" + strings.Join(params, "&")
+	}
+	return connString + "&" + strings.Join(params, "&")
+}
+
+func isInMemory(connString string) bool {
+	return strings.Contains(connString, ":memory:") || strings.Contains(connString, "mode=memory")
+}
+
+// sqliteFilePath returns the path of the file behind a database path or DSN,
+// or "" for an in-memory database.
+func sqliteFilePath(connString string) string {
+	if isInMemory(connString) {
+		return ""
+	}
+	path, _, _ := strings.Cut(connString, "?")
+	return strings.TrimPrefix(path, "file:")
+}
@@ -0,0 +1,5 @@
+package store
+
+// NewTestPostgresStore lets the external conformance tests use the embedded
+// PostgreSQL server that the package tests run.
+var NewTestPostgresStore = testPostgresStore
@@ -0,0 +1,314 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bhavanki/rewind/pkg/model"
+)
+
+// filterField describes the column that a filter field path is stored in.
+// Fields that hold several values store them joined with a separator.
+//
+// Fields that hold entity refs are instead matched against the qualified refs
+// in the relation table, so that a filter matches however the ref was written
+// in the spec. refKind is the kind that a ref without one is given, and a ref
+// without a namespace is given that of the referring entity, as in
+// model.SpecRefs.
+type filterField struct {
+	column    string
+	separator string
+	ref       bool
+	refKind   string
+}
+
+// commonFilterFields are the field paths available for every kind. The
+// "entity." paths are the column names that filters were originally keyed
+// on, and are kept for compatibility.
+var commonFilterFields = map[string]filterField{
+	"metadata.name":      {column: "entity.name"},
+	"metadata.namespace": {column: "entity.namespace"},
+	"metadata.title":     {column: "entity.title"},
+	"metadata.tags":      {column: "entity.tags", separator: ","},
+	"entity.name":        {column: "entity.name"},
+	"entity.namespace":   {column: "entity.namespace"},
+}
+
+// specFilterFields are the spec field paths available for each kind. They
+// refer to the kind's own table, so they can only be used when listing a
+// single kind.
+var specFilterFields = map[string]map[string]filterField{
+	model.KindComponent: {
+		"spec.type":           {column: "component.type"},
+		"spec.lifecycle":      {column: "component.lifecycle"},
+		"spec.owner":          {column: "component.owner", ref: true, refKind: model.KindGroup},
+		"spec.system":         {column: "component.system", ref: true, refKind: model.KindSystem},
+		"spec.subcomponentOf": {column: "component.subcomponent_of", ref: true, refKind: model.KindComponent},
+		"spec.providesApis":   {column: "component.provides_apis", separator: " ", ref: true, refKind: model.KindAPI},
+		"spec.consumesApis":   {column: "component.consumes_apis", separator: " ", ref: true, refKind: model.KindAPI},
+		"spec.dependsOn":      {column: "component.depends_on", separator: " ", ref: true},
+		"spec.dependencyOf":   {column: "component.dependency_of", separator: " ", ref: true},
+	},
+	model.KindAPI: {
+		"spec.type":      {column: "api.type"},
+		"spec.lifecycle": {column: "api.lifecycle"},
+		"spec.owner":     {column: "api.owner", ref: true, refKind: model.KindGroup},
+		"spec.system":    {column: "api.system", ref: true, refKind: model.KindSystem},
+	},
+	model.KindUser: {
+		"spec.profile.displayName": {column: `"user".display_name`},
+		"spec.profile.email":       {column: `"user".email`},
+		"spec.memberOf":            {column: `"user".member_of`, separator: " ", ref: true, refKind: model.KindGroup},
+	},
+	model.KindGroup: {
+		"spec.type":                {column: "grp.type"},
+		"spec.profile.displayName": {column: "grp.display_name"},
+		"spec.profile.email":       {column: "grp.email"},
+		"spec.parent":              {column: "grp.parent", ref: true, refKind: model.KindGroup},
+		"spec.children":            {column: "grp.children", separator: " ", ref: true, refKind: model.KindGroup},
+		"spec.members":             {column: "grp.members", separator: " ", ref: true, refKind: model.KindUser},
+	},
+	model.KindSystem: {
+		"spec.owner":  {column: "system.owner", ref: true, refKind: model.KindGroup},
+		"spec.domain": {column: "system.domain", ref: true, refKind: model.KindDomain},
+		"spec.type":   {column: "system.type"},
+	},
+	model.KindResource: {
+		"spec.type":         {column: "resource.type"},
+		"spec.owner":        {column: "resource.owner", ref: true, refKind: model.KindGroup},
+		"spec.system":       {column: "resource.system", ref: true, refKind: model.KindSystem},
+		"spec.dependsOn":    {column: "resource.depends_on", separator: " ", ref: true},
+		"spec.dependencyOf": {column: "resource.dependency_of", separator: " ", ref: true},
+	},
+	model.KindDomain: {
+		"spec.owner":       {column: "domain.owner", ref: true, refKind: model.KindGroup},
+		"spec.subdomainOf": {column: "domain.subdomain_of", ref: true, refKind: model.KindDomain},
+		"spec.type":        {column: "domain.type"},
+	},
+	model.KindLocation: {
+		"spec.type":    {column: "location.type"},
+		"spec.target":  {column: "location.target"},
+		"spec.targets": {column: "location.targets", separator: "\n"},
+	},
+	model.KindTemplate: {
+		"spec.type":  {column: "template.type"},
+		"spec.owner": {column: "template.owner", ref: true, refKind: model.KindGroup},
+	},
+}
+
+// ValidateFilters checks that every filter names a field path that is
+// available when listing the given kinds, and that its operator and values
+// are usable. Spec field paths are only available when exactly one kind is
+// listed. The error for an unusable filter wraps ErrInvalid.
+func ValidateFilters(kinds []string, filters []Filter) error {
+	for _, filter := range filters {
+		if _, err := resolveFilter(kinds, filter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveFilter checks a filter and returns the field that it refers to.
+// Label and annotation filters refer to no field.
+func resolveFilter(kinds []string, filter Filter) (filterField, error) {
+	switch filter.Operator {
+	case FilterEquals, FilterNotEquals, FilterExists, FilterNotExists, FilterPrefix:
+	case FilterIn, FilterNotIn:
+		if len(filter.Values) == 0 {
+			return filterField{}, fmt.Errorf("%w: no values for %s filter on %s", ErrInvalid, filter.Operator, filter.Key)
+		}
+	default:
+		return filterField{}, fmt.Errorf("%w: unsupported filter operator %s", ErrInvalid, filter.Operator)
+	}
+
+	for _, prefix := range []string{LabelFilterKeyPrefix, AnnotationFilterKeyPrefix} {
+		if key, found := strings.CutPrefix(filter.Key, prefix); found {
+			if key == "" {
+				return filterField{}, fmt.Errorf("%w: empty key in filter on %s", ErrInvalid, filter.Key)
+			}
+			return filterField{}, nil
+		}
+	}
+
+	field, ok := commonFilterFields[filter.Key]
+	if !ok && len(kinds) == 1 {
+		field, ok = specFilterFields[kinds[0]][filter.Key]
+	}
+	if !ok {
+		return filterField{}, fmt.Errorf("%w: unsupported filter field %s", ErrInvalid, filter.Key)
+	}
+	if field.ref {
+		values := filter.Values
+		switch filter.Operator {
+		case FilterEquals, FilterNotEquals:
+			values = []string{filter.Value}
+		case FilterExists, FilterNotExists, FilterPrefix:
+			values = nil
+		}
+		for _, value := range values {
+			if _, err := model.MakeEntityRef(value); err != nil {
+				return filterField{}, fmt.Errorf("%w: invalid entity ref %s in filter on %s: %w", ErrInvalid, value, filter.Key, err)
+			}
+		}
+	}
+	return field, nil
+}
+
+// filterRef parses the entity ref in a filter on a ref field, and gives it
+// the field's kind if it has none. Its namespace is left for the caller to
+// fill in, since it depends on the referring entity.
+func filterRef(field filterField, value string) model.EntityRef {
+	ref, _ := model.MakeEntityRef(value)
+	if ref.Kind == "" {
+		ref.Kind = field.refKind
+	}
+	return ref
+}
+
+// filterClause builds a SQL condition for a filter, along with its query
+// parameters. Label and annotation filters become semi-joins against their
+// tables, so an entity without a label never matches a positive label filter,
+// and always matches a negative one.
+func filterClause(d dialect, kinds []string, filter Filter) (string, []any, error) {
+	field, err := resolveFilter(kinds, filter)
+	if err != nil {
+		return "", nil, err
+	}
+	if labelKey, found := strings.CutPrefix(filter.Key, LabelFilterKeyPrefix); found {
+		return keyValueFilterClause("label", labelKey, filter)
+	}
+	if annotationKey, found := strings.CutPrefix(filter.Key, AnnotationFilterKeyPrefix); found {
+		return keyValueFilterClause("annotation", annotationKey, filter)
+	}
+	if field.ref {
+		return refFilterClause(field, filter)
+	}
+	if field.separator != "" {
+		return d.multiValueFilterClause(field, filter)
+	}
+	return columnFilterClause(field.column, filter)
+}
+
+func keyValueFilterClause(table string, key string, filter Filter) (string, []any, error) {
+	subquery := fmt.Sprintf("EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.entity_id = entity.id AND %[1]s.k = ?", table)
+	switch filter.Operator {
+	case FilterEquals:
+		return fmt.Sprintf("%s AND %s.v = ?)", subquery, table), []any{key, filter.Value}, nil
+	case FilterNotEquals:
+		return fmt.Sprintf("NOT %s AND %s.v = ?)", subquery, table), []any{key, filter.Value}, nil
+	case FilterIn, FilterNotIn:
+		if len(filter.Values) == 0 {
+			return "", nil, fmt.Errorf("no values for %s filter on %s %s", filter.Operator, table, key)
+		}
+		clause := fmt.Sprintf("%s AND %s.v IN (%s))", subquery, table, placeholders(len(filter.Values)))
+		if filter.Operator == FilterNotIn {
+			clause = "NOT " + clause
+		}
+		return clause, append([]any{key}, stringsToAny(filter.Values)...), nil
+	case FilterExists:
+		return subquery + ")", []any{key}, nil
+	case FilterNotExists:
+		return "NOT " + subquery + ")", []any{key}, nil
+	case FilterPrefix:
+		return fmt.Sprintf(`%s AND %s.v LIKE ? ESCAPE '\')`, subquery, table), []any{key, escapeLike(filter.Value) + "%"}, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported filter operator %s", filter.Operator)
+	}
+}
+
+// refFilterClause builds a semi-join against the relation table for a filter
+// on a ref field. A filter ref without a namespace is qualified with the
+// namespace of each entity, as its spec refs are. A prefix filter matches the
+// start of the qualified refs.
+func refFilterClause(field filterField, filter Filter) (string, []any, error) {
+	subquery := "EXISTS (SELECT 1 FROM relation WHERE relation.entity_id = entity.id AND relation.field = ?"
+	target := func(value string) (string, []any) {
+		ref := filterRef(field, value)
+		if ref.Namespace != "" {
+			return "?", []any{ref.String()}
+		}
+		kind := ""
+		if ref.Kind != "" {
+			kind = ref.Kind + ":"
+		}
+		return "? || entity.namespace || '/' || ?", []any{kind, ref.Name}
+	}
+	targets := func(values []string) (string, []any) {
+		expressions := make([]string, len(values))
+		parameters := []any{filter.Key}
+		for i, value := range values {
+			expression, targetParameters := target(value)
+			expressions[i] = expression
+			parameters = append(parameters, targetParameters...)
+		}
+		return fmt.Sprintf("%s AND relation.target_ref IN (%s))", subquery, strings.Join(expressions, ", ")), parameters
+	}
+
+	switch filter.Operator {
+	case FilterEquals:
+		clause, parameters := targets([]string{filter.Value})
+		return clause, parameters, nil
+	case FilterNotEquals:
+		clause, parameters := targets([]string{filter.Value})
+		return "NOT " + clause, parameters, nil
+	case FilterIn, FilterNotIn:
+		if len(filter.Values) == 0 {
+			return "", nil, fmt.Errorf("no values for %s filter on %s", filter.Operator, filter.Key)
+		}
+		clause, parameters := targets(filter.Values)
+		if filter.Operator == FilterNotIn {
+			clause = "NOT " + clause
+		}
+		return clause, parameters, nil
+	case FilterExists:
+		return subquery + ")", []any{filter.Key}, nil
+	case FilterNotExists:
+		return "NOT " + subquery + ")", []any{filter.Key}, nil
+	case FilterPrefix:
+		return subquery + ` AND relation.target_ref LIKE ? ESCAPE '\')`, []any{filter.Key, escapeLike(filter.Value) + "%"}, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported filter operator %s", filter.Operator)
+	}
+}
+
+func columnFilterClause(column string, filter Filter) (string, []any, error) {
+	switch filter.Operator {
+	case FilterEquals:
+		return fmt.Sprintf("%s = ?", column), []any{filter.Value}, nil
+	case FilterNotEquals:
+		return fmt.Sprintf("(%s IS NULL OR %s != ?)", column, column), []any{filter.Value}, nil
+	case FilterIn:
+		if len(filter.Values) == 0 {
+			return "", nil, fmt.Errorf("no values for %s filter on %s", filter.Operator, column)
+		}
+		return fmt.Sprintf("%s IN (%s)", column, placeholders(len(filter.Values))), stringsToAny(filter.Values), nil
+	case FilterNotIn:
+		if len(filter.Values) == 0 {
+			return "", nil, fmt.Errorf("no values for %s filter on %s", filter.Operator, column)
+		}
+		return fmt.Sprintf("(%s IS NULL OR %s NOT IN (%s))", column, column, placeholders(len(filter.Values))), stringsToAny(filter.Values), nil
+	case FilterExists:
+		return fmt.Sprintf("%s IS NOT NULL", column), nil, nil
+	case FilterNotExists:
+		return fmt.Sprintf("%s IS NULL", column), nil, nil
+	case FilterPrefix:
+		return fmt.Sprintf(`%s LIKE ? ESCAPE '\'`, column), []any{escapeLike(filter.Value) + "%"}, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported filter operator %s", filter.Operator)
+	}
+}
+
+// escapeLike escapes the LIKE wildcards in a value, for use with
+// ESCAPE '\'.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func stringsToAny(ss []string) []any {
+	as := make([]any, len(ss))
+	for i, s := range ss {
+		as[i] = s
+	}
+	return as
+}
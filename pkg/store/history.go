@@ -0,0 +1,182 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/yaml.v3"
+)
+
+type actorKey struct{}
+
+// WithActor returns a context that names the actor, such as a user, that is
+// responsible for changes made with it. Stores record the actor in the
+// history of each entity that is changed.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor named in a context, or an empty string if
+// there is none.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+var (
+	historyInsertStatement = `INSERT INTO entity_history (entity_uid, kind, namespace, name, version, operation, actor, changed_at, previous_yaml, current_yaml) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	historyLatestStatement = `SELECT current_yaml FROM entity_history WHERE entity_uid = ? ORDER BY id DESC LIMIT 1`
+	historyRefStatement    = `SELECT entity_uid FROM entity_history WHERE kind = ? AND namespace = ? AND name = ? ORDER BY id DESC LIMIT 1`
+	historyListStatement   = `SELECT kind, namespace, name, entity_uid, version, operation, actor, changed_at, previous_yaml, current_yaml FROM entity_history WHERE entity_uid = ? ORDER BY id DESC`
+	historyReadStatement   = `SELECT kind, namespace, name, entity_uid, version, operation, actor, changed_at, previous_yaml, current_yaml FROM entity_history WHERE entity_uid = ? AND version = ? AND operation <> ? ORDER BY id DESC LIMIT 1`
+
+	entityUIDByRefStatement = `SELECT uid FROM entity WHERE kind = ? AND namespace = ? AND name = ? AND deleted_at IS NULL`
+)
+
+// recordRevision adds a change to an entity to its history. The entity is as
+// it is after the change, or for a delete, as it was before it; value is the
+// whole entity after the change, or nil for a delete. The YAML from before the
+// change is taken from the entity's latest revision, so it is empty for the
+// first change recorded to an entity that was created before history was kept.
+func recordRevision(ctx context.Context, tx *sqlx.Tx, operation string, e model.Entity, value any) error {
+	var current sql.NullString
+	if value != nil {
+		b, err := yaml.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal entity for history: %w", err)
+		}
+		current = nullString(string(b))
+	}
+
+	var previous sql.NullString
+	if operation != model.OperationCreate {
+		err := tx.QueryRowxContext(ctx, tx.Rebind(historyLatestStatement), e.Metadata.UID).Scan(&previous)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to query for entity history: %w", err)
+		}
+	}
+
+	changedAt := e.Metadata.UpdatedAt
+	if operation == model.OperationDelete {
+		changedAt = storeTime()
+	}
+	_, err := tx.ExecContext(
+		ctx,
+		tx.Rebind(historyInsertStatement),
+		e.Metadata.UID,
+		e.Kind,
+		e.Metadata.Namespace,
+		e.Metadata.Name,
+		e.Version,
+		operation,
+		nullString(ActorFromContext(ctx)),
+		changedAt,
+		previous,
+		current,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record entity history: %w", err)
+	}
+	return nil
+}
+
+// historyUID finds the UID whose history belongs to a ref: that of the entity
+// with the ref, or if there is none, that of the entity that last had it.
+func historyUID(ctx context.Context, db *sqlx.DB, ref model.EntityRef) (string, error) {
+	var uid sql.NullString
+	err := db.QueryRowxContext(ctx, db.Rebind(entityUIDByRefStatement), ref.Kind, ref.Namespace, ref.Name).Scan(&uid)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = db.QueryRowxContext(ctx, db.Rebind(historyRefStatement), ref.Kind, ref.Namespace, ref.Name).Scan(&uid)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%s %w", ref, ErrNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query for entity UID: %w", err)
+	}
+	return fromNullString(uid), nil
+}
+
+// listRevisions lists the history of the entity with a ref, newest first.
+func listRevisions(ctx context.Context, db *sqlx.DB, ref model.EntityRef, pagination Pagination) ([]model.Revision, Pagination, error) {
+	uid, err := historyUID(ctx, db, ref)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+
+	rows, err := db.QueryxContext(ctx, db.Rebind(historyListStatement+limitClause(pagination)), uid)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to list entity history: %w", err)
+	}
+	defer rows.Close()
+	revisions := []model.Revision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, Pagination{}, err
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to list entity history: %w", err)
+	}
+
+	return revisions, Pagination{
+		Limit:  pagination.Limit,
+		Offset: pagination.Offset + len(revisions),
+	}, nil
+}
+
+// readRevision reads the revision that made a version of the entity with a
+// ref.
+func readRevision(ctx context.Context, db *sqlx.DB, ref model.EntityRef, version int64) (model.Revision, error) {
+	uid, err := historyUID(ctx, db, ref)
+	if err != nil {
+		return model.Revision{}, err
+	}
+
+	rows, err := db.QueryxContext(ctx, db.Rebind(historyReadStatement), uid, version, model.OperationDelete)
+	if err != nil {
+		return model.Revision{}, fmt.Errorf("failed to query for entity history: %w", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return model.Revision{}, fmt.Errorf("failed to query for entity history: %w", err)
+		}
+		return model.Revision{}, fmt.Errorf("version %d of %s %w", version, ref, ErrNotFound)
+	}
+	return scanRevision(rows)
+}
+
+func scanRevision(rows *sqlx.Rows) (model.Revision, error) {
+	var revision model.Revision
+	var actor sql.NullString
+	var changedAt sql.NullTime
+	var previous sql.NullString
+	var current sql.NullString
+	err := rows.Scan(
+		&revision.EntityRef.Kind,
+		&revision.EntityRef.Namespace,
+		&revision.EntityRef.Name,
+		&revision.UID,
+		&revision.Version,
+		&revision.Operation,
+		&actor,
+		&changedAt,
+		&previous,
+		&current,
+	)
+	if err != nil {
+		return model.Revision{}, fmt.Errorf("failed to scan columns for entity history: %w", err)
+	}
+	revision.Actor = fromNullString(actor)
+	revision.Timestamp = fromNullTime(changedAt)
+	revision.Previous = fromNullString(previous)
+	revision.Current = fromNullString(current)
+	return revision, nil
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package store
+
+// lockFile does nothing on platforms without flock. Processes starting
+// against the same database at the same time rely on SQLite's own locking
+// and the busy timeout instead.
+func lockFile(path string) (func() error, error) {
+	return func() error { return nil }, nil
+}
@@ -0,0 +1,1303 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// memoryStore implements Store in memory, without a database. It is meant
+// for tests and for embedding, and is safe for concurrent use. Entities are
+// copied on the way in and out, so callers never share state with the store.
+// Operations never block on anything but the store's own lock, so contexts
+// are only checked before an operation starts.
+type memoryStore struct {
+	mu      sync.RWMutex
+	nextID  int64
+	records map[int64]*memoryRecord
+	ids     map[model.EntityRef]int64
+	uids    map[string]int64
+	history []model.Revision
+	// tombstones holds deleted records, oldest first, until they are purged.
+	tombstones []*memoryRecord
+}
+
+var _ Store = &memoryStore{}
+
+// NewMemoryStore makes an empty in-memory store.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		records: make(map[int64]*memoryRecord),
+		ids:     make(map[model.EntityRef]int64),
+		uids:    make(map[string]int64),
+	}
+}
+
+// memoryRecord is a stored entity of any kind. Its fields hold the values
+// that filters and ordering are evaluated against, keyed by the column names
+// that the SQL stores use, so that filter field paths resolve the same way.
+type memoryRecord struct {
+	entity    model.Entity
+	value     any
+	fields    map[string][]string
+	deletedAt time.Time
+}
+
+// memoryKind describes how to store entities of one kind.
+type memoryKind[T any] struct {
+	kind     string
+	entityOf func(*T) *model.Entity
+	// cloneSpec copies the parts of the spec that would otherwise be shared.
+	cloneSpec func(T) (T, error)
+	// fields returns the values of the spec columns.
+	fields func(T) map[string][]string
+}
+
+func (k memoryKind[T]) clone(t T) (T, error) {
+	t, err := k.cloneSpec(t)
+	if err != nil {
+		return t, err
+	}
+	e := k.entityOf(&t)
+	*e = cloneEntity(*e)
+	e.Relations = nil
+	return t, nil
+}
+
+func (k memoryKind[T]) record(t T) *memoryRecord {
+	e := *k.entityOf(&t)
+	fields := map[string][]string{
+		"entity.kind":      fieldValue(e.Kind),
+		"entity.namespace": fieldValue(e.Metadata.Namespace),
+		"entity.name":      fieldValue(e.Metadata.Name),
+		"entity.title":     fieldValue(e.Metadata.Title),
+		"entity.tags":      slices.Clone(e.Metadata.Tags),
+	}
+	maps.Copy(fields, k.fields(t))
+	return &memoryRecord{
+		entity: e,
+		value:  t,
+		fields: fields,
+	}
+}
+
+// cloneEntity copies an entity's metadata, normalizing empty collections the
+// same way that reading them back from a database does.
+func cloneEntity(e model.Entity) model.Entity {
+	labels := make(map[string]string, len(e.Metadata.Labels))
+	maps.Copy(labels, e.Metadata.Labels)
+	e.Metadata.Labels = labels
+	annotations := make(map[string]string, len(e.Metadata.Annotations))
+	maps.Copy(annotations, e.Metadata.Annotations)
+	e.Metadata.Annotations = annotations
+	e.Metadata.Tags = cloneStrings(e.Metadata.Tags)
+	e.Metadata.Links = append(make([]model.Link, 0, len(e.Metadata.Links)), e.Metadata.Links...)
+	return e
+}
+
+func cloneStrings(ss []string) []string {
+	if len(ss) == 0 {
+		return nil
+	}
+	return slices.Clone(ss)
+}
+
+func cloneRefs(refs []model.EntityRef) []model.EntityRef {
+	var cloned []model.EntityRef
+	for _, ref := range refs {
+		if !ref.Empty() {
+			cloned = append(cloned, ref)
+		}
+	}
+	return cloned
+}
+
+func fieldValue(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+func refValue(ref model.EntityRef) []string {
+	if ref.Empty() {
+		return nil
+	}
+	return []string{ref.String()}
+}
+
+func refValues(refs []model.EntityRef) []string {
+	var values []string
+	for _, ref := range refs {
+		if !ref.Empty() {
+			values = append(values, ref.String())
+		}
+	}
+	return values
+}
+
+func memoryCreate[T any](ctx context.Context, s *memoryStore, k memoryKind[T], t T) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	if err := validateEntity(*k.entityOf(&t), k.kind); err != nil {
+		return zero, err
+	}
+	t, err := k.clone(t)
+	if err != nil {
+		return zero, err
+	}
+	e := k.entityOf(&t)
+	ref := e.EntityRef()
+	uid, err := newUID()
+	if err != nil {
+		return zero, err
+	}
+	now := storeTime()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.ids[ref]; exists {
+		return zero, fmt.Errorf("%s %w", ref, ErrAlreadyExists)
+	}
+	s.nextID++
+	e.ID = s.nextID
+	e.Version = 1
+	e.Metadata.UID = uid
+	e.Metadata.Etag = versionEtag(e.Version)
+	e.Metadata.CreatedAt = now
+	e.Metadata.UpdatedAt = now
+	if err := s.recordRevision(ctx, model.OperationCreate, *e, nil, t); err != nil {
+		return zero, err
+	}
+	s.records[e.ID] = k.record(t)
+	s.ids[ref] = e.ID
+	s.uids[uid] = e.ID
+
+	return k.clone(t)
+}
+
+func memoryRead[T any](ctx context.Context, s *memoryStore, k memoryKind[T], ref model.EntityRef) (T, error) {
+	if err := ctx.Err(); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := recordValue[T](s.records[s.ids[ref]])
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%s %w", ref, ErrNotFound)
+	}
+	return k.clone(t)
+}
+
+// memoryUpdate replaces a stored entity. The entity is found by its ID if it
+// has one, so that it may be renamed, and otherwise by its ref.
+func memoryUpdate[T any](ctx context.Context, s *memoryStore, k memoryKind[T], t T) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	if err := validateEntity(*k.entityOf(&t), k.kind); err != nil {
+		return zero, err
+	}
+	t, err := k.clone(t)
+	if err != nil {
+		return zero, err
+	}
+	e := k.entityOf(&t)
+	ref := e.EntityRef()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := e.ID
+	if id == 0 {
+		id = s.ids[ref]
+	}
+	current := s.records[id]
+	if _, ok := recordValue[T](current); !ok {
+		if e.ID != 0 {
+			return zero, fmt.Errorf("%s with ID %d %w", k.kind, e.ID, ErrNotFound)
+		}
+		return zero, fmt.Errorf("%s %w", ref, ErrNotFound)
+	}
+	if e.Version != 0 && e.Version != current.entity.Version {
+		return zero, fmt.Errorf("%w: %s is at version %d, not %d", ErrVersionMismatch, ref, current.entity.Version, e.Version)
+	}
+	if otherID, exists := s.ids[ref]; exists && otherID != id {
+		return zero, fmt.Errorf("%w: %s is already taken by another entity", ErrConflict, ref)
+	}
+	delete(s.ids, current.entity.EntityRef())
+	e.ID = id
+	e.Version = current.entity.Version + 1
+	e.Metadata.UID = current.entity.Metadata.UID
+	e.Metadata.Etag = versionEtag(e.Version)
+	e.Metadata.CreatedAt = current.entity.Metadata.CreatedAt
+	e.Metadata.UpdatedAt = storeTime()
+	if err := s.recordRevision(ctx, model.OperationUpdate, *e, current.value, t); err != nil {
+		return zero, err
+	}
+	s.records[id] = k.record(t)
+	s.ids[ref] = id
+
+	return k.clone(t)
+}
+
+func memoryDelete[T any](ctx context.Context, s *memoryStore, k memoryKind[T], ref model.EntityRef, version int64) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.ids[ref]
+	t, ok := recordValue[T](s.records[id])
+	if !ok {
+		return zero, fmt.Errorf("%s %w", ref, ErrNotFound)
+	}
+	if current := s.records[id].entity.Version; version != 0 && version != current {
+		return zero, fmt.Errorf("%w: %s is at version %d, not %d", ErrVersionMismatch, ref, current, version)
+	}
+	if err := s.recordRevision(ctx, model.OperationDelete, s.records[id].entity, t, nil); err != nil {
+		return zero, err
+	}
+	s.records[id].deletedAt = storeTime()
+	s.tombstones = append(s.tombstones, s.records[id])
+	delete(s.uids, s.records[id].entity.Metadata.UID)
+	delete(s.records, id)
+	delete(s.ids, ref)
+
+	return k.clone(t)
+}
+
+// memoryRestore brings a deleted record back as a new version of its entity,
+// and returns the record to store. The caller must hold the lock for writing.
+func memoryRestore[T any](ctx context.Context, s *memoryStore, k memoryKind[T], r *memoryRecord) (*memoryRecord, error) {
+	t, ok := recordValue[T](r)
+	if !ok {
+		return nil, fmt.Errorf("%w: deleted %s is not a %s", ErrInvalid, r.entity.EntityRef(), k.kind)
+	}
+	t, err := k.clone(t)
+	if err != nil {
+		return nil, err
+	}
+	e := k.entityOf(&t)
+	e.Version++
+	e.Metadata.Etag = versionEtag(e.Version)
+	e.Metadata.UpdatedAt = storeTime()
+	if err := s.recordRevision(ctx, model.OperationRestore, *e, nil, t); err != nil {
+		return nil, err
+	}
+	return k.record(t), nil
+}
+
+// recordRevision adds a change to an entity to the history. The entity is as
+// it is after the change, or for a delete, as it was before it; previous and
+// current are the whole entity before and after the change, or nil. The
+// caller must hold the lock for writing.
+func (s *memoryStore) recordRevision(ctx context.Context, operation string, e model.Entity, previous, current any) error {
+	revision := model.Revision{
+		EntityRef: e.EntityRef(),
+		UID:       e.Metadata.UID,
+		Version:   e.Version,
+		Operation: operation,
+		Actor:     ActorFromContext(ctx),
+		Timestamp: e.Metadata.UpdatedAt,
+	}
+	if operation == model.OperationDelete {
+		revision.Timestamp = storeTime()
+	}
+	if previous != nil {
+		b, err := yaml.Marshal(previous)
+		if err != nil {
+			return fmt.Errorf("failed to marshal entity for history: %w", err)
+		}
+		revision.Previous = string(b)
+	}
+	if current != nil {
+		b, err := yaml.Marshal(current)
+		if err != nil {
+			return fmt.Errorf("failed to marshal entity for history: %w", err)
+		}
+		revision.Current = string(b)
+	}
+	s.history = append(s.history, revision)
+	return nil
+}
+
+// historyUID finds the UID whose history belongs to a ref: that of the entity
+// with the ref, or if there is none, that of the entity that last had it. The
+// caller must hold the lock.
+func (s *memoryStore) historyUID(ref model.EntityRef) (string, error) {
+	if r, ok := s.records[s.ids[ref]]; ok {
+		return r.entity.Metadata.UID, nil
+	}
+	for i := len(s.history) - 1; i >= 0; i-- {
+		if s.history[i].EntityRef == ref {
+			return s.history[i].UID, nil
+		}
+	}
+	return "", fmt.Errorf("%s %w", ref, ErrNotFound)
+}
+
+// recordValue returns a record's entity if it is of type T. A nil record
+// holds no entity.
+func recordValue[T any](r *memoryRecord) (T, bool) {
+	if r == nil {
+		var zero T
+		return zero, false
+	}
+	t, ok := r.value.(T)
+	return t, ok
+}
+
+// matches reports whether a record passes a filter, with the same semantics
+// as the filter clauses of the SQL stores.
+func (r *memoryRecord) matches(kinds []string, filter Filter) (bool, error) {
+	field, err := resolveFilter(kinds, filter)
+	if err != nil {
+		return false, err
+	}
+	if labelKey, found := strings.CutPrefix(filter.Key, LabelFilterKeyPrefix); found {
+		return matchValues(keyValues(r.entity.Metadata.Labels, labelKey), filter), nil
+	}
+	if annotationKey, found := strings.CutPrefix(filter.Key, AnnotationFilterKeyPrefix); found {
+		return matchValues(keyValues(r.entity.Metadata.Annotations, annotationKey), filter), nil
+	}
+	if field.ref {
+		return matchValues(r.specRefs(filter.Key), r.qualifyRefFilter(field, filter)), nil
+	}
+	return matchValues(r.fields[field.column], filter), nil
+}
+
+// specRefs returns the qualified refs in a spec field of a record, as the
+// relation table holds them.
+func (r *memoryRecord) specRefs(field string) []string {
+	var refs []string
+	for _, specRef := range model.SpecRefs(r.value) {
+		if specRef.Field == field {
+			refs = append(refs, specRef.Ref.String())
+		}
+	}
+	return refs
+}
+
+// qualifyRefFilter qualifies the refs in a filter on a ref field for
+// matching against the spec refs of a record.
+func (r *memoryRecord) qualifyRefFilter(field filterField, filter Filter) Filter {
+	qualify := func(value string) string {
+		ref := filterRef(field, value)
+		if ref.Namespace == "" {
+			ref.Namespace = r.entity.Metadata.Namespace
+		}
+		return ref.String()
+	}
+	switch filter.Operator {
+	case FilterEquals, FilterNotEquals:
+		filter.Value = qualify(filter.Value)
+	case FilterIn, FilterNotIn:
+		values := make([]string, len(filter.Values))
+		for i, value := range filter.Values {
+			values[i] = qualify(value)
+		}
+		filter.Values = values
+	}
+	return filter
+}
+
+func keyValues(m map[string]string, key string) []string {
+	if v, ok := m[key]; ok {
+		return []string{v}
+	}
+	return nil
+}
+
+// matchValues matches a filter against the values of a field. A field
+// matches when any one of its values matches, and a negated filter matches
+// when none of them do, including when the field has no values.
+func matchValues(values []string, filter Filter) bool {
+	in := func(value string) bool {
+		return slices.Contains(filter.Values, value)
+	}
+	switch filter.Operator {
+	case FilterEquals:
+		return slices.Contains(values, filter.Value)
+	case FilterNotEquals:
+		return !slices.Contains(values, filter.Value)
+	case FilterIn:
+		return slices.ContainsFunc(values, in)
+	case FilterNotIn:
+		return !slices.ContainsFunc(values, in)
+	case FilterExists:
+		return len(values) > 0
+	case FilterNotExists:
+		return len(values) == 0
+	case FilterPrefix:
+		return slices.ContainsFunc(values, func(value string) bool {
+			return strings.HasPrefix(value, filter.Value)
+		})
+	default:
+		return false
+	}
+}
+
+// sortedRecords returns the stored records in the order they were created.
+// The caller must hold the lock.
+func (s *memoryStore) sortedRecords() []*memoryRecord {
+	records := slices.Collect(maps.Values(s.records))
+	slices.SortFunc(records, func(a, b *memoryRecord) int {
+		return cmp.Compare(a.entity.ID, b.entity.ID)
+	})
+	return records
+}
+
+func (s *memoryStore) ListEntities(ctx context.Context, kinds []string, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Pagination{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matched []*memoryRecord
+	for _, r := range s.sortedRecords() {
+		if len(kinds) > 0 && !slices.Contains(kinds, r.entity.Kind) {
+			continue
+		}
+		ok := true
+		for _, filter := range filters {
+			var err error
+			if ok, err = r.matches(kinds, filter); err != nil {
+				return nil, Pagination{}, err
+			}
+			if !ok {
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, r)
+		}
+	}
+
+	if ordering.OrderBy != "" {
+		orderBy := string(ordering.OrderBy)
+		slices.SortStableFunc(matched, func(a, b *memoryRecord) int {
+			c := cmp.Compare(strings.Join(a.fields[orderBy], ""), strings.Join(b.fields[orderBy], ""))
+			if ordering.Descending {
+				return -c
+			}
+			return c
+		})
+	}
+
+	results := []model.EntityRef{}
+	for _, r := range paginate(matched, pagination) {
+		results = append(results, r.entity.EntityRef())
+	}
+	return results, Pagination{
+		Limit:  pagination.Limit,
+		Offset: pagination.Offset + len(results),
+	}, nil
+}
+
+// paginate returns a page of items. As with LIMIT and OFFSET, the offset is
+// only applied along with a limit.
+func paginate[T any](items []T, pagination Pagination) []T {
+	if pagination.Limit <= 0 {
+		return items
+	}
+	start := min(pagination.Offset, len(items))
+	end := min(start+pagination.Limit, len(items))
+	return items[start:end]
+}
+
+func (s *memoryStore) ResolveUID(ctx context.Context, uid string) (model.EntityRef, error) {
+	if err := ctx.Err(); err != nil {
+		return model.EntityRef{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.records[s.uids[uid]]
+	if !ok {
+		return model.EntityRef{}, fmt.Errorf("entity with UID %s %w", uid, ErrNotFound)
+	}
+	return r.entity.EntityRef(), nil
+}
+
+func (s *memoryStore) ListRevisions(ctx context.Context, ref model.EntityRef, pagination Pagination) ([]model.Revision, Pagination, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Pagination{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	uid, err := s.historyUID(ref)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	var matched []model.Revision
+	for i := len(s.history) - 1; i >= 0; i-- {
+		if s.history[i].UID == uid {
+			matched = append(matched, s.history[i])
+		}
+	}
+
+	revisions := append([]model.Revision{}, paginate(matched, pagination)...)
+	return revisions, Pagination{
+		Limit:  pagination.Limit,
+		Offset: pagination.Offset + len(revisions),
+	}, nil
+}
+
+func (s *memoryStore) ReadRevision(ctx context.Context, ref model.EntityRef, version int64) (model.Revision, error) {
+	if err := ctx.Err(); err != nil {
+		return model.Revision{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	uid, err := s.historyUID(ref)
+	if err != nil {
+		return model.Revision{}, err
+	}
+	for i := len(s.history) - 1; i >= 0; i-- {
+		r := s.history[i]
+		if r.UID == uid && r.Version == version && r.Operation != model.OperationDelete {
+			return r, nil
+		}
+	}
+	return model.Revision{}, fmt.Errorf("version %d of %s %w", version, ref, ErrNotFound)
+}
+
+func (s *memoryStore) RestoreEntity(ctx context.Context, ref model.EntityRef) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := len(s.tombstones) - 1
+	for i >= 0 && s.tombstones[i].entity.EntityRef() != ref {
+		i--
+	}
+	if i < 0 {
+		return fmt.Errorf("deleted %s %w", ref, ErrNotFound)
+	}
+	if _, exists := s.ids[ref]; exists {
+		return fmt.Errorf("%w: %s is already taken by another entity", ErrConflict, ref)
+	}
+
+	var r *memoryRecord
+	var err error
+	switch ref.Kind {
+	case model.KindComponent:
+		r, err = memoryRestore(ctx, s, memoryComponents, s.tombstones[i])
+	case model.KindAPI:
+		r, err = memoryRestore(ctx, s, memoryAPIs, s.tombstones[i])
+	case model.KindUser:
+		r, err = memoryRestore(ctx, s, memoryUsers, s.tombstones[i])
+	case model.KindGroup:
+		r, err = memoryRestore(ctx, s, memoryGroups, s.tombstones[i])
+	case model.KindSystem:
+		r, err = memoryRestore(ctx, s, memorySystems, s.tombstones[i])
+	case model.KindResource:
+		r, err = memoryRestore(ctx, s, memoryResources, s.tombstones[i])
+	case model.KindDomain:
+		r, err = memoryRestore(ctx, s, memoryDomains, s.tombstones[i])
+	case model.KindLocation:
+		r, err = memoryRestore(ctx, s, memoryLocations, s.tombstones[i])
+	case model.KindTemplate:
+		r, err = memoryRestore(ctx, s, memoryTemplates, s.tombstones[i])
+	default:
+		err = fmt.Errorf("%w: unsupported kind %s", ErrInvalid, ref.Kind)
+	}
+	if err != nil {
+		return err
+	}
+	s.tombstones = slices.Delete(s.tombstones, i, i+1)
+	s.records[r.entity.ID] = r
+	s.ids[ref] = r.entity.ID
+	s.uids[r.entity.Metadata.UID] = r.entity.ID
+	return nil
+}
+
+func (s *memoryStore) ListDeletedEntities(ctx context.Context, kinds []string, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Pagination{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var refs []model.EntityRef
+	for _, r := range s.tombstones {
+		ref := r.entity.EntityRef()
+		if len(kinds) > 0 && !slices.Contains(kinds, ref.Kind) {
+			continue
+		}
+		if !slices.Contains(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	slices.SortFunc(refs, func(a, b model.EntityRef) int {
+		return cmp.Or(
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+
+	results := append([]model.EntityRef{}, paginate(refs, pagination)...)
+	return results, Pagination{
+		Limit:  pagination.Limit,
+		Offset: pagination.Offset + len(results),
+	}, nil
+}
+
+func (s *memoryStore) PurgeEntities(ctx context.Context, before time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.tombstones)
+	s.tombstones = slices.DeleteFunc(s.tombstones, func(r *memoryRecord) bool {
+		return r.deletedAt.Before(before)
+	})
+	return int64(n - len(s.tombstones)), nil
+}
+
+// ListRelations computes relations from the stored specs as it goes, which
+// keeps them as up to date as the SQL stores keep theirs.
+func (s *memoryStore) ListRelations(ctx context.Context, ref model.EntityRef) ([]model.Relation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.records[s.ids[ref]]
+	if !ok {
+		return nil, fmt.Errorf("%s %w", ref, ErrNotFound)
+	}
+	relations := append([]model.Relation{}, model.SpecRelations(r.value)...)
+	for _, other := range s.records {
+		for _, relation := range model.SpecRelations(other.value) {
+			if relation.TargetRef == ref {
+				relations = append(relations, model.Relation{
+					Type:      model.InverseRelation(relation.Type),
+					TargetRef: other.entity.EntityRef(),
+				})
+			}
+		}
+	}
+	return model.SortRelations(relations), nil
+}
+
+func (s *memoryStore) ListReferrers(ctx context.Context, ref model.EntityRef) ([]model.Referrer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	referrers := []model.Referrer{}
+	for _, r := range s.records {
+		for _, specRef := range model.SpecRefs(r.value) {
+			if specRef.Ref == ref {
+				referrers = append(referrers, model.Referrer{
+					EntityRef: r.entity.EntityRef(),
+					Field:     specRef.Field,
+				})
+			}
+		}
+	}
+	return model.SortReferrers(referrers), nil
+}
+
+func (s *memoryStore) ListDanglingRefs(ctx context.Context) ([]model.DanglingRef, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	refs := []model.DanglingRef{}
+	for _, r := range s.records {
+		specRefs := model.SpecRefs(r.value)
+		var owner model.EntityRef
+		for _, specRef := range specRefs {
+			if specRef.Field == "spec.owner" {
+				owner = specRef.Ref
+			}
+		}
+		for _, specRef := range specRefs {
+			if _, ok := s.ids[specRef.Ref]; !ok {
+				refs = append(refs, model.DanglingRef{
+					EntityRef: r.entity.EntityRef(),
+					Owner:     owner,
+					Field:     specRef.Field,
+					TargetRef: specRef.Ref,
+				})
+			}
+		}
+	}
+	return model.SortDanglingRefs(refs), nil
+}
+
+func (s *memoryStore) ListSpecRefs(ctx context.Context, fields []string) ([]model.EntitySpecRef, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	refs := []model.EntitySpecRef{}
+	for _, r := range s.records {
+		for _, specRef := range model.SpecRefs(r.value) {
+			if len(fields) == 0 || slices.Contains(fields, specRef.Field) {
+				refs = append(refs, model.EntitySpecRef{
+					EntityRef: r.entity.EntityRef(),
+					SpecRef:   specRef,
+				})
+			}
+		}
+	}
+	return model.SortEntitySpecRefs(refs), nil
+}
+
+func (s *memoryStore) ListExistingRefs(ctx context.Context, refs []model.EntityRef) ([]model.EntityRef, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	existing := []model.EntityRef{}
+	for _, ref := range refs {
+		if _, ok := s.ids[ref]; ok {
+			existing = append(existing, ref)
+		}
+	}
+	return model.SortEntityRefs(existing), nil
+}
+
+func (s *memoryStore) SearchEntities(ctx context.Context, query string, pagination Pagination) ([]SearchMatch, Pagination, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Pagination{}, err
+	}
+	terms := slices.Compact(slices.Sorted(slices.Values(searchTerms(query))))
+	if len(terms) == 0 {
+		return nil, Pagination{}, fmt.Errorf("%w: empty search query", ErrInvalid)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type rankedMatch struct {
+		match SearchMatch
+		rank  int
+	}
+	var matches []rankedMatch
+	for _, r := range s.sortedRecords() {
+		if match, rank, ok := searchRecord(r, terms); ok {
+			matches = append(matches, rankedMatch{match: match, rank: rank})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b rankedMatch) int {
+		return cmp.Compare(b.rank, a.rank)
+	})
+
+	results := []SearchMatch{}
+	for _, m := range paginate(matches, pagination) {
+		results = append(results, m.match)
+	}
+	return results, Pagination{
+		Limit:  pagination.Limit,
+		Offset: pagination.Offset + len(results),
+	}, nil
+}
+
+// searchTerms splits free text into lower-case words.
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchRecord matches a record against search terms. As with the search
+// indexes of the SQL stores, each term must match the start of a word in the
+// name, title, description, tags or annotation values. The rank is the number
+// of matching words, and the snippet is the first text that matches, with
+// matching words marked.
+func searchRecord(r *memoryRecord, terms []string) (SearchMatch, int, bool) {
+	texts := []string{
+		r.entity.Metadata.Name,
+		r.entity.Metadata.Title,
+		r.entity.Metadata.Description,
+		strings.Join(r.entity.Metadata.Tags, " "),
+	}
+	for _, k := range slices.Sorted(maps.Keys(r.entity.Metadata.Annotations)) {
+		texts = append(texts, r.entity.Metadata.Annotations[k])
+	}
+
+	matchesTerm := func(word string) (string, bool) {
+		for _, term := range terms {
+			if strings.HasPrefix(word, term) {
+				return term, true
+			}
+		}
+		return "", false
+	}
+
+	found := make(map[string]bool)
+	rank := 0
+	snippet := ""
+	for _, text := range texts {
+		marked, count := markWords(text, func(word string) bool {
+			term, ok := matchesTerm(strings.ToLower(word))
+			if ok {
+				found[term] = true
+			}
+			return ok
+		})
+		if count > 0 && snippet == "" {
+			snippet = markSnippet(marked)
+		}
+		rank += count
+	}
+	if len(found) < len(terms) {
+		return SearchMatch{}, 0, false
+	}
+	return SearchMatch{
+		EntityRef: r.entity.EntityRef(),
+		Snippet:   snippet,
+	}, rank, true
+}
+
+// markWords surrounds the words in text that match with snippet markers, and
+// counts them.
+func markWords(text string, match func(string) bool) (string, int) {
+	var b strings.Builder
+	count := 0
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		word := text[start:end]
+		if match(word) {
+			b.WriteString(snippetMarkStart + word + snippetMarkEnd)
+			count++
+		} else {
+			b.WriteString(word)
+		}
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+		b.WriteRune(r)
+	}
+	flush(len(text))
+	return b.String(), count
+}
+
+// ---
+
+var memoryComponents = memoryKind[model.Component]{
+	kind:     model.KindComponent,
+	entityOf: func(c *model.Component) *model.Entity { return &c.Entity },
+	cloneSpec: func(c model.Component) (model.Component, error) {
+		c.Spec.ProvidesAPIs = cloneRefs(c.Spec.ProvidesAPIs)
+		c.Spec.ConsumesAPIs = cloneRefs(c.Spec.ConsumesAPIs)
+		c.Spec.DependsOn = cloneRefs(c.Spec.DependsOn)
+		c.Spec.DependencyOf = cloneRefs(c.Spec.DependencyOf)
+		return c, nil
+	},
+	fields: func(c model.Component) map[string][]string {
+		return map[string][]string{
+			"component.type":            fieldValue(c.Spec.Type),
+			"component.lifecycle":       fieldValue(c.Spec.Lifecycle),
+			"component.owner":           refValue(c.Spec.Owner),
+			"component.system":          refValue(c.Spec.System),
+			"component.subcomponent_of": refValue(c.Spec.SubcomponentOf),
+			"component.provides_apis":   refValues(c.Spec.ProvidesAPIs),
+			"component.consumes_apis":   refValues(c.Spec.ConsumesAPIs),
+			"component.depends_on":      refValues(c.Spec.DependsOn),
+			"component.dependency_of":   refValues(c.Spec.DependencyOf),
+		}
+	},
+}
+
+func (s *memoryStore) CreateComponent(ctx context.Context, c model.Component) (model.Component, error) {
+	return memoryCreate(ctx, s, memoryComponents, c)
+}
+
+func (s *memoryStore) ReadComponent(ctx context.Context, ref model.EntityRef) (model.Component, error) {
+	return memoryRead(ctx, s, memoryComponents, ref)
+}
+
+func (s *memoryStore) UpdateComponent(ctx context.Context, c model.Component) (model.Component, error) {
+	return memoryUpdate(ctx, s, memoryComponents, c)
+}
+
+func (s *memoryStore) DeleteComponent(ctx context.Context, ref model.EntityRef, version int64) (model.Component, error) {
+	return memoryDelete(ctx, s, memoryComponents, ref, version)
+}
+
+func (s *memoryStore) ListComponents(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return s.ListEntities(ctx, []string{model.KindComponent}, filters, ordering, pagination)
+}
+
+// ---
+
+var memoryAPIs = memoryKind[model.API]{
+	kind:     model.KindAPI,
+	entityOf: func(a *model.API) *model.Entity { return &a.Entity },
+	cloneSpec: func(a model.API) (model.API, error) {
+		return a, nil
+	},
+	fields: func(a model.API) map[string][]string {
+		return map[string][]string{
+			"api.type":      fieldValue(a.Spec.Type),
+			"api.lifecycle": fieldValue(a.Spec.Lifecycle),
+			"api.owner":     refValue(a.Spec.Owner),
+			"api.system":    refValue(a.Spec.System),
+		}
+	},
+}
+
+func (s *memoryStore) CreateAPI(ctx context.Context, a model.API) (model.API, error) {
+	return memoryCreate(ctx, s, memoryAPIs, a)
+}
+
+func (s *memoryStore) ReadAPI(ctx context.Context, ref model.EntityRef) (model.API, error) {
+	return memoryRead(ctx, s, memoryAPIs, ref)
+}
+
+func (s *memoryStore) UpdateAPI(ctx context.Context, a model.API) (model.API, error) {
+	return memoryUpdate(ctx, s, memoryAPIs, a)
+}
+
+func (s *memoryStore) DeleteAPI(ctx context.Context, ref model.EntityRef, version int64) (model.API, error) {
+	return memoryDelete(ctx, s, memoryAPIs, ref, version)
+}
+
+func (s *memoryStore) ListAPIs(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return s.ListEntities(ctx, []string{model.KindAPI}, filters, ordering, pagination)
+}
+
+// ---
+
+var memoryUsers = memoryKind[model.User]{
+	kind:     model.KindUser,
+	entityOf: func(u *model.User) *model.Entity { return &u.Entity },
+	cloneSpec: func(u model.User) (model.User, error) {
+		u.Spec.MemberOf = cloneRefs(u.Spec.MemberOf)
+		return u, nil
+	},
+	fields: func(u model.User) map[string][]string {
+		return map[string][]string{
+			`"user".display_name`: fieldValue(u.Spec.Profile.DisplayName),
+			`"user".email`:        fieldValue(u.Spec.Profile.Email),
+			`"user".member_of`:    refValues(u.Spec.MemberOf),
+		}
+	},
+}
+
+func (s *memoryStore) CreateUser(ctx context.Context, u model.User) (model.User, error) {
+	return memoryCreate(ctx, s, memoryUsers, u)
+}
+
+func (s *memoryStore) ReadUser(ctx context.Context, ref model.EntityRef) (model.User, error) {
+	return memoryRead(ctx, s, memoryUsers, ref)
+}
+
+func (s *memoryStore) UpdateUser(ctx context.Context, u model.User) (model.User, error) {
+	return memoryUpdate(ctx, s, memoryUsers, u)
+}
+
+func (s *memoryStore) DeleteUser(ctx context.Context, ref model.EntityRef, version int64) (model.User, error) {
+	return memoryDelete(ctx, s, memoryUsers, ref, version)
+}
+
+func (s *memoryStore) ListUsers(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return s.ListEntities(ctx, []string{model.KindUser}, filters, ordering, pagination)
+}
+
+// ---
+
+var memoryGroups = memoryKind[model.Group]{
+	kind:     model.KindGroup,
+	entityOf: func(g *model.Group) *model.Entity { return &g.Entity },
+	cloneSpec: func(g model.Group) (model.Group, error) {
+		g.Spec.Children = cloneRefs(g.Spec.Children)
+		g.Spec.Members = cloneRefs(g.Spec.Members)
+		return g, nil
+	},
+	fields: func(g model.Group) map[string][]string {
+		return map[string][]string{
+			"grp.type":         fieldValue(g.Spec.Type),
+			"grp.display_name": fieldValue(g.Spec.Profile.DisplayName),
+			"grp.email":        fieldValue(g.Spec.Profile.Email),
+			"grp.parent":       refValue(g.Spec.Parent),
+			"grp.children":     refValues(g.Spec.Children),
+			"grp.members":      refValues(g.Spec.Members),
+		}
+	},
+}
+
+func (s *memoryStore) CreateGroup(ctx context.Context, g model.Group) (model.Group, error) {
+	return memoryCreate(ctx, s, memoryGroups, g)
+}
+
+func (s *memoryStore) ReadGroup(ctx context.Context, ref model.EntityRef) (model.Group, error) {
+	return memoryRead(ctx, s, memoryGroups, ref)
+}
+
+func (s *memoryStore) UpdateGroup(ctx context.Context, g model.Group) (model.Group, error) {
+	return memoryUpdate(ctx, s, memoryGroups, g)
+}
+
+func (s *memoryStore) DeleteGroup(ctx context.Context, ref model.EntityRef, version int64) (model.Group, error) {
+	return memoryDelete(ctx, s, memoryGroups, ref, version)
+}
+
+func (s *memoryStore) ListGroups(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return s.ListEntities(ctx, []string{model.KindGroup}, filters, ordering, pagination)
+}
+
+// ---
+
+var memorySystems = memoryKind[model.System]{
+	kind:     model.KindSystem,
+	entityOf: func(sy *model.System) *model.Entity { return &sy.Entity },
+	cloneSpec: func(sy model.System) (model.System, error) {
+		return sy, nil
+	},
+	fields: func(sy model.System) map[string][]string {
+		return map[string][]string{
+			"system.owner":  refValue(sy.Spec.Owner),
+			"system.domain": refValue(sy.Spec.Domain),
+			"system.type":   fieldValue(sy.Spec.Type),
+		}
+	},
+}
+
+func (s *memoryStore) CreateSystem(ctx context.Context, sy model.System) (model.System, error) {
+	return memoryCreate(ctx, s, memorySystems, sy)
+}
+
+func (s *memoryStore) ReadSystem(ctx context.Context, ref model.EntityRef) (model.System, error) {
+	return memoryRead(ctx, s, memorySystems, ref)
+}
+
+func (s *memoryStore) UpdateSystem(ctx context.Context, sy model.System) (model.System, error) {
+	return memoryUpdate(ctx, s, memorySystems, sy)
+}
+
+func (s *memoryStore) DeleteSystem(ctx context.Context, ref model.EntityRef, version int64) (model.System, error) {
+	return memoryDelete(ctx, s, memorySystems, ref, version)
+}
+
+func (s *memoryStore) ListSystems(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return s.ListEntities(ctx, []string{model.KindSystem}, filters, ordering, pagination)
+}
+
+// ---
+
+var memoryResources = memoryKind[model.Resource]{
+	kind:     model.KindResource,
+	entityOf: func(r *model.Resource) *model.Entity { return &r.Entity },
+	cloneSpec: func(r model.Resource) (model.Resource, error) {
+		r.Spec.DependsOn = cloneRefs(r.Spec.DependsOn)
+		r.Spec.DependencyOf = cloneRefs(r.Spec.DependencyOf)
+		return r, nil
+	},
+	fields: func(r model.Resource) map[string][]string {
+		return map[string][]string{
+			"resource.type":          fieldValue(r.Spec.Type),
+			"resource.owner":         refValue(r.Spec.Owner),
+			"resource.system":        refValue(r.Spec.System),
+			"resource.depends_on":    refValues(r.Spec.DependsOn),
+			"resource.dependency_of": refValues(r.Spec.DependencyOf),
+		}
+	},
+}
+
+func (s *memoryStore) CreateResource(ctx context.Context, r model.Resource) (model.Resource, error) {
+	return memoryCreate(ctx, s, memoryResources, r)
+}
+
+func (s *memoryStore) ReadResource(ctx context.Context, ref model.EntityRef) (model.Resource, error) {
+	return memoryRead(ctx, s, memoryResources, ref)
+}
+
+func (s *memoryStore) UpdateResource(ctx context.Context, r model.Resource) (model.Resource, error) {
+	return memoryUpdate(ctx, s, memoryResources, r)
+}
+
+func (s *memoryStore) DeleteResource(ctx context.Context, ref model.EntityRef, version int64) (model.Resource, error) {
+	return memoryDelete(ctx, s, memoryResources, ref, version)
+}
+
+func (s *memoryStore) ListResources(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return s.ListEntities(ctx, []string{model.KindResource}, filters, ordering, pagination)
+}
+
+// ---
+
+var memoryDomains = memoryKind[model.Domain]{
+	kind:     model.KindDomain,
+	entityOf: func(d *model.Domain) *model.Entity { return &d.Entity },
+	cloneSpec: func(d model.Domain) (model.Domain, error) {
+		return d, nil
+	},
+	fields: func(d model.Domain) map[string][]string {
+		return map[string][]string{
+			"domain.owner":        refValue(d.Spec.Owner),
+			"domain.subdomain_of": refValue(d.Spec.SubdomainOf),
+			"domain.type":         fieldValue(d.Spec.Type),
+		}
+	},
+}
+
+func (s *memoryStore) CreateDomain(ctx context.Context, d model.Domain) (model.Domain, error) {
+	return memoryCreate(ctx, s, memoryDomains, d)
+}
+
+func (s *memoryStore) ReadDomain(ctx context.Context, ref model.EntityRef) (model.Domain, error) {
+	return memoryRead(ctx, s, memoryDomains, ref)
+}
+
+func (s *memoryStore) UpdateDomain(ctx context.Context, d model.Domain) (model.Domain, error) {
+	return memoryUpdate(ctx, s, memoryDomains, d)
+}
+
+func (s *memoryStore) DeleteDomain(ctx context.Context, ref model.EntityRef, version int64) (model.Domain, error) {
+	return memoryDelete(ctx, s, memoryDomains, ref, version)
+}
+
+func (s *memoryStore) ListDomains(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return s.ListEntities(ctx, []string{model.KindDomain}, filters, ordering, pagination)
+}
+
+// ---
+
+var memoryLocations = memoryKind[model.Location]{
+	kind:     model.KindLocation,
+	entityOf: func(l *model.Location) *model.Entity { return &l.Entity },
+	cloneSpec: func(l model.Location) (model.Location, error) {
+		l.Spec.Targets = cloneStrings(l.Spec.Targets)
+		return l, nil
+	},
+	fields: func(l model.Location) map[string][]string {
+		return map[string][]string{
+			"location.type":    fieldValue(l.Spec.Type),
+			"location.target":  fieldValue(l.Spec.Target),
+			"location.targets": cloneStrings(l.Spec.Targets),
+		}
+	},
+}
+
+func (s *memoryStore) CreateLocation(ctx context.Context, l model.Location) (model.Location, error) {
+	return memoryCreate(ctx, s, memoryLocations, l)
+}
+
+func (s *memoryStore) ReadLocation(ctx context.Context, ref model.EntityRef) (model.Location, error) {
+	return memoryRead(ctx, s, memoryLocations, ref)
+}
+
+func (s *memoryStore) UpdateLocation(ctx context.Context, l model.Location) (model.Location, error) {
+	return memoryUpdate(ctx, s, memoryLocations, l)
+}
+
+func (s *memoryStore) DeleteLocation(ctx context.Context, ref model.EntityRef, version int64) (model.Location, error) {
+	return memoryDelete(ctx, s, memoryLocations, ref, version)
+}
+
+func (s *memoryStore) ListLocations(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return s.ListEntities(ctx, []string{model.KindLocation}, filters, ordering, pagination)
+}
+
+// ---
+
+// memoryTemplates copies template specs through YAML, which is also how the
+// SQL stores keep them, so that the structured parts are never shared.
+var memoryTemplates = memoryKind[model.Template]{
+	kind:     model.KindTemplate,
+	entityOf: func(t *model.Template) *model.Entity { return &t.Entity },
+	cloneSpec: func(t model.Template) (model.Template, error) {
+		b, err := yaml.Marshal(t.Spec)
+		if err != nil {
+			return model.Template{}, fmt.Errorf("%w: failed to marshal template spec: %s", ErrInvalid, err)
+		}
+		var spec model.TemplateSpec
+		if err := yaml.Unmarshal(b, &spec); err != nil {
+			return model.Template{}, fmt.Errorf("failed to unmarshal template spec: %w", err)
+		}
+		t.Spec = spec
+		return t, nil
+	},
+	fields: func(t model.Template) map[string][]string {
+		return map[string][]string{
+			"template.type":  fieldValue(t.Spec.Type),
+			"template.owner": refValue(t.Spec.Owner),
+		}
+	},
+}
+
+func (s *memoryStore) CreateTemplate(ctx context.Context, t model.Template) (model.Template, error) {
+	return memoryCreate(ctx, s, memoryTemplates, t)
+}
+
+func (s *memoryStore) ReadTemplate(ctx context.Context, ref model.EntityRef) (model.Template, error) {
+	return memoryRead(ctx, s, memoryTemplates, ref)
+}
+
+func (s *memoryStore) UpdateTemplate(ctx context.Context, t model.Template) (model.Template, error) {
+	return memoryUpdate(ctx, s, memoryTemplates, t)
+}
+
+func (s *memoryStore) DeleteTemplate(ctx context.Context, ref model.EntityRef, version int64) (model.Template, error) {
+	return memoryDelete(ctx, s, memoryTemplates, ref, version)
+}
+
+func (s *memoryStore) ListTemplates(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return s.ListEntities(ctx, []string{model.KindTemplate}, filters, ordering, pagination)
+}
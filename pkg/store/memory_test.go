@@ -0,0 +1,328 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_CreateReadUpdateDelete(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	c, err := store.CreateComponent(ctx, model.TestFullComponent)
+	require.NoError(t, err)
+	assert.NotZero(t, c.ID)
+	expected := model.TestFullComponent
+	expected.Entity = withStoreFields(expected.Entity, c.Entity)
+	assert.Equal(t, expected, c)
+
+	r, err := store.ReadComponent(ctx, model.TestFullComponent.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, expected, r)
+
+	_, err = store.CreateComponent(ctx, model.TestFullComponent)
+	assert.Error(t, err, "duplicate ref")
+
+	c.Metadata.Title = "my-new-title"
+	c.Metadata.Labels = map[string]string{"key0": "value0"}
+	c.Spec.DependsOn = nil
+	u, err := store.UpdateComponent(ctx, c)
+	require.NoError(t, err)
+	c.Version++
+	c.Metadata.Etag = "2"
+	c.Metadata.UpdatedAt = u.Metadata.UpdatedAt
+	assert.Equal(t, c, u)
+	r, err = store.ReadComponent(ctx, c.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, c, r)
+
+	_, err = store.ReadAPI(ctx, c.EntityRef())
+	assert.Error(t, err, "wrong kind")
+
+	d, err := store.DeleteComponent(ctx, c.EntityRef(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, c, d)
+	_, err = store.ReadComponent(ctx, c.EntityRef())
+	assert.Error(t, err)
+	_, err = store.DeleteComponent(ctx, c.EntityRef(), 0)
+	assert.Error(t, err)
+	_, err = store.UpdateComponent(ctx, c)
+	assert.Error(t, err)
+}
+
+func TestMemoryStore_AllKinds(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	api, err := store.CreateAPI(ctx, model.TestFullAPI)
+	require.NoError(t, err)
+	ra, err := store.ReadAPI(ctx, api.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, api, ra)
+
+	user, err := store.CreateUser(ctx, model.TestFullUser)
+	require.NoError(t, err)
+	ru, err := store.ReadUser(ctx, user.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, user, ru)
+
+	group, err := store.CreateGroup(ctx, model.TestFullGroup)
+	require.NoError(t, err)
+	rg, err := store.ReadGroup(ctx, group.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, group, rg)
+
+	system, err := store.CreateSystem(ctx, model.TestFullSystem)
+	require.NoError(t, err)
+	rs, err := store.ReadSystem(ctx, system.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, system, rs)
+
+	resource, err := store.CreateResource(ctx, model.TestFullResource)
+	require.NoError(t, err)
+	rr, err := store.ReadResource(ctx, resource.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, resource, rr)
+
+	domain, err := store.CreateDomain(ctx, model.TestFullDomain)
+	require.NoError(t, err)
+	rd, err := store.ReadDomain(ctx, domain.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, domain, rd)
+
+	location, err := store.CreateLocation(ctx, model.TestFullLocation)
+	require.NoError(t, err)
+	rl, err := store.ReadLocation(ctx, location.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, location, rl)
+
+	template, err := store.CreateTemplate(ctx, model.TestFullTemplate)
+	require.NoError(t, err)
+	rt, err := store.ReadTemplate(ctx, template.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, template, rt)
+
+	refs, _, err := store.ListEntities(ctx, nil, nil, Ordering{OrderBy: OrderByKind}, Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{
+		api.EntityRef(),
+		domain.EntityRef(),
+		group.EntityRef(),
+		location.EntityRef(),
+		resource.EntityRef(),
+		system.EntityRef(),
+		template.EntityRef(),
+		user.EntityRef(),
+	}, refs)
+}
+
+func TestMemoryStore_CopiesEntities(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	c := model.TestFullComponent
+	c.Metadata.Labels = map[string]string{"key0": "value0"}
+	c.Spec.DependsOn = []model.EntityRef{model.TestResource1EntityRef}
+	created, err := store.CreateComponent(ctx, c)
+	require.NoError(t, err)
+
+	c.Metadata.Labels["key0"] = "changed"
+	c.Spec.DependsOn[0] = model.TestResource2EntityRef
+	created.Metadata.Labels["key0"] = "changed"
+
+	r, err := store.ReadComponent(ctx, c.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, "value0", r.Metadata.Labels["key0"])
+	assert.Equal(t, []model.EntityRef{model.TestResource1EntityRef}, r.Spec.DependsOn)
+}
+
+func TestMemoryStore_ListComponents(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	c1 := model.TestFullComponent
+	c1.Metadata.Name = "b"
+	c1.Metadata.Labels = map[string]string{"tier": "frontend"}
+	c1.Metadata.Tags = []string{"java", "web"}
+	_, err := store.CreateComponent(ctx, c1)
+	require.NoError(t, err)
+	c2 := model.TestFullComponent
+	c2.Metadata.Name = "c"
+	c2.Metadata.Labels = map[string]string{"tier": "backend"}
+	c2.Metadata.Tags = []string{"go"}
+	c2.Spec.Owner = model.TestOwner2EntityRef
+	_, err = store.CreateComponent(ctx, c2)
+	require.NoError(t, err)
+	c3 := model.TestFullComponent
+	c3.Metadata.Name = "a"
+	c3.Metadata.Labels = nil
+	c3.Metadata.Tags = nil
+	_, err = store.CreateComponent(ctx, c3)
+	require.NoError(t, err)
+
+	type testCase struct {
+		filters       []Filter
+		ordering      Ordering
+		pagination    Pagination
+		expectedNames []string
+		expectedErr   bool
+		description   string
+	}
+	tcs := []testCase{
+		{
+			expectedNames: []string{"b", "c", "a"},
+			description:   "no filters, no ordering",
+		},
+		{
+			ordering:      Ordering{OrderBy: OrderByName},
+			expectedNames: []string{"a", "b", "c"},
+			description:   "ordered by name",
+		},
+		{
+			ordering:      Ordering{OrderBy: OrderByName, Descending: true},
+			expectedNames: []string{"c", "b", "a"},
+			description:   "ordered by name descending",
+		},
+		{
+			ordering:      Ordering{OrderBy: OrderByName},
+			pagination:    Pagination{Limit: 2, Offset: 1},
+			expectedNames: []string{"b", "c"},
+			description:   "page",
+		},
+		{
+			ordering:      Ordering{OrderBy: OrderByName},
+			pagination:    Pagination{Limit: 2, Offset: 5},
+			expectedNames: []string{},
+			description:   "page past the end",
+		},
+		{
+			filters:       []Filter{{Key: "metadata.labels.tier", Operator: FilterNotEquals, Value: "frontend"}},
+			ordering:      Ordering{OrderBy: OrderByName},
+			expectedNames: []string{"a", "c"},
+			description:   "label not equals",
+		},
+		{
+			filters:       []Filter{{Key: "metadata.labels.tier", Operator: FilterExists}},
+			ordering:      Ordering{OrderBy: OrderByName},
+			expectedNames: []string{"b", "c"},
+			description:   "label exists",
+		},
+		{
+			filters:       []Filter{{Key: "metadata.tags", Operator: FilterIn, Values: []string{"web", "go"}}},
+			ordering:      Ordering{OrderBy: OrderByName},
+			expectedNames: []string{"b", "c"},
+			description:   "tags in",
+		},
+		{
+			filters:       []Filter{{Key: "metadata.tags", Operator: FilterNotIn, Values: []string{"web", "go"}}},
+			expectedNames: []string{"a"},
+			description:   "tags not in",
+		},
+		{
+			filters:       []Filter{{Key: "spec.owner", Value: model.TestOwner2EntityRef.String()}},
+			expectedNames: []string{"c"},
+			description:   "spec field",
+		},
+		{
+			filters:       []Filter{{Key: "metadata.name", Operator: FilterPrefix, Value: "b"}},
+			expectedNames: []string{"b"},
+			description:   "prefix",
+		},
+		{
+			filters:     []Filter{{Key: "spec.nope", Value: "x"}},
+			expectedErr: true,
+			description: "unsupported field",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			refs, pagination, err := store.ListComponents(ctx, tc.filters, tc.ordering, tc.pagination)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			names := []string{}
+			for _, ref := range refs {
+				names = append(names, ref.Name)
+			}
+			assert.Equal(t, tc.expectedNames, names)
+			assert.Equal(t, tc.pagination.Offset+len(refs), pagination.Offset)
+		})
+	}
+}
+
+func TestMemoryStore_SearchEntities(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	c1 := model.TestFullComponent
+	c1.Metadata.Name = "payments-api"
+	c1.Metadata.Title = "Payments API"
+	c1.Metadata.Description = "Takes card payments and issues refunds"
+	c1.Metadata.Annotations = nil
+	c1, err := store.CreateComponent(ctx, c1)
+	require.NoError(t, err)
+	c2 := model.TestFullComponent
+	c2.Metadata.Name = "ledger"
+	c2.Metadata.Title = "Ledger"
+	c2.Metadata.Description = "Records every transaction"
+	c2.Metadata.Tags = []string{"go", "payments"}
+	c2.Metadata.Annotations = map[string]string{"runbook": "https://wiki.example.com/refunds"}
+	c2, err = store.CreateComponent(ctx, c2)
+	require.NoError(t, err)
+
+	matches, _, err := store.SearchEntities(ctx, "payments", Pagination{})
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, c1.EntityRef(), matches[0].EntityRef)
+	assert.Equal(t, "<mark>payments</mark>-api", matches[0].Snippet)
+	assert.Equal(t, c2.EntityRef(), matches[1].EntityRef)
+
+	matches, _, err = store.SearchEntities(ctx, "card refund", Pagination{})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, c1.EntityRef(), matches[0].EntityRef)
+
+	matches, nextPagination, err := store.SearchEntities(ctx, "refund", Pagination{Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, Pagination{Limit: 1, Offset: 1}, nextPagination)
+
+	_, _, err = store.SearchEntities(ctx, " ", Pagination{})
+	assert.Error(t, err)
+}
+
+func TestMemoryStore_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := model.TestFullComponent
+			c.Metadata.Name = fmt.Sprintf("component-%d", i)
+			c, err := store.CreateComponent(ctx, c)
+			assert.NoError(t, err)
+			c.Metadata.Title = "updated"
+			_, err = store.UpdateComponent(ctx, c)
+			assert.NoError(t, err)
+			_, _, err = store.ListComponents(ctx, nil, Ordering{OrderBy: OrderByName}, Pagination{})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	refs, _, err := store.ListComponents(ctx, []Filter{{Key: "metadata.title", Value: "updated"}}, Ordering{}, Pagination{})
+	require.NoError(t, err)
+	assert.Len(t, refs, 20)
+}
@@ -11,10 +11,12 @@ import (
 //go:embed migrations/*
 var dbMigrations embed.FS
 
+// runMigrations brings a database up to date using the migrations for a
+// sql-migrate dialect, which are kept in a directory named for it.
 func runMigrations(db *sql.DB, dialect string) error {
 	migrations := migrate.EmbedFileSystemMigrationSource{
 		FileSystem: dbMigrations,
-		Root:       "migrations",
+		Root:       "migrations/" + dialect,
 	}
 	_, err := migrate.Exec(db, dialect, migrations, migrate.Up)
 	if err != nil {
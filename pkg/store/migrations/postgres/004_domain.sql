@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE domain (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  entity_id BIGINT NOT NULL,
+  owner VARCHAR(512) NOT NULL,
+  subdomain_of VARCHAR(512),
+  type VARCHAR(255),
+  CONSTRAINT fk_entity
+    FOREIGN KEY (entity_id)
+    REFERENCES entity(id)
+    ON DELETE CASCADE
+);
+
+-- +migrate Down
+
+DROP TABLE domain;
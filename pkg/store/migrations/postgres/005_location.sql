@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE location (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  entity_id BIGINT NOT NULL,
+  type VARCHAR(50),
+  target TEXT,
+  targets TEXT[],
+  CONSTRAINT fk_entity
+    FOREIGN KEY (entity_id)
+    REFERENCES entity(id)
+    ON DELETE CASCADE
+);
+
+-- +migrate Down
+
+DROP TABLE location;
@@ -0,0 +1,18 @@
+-- +migrate Up
+CREATE TABLE template (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  entity_id BIGINT NOT NULL,
+  type VARCHAR(255) NOT NULL,
+  owner VARCHAR(512),
+  parameters TEXT,
+  steps TEXT,
+  output TEXT,
+  CONSTRAINT fk_entity
+    FOREIGN KEY (entity_id)
+    REFERENCES entity(id)
+    ON DELETE CASCADE
+);
+
+-- +migrate Down
+
+DROP TABLE template;
@@ -0,0 +1,25 @@
+-- +migrate Up
+CREATE TABLE entity_search (
+  entity_id BIGINT PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  title VARCHAR(255),
+  description TEXT,
+  tags TEXT,
+  annotations TEXT,
+  document TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', name), 'A') ||
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(tags, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(annotations, '')), 'C')
+  ) STORED,
+  CONSTRAINT fk_entity
+    FOREIGN KEY (entity_id)
+    REFERENCES entity(id)
+    ON DELETE CASCADE
+);
+CREATE INDEX entity_search_document_idx ON entity_search USING GIN (document);
+
+-- +migrate Down
+
+DROP TABLE entity_search;
@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE entity ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+-- +migrate Down
+
+ALTER TABLE entity DROP COLUMN version;
@@ -0,0 +1,12 @@
+-- +migrate Up
+ALTER TABLE entity ADD COLUMN uid VARCHAR(36) NOT NULL DEFAULT gen_random_uuid()::text;
+ALTER TABLE entity ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE entity ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+CREATE UNIQUE INDEX entity_uid_idx ON entity (uid);
+
+-- +migrate Down
+
+DROP INDEX entity_uid_idx;
+ALTER TABLE entity DROP COLUMN updated_at;
+ALTER TABLE entity DROP COLUMN created_at;
+ALTER TABLE entity DROP COLUMN uid;
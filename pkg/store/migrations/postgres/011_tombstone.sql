@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE entity ADD COLUMN deleted_at TIMESTAMPTZ;
+DROP INDEX entity_ref_idx;
+CREATE UNIQUE INDEX entity_ref_idx ON entity (kind, namespace, name) WHERE deleted_at IS NULL;
+CREATE INDEX entity_deleted_at_idx ON entity (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +migrate Down
+
+DELETE FROM entity WHERE deleted_at IS NOT NULL;
+DROP INDEX entity_deleted_at_idx;
+DROP INDEX entity_ref_idx;
+CREATE UNIQUE INDEX entity_ref_idx ON entity (kind, namespace, name);
+ALTER TABLE entity DROP COLUMN deleted_at;
@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE system (
+  id INTEGER PRIMARY KEY,
+  entity_id INTEGER NOT NULL,
+  owner VARCHAR(512) NOT NULL,
+  domain VARCHAR(512),
+  type VARCHAR(255),
+  CONSTRAINT fk_entity
+    FOREIGN KEY (entity_id)
+    REFERENCES entity(id)
+    ON DELETE CASCADE
+);
+
+-- +migrate Down
+
+DROP TABLE system;
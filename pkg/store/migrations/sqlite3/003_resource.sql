@@ -0,0 +1,18 @@
+-- +migrate Up
+CREATE TABLE resource (
+  id INTEGER PRIMARY KEY,
+  entity_id INTEGER NOT NULL,
+  type VARCHAR(255) NOT NULL,
+  owner VARCHAR(512) NOT NULL,
+  system VARCHAR(512),
+  depends_on TEXT,
+  dependency_of TEXT,
+  CONSTRAINT fk_entity
+    FOREIGN KEY (entity_id)
+    REFERENCES entity(id)
+    ON DELETE CASCADE
+);
+
+-- +migrate Down
+
+DROP TABLE resource;
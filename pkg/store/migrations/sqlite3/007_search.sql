@@ -0,0 +1,8 @@
+-- +migrate Up
+-- The SQLite search index lives in an FTS5 table, which is optional at build
+-- time, so it is created when the store is opened rather than here. This
+-- migration keeps the numbering in step with the PostgreSQL migrations.
+SELECT 1;
+
+-- +migrate Down
+SELECT 1;
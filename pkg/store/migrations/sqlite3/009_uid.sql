@@ -0,0 +1,17 @@
+-- +migrate Up
+ALTER TABLE entity ADD COLUMN uid VARCHAR(36);
+ALTER TABLE entity ADD COLUMN created_at DATETIME;
+ALTER TABLE entity ADD COLUMN updated_at DATETIME;
+UPDATE entity SET
+  uid = lower(hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' || substr(hex(randomblob(2)), 2) || '-' ||
+    substr('89ab', 1 + (abs(random()) % 4), 1) || substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6))),
+  created_at = CURRENT_TIMESTAMP,
+  updated_at = CURRENT_TIMESTAMP;
+CREATE UNIQUE INDEX entity_uid_idx ON entity (uid);
+
+-- +migrate Down
+
+DROP INDEX entity_uid_idx;
+ALTER TABLE entity DROP COLUMN updated_at;
+ALTER TABLE entity DROP COLUMN created_at;
+ALTER TABLE entity DROP COLUMN uid;
@@ -0,0 +1,22 @@
+-- +migrate Up
+CREATE TABLE entity_history (
+  id INTEGER PRIMARY KEY,
+  entity_uid VARCHAR(36) NOT NULL,
+  kind VARCHAR(50) NOT NULL,
+  namespace VARCHAR(255) NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  version INTEGER NOT NULL,
+  operation VARCHAR(10) NOT NULL,
+  actor VARCHAR(255),
+  changed_at DATETIME NOT NULL,
+  previous_yaml TEXT,
+  current_yaml TEXT
+);
+CREATE INDEX entity_history_uid_idx ON entity_history (entity_uid);
+CREATE INDEX entity_history_ref_idx ON entity_history (kind, namespace, name);
+
+-- +migrate Down
+
+DROP INDEX entity_history_ref_idx;
+DROP INDEX entity_history_uid_idx;
+DROP TABLE entity_history;
@@ -0,0 +1,20 @@
+-- +migrate Up
+CREATE TABLE relation (
+  id INTEGER PRIMARY KEY,
+  entity_id INTEGER NOT NULL,
+  type VARCHAR(50) NOT NULL,
+  field VARCHAR(50) NOT NULL,
+  target_ref VARCHAR(512) NOT NULL,
+  CONSTRAINT fk_entity
+    FOREIGN KEY (entity_id)
+    REFERENCES entity(id)
+    ON DELETE CASCADE
+);
+CREATE INDEX relation_entity_idx ON relation (entity_id);
+CREATE INDEX relation_target_idx ON relation (target_ref);
+
+-- +migrate Down
+
+DROP INDEX relation_target_idx;
+DROP INDEX relation_entity_idx;
+DROP TABLE relation;
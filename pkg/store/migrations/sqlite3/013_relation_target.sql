@@ -0,0 +1,13 @@
+-- +migrate Up
+-- The relations are refilled from the entities when the store is opened, so
+-- that every row has the parts of its target ref.
+DELETE FROM relation;
+ALTER TABLE relation ADD COLUMN target_kind VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE relation ADD COLUMN target_namespace VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE relation ADD COLUMN target_name VARCHAR(255) NOT NULL DEFAULT '';
+
+-- +migrate Down
+
+ALTER TABLE relation DROP COLUMN target_name;
+ALTER TABLE relation DROP COLUMN target_namespace;
+ALTER TABLE relation DROP COLUMN target_kind;
@@ -0,0 +1,806 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	entityInsertStatement  = `INSERT INTO entity (uid, apiVersion, kind, namespace, name, title, description, tags, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, version`
+	entityIDStatement      = `SELECT id FROM entity WHERE kind = ? AND namespace = ? AND name = ? AND deleted_at IS NULL`
+	entityVersionStatement = `SELECT version FROM entity WHERE id = ? AND kind = ? AND deleted_at IS NULL`
+	entityCreatedStatement = `SELECT uid, created_at FROM entity WHERE id = ?`
+	entityUIDStatement     = `SELECT kind, namespace, name FROM entity WHERE uid = ? AND deleted_at IS NULL`
+	entityReadStatement    = `SELECT id, version, uid, apiVersion, kind, namespace, name, title, description, tags, created_at, updated_at FROM entity WHERE kind = ? AND namespace = ? AND name = ? AND deleted_at IS NULL`
+	entityUpdateStatement  = `UPDATE entity SET (apiVersion, kind, namespace, name, title, description, tags, updated_at) = (?, ?, ?, ?, ?, ?, ?, ?), version = version + 1 WHERE id = ? AND kind = ? AND deleted_at IS NULL`
+
+	labelInsertStatement      = `INSERT INTO label (entity_id, k, v) VALUES (?, ?, ?)`
+	labelSelectStatement      = `SELECT k, v FROM label WHERE entity_id = ?`
+	labelUpdateStatement      = `UPDATE label SET v = ? WHERE entity_id = ? AND k = ?`
+	labelDeleteStatement      = `DELETE FROM label WHERE entity_id = ? AND k = ?`
+	annotationInsertStatement = `INSERT INTO annotation (entity_id, k, v) VALUES (?, ?, ?)`
+	annotationSelectStatement = `SELECT k, v FROM annotation WHERE entity_id = ?`
+	annotationUpdateStatement = `UPDATE annotation SET v = ? WHERE entity_id = ? AND k = ?`
+	annotationDeleteStatement = `DELETE FROM annotation WHERE entity_id = ? AND k = ?`
+	linkInsertStatement       = `INSERT INTO link (entity_id, idx, url, title, icon, type) VALUES (?, ?, ?, ?, ?, ?)`
+	linkSelectStatement       = `SELECT url, title, icon, type FROM link WHERE entity_id = ? ORDER BY idx`
+	linkUpdateStatement       = `UPDATE link SET (idx, title, icon, type) = (?, ?, ?, ?) WHERE entity_id = ? and url = ?`
+	linkDeleteStatement       = `DELETE FROM link WHERE entity_id = ? and url = ?`
+
+	entityDeleteStatement    = `UPDATE entity SET deleted_at = ? WHERE id = ? AND kind = ? AND deleted_at IS NULL`
+	entityRestoreStatement   = `UPDATE entity SET deleted_at = NULL, version = version + 1, updated_at = ? WHERE id = ?`
+	entityTombstoneStatement = `SELECT id FROM entity WHERE kind = ? AND namespace = ? AND name = ? AND deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC LIMIT 1`
+	entityPurgeStatement     = `DELETE FROM entity WHERE deleted_at IS NOT NULL AND deleted_at < ?`
+
+	entityDeletedListStatementPrefix = `SELECT DISTINCT kind, namespace, name FROM entity WHERE deleted_at IS NOT NULL`
+
+	// entityVersionCondition is added to the WHERE clause of an update or
+	// delete to make it conditional on the entity's version.
+	entityVersionCondition = ` AND version = ?`
+
+	entityListStatementPrefix = `SELECT entity.kind, entity.namespace, entity.name FROM entity`
+)
+
+// tagsSeparator joins tags in the entity table when they are stored as text.
+const tagsSeparator = ","
+
+func createEntity(ctx context.Context, d dialect, e model.Entity, tx *sqlx.Tx) (model.Entity, error) {
+	uid, err := newUID()
+	if err != nil {
+		return model.Entity{}, err
+	}
+	now := storeTime()
+
+	var id int64
+	var version int64
+	err = tx.QueryRowxContext(
+		ctx,
+		tx.Rebind(entityInsertStatement),
+		uid,
+		e.APIVersion,
+		e.Kind,
+		e.Metadata.Namespace,
+		e.Metadata.Name,
+		nullString(e.Metadata.Title),
+		nullString(e.Metadata.Description),
+		d.listValue(e.Metadata.Tags, tagsSeparator),
+		now,
+		now,
+	).Scan(&id, &version)
+	if err != nil {
+		if d.isUniqueViolation(err) {
+			return model.Entity{}, fmt.Errorf("%s %w", e.EntityRef(), ErrAlreadyExists)
+		}
+		return model.Entity{}, fmt.Errorf("failed to create entity: %w", err)
+	}
+
+	re := e
+	re.ID = id
+	re.Version = version
+	re.Metadata.UID = uid
+	re.Metadata.Etag = versionEtag(version)
+	re.Metadata.CreatedAt = now
+	re.Metadata.UpdatedAt = now
+	re.Relations = nil
+
+	for labelKey, labelValue := range e.Metadata.Labels {
+		_, err := tx.ExecContext(
+			ctx,
+			tx.Rebind(labelInsertStatement),
+			id,
+			labelKey,
+			labelValue,
+		)
+		if err != nil {
+			return model.Entity{}, fmt.Errorf("failed to create label: %w", err)
+		}
+	}
+	for annotationKey, annotationValue := range e.Metadata.Annotations {
+		_, err := tx.ExecContext(
+			ctx,
+			tx.Rebind(annotationInsertStatement),
+			id,
+			annotationKey,
+			annotationValue,
+		)
+		if err != nil {
+			return model.Entity{}, fmt.Errorf("failed to create annotation: %w", err)
+		}
+	}
+	for i, link := range e.Metadata.Links {
+		_, err := tx.ExecContext(
+			ctx,
+			tx.Rebind(linkInsertStatement),
+			id,
+			i,
+			link.URL,
+			nullString(link.Title),
+			nullString(link.Icon),
+			nullString(link.Type),
+		)
+		if err != nil {
+			return model.Entity{}, fmt.Errorf("failed to create link: %w", err)
+		}
+	}
+
+	if err := d.indexEntity(ctx, re, tx); err != nil {
+		return model.Entity{}, err
+	}
+
+	return re, nil
+}
+
+// getEntityID finds the ID of the entity with a ref.
+func getEntityID(ctx context.Context, ref model.EntityRef, tx *sqlx.Tx) (int64, error) {
+	var id int64
+	err := tx.QueryRowxContext(ctx, tx.Rebind(entityIDStatement), ref.Kind, ref.Namespace, ref.Name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%s %w", ref, ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query for entity ID: %w", err)
+	}
+	return id, nil
+}
+
+func readEntity(ctx context.Context, d dialect, ref model.EntityRef, tx *sqlx.Tx) (model.Entity, error) {
+	rows, err := tx.QueryxContext(ctx, tx.Rebind(entityReadStatement), ref.Kind, ref.Namespace, ref.Name)
+	if err != nil {
+		return model.Entity{}, fmt.Errorf("failed to query for entity: %w", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return model.Entity{}, fmt.Errorf("%s %w", ref, ErrNotFound)
+	}
+	var id int64
+	var version int64
+	var uid sql.NullString
+	var apiVersion string
+	var kind string
+	var namespace string
+	var name string
+	var title sql.NullString
+	var description sql.NullString
+	tags := d.newList(tagsSeparator)
+	var createdAt sql.NullTime
+	var updatedAt sql.NullTime
+	err = rows.Scan(&id, &version, &uid, &apiVersion, &kind, &namespace, &name, &title, &description, tags, &createdAt, &updatedAt)
+	if err != nil {
+		return model.Entity{}, fmt.Errorf("failed to scan columns for entity: %w", err)
+	}
+	rows.Close()
+
+	e := model.Entity{
+		ID:         id,
+		Version:    version,
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Metadata: model.Metadata{
+			UID:         fromNullString(uid),
+			Etag:        versionEtag(version),
+			Name:        name,
+			Namespace:   namespace,
+			Title:       fromNullString(title),
+			Description: fromNullString(description),
+			CreatedAt:   fromNullTime(createdAt),
+			UpdatedAt:   fromNullTime(updatedAt),
+		},
+	}
+	if items := tags.Items(); len(items) > 0 {
+		e.Metadata.Tags = items
+	}
+
+	e.Metadata.Labels, err = readLabels(ctx, id, tx)
+	if err != nil {
+		return model.Entity{}, err
+	}
+	e.Metadata.Annotations, err = readAnnotations(ctx, id, tx)
+	if err != nil {
+		return model.Entity{}, err
+	}
+	e.Metadata.Links, err = readLinks(ctx, id, tx)
+	if err != nil {
+		return model.Entity{}, err
+	}
+
+	return e, nil
+}
+
+func readLabels(ctx context.Context, id int64, tx *sqlx.Tx) (map[string]string, error) {
+	lrows, err := tx.QueryxContext(ctx, tx.Rebind(labelSelectStatement), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query for labels: %w", err)
+	}
+	defer lrows.Close()
+
+	labels := make(map[string]string)
+	for lrows.Next() {
+		var k string
+		var v string
+		err = lrows.Scan(&k, &v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan columns for label: %w", err)
+		}
+		labels[k] = v
+	}
+
+	return labels, nil
+}
+
+func readAnnotations(ctx context.Context, id int64, tx *sqlx.Tx) (map[string]string, error) {
+	arows, err := tx.QueryxContext(ctx, tx.Rebind(annotationSelectStatement), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query for annotations: %w", err)
+	}
+	defer arows.Close()
+
+	annotations := make(map[string]string)
+	for arows.Next() {
+		var k string
+		var v string
+		err = arows.Scan(&k, &v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan columns for annotation: %w", err)
+		}
+		annotations[k] = v
+	}
+
+	return annotations, nil
+}
+
+func readLinks(ctx context.Context, id int64, tx *sqlx.Tx) ([]model.Link, error) {
+	krows, err := tx.QueryxContext(ctx, tx.Rebind(linkSelectStatement), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query for links: %w", err)
+	}
+	defer krows.Close()
+
+	links := make([]model.Link, 0)
+	for krows.Next() {
+		var url string
+		var title sql.NullString
+		var icon sql.NullString
+		var linkType sql.NullString
+		err = krows.Scan(&url, &title, &icon, &linkType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan columns for link: %w", err)
+		}
+		links = append(links, model.Link{
+			URL:   url,
+			Title: fromNullString(title),
+			Icon:  fromNullString(icon),
+			Type:  fromNullString(linkType),
+		})
+	}
+
+	return links, nil
+}
+
+// updateEntity updates an entity by its ID, or by its ref if it has no ID.
+// An entity with an ID may be given a new ref. If the entity has a version,
+// it is only updated if the stored entity is at that version.
+func updateEntity(ctx context.Context, d dialect, e model.Entity, tx *sqlx.Tx) (model.Entity, error) {
+	if e.ID == 0 {
+		id, err := getEntityID(ctx, e.EntityRef(), tx)
+		if err != nil {
+			return model.Entity{}, err
+		}
+		e.ID = id
+	}
+	now := storeTime()
+
+	statement := entityUpdateStatement
+	parameters := []any{
+		e.APIVersion,
+		e.Kind,
+		e.Metadata.Namespace,
+		e.Metadata.Name,
+		nullString(e.Metadata.Title),
+		nullString(e.Metadata.Description),
+		d.listValue(e.Metadata.Tags, tagsSeparator),
+		now,
+		e.ID,
+		e.Kind,
+	}
+	if e.Version != 0 {
+		statement += entityVersionCondition
+		parameters = append(parameters, e.Version)
+	}
+	var version int64
+	err := tx.QueryRowxContext(ctx, tx.Rebind(statement+" RETURNING version"), parameters...).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Entity{}, unmatchedEntityError(ctx, e, tx)
+	}
+	if err != nil {
+		if d.isUniqueViolation(err) {
+			return model.Entity{}, fmt.Errorf("%w: %s is already taken by another entity", ErrConflict, e.EntityRef())
+		}
+		return model.Entity{}, fmt.Errorf("failed to update entity: %w", err)
+	}
+
+	var uid sql.NullString
+	var createdAt sql.NullTime
+	err = tx.QueryRowxContext(ctx, tx.Rebind(entityCreatedStatement), e.ID).Scan(&uid, &createdAt)
+	if err != nil {
+		return model.Entity{}, fmt.Errorf("failed to query for entity UID: %w", err)
+	}
+
+	re := e
+	re.Version = version
+	re.Metadata.UID = fromNullString(uid)
+	re.Metadata.Etag = versionEtag(version)
+	re.Metadata.CreatedAt = fromNullTime(createdAt)
+	re.Metadata.UpdatedAt = now
+	re.Relations = nil
+
+	currentLabels, err := readLabels(ctx, e.ID, tx)
+	if err != nil {
+		return model.Entity{}, fmt.Errorf("failed to read labels for updating entity: %w", err)
+	}
+	for labelKey, labelValue := range e.Metadata.Labels {
+		_, exists := currentLabels[labelKey]
+		if !exists {
+			_, err := tx.ExecContext(
+				ctx,
+				tx.Rebind(labelInsertStatement),
+				e.ID,
+				labelKey,
+				labelValue,
+			)
+			if err != nil {
+				return model.Entity{}, fmt.Errorf("failed to create label: %w", err)
+			}
+		} else {
+			_, err := tx.ExecContext(
+				ctx,
+				tx.Rebind(labelUpdateStatement),
+				labelValue,
+				e.ID,
+				labelKey,
+			)
+			if err != nil {
+				return model.Entity{}, fmt.Errorf("failed to update label: %w", err)
+			}
+		}
+	}
+	for labelKey := range currentLabels {
+		_, remains := e.Metadata.Labels[labelKey]
+		if !remains {
+			_, err := tx.ExecContext(
+				ctx,
+				tx.Rebind(labelDeleteStatement),
+				e.ID,
+				labelKey,
+			)
+			if err != nil {
+				return model.Entity{}, fmt.Errorf("failed to delete label: %w", err)
+			}
+		}
+	}
+
+	currentAnnotations, err := readAnnotations(ctx, e.ID, tx)
+	if err != nil {
+		return model.Entity{}, fmt.Errorf("failed to read annotations for updating entity: %w", err)
+	}
+	for annotationKey, annotationValue := range e.Metadata.Annotations {
+		_, exists := currentAnnotations[annotationKey]
+		if !exists {
+			_, err := tx.ExecContext(
+				ctx,
+				tx.Rebind(annotationInsertStatement),
+				e.ID,
+				annotationKey,
+				annotationValue,
+			)
+			if err != nil {
+				return model.Entity{}, fmt.Errorf("failed to create annotation: %w", err)
+			}
+		} else {
+			_, err := tx.ExecContext(
+				ctx,
+				tx.Rebind(annotationUpdateStatement),
+				annotationValue,
+				e.ID,
+				annotationKey,
+			)
+			if err != nil {
+				return model.Entity{}, fmt.Errorf("failed to update annotation: %w", err)
+			}
+		}
+	}
+	for annotationKey := range currentAnnotations {
+		_, remains := e.Metadata.Annotations[annotationKey]
+		if !remains {
+			_, err := tx.ExecContext(
+				ctx,
+				tx.Rebind(annotationDeleteStatement),
+				e.ID,
+				annotationKey,
+			)
+			if err != nil {
+				return model.Entity{}, fmt.Errorf("failed to delete annotation: %w", err)
+			}
+		}
+	}
+
+	currentLinks, err := readLinks(ctx, e.ID, tx)
+	if err != nil {
+		return model.Entity{}, fmt.Errorf("failed to read links for updating entity: %w", err)
+	}
+	currentLinkMap := make(map[string]model.Link, len(currentLinks))
+	for _, link := range currentLinks {
+		currentLinkMap[link.URL] = link
+	}
+
+	entityLinkURLs := make([]string, 0, len(e.Metadata.Links))
+	for i, link := range e.Metadata.Links {
+		_, exists := currentLinkMap[link.URL]
+		if !exists {
+			_, err := tx.ExecContext(
+				ctx,
+				tx.Rebind(linkInsertStatement),
+				e.ID,
+				i,
+				link.URL,
+				nullString(link.Title),
+				nullString(link.Icon),
+				nullString(link.Type),
+			)
+			if err != nil {
+				return model.Entity{}, fmt.Errorf("failed to create link: %w", err)
+			}
+		} else {
+			_, err := tx.ExecContext(
+				ctx,
+				tx.Rebind(linkUpdateStatement),
+				i,
+				nullString(link.Title),
+				nullString(link.Icon),
+				nullString(link.Type),
+				e.ID,
+				link.URL,
+			)
+			if err != nil {
+				return model.Entity{}, fmt.Errorf("failed to update link: %w", err)
+			}
+		}
+		entityLinkURLs = append(entityLinkURLs, link.URL)
+	}
+	for linkURL := range currentLinkMap {
+		if !slices.Contains(entityLinkURLs, linkURL) {
+			_, err := tx.ExecContext(
+				ctx,
+				tx.Rebind(linkDeleteStatement),
+				e.ID,
+				linkURL,
+			)
+			if err != nil {
+				return model.Entity{}, fmt.Errorf("failed to delete link: %w", err)
+			}
+		}
+	}
+
+	if err := d.indexEntity(ctx, re, tx); err != nil {
+		return model.Entity{}, err
+	}
+
+	return re, nil
+}
+
+// unmatchedEntityError explains why a statement for an entity, conditioned on
+// its ID, kind and version, matched no rows: either the entity is gone, or it
+// is at another version.
+func unmatchedEntityError(ctx context.Context, e model.Entity, tx *sqlx.Tx) error {
+	var current int64
+	err := tx.QueryRowxContext(ctx, tx.Rebind(entityVersionStatement), e.ID, e.Kind).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s with ID %d %w", e.Kind, e.ID, ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query for entity version: %w", err)
+	}
+	return fmt.Errorf("%w: %s is at version %d, not %d", ErrVersionMismatch, e.EntityRef(), current, e.Version)
+}
+
+// resolveUID finds the ref of the entity with a UID.
+func resolveUID(ctx context.Context, db *sqlx.DB, uid string) (model.EntityRef, error) {
+	var ref model.EntityRef
+	err := db.QueryRowxContext(ctx, db.Rebind(entityUIDStatement), uid).Scan(&ref.Kind, &ref.Namespace, &ref.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.EntityRef{}, fmt.Errorf("entity with UID %s %w", uid, ErrNotFound)
+	}
+	if err != nil {
+		return model.EntityRef{}, fmt.Errorf("failed to query for entity with UID: %w", err)
+	}
+	return ref, nil
+}
+
+// deleteEntity deletes an entity by its ID, leaving a tombstone. If version is
+// not zero, the entity is only deleted if it is at that version.
+func deleteEntity(ctx context.Context, d dialect, e model.Entity, version int64, db *sqlx.DB) (err error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for delete: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	statement := entityDeleteStatement
+	parameters := []any{storeTime(), e.ID, e.Kind}
+	if version != 0 {
+		statement += entityVersionCondition
+		parameters = append(parameters, version)
+	}
+	result, err := tx.ExecContext(ctx, tx.Rebind(statement), parameters...)
+	if err != nil {
+		return fmt.Errorf("failed to delete entity: %w", err)
+	}
+	if n, rerr := result.RowsAffected(); rerr != nil {
+		return fmt.Errorf("failed to delete entity: %w", rerr)
+	} else if n == 0 {
+		e.Version = version
+		return unmatchedEntityError(ctx, e, tx)
+	}
+	if err = d.unindexEntity(ctx, e.ID, tx); err != nil {
+		return err
+	}
+	if err = deleteRelations(ctx, tx, e.ID); err != nil {
+		return err
+	}
+	if err = recordRevision(ctx, tx, model.OperationDelete, e, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// restoreEntity restores the entity most recently deleted with a ref, as a new
+// version, and returns it.
+func restoreEntity(ctx context.Context, d dialect, ref model.EntityRef, tx *sqlx.Tx) (model.Entity, error) {
+	var id int64
+	err := tx.QueryRowxContext(ctx, tx.Rebind(entityTombstoneStatement), ref.Kind, ref.Namespace, ref.Name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Entity{}, fmt.Errorf("deleted %s %w", ref, ErrNotFound)
+	}
+	if err != nil {
+		return model.Entity{}, fmt.Errorf("failed to query for deleted entity: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, tx.Rebind(entityRestoreStatement), storeTime(), id)
+	if err != nil {
+		if d.isUniqueViolation(err) {
+			return model.Entity{}, fmt.Errorf("%w: %s is already taken by another entity", ErrConflict, ref)
+		}
+		return model.Entity{}, fmt.Errorf("failed to restore entity: %w", err)
+	}
+
+	e, err := readEntity(ctx, d, ref, tx)
+	if err != nil {
+		return model.Entity{}, err
+	}
+	if err := d.indexEntity(ctx, e, tx); err != nil {
+		return model.Entity{}, err
+	}
+	return e, nil
+}
+
+// listDeletedEntities lists the refs that deleted entities of the given kinds,
+// or of all kinds if none are given, had. Each ref is listed once, however
+// many entities were deleted with it.
+func listDeletedEntities(ctx context.Context, db *sqlx.DB, kinds []string, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	statement := entityDeletedListStatementPrefix
+	parameters := []any{}
+	if len(kinds) > 0 {
+		statement += fmt.Sprintf(" AND kind IN (%s)", placeholders(len(kinds)))
+		for _, kind := range kinds {
+			parameters = append(parameters, kind)
+		}
+	}
+	statement += " ORDER BY kind, namespace, name" + limitClause(pagination)
+
+	rows, err := db.QueryxContext(ctx, db.Rebind(statement), parameters...)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to list deleted entities: %w", err)
+	}
+	defer rows.Close()
+	results := []model.EntityRef{}
+	for rows.Next() {
+		var ref model.EntityRef
+		if err := rows.Scan(&ref.Kind, &ref.Namespace, &ref.Name); err != nil {
+			return nil, Pagination{}, fmt.Errorf("failed to scan columns for entity: %w", err)
+		}
+		results = append(results, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to list deleted entities: %w", err)
+	}
+
+	return results, Pagination{
+		Limit:  pagination.Limit,
+		Offset: pagination.Offset + len(results),
+	}, nil
+}
+
+// purgeEntities removes the entities deleted before a time for good, along
+// with everything that cascades from them.
+func purgeEntities(ctx context.Context, db *sqlx.DB, before time.Time) (int64, error) {
+	result, err := db.ExecContext(ctx, db.Rebind(entityPurgeStatement), before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted entities: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted entities: %w", err)
+	}
+	return n, nil
+}
+
+// listEntities lists the refs of entities of the given kinds, or of all kinds
+// if none are given. The list statement prefix selects the kind, namespace and
+// name of each entity, and must not include a WHERE clause.
+func listEntities(ctx context.Context, d dialect, db *sqlx.DB, listStatementPrefix string, kinds []string, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	whereClauses := []string{"entity.deleted_at IS NULL"}
+	queryParameters := []any{}
+	if len(kinds) == 1 {
+		whereClauses = append(whereClauses, "entity.kind = ?")
+		queryParameters = append(queryParameters, kinds[0])
+	} else if len(kinds) > 1 {
+		whereClauses = append(whereClauses, fmt.Sprintf("entity.kind IN (%s)", placeholders(len(kinds))))
+		for _, kind := range kinds {
+			queryParameters = append(queryParameters, kind)
+		}
+	}
+	for _, filter := range filters {
+		clause, parameters, err := filterClause(d, kinds, filter)
+		if err != nil {
+			return nil, Pagination{}, err
+		}
+		whereClauses = append(whereClauses, clause)
+		queryParameters = append(queryParameters, parameters...)
+	}
+
+	listStatement := listStatementPrefix
+	if len(whereClauses) > 0 {
+		listStatement += " WHERE "
+		listStatement += strings.Join(whereClauses, " AND ")
+	}
+
+	if ordering.OrderBy != "" {
+		orderByClause := " ORDER BY " + string(ordering.OrderBy)
+		if ordering.Descending {
+			orderByClause += " DESC"
+		} else {
+			orderByClause += " ASC"
+		}
+		listStatement += orderByClause
+	}
+
+	listStatement += limitClause(pagination)
+
+	rows, err := db.QueryxContext(ctx, db.Rebind(listStatement), queryParameters...)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to list entities: %w", err)
+	}
+	defer rows.Close()
+	results := []model.EntityRef{}
+	nextOffset := pagination.Offset
+	for rows.Next() {
+		var kind string
+		var namespace string
+		var name string
+		err = rows.Scan(&kind, &namespace, &name)
+		if err != nil {
+			return nil, Pagination{}, fmt.Errorf("failed to scan columns for entity: %w", err)
+		}
+		results = append(results, model.EntityRef{
+			Kind:      kind,
+			Namespace: namespace,
+			Name:      name,
+		})
+		nextOffset++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	return results, Pagination{
+		Limit:  pagination.Limit,
+		Offset: nextOffset,
+	}, nil
+}
+
+// limitClause returns the LIMIT and OFFSET clause for a page, or an empty
+// string if there is no limit.
+func limitClause(pagination Pagination) string {
+	if pagination.Limit <= 0 {
+		return ""
+	}
+	clause := fmt.Sprintf(" LIMIT %d", pagination.Limit)
+	if pagination.Offset > 0 {
+		clause += fmt.Sprintf(" OFFSET %d", pagination.Offset)
+	}
+	return clause
+}
+
+// Search snippets come out of the search index with matches between these
+// control characters, which cannot be confused with the indexed text. They
+// are turned into <mark> tags by markSnippet.
+const (
+	snippetMarkStart = "\x02"
+	snippetMarkEnd   = "\x03"
+)
+
+// markSnippet escapes a search snippet as HTML text, and then replaces its
+// match markers with <mark> tags.
+func markSnippet(snippet string) string {
+	return snippetMarkReplacer.Replace(html.EscapeString(snippet))
+}
+
+var snippetMarkReplacer = strings.NewReplacer(snippetMarkStart, "<mark>", snippetMarkEnd, "</mark>")
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func nullString(s string) sql.NullString {
+	if s == "" {
+		return sql.NullString{
+			Valid: false,
+		}
+	}
+	return sql.NullString{
+		String: s,
+		Valid:  true,
+	}
+}
+func fromNullString(ns sql.NullString) string {
+	if ns.Valid {
+		return ns.String
+	}
+	return ""
+}
+
+// fromNullTime converts a nullable timestamp column to UTC. NULL becomes the
+// zero time.
+func fromNullTime(nt sql.NullTime) time.Time {
+	if nt.Valid {
+		return nt.Time.UTC()
+	}
+	return time.Time{}
+}
+
+// yamlString marshals a structured value to YAML for storage in a text
+// column. Empty values are stored as NULL.
+func yamlString(v any) (sql.NullString, error) {
+	if v == nil {
+		return nullString(""), nil
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return nullString(string(b)), nil
+}
+
+func fromYAMLString(ns sql.NullString, v any) error {
+	if !ns.Valid {
+		return nil
+	}
+	return yaml.Unmarshal([]byte(ns.String), v)
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// postgresMigrationLockID identifies the advisory lock that serializes
+// migrations across processes sharing a database.
+const postgresMigrationLockID = 0x72657769 // "rewi"
+
+type postgresStore struct {
+	sqlStore
+}
+
+var _ Store = postgresStore{}
+
+// NewPostgresStore opens a store on a PostgreSQL database, given a lib/pq
+// connection string or URL, and brings its schema up to date.
+func NewPostgresStore(connString string) (*postgresStore, error) {
+	db, err := sqlx.Open("postgres", connString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err = preparePostgresDatabase(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &postgresStore{
+		sqlStore: sqlStore{
+			db:      db,
+			dialect: postgresDialect{},
+		},
+	}, nil
+}
+
+// preparePostgresDatabase runs migrations while holding an advisory lock, so
+// that processes starting at the same time take turns.
+func preparePostgresDatabase(db *sqlx.DB) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", postgresMigrationLockID); err != nil {
+		return fmt.Errorf("failed to lock database for migrations: %w", err)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", postgresMigrationLockID)
+	}()
+
+	if err := runMigrations(db.DB, "postgres"); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	return sqlStore{db: db, dialect: postgresDialect{}}.fillRelations(ctx)
+}
+
+// postgresDialect stores lists as text arrays.
+type postgresDialect struct{}
+
+func (postgresDialect) listValue(items []string, sep string) any {
+	if len(items) == 0 {
+		return nil
+	}
+	return pq.StringArray(items)
+}
+
+func (postgresDialect) newList(sep string) listColumn {
+	return &postgresList{}
+}
+
+func (postgresDialect) multiValueFilterClause(field filterField, filter Filter) (string, []any, error) {
+	column := field.column
+	switch filter.Operator {
+	case FilterEquals:
+		return fmt.Sprintf("? = ANY(%s)", column), []any{filter.Value}, nil
+	case FilterNotEquals:
+		return fmt.Sprintf("(%s IS NULL OR NOT ? = ANY(%s))", column, column), []any{filter.Value}, nil
+	case FilterIn:
+		return fmt.Sprintf("%s && ?::text[]", column), []any{pq.StringArray(filter.Values)}, nil
+	case FilterNotIn:
+		return fmt.Sprintf("(%s IS NULL OR NOT %s && ?::text[])", column, column), []any{pq.StringArray(filter.Values)}, nil
+	case FilterExists:
+		return fmt.Sprintf("%s IS NOT NULL", column), nil, nil
+	case FilterNotExists:
+		return fmt.Sprintf("%s IS NULL", column), nil, nil
+	case FilterPrefix:
+		return fmt.Sprintf(`EXISTS (SELECT 1 FROM unnest(%s) AS item WHERE item LIKE ? ESCAPE '\')`, column), []any{escapeLike(filter.Value) + "%"}, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported filter operator %s", filter.Operator)
+	}
+}
+
+// uniqueViolation is the SQLSTATE for a unique_violation.
+const uniqueViolation = pq.ErrorCode("23505")
+
+func (postgresDialect) isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+type postgresList struct {
+	items pq.StringArray
+}
+
+func (l *postgresList) Scan(src any) error {
+	l.items = nil
+	return l.items.Scan(src)
+}
+
+func (l *postgresList) Items() []string {
+	if len(l.items) == 0 {
+		return nil
+	}
+	return l.items
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// The search index is the entity_search table, whose document column is a
+// weighted text search vector generated from the other columns. Rows are
+// removed along with their entities by a cascading delete.
+var (
+	postgresSearchIndexUpsertStatement = `INSERT INTO entity_search (entity_id, name, title, description, tags, annotations) VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (entity_id) DO UPDATE SET (name, title, description, tags, annotations) = (EXCLUDED.name, EXCLUDED.title, EXCLUDED.description, EXCLUDED.tags, EXCLUDED.annotations)`
+	postgresSearchIndexDeleteStatement = `DELETE FROM entity_search WHERE entity_id = ?`
+
+	postgresSearchStatement = `SELECT entity.kind, entity.namespace, entity.name,
+  ts_headline('simple', concat_ws(' ... ', entity_search.name, entity_search.title, entity_search.description, entity_search.tags, entity_search.annotations), query,
+    'StartSel="' || chr(2) || '", StopSel="' || chr(3) || '", MaxWords=16, MinWords=4, MaxFragments=1')
+FROM entity_search INNER JOIN entity ON entity.id = entity_search.entity_id, to_tsquery('simple', ?) AS query
+WHERE entity_search.document @@ query ORDER BY ts_rank(entity_search.document, query) DESC, entity.id`
+)
+
+func (postgresDialect) indexEntity(ctx context.Context, e model.Entity, tx *sqlx.Tx) error {
+	annotationValues := make([]string, 0, len(e.Metadata.Annotations))
+	for _, v := range e.Metadata.Annotations {
+		annotationValues = append(annotationValues, v)
+	}
+	_, err := tx.ExecContext(
+		ctx,
+		tx.Rebind(postgresSearchIndexUpsertStatement),
+		e.ID,
+		e.Metadata.Name,
+		nullString(e.Metadata.Title),
+		nullString(e.Metadata.Description),
+		nullString(strings.Join(e.Metadata.Tags, " ")),
+		nullString(strings.Join(annotationValues, " ")),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add entity to search index: %w", err)
+	}
+	return nil
+}
+
+func (postgresDialect) unindexEntity(ctx context.Context, id int64, tx *sqlx.Tx) error {
+	if _, err := tx.ExecContext(ctx, tx.Rebind(postgresSearchIndexDeleteStatement), id); err != nil {
+		return fmt.Errorf("failed to remove entity from search index: %w", err)
+	}
+	return nil
+}
+
+// searchEntities finds the entities that match a query, best matches first.
+// Each word of the query must match a word in the entity, or the start of
+// one.
+func (postgresDialect) searchEntities(ctx context.Context, db *sqlx.DB, query string, pagination Pagination) ([]SearchMatch, Pagination, error) {
+	tsQuery := postgresTSQuery(query)
+	if tsQuery == "" {
+		return nil, Pagination{}, fmt.Errorf("%w: empty search query", ErrInvalid)
+	}
+
+	statement := postgresSearchStatement + limitClause(pagination)
+
+	rows, err := db.QueryxContext(ctx, db.Rebind(statement), tsQuery)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to search entities: %w", err)
+	}
+	defer rows.Close()
+	results := []SearchMatch{}
+	nextOffset := pagination.Offset
+	for rows.Next() {
+		var match SearchMatch
+		err = rows.Scan(&match.EntityRef.Kind, &match.EntityRef.Namespace, &match.EntityRef.Name, &match.Snippet)
+		if err != nil {
+			return nil, Pagination{}, fmt.Errorf("failed to scan columns for search result: %w", err)
+		}
+		match.Snippet = markSnippet(match.Snippet)
+		results = append(results, match)
+		nextOffset++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to search entities: %w", err)
+	}
+
+	return results, Pagination{
+		Limit:  pagination.Limit,
+		Offset: nextOffset,
+	}, nil
+}
+
+// postgresTSQuery turns free text into a tsquery that matches each word as a
+// prefix. Words are quoted so that tsquery operators in them are taken
+// literally.
+func postgresTSQuery(query string) string {
+	words := strings.Fields(query)
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = "'" + strings.ReplaceAll(strings.ReplaceAll(word, `\`, `\\`), "'", "''") + "':*"
+	}
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,314 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// The relation table holds one row for each entity ref in the spec of a live
+// entity, with the ref qualified as by model.SpecRefs. The ref is kept both
+// whole and in parts, so that it can be matched against the entity ref index
+// as well as by prefix. Rows are replaced
+// whenever an entity is written, and removed when it is deleted. Relations to
+// an entity are found by its ref, so they follow the ref rather than the
+// entity that has it.
+var (
+	relationInsertStatement = `INSERT INTO relation (entity_id, type, field, target_ref, target_kind, target_namespace, target_name)
+VALUES (?, ?, ?, ?, ?, ?, ?)`
+	relationDeleteStatement   = `DELETE FROM relation WHERE entity_id = ?`
+	relationCountStatement    = `SELECT COUNT(*) FROM relation`
+	relationOutgoingStatement = `SELECT type, target_ref FROM relation WHERE entity_id = ?`
+	relationIncomingStatement = `SELECT relation.type, entity.kind, entity.namespace, entity.name FROM relation
+INNER JOIN entity ON entity.id = relation.entity_id
+WHERE relation.target_ref = ? AND entity.deleted_at IS NULL`
+	relationReferrersStatement = `SELECT entity.kind, entity.namespace, entity.name, relation.field FROM relation
+INNER JOIN entity ON entity.id = relation.entity_id
+WHERE relation.target_ref = ? AND entity.deleted_at IS NULL`
+	// A kind left out of a ref makes it dangle, since it cannot match.
+	relationDanglingStatement = `SELECT entity.kind, entity.namespace, entity.name, relation.field, relation.target_ref, owner.target_ref FROM relation
+INNER JOIN entity ON entity.id = relation.entity_id
+LEFT OUTER JOIN relation owner ON owner.entity_id = entity.id AND owner.field = 'spec.owner'
+WHERE entity.deleted_at IS NULL AND NOT EXISTS (
+	SELECT 1 FROM entity target
+	WHERE target.kind = relation.target_kind AND target.namespace = relation.target_namespace AND target.name = relation.target_name
+	AND target.deleted_at IS NULL
+)`
+	relationSpecRefsStatement = `SELECT entity.kind, entity.namespace, entity.name, relation.field, relation.type, relation.target_ref FROM relation
+INNER JOIN entity ON entity.id = relation.entity_id
+WHERE entity.deleted_at IS NULL`
+
+	entityLiveRefsStatement = `SELECT kind, namespace, name FROM entity WHERE deleted_at IS NULL ORDER BY id`
+	// Each ref is matched by the entity ref index.
+	entityExistingRefsStatement = `SELECT kind, namespace, name FROM entity WHERE deleted_at IS NULL AND (%s)`
+	entityExistingRefClause     = `(kind = ? AND namespace = ? AND name = ?)`
+)
+
+// existingRefsBatch is how many refs ListExistingRefs looks up in one query,
+// so that the query stays within the number of parameters that SQLite
+// allows.
+const existingRefsBatch = 250
+
+// writeRelations replaces the relations of an entity with those that its
+// spec makes; value is the whole entity.
+func writeRelations(ctx context.Context, tx *sqlx.Tx, e model.Entity, value any) error {
+	if err := deleteRelations(ctx, tx, e.ID); err != nil {
+		return err
+	}
+	for _, ref := range model.SpecRefs(value) {
+		_, err := tx.ExecContext(ctx, tx.Rebind(relationInsertStatement), e.ID, ref.Relation, ref.Field, ref.Ref,
+			ref.Ref.Kind, ref.Ref.Namespace, ref.Ref.Name)
+		if err != nil {
+			return fmt.Errorf("failed to create relation: %w", err)
+		}
+	}
+	return nil
+}
+
+func deleteRelations(ctx context.Context, tx *sqlx.Tx, id int64) error {
+	if _, err := tx.ExecContext(ctx, tx.Rebind(relationDeleteStatement), id); err != nil {
+		return fmt.Errorf("failed to delete relations: %w", err)
+	}
+	return nil
+}
+
+// listRelations lists the relations of the entity with a ref: those from its
+// own spec, and the inverses of those to it from other entities.
+func listRelations(ctx context.Context, tx *sqlx.Tx, ref model.EntityRef) ([]model.Relation, error) {
+	id, err := getEntityID(ctx, ref, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	relations := []model.Relation{}
+	rows, err := tx.QueryxContext(ctx, tx.Rebind(relationOutgoingStatement), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query for relations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var relation model.Relation
+		if err := rows.Scan(&relation.Type, &relation.TargetRef); err != nil {
+			return nil, fmt.Errorf("failed to scan columns for relation: %w", err)
+		}
+		relations = append(relations, relation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query for relations: %w", err)
+	}
+
+	rows, err = tx.QueryxContext(ctx, tx.Rebind(relationIncomingStatement), ref.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query for relations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var relationType string
+		var source model.EntityRef
+		if err := rows.Scan(&relationType, &source.Kind, &source.Namespace, &source.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan columns for relation: %w", err)
+		}
+		relations = append(relations, model.Relation{
+			Type:      model.InverseRelation(relationType),
+			TargetRef: source,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query for relations: %w", err)
+	}
+
+	return model.SortRelations(relations), nil
+}
+
+func (s sqlStore) ListRelations(ctx context.Context, ref model.EntityRef) (relations []model.Relation, err error) {
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction for read: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	relations, err = listRelations(ctx, tx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction for read: %w", err)
+	}
+	return relations, nil
+}
+
+// ListReferrers does not need the referred entity to exist, so that refs to
+// missing entities can be found too.
+func (s sqlStore) ListReferrers(ctx context.Context, ref model.EntityRef) ([]model.Referrer, error) {
+	rows, err := s.db.QueryxContext(ctx, s.db.Rebind(relationReferrersStatement), ref.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query for referrers: %w", err)
+	}
+	defer rows.Close()
+	referrers := []model.Referrer{}
+	for rows.Next() {
+		var referrer model.Referrer
+		err := rows.Scan(&referrer.EntityRef.Kind, &referrer.EntityRef.Namespace, &referrer.EntityRef.Name, &referrer.Field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan columns for referrer: %w", err)
+		}
+		referrers = append(referrers, referrer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query for referrers: %w", err)
+	}
+	return model.SortReferrers(referrers), nil
+}
+
+func (s sqlStore) ListDanglingRefs(ctx context.Context) ([]model.DanglingRef, error) {
+	rows, err := s.db.QueryxContext(ctx, relationDanglingStatement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query for dangling refs: %w", err)
+	}
+	defer rows.Close()
+	refs := []model.DanglingRef{}
+	for rows.Next() {
+		var ref model.DanglingRef
+		err := rows.Scan(&ref.EntityRef.Kind, &ref.EntityRef.Namespace, &ref.EntityRef.Name, &ref.Field, &ref.TargetRef, &ref.Owner)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan columns for dangling ref: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query for dangling refs: %w", err)
+	}
+	return model.SortDanglingRefs(refs), nil
+}
+
+func (s sqlStore) ListSpecRefs(ctx context.Context, fields []string) ([]model.EntitySpecRef, error) {
+	statement := relationSpecRefsStatement
+	if len(fields) > 0 {
+		statement += fmt.Sprintf(" AND relation.field IN (%s)", placeholders(len(fields)))
+	}
+	rows, err := s.db.QueryxContext(ctx, s.db.Rebind(statement), stringsToAny(fields)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query for spec refs: %w", err)
+	}
+	defer rows.Close()
+	refs := []model.EntitySpecRef{}
+	for rows.Next() {
+		var ref model.EntitySpecRef
+		err := rows.Scan(&ref.EntityRef.Kind, &ref.EntityRef.Namespace, &ref.EntityRef.Name, &ref.Field, &ref.Relation, &ref.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan columns for spec ref: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query for spec refs: %w", err)
+	}
+	return model.SortEntitySpecRefs(refs), nil
+}
+
+func (s sqlStore) ListExistingRefs(ctx context.Context, refs []model.EntityRef) ([]model.EntityRef, error) {
+	existing := []model.EntityRef{}
+	for batch := range slices.Chunk(refs, existingRefsBatch) {
+		clauses := make([]string, len(batch))
+		parameters := make([]any, 0, 3*len(batch))
+		for i, ref := range batch {
+			clauses[i] = entityExistingRefClause
+			parameters = append(parameters, ref.Kind, ref.Namespace, ref.Name)
+		}
+		statement := fmt.Sprintf(entityExistingRefsStatement, strings.Join(clauses, " OR "))
+		rows, err := s.db.QueryxContext(ctx, s.db.Rebind(statement), parameters...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query for entities: %w", err)
+		}
+		for rows.Next() {
+			var ref model.EntityRef
+			if err := rows.Scan(&ref.Kind, &ref.Namespace, &ref.Name); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan columns for entity: %w", err)
+			}
+			existing = append(existing, ref)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to query for entities: %w", err)
+		}
+	}
+	return model.SortEntityRefs(existing), nil
+}
+
+// fillRelations computes the relations of every live entity if there are
+// none yet, as when the relation table has just been created for a database
+// that already has entities.
+func (s sqlStore) fillRelations(ctx context.Context) (err error) {
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for relations: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	var n int
+	if err = tx.QueryRowxContext(ctx, relationCountStatement).Scan(&n); err != nil {
+		return fmt.Errorf("failed to count relations: %w", err)
+	}
+	if n > 0 {
+		return tx.Commit()
+	}
+
+	var refs []model.EntityRef
+	rows, err := tx.QueryxContext(ctx, entityLiveRefsStatement)
+	if err != nil {
+		return fmt.Errorf("failed to list entities for relations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ref model.EntityRef
+		if err = rows.Scan(&ref.Kind, &ref.Namespace, &ref.Name); err != nil {
+			return fmt.Errorf("failed to scan columns for entity: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to list entities for relations: %w", err)
+	}
+	for _, ref := range refs {
+		var entity model.Entity
+		entity, err = readEntity(ctx, s.dialect, ref, tx)
+		if err != nil {
+			return err
+		}
+		var value any
+		value, err = s.readKind(ctx, entity, tx)
+		if err != nil {
+			return err
+		}
+		if err = writeRelations(ctx, tx, entity, value); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction for relations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type sqliteStore struct {
+	sqlStore
+}
+
+var _ Store = sqliteStore{}
+
+// NewSqliteStore opens a store on a SQLite database, given its path or a
+// go-sqlite3 DSN, and brings its schema up to date. Processes opening the
+// same database file take turns running migrations.
+func NewSqliteStore(connString string) (*sqliteStore, error) {
+	db, err := sqlx.Open("sqlite3", sqliteDSN(connString))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database at %s: %w", connString, err)
+	}
+	if isInMemory(connString) {
+		// Each connection to an in-memory database has a database of its
+		// own, so the pool must never open a second one.
+		db.SetMaxOpenConns(1)
+	}
+
+	if err = prepareSqliteDatabase(db, sqliteFilePath(connString)); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &sqliteStore{
+		sqlStore: sqlStore{
+			db:      db,
+			dialect: sqliteDialect{},
+		},
+	}, nil
+}
+
+func prepareSqliteDatabase(db *sqlx.DB, path string) error {
+	if path != "" {
+		unlock, err := lockFile(path + ".lock")
+		if err != nil {
+			return fmt.Errorf("failed to lock database for migrations: %w", err)
+		}
+		defer unlock()
+	}
+
+	if err := runMigrations(db.DB, "sqlite3"); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	if err := createSearchIndex(db); err != nil {
+		return err
+	}
+	return sqlStore{db: db, dialect: sqliteDialect{}}.fillRelations(context.Background())
+}
+
+// sqliteDialect stores lists as text, joined with a separator that does not
+// occur in the items.
+type sqliteDialect struct{}
+
+func (sqliteDialect) listValue(items []string, sep string) any {
+	return nullString(strings.Join(items, sep))
+}
+
+func (sqliteDialect) newList(sep string) listColumn {
+	return &sqliteList{
+		sep: sep,
+	}
+}
+
+// multiValueFilterClause matches against each of the separator-joined values
+// in a column, by surrounding the column with separators and looking for a
+// separator-delimited value within it.
+func (sqliteDialect) multiValueFilterClause(field filterField, filter Filter) (string, []any, error) {
+	column := field.column
+	sep := field.separator
+	anyOf := func(values []string) (string, []any) {
+		clauses := make([]string, len(values))
+		parameters := []any{}
+		for i, value := range values {
+			clauses[i] = fmt.Sprintf(`(? || %s || ?) LIKE ? ESCAPE '\'`, column)
+			parameters = append(parameters, sep, sep, "%"+sep+escapeLike(value)+sep+"%")
+		}
+		return "(" + strings.Join(clauses, " OR ") + ")", parameters
+	}
+
+	switch filter.Operator {
+	case FilterEquals:
+		clause, parameters := anyOf([]string{filter.Value})
+		return clause, parameters, nil
+	case FilterNotEquals:
+		clause, parameters := anyOf([]string{filter.Value})
+		return fmt.Sprintf("(%s IS NULL OR NOT %s)", column, clause), parameters, nil
+	case FilterIn:
+		clause, parameters := anyOf(filter.Values)
+		return clause, parameters, nil
+	case FilterNotIn:
+		clause, parameters := anyOf(filter.Values)
+		return fmt.Sprintf("(%s IS NULL OR NOT %s)", column, clause), parameters, nil
+	case FilterExists:
+		return fmt.Sprintf("%s IS NOT NULL", column), nil, nil
+	case FilterNotExists:
+		return fmt.Sprintf("%s IS NULL", column), nil, nil
+	case FilterPrefix:
+		return fmt.Sprintf(`(? || %s) LIKE ? ESCAPE '\'`, column), []any{sep, "%" + sep + escapeLike(filter.Value) + "%"}, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported filter operator %s", filter.Operator)
+	}
+}
+
+type sqliteList struct {
+	sep   string
+	items []string
+}
+
+func (l *sqliteList) Scan(src any) error {
+	l.items = nil
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case string:
+		l.items = strings.Split(src, l.sep)
+	case []byte:
+		l.items = strings.Split(string(src), l.sep)
+	default:
+		return fmt.Errorf("found non-string value %T for list", src)
+	}
+	return nil
+}
+
+func (l *sqliteList) Items() []string {
+	return l.items
+}
@@ -0,0 +1,9 @@
+//go:build !cgo
+
+package store
+
+// isUniqueViolation never finds a violation when go-sqlite3 is built without
+// cgo, since no SQLite database can be opened then.
+func (sqliteDialect) isUniqueViolation(err error) bool {
+	return false
+}
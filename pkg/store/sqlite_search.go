@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// The search index is an FTS5 table whose rowids are entity IDs. FTS5 is
+// only compiled into go-sqlite3 with the sqlite_fts5 build tag, so the table
+// is created when the store is opened rather than by a migration, and search
+// is disabled when the module is missing.
+var (
+	searchIndexCreateStatement = `CREATE VIRTUAL TABLE IF NOT EXISTS entity_search USING fts5(name, title, description, tags, annotations)`
+	searchIndexExistsStatement = `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'entity_search'`
+	searchIndexClearStatement  = `DELETE FROM entity_search`
+	searchIndexFillStatement   = `INSERT INTO entity_search (rowid, name, title, description, tags, annotations)
+SELECT entity.id, entity.name, entity.title, entity.description, REPLACE(entity.tags, ',', ' '),
+  (SELECT GROUP_CONCAT(annotation.v, ' ') FROM annotation WHERE annotation.entity_id = entity.id)
+FROM entity WHERE entity.deleted_at IS NULL`
+	searchIndexInsertStatement = `INSERT INTO entity_search (rowid, name, title, description, tags, annotations) VALUES (?, ?, ?, ?, ?, ?)`
+	searchIndexDeleteStatement = `DELETE FROM entity_search WHERE rowid = ?`
+
+	searchStatement = `SELECT entity.kind, entity.namespace, entity.name, snippet(entity_search, -1, char(2), char(3), '...', 16)
+FROM entity_search INNER JOIN entity ON entity.id = entity_search.rowid
+WHERE entity_search MATCH ? ORDER BY rank`
+)
+
+// createSearchIndex creates the search index if FTS5 is available, and
+// rebuilds its contents from the entity tables, which are authoritative.
+func createSearchIndex(db *sqlx.DB) error {
+	if _, err := db.Exec(searchIndexCreateStatement); err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			slog.Warn("full-text search is disabled; build with the sqlite_fts5 tag to enable it")
+			return nil
+		}
+		return fmt.Errorf("failed to create search index: %w", err)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for search index: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(searchIndexClearStatement); err != nil {
+		return fmt.Errorf("failed to clear search index: %w", err)
+	}
+	if _, err := tx.Exec(searchIndexFillStatement); err != nil {
+		return fmt.Errorf("failed to fill search index: %w", err)
+	}
+	return tx.Commit()
+}
+
+func searchIndexExists(ctx context.Context, q sqlx.QueryerContext) (bool, error) {
+	var count int
+	if err := sqlx.GetContext(ctx, q, &count, searchIndexExistsStatement); err != nil {
+		return false, fmt.Errorf("failed to check for search index: %w", err)
+	}
+	return count > 0, nil
+}
+
+// indexEntity adds an entity to the search index, replacing any earlier
+// entry for it.
+func (sqliteDialect) indexEntity(ctx context.Context, e model.Entity, tx *sqlx.Tx) error {
+	exists, err := searchIndexExists(ctx, tx)
+	if err != nil || !exists {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, searchIndexDeleteStatement, e.ID); err != nil {
+		return fmt.Errorf("failed to remove entity from search index: %w", err)
+	}
+	annotationValues := make([]string, 0, len(e.Metadata.Annotations))
+	for _, v := range e.Metadata.Annotations {
+		annotationValues = append(annotationValues, v)
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		searchIndexInsertStatement,
+		e.ID,
+		e.Metadata.Name,
+		nullString(e.Metadata.Title),
+		nullString(e.Metadata.Description),
+		nullString(strings.Join(e.Metadata.Tags, " ")),
+		nullString(strings.Join(annotationValues, " ")),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add entity to search index: %w", err)
+	}
+	return nil
+}
+
+func (sqliteDialect) unindexEntity(ctx context.Context, id int64, tx *sqlx.Tx) error {
+	exists, err := searchIndexExists(ctx, tx)
+	if err != nil || !exists {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, searchIndexDeleteStatement, id); err != nil {
+		return fmt.Errorf("failed to remove entity from search index: %w", err)
+	}
+	return nil
+}
+
+// searchEntities finds the entities that match a query, best matches first.
+// Each word of the query must match a word in the entity, or the start of
+// one.
+func (sqliteDialect) searchEntities(ctx context.Context, db *sqlx.DB, query string, pagination Pagination) ([]SearchMatch, Pagination, error) {
+	exists, err := searchIndexExists(ctx, db)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	if !exists {
+		return nil, Pagination{}, ErrSearchUnavailable
+	}
+
+	matchQuery := searchMatchQuery(query)
+	if matchQuery == "" {
+		return nil, Pagination{}, fmt.Errorf("%w: empty search query", ErrInvalid)
+	}
+
+	statement := searchStatement + limitClause(pagination)
+
+	rows, err := db.QueryxContext(ctx, statement, matchQuery)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to search entities: %w", err)
+	}
+	defer rows.Close()
+	results := []SearchMatch{}
+	nextOffset := pagination.Offset
+	for rows.Next() {
+		var match SearchMatch
+		err = rows.Scan(&match.EntityRef.Kind, &match.EntityRef.Namespace, &match.EntityRef.Name, &match.Snippet)
+		if err != nil {
+			return nil, Pagination{}, fmt.Errorf("failed to scan columns for search result: %w", err)
+		}
+		match.Snippet = markSnippet(match.Snippet)
+		results = append(results, match)
+		nextOffset++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to search entities: %w", err)
+	}
+
+	return results, Pagination{
+		Limit:  pagination.Limit,
+		Offset: nextOffset,
+	}, nil
+}
+
+// searchMatchQuery turns free text into an FTS5 query that matches each word
+// as a prefix. Words are quoted so that FTS5 operators and punctuation in
+// them are taken literally.
+func searchMatchQuery(query string) string {
+	words := strings.Fields(query)
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"*`
+	}
+	return strings.Join(terms, " ")
+}
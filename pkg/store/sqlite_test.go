@@ -0,0 +1,1800 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStore(t *testing.T) *sqliteStore {
+	store, err := NewSqliteStore("file::memory:")
+	require.NoError(t, err)
+	return store
+}
+
+// withStoreFields returns an entity with the fields that the store sets
+// copied from a stored entity.
+func withStoreFields(e model.Entity, stored model.Entity) model.Entity {
+	e.ID = stored.ID
+	e.Version = stored.Version
+	e.Metadata.UID = stored.Metadata.UID
+	e.Metadata.Etag = stored.Metadata.Etag
+	e.Metadata.CreatedAt = stored.Metadata.CreatedAt
+	e.Metadata.UpdatedAt = stored.Metadata.UpdatedAt
+	return e
+}
+
+func TestCreateComponentAndReadComponent(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	c, err := store.CreateComponent(ctx, model.TestFullComponent)
+	assert.NoError(t, err)
+	stored := c.Entity
+	c = model.TestFullComponent
+	c.Entity = withStoreFields(c.Entity, stored)
+
+	r, err := store.ReadComponent(ctx, model.TestFullComponent.EntityRef())
+	assert.NoError(t, err)
+
+	assert.Equal(t, c, r)
+}
+
+func TestUpdateComponent(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	c, err := store.CreateComponent(ctx, model.TestFullComponent)
+	assert.NoError(t, err)
+
+	c.Metadata.Title = "my-new-title"
+	c.Metadata.Description = "my-new-description"
+	c.Metadata.Labels = map[string]string{
+		"key0": "value0",
+		"key1": "value1a",
+	}
+	c.Metadata.Annotations = map[string]string{
+		"keya": "valuea1",
+	}
+	c.Metadata.Tags = []string{"tag0"}
+	c.Metadata.Links = []model.Link{
+		{
+			URL:   "http://example.com/url0",
+			Title: "link0",
+			Icon:  "icon0",
+			Type:  "linktype0",
+		},
+		{
+			URL:   "http://example.com/url1",
+			Title: "link1a",
+			Icon:  "icon1a",
+			Type:  "linktype1a",
+		},
+	}
+	c.Spec.Type = model.ComponentTypeLibrary
+	c.Spec.Lifecycle = model.ComponentLifecycleProduction
+	c.Spec.Owner = model.TestOwner2EntityRef
+	c.Spec.System = model.TestSystem2EntityRef
+	c.Spec.SubcomponentOf = model.TestComponent2EntityRef
+	c.Spec.ProvidesAPIs = []model.EntityRef{
+		model.TestAPI2EntityRef,
+	}
+	c.Spec.ConsumesAPIs = nil
+	c.Spec.DependsOn = []model.EntityRef{
+		model.TestResource2EntityRef,
+	}
+	c.Spec.DependencyOf = nil
+
+	u, err := store.UpdateComponent(ctx, c)
+	assert.NoError(t, err)
+	c.Version++
+	c.Metadata.Etag = "2"
+	c.Metadata.UpdatedAt = u.Metadata.UpdatedAt
+	assert.Equal(t, c, u)
+
+	r, err := store.ReadComponent(ctx, model.TestFullComponent.EntityRef())
+	assert.NoError(t, err)
+	assert.Equal(t, c, r)
+}
+
+func TestDeleteComponent(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	c, err := store.CreateComponent(ctx, model.TestFullComponent)
+	assert.NoError(t, err)
+	id := c.ID
+
+	d, err := store.DeleteComponent(ctx, model.TestFullComponent.EntityRef(), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, c, d)
+
+	_, err = store.readEntity(c.EntityRef())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	rows, err := store.db.Queryx(componentSelectStatement, id)
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+	rows.Close()
+
+	_, err = store.PurgeEntities(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	rows, err = store.db.Queryx(componentSelectStatement, id)
+	defer rows.Close()
+	assert.NoError(t, err)
+	assert.False(t, rows.Next())
+}
+
+func TestListComponents(t *testing.T) {
+	ctx := context.Background()
+	component1 := model.Component{
+		Entity: model.Entity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       model.KindComponent,
+			Metadata: model.Metadata{
+				Namespace: "default",
+				Name:      "component1",
+			},
+		},
+		Spec: model.ComponentSpec{
+			Type:      model.ComponentTypeService,
+			Lifecycle: model.ComponentLifecycleExperimental,
+			Owner:     model.TestFullUser.EntityRef(),
+		},
+	}
+
+	component2 := component1
+	component2.Entity.Metadata.Namespace = "ns1"
+	component2.Entity.Metadata.Name = "component2"
+	component2.Spec.Type = model.ComponentTypeLibrary
+
+	component3 := component1
+	component3.Entity.Metadata.Namespace = "ns1"
+	component3.Entity.Metadata.Name = "component3"
+	component3.Spec.Type = model.ComponentTypeWebsite
+
+	type testCase struct {
+		components         []model.Component
+		filters            []Filter
+		ordering           Ordering
+		pagination         Pagination
+		expectedEntityRefs []model.EntityRef
+		description        string
+	}
+	tcs := []testCase{
+		{
+			components:         nil,
+			expectedEntityRefs: nil,
+			description:        "empty",
+		},
+		{
+			components: []model.Component{
+				component1,
+			},
+			expectedEntityRefs: []model.EntityRef{
+				component1.EntityRef(),
+			},
+			description: "single hit, no filters",
+		},
+		{
+			components: []model.Component{
+				component1,
+				component2,
+			},
+			expectedEntityRefs: []model.EntityRef{
+				component1.EntityRef(),
+				component2.EntityRef(),
+			},
+			description: "multiple hits, no filters",
+		},
+		{
+			components: []model.Component{
+				component1,
+				component2,
+			},
+			filters: []Filter{
+				{
+					Key:   "entity.namespace",
+					Value: "ns1",
+				},
+			},
+			expectedEntityRefs: []model.EntityRef{
+				component2.EntityRef(),
+			},
+			description: "filter on namespace",
+		},
+		{
+			components: []model.Component{
+				component1,
+				component2,
+			},
+			filters: []Filter{
+				{
+					Key:   "entity.name",
+					Value: "component1",
+				},
+			},
+			expectedEntityRefs: []model.EntityRef{
+				component1.EntityRef(),
+			},
+			description: "filter on name",
+		},
+		{
+			components: []model.Component{
+				component1,
+				component2,
+			},
+			filters: []Filter{
+				{
+					Key:   "entity.namespace",
+					Value: "default",
+				},
+				{
+					Key:   "entity.name",
+					Value: "component1",
+				},
+			},
+			expectedEntityRefs: []model.EntityRef{
+				component1.EntityRef(),
+			},
+			description: "filter on namespace and name",
+		},
+		{
+			components: []model.Component{
+				component1,
+				component2,
+			},
+			filters: []Filter{
+				{
+					Key:   "entity.namespace",
+					Value: "ns1",
+				},
+				{
+					Key:   "entity.name",
+					Value: "component1",
+				},
+			},
+			expectedEntityRefs: nil,
+			description:        "filter on namespace and name, no hits",
+		},
+		{
+			components: []model.Component{
+				component1,
+				component2,
+			},
+			ordering: Ordering{
+				OrderBy: OrderByName,
+			},
+			expectedEntityRefs: []model.EntityRef{
+				component1.EntityRef(),
+				component2.EntityRef(),
+			},
+			description: "multiple hits, ascending name order",
+		},
+		{
+			components: []model.Component{
+				component1,
+				component2,
+			},
+			ordering: Ordering{
+				OrderBy:    OrderByName,
+				Descending: true,
+			},
+			expectedEntityRefs: []model.EntityRef{
+				component2.EntityRef(),
+				component1.EntityRef(),
+			},
+			description: "multiple hits, descending name order",
+		},
+		{
+			components: []model.Component{
+				component1,
+				component2,
+			},
+			ordering: Ordering{
+				OrderBy: OrderByNamespace,
+			},
+			expectedEntityRefs: []model.EntityRef{
+				component1.EntityRef(),
+				component2.EntityRef(),
+			},
+			description: "multiple hits, ascending namespace order",
+		},
+		{
+			components: []model.Component{
+				component1,
+				component2,
+				component3,
+			},
+			pagination: Pagination{
+				Limit: 2,
+			},
+			expectedEntityRefs: []model.EntityRef{
+				component1.EntityRef(),
+				component2.EntityRef(),
+			},
+			description: "multiple hits, first page",
+		},
+		{
+			components: []model.Component{
+				component1,
+				component2,
+				component3,
+			},
+			pagination: Pagination{
+				Limit:  2,
+				Offset: 2,
+			},
+			expectedEntityRefs: []model.EntityRef{
+				component3.EntityRef(),
+			},
+			description: "multiple hits, second page",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			store := testStore(t)
+			for _, c := range tc.components {
+				_, err := store.CreateComponent(ctx, c)
+				assert.NoError(t, err)
+			}
+
+			refs, pagination, err := store.ListComponents(ctx, tc.filters, tc.ordering, tc.pagination)
+			assert.NoError(t, err)
+			if tc.ordering.OrderBy != "" {
+				assert.Equal(t, tc.expectedEntityRefs, refs)
+			} else {
+				assert.ElementsMatch(t, tc.expectedEntityRefs, refs)
+			}
+			if tc.pagination.Limit > 0 {
+				assert.Equal(t, tc.pagination.Limit, pagination.Limit)
+				assert.Equal(t, tc.pagination.Offset+len(refs), pagination.Offset)
+			}
+		})
+	}
+}
+
+// ---
+
+func TestCreateAPIAndReadAPI(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	a, err := store.CreateAPI(ctx, model.TestFullAPI)
+	assert.NoError(t, err)
+	stored := a.Entity
+	a = model.TestFullAPI
+	a.Entity = withStoreFields(a.Entity, stored)
+
+	r, err := store.ReadAPI(ctx, model.TestFullAPI.EntityRef())
+	assert.NoError(t, err)
+
+	assert.Equal(t, a, r)
+}
+
+func TestUpdateAPI(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	a, err := store.CreateAPI(ctx, model.TestFullAPI)
+	assert.NoError(t, err)
+
+	// metadata updates tested for component
+	a.Spec.Type = model.APITypeGRPC
+	a.Spec.Lifecycle = model.APILifecycleProduction
+	a.Spec.Owner = model.TestOwner2EntityRef
+	a.Spec.System = model.TestSystem2EntityRef
+	a.Spec.Definition = "my-new-definition"
+
+	u, err := store.UpdateAPI(ctx, a)
+	assert.NoError(t, err)
+	a.Version++
+	a.Metadata.Etag = "2"
+	a.Metadata.UpdatedAt = u.Metadata.UpdatedAt
+	assert.Equal(t, a, u)
+
+	r, err := store.ReadAPI(ctx, model.TestFullAPI.EntityRef())
+	assert.NoError(t, err)
+	assert.Equal(t, a, r)
+}
+
+func TestDeleteAPI(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	a, err := store.CreateAPI(ctx, model.TestFullAPI)
+	assert.NoError(t, err)
+	id := a.ID
+
+	d, err := store.DeleteAPI(ctx, model.TestFullAPI.EntityRef(), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, a, d)
+
+	_, err = store.readEntity(a.EntityRef())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	rows, err := store.db.Queryx(apiSelectStatement, id)
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+	rows.Close()
+
+	_, err = store.PurgeEntities(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	rows, err = store.db.Queryx(apiSelectStatement, id)
+	defer rows.Close()
+	assert.NoError(t, err)
+	assert.False(t, rows.Next())
+}
+
+// ---
+
+func TestCreateUserAndReadUser(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	u, err := store.CreateUser(ctx, model.TestFullUser)
+	assert.NoError(t, err)
+	stored := u.Entity
+	u = model.TestFullUser
+	u.Entity = withStoreFields(u.Entity, stored)
+
+	r, err := store.ReadUser(ctx, model.TestFullUser.EntityRef())
+	assert.NoError(t, err)
+
+	assert.Equal(t, u, r)
+}
+
+func TestUpdateUser(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	u, err := store.CreateUser(ctx, model.TestFullUser)
+	assert.NoError(t, err)
+
+	// metadata updates tested for component
+	u.Spec.Profile.DisplayName = "new-displayName"
+	u.Spec.Profile.Email = "new-email"
+	u.Spec.Profile.Picture = "new-picture"
+	u.Spec.MemberOf = []model.EntityRef{
+		model.TestGroup2EntityRef,
+	}
+
+	uu, err := store.UpdateUser(ctx, u)
+	assert.NoError(t, err)
+	u.Version++
+	u.Metadata.Etag = "2"
+	u.Metadata.UpdatedAt = uu.Metadata.UpdatedAt
+	assert.Equal(t, u, uu)
+
+	r, err := store.ReadUser(ctx, model.TestFullUser.EntityRef())
+	assert.NoError(t, err)
+	assert.Equal(t, u, r)
+}
+
+func TestDeleteUser(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	u, err := store.CreateUser(ctx, model.TestFullUser)
+	assert.NoError(t, err)
+	id := u.ID
+
+	d, err := store.DeleteUser(ctx, model.TestFullUser.EntityRef(), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, u, d)
+
+	_, err = store.readEntity(u.EntityRef())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	rows, err := store.db.Queryx(userSelectStatement, id)
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+	rows.Close()
+
+	_, err = store.PurgeEntities(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	rows, err = store.db.Queryx(userSelectStatement, id)
+	defer rows.Close()
+	assert.NoError(t, err)
+	assert.False(t, rows.Next())
+}
+
+// ---
+
+func TestCreateGroupAndReadGroup(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	g, err := store.CreateGroup(ctx, model.TestFullGroup)
+	assert.NoError(t, err)
+	stored := g.Entity
+	g = model.TestFullGroup
+	g.Entity = withStoreFields(g.Entity, stored)
+
+	r, err := store.ReadGroup(ctx, model.TestFullGroup.EntityRef())
+	assert.NoError(t, err)
+
+	assert.Equal(t, g, r)
+}
+
+func TestUpdateGroup(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	g, err := store.CreateGroup(ctx, model.TestFullGroup)
+	assert.NoError(t, err)
+
+	// metadata updates tested for component
+	g.Spec.Type = "business-unit"
+	g.Spec.Profile.DisplayName = "new-displayName"
+	g.Spec.Profile.Email = "new-email"
+	g.Spec.Profile.Picture = "new-picture"
+	g.Spec.Parent = model.TestGroup2EntityRef
+	g.Spec.Children = []model.EntityRef{
+		model.TestGroupEntityRef,
+	}
+	g.Spec.Members = []model.EntityRef{
+		model.TestUser2EntityRef,
+	}
+
+	u, err := store.UpdateGroup(ctx, g)
+	assert.NoError(t, err)
+	g.Version++
+	g.Metadata.Etag = "2"
+	g.Metadata.UpdatedAt = u.Metadata.UpdatedAt
+	assert.Equal(t, g, u)
+
+	r, err := store.ReadGroup(ctx, model.TestFullGroup.EntityRef())
+	assert.NoError(t, err)
+	assert.Equal(t, g, r)
+}
+
+func TestDeleteGroup(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	g, err := store.CreateGroup(ctx, model.TestFullGroup)
+	assert.NoError(t, err)
+	id := g.ID
+
+	d, err := store.DeleteGroup(ctx, model.TestFullGroup.EntityRef(), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, g, d)
+
+	_, err = store.readEntity(g.EntityRef())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	rows, err := store.db.Queryx(groupSelectStatement, id)
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+	rows.Close()
+
+	_, err = store.PurgeEntities(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	rows, err = store.db.Queryx(groupSelectStatement, id)
+	defer rows.Close()
+	assert.NoError(t, err)
+	assert.False(t, rows.Next())
+}
+
+// ---
+
+func TestCreateSystemAndReadSystem(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	s, err := store.CreateSystem(ctx, model.TestFullSystem)
+	assert.NoError(t, err)
+	stored := s.Entity
+	s = model.TestFullSystem
+	s.Entity = withStoreFields(s.Entity, stored)
+
+	r, err := store.ReadSystem(ctx, model.TestFullSystem.EntityRef())
+	assert.NoError(t, err)
+
+	assert.Equal(t, s, r)
+}
+
+func TestUpdateSystem(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	s, err := store.CreateSystem(ctx, model.TestFullSystem)
+	assert.NoError(t, err)
+
+	// metadata updates tested for component
+	s.Spec.Owner = model.TestOwner2EntityRef
+	s.Spec.Domain = model.TestDomain2EntityRef
+	s.Spec.Type = ""
+
+	u, err := store.UpdateSystem(ctx, s)
+	assert.NoError(t, err)
+	s.Version++
+	s.Metadata.Etag = "2"
+	s.Metadata.UpdatedAt = u.Metadata.UpdatedAt
+	assert.Equal(t, s, u)
+
+	r, err := store.ReadSystem(ctx, model.TestFullSystem.EntityRef())
+	assert.NoError(t, err)
+	assert.Equal(t, s, r)
+}
+
+func TestDeleteSystem(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	s, err := store.CreateSystem(ctx, model.TestFullSystem)
+	assert.NoError(t, err)
+	id := s.ID
+
+	d, err := store.DeleteSystem(ctx, model.TestFullSystem.EntityRef(), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, s, d)
+
+	_, err = store.readEntity(s.EntityRef())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	rows, err := store.db.Queryx(systemSelectStatement, id)
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+	rows.Close()
+
+	_, err = store.PurgeEntities(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	rows, err = store.db.Queryx(systemSelectStatement, id)
+	defer rows.Close()
+	assert.NoError(t, err)
+	assert.False(t, rows.Next())
+}
+
+func TestListSystems(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	system1 := model.TestFullSystem
+	system1.Metadata.Namespace = "default"
+	system1.Metadata.Name = "system1"
+	system2 := model.TestFullSystem
+	system2.Metadata.Namespace = "ns1"
+	system2.Metadata.Name = "system2"
+
+	for _, s := range []model.System{system1, system2} {
+		_, err := store.CreateSystem(ctx, s)
+		assert.NoError(t, err)
+	}
+	_, err := store.CreateComponent(ctx, model.TestFullComponent)
+	assert.NoError(t, err)
+
+	refs, pagination, err := store.ListSystems(ctx, nil, Ordering{OrderBy: OrderByName}, Pagination{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{system1.EntityRef(), system2.EntityRef()}, refs)
+	assert.Equal(t, Pagination{Limit: 10, Offset: 2}, pagination)
+
+	refs, _, err = store.ListSystems(ctx, []Filter{{Key: "entity.namespace", Value: "ns1"}}, Ordering{}, Pagination{})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{system2.EntityRef()}, refs)
+}
+
+// ---
+
+func TestCreateResourceAndReadResource(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	r, err := store.CreateResource(ctx, model.TestFullResource)
+	assert.NoError(t, err)
+	stored := r.Entity
+	r = model.TestFullResource
+	r.Entity = withStoreFields(r.Entity, stored)
+
+	rr, err := store.ReadResource(ctx, model.TestFullResource.EntityRef())
+	assert.NoError(t, err)
+
+	assert.Equal(t, r, rr)
+}
+
+func TestUpdateResource(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	r, err := store.CreateResource(ctx, model.TestFullResource)
+	assert.NoError(t, err)
+
+	// metadata updates tested for component
+	r.Spec.Type = "s3-bucket"
+	r.Spec.Owner = model.TestOwner2EntityRef
+	r.Spec.System = model.TestSystem2EntityRef
+	r.Spec.DependsOn = nil
+	r.Spec.DependencyOf = []model.EntityRef{
+		model.TestComponent2EntityRef,
+		model.TestResource2EntityRef,
+	}
+
+	u, err := store.UpdateResource(ctx, r)
+	assert.NoError(t, err)
+	r.Version++
+	r.Metadata.Etag = "2"
+	r.Metadata.UpdatedAt = u.Metadata.UpdatedAt
+	assert.Equal(t, r, u)
+
+	rr, err := store.ReadResource(ctx, model.TestFullResource.EntityRef())
+	assert.NoError(t, err)
+	assert.Equal(t, r, rr)
+}
+
+func TestDeleteResource(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	r, err := store.CreateResource(ctx, model.TestFullResource)
+	assert.NoError(t, err)
+	id := r.ID
+
+	d, err := store.DeleteResource(ctx, model.TestFullResource.EntityRef(), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, r, d)
+
+	_, err = store.readEntity(r.EntityRef())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	rows, err := store.db.Queryx(resourceSelectStatement, id)
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+	rows.Close()
+
+	_, err = store.PurgeEntities(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	rows, err = store.db.Queryx(resourceSelectStatement, id)
+	defer rows.Close()
+	assert.NoError(t, err)
+	assert.False(t, rows.Next())
+}
+
+// ---
+
+func TestCreateDomainAndReadDomain(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	d, err := store.CreateDomain(ctx, model.TestFullDomain)
+	assert.NoError(t, err)
+	stored := d.Entity
+	d = model.TestFullDomain
+	d.Entity = withStoreFields(d.Entity, stored)
+
+	r, err := store.ReadDomain(ctx, model.TestFullDomain.EntityRef())
+	assert.NoError(t, err)
+
+	assert.Equal(t, d, r)
+}
+
+func TestUpdateDomain(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	d, err := store.CreateDomain(ctx, model.TestFullDomain)
+	assert.NoError(t, err)
+
+	// metadata updates tested for component
+	d.Spec.Owner = model.TestOwner2EntityRef
+	d.Spec.SubdomainOf = model.EntityRef{}
+	d.Spec.Type = ""
+
+	u, err := store.UpdateDomain(ctx, d)
+	assert.NoError(t, err)
+	d.Version++
+	d.Metadata.Etag = "2"
+	d.Metadata.UpdatedAt = u.Metadata.UpdatedAt
+	assert.Equal(t, d, u)
+
+	r, err := store.ReadDomain(ctx, model.TestFullDomain.EntityRef())
+	assert.NoError(t, err)
+	assert.Equal(t, d, r)
+}
+
+func TestDeleteDomain(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	d, err := store.CreateDomain(ctx, model.TestFullDomain)
+	assert.NoError(t, err)
+	id := d.ID
+
+	dd, err := store.DeleteDomain(ctx, model.TestFullDomain.EntityRef(), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, d, dd)
+
+	_, err = store.readEntity(d.EntityRef())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	rows, err := store.db.Queryx(domainSelectStatement, id)
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+	rows.Close()
+
+	_, err = store.PurgeEntities(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	rows, err = store.db.Queryx(domainSelectStatement, id)
+	defer rows.Close()
+	assert.NoError(t, err)
+	assert.False(t, rows.Next())
+}
+
+func TestListSystemsInDomain(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	system1 := model.TestFullSystem
+	system1.Metadata.Name = "system1"
+	system1.Spec.Domain = model.TestDomainEntityRef
+	system2 := model.TestFullSystem
+	system2.Metadata.Name = "system2"
+	system2.Spec.Domain = model.TestDomain2EntityRef
+	system3 := model.TestFullSystem
+	system3.Metadata.Name = "system3"
+	system3.Spec.Domain = model.EntityRef{}
+
+	for _, s := range []model.System{system1, system2, system3} {
+		_, err := store.CreateSystem(ctx, s)
+		assert.NoError(t, err)
+	}
+
+	filters := []Filter{
+		{
+			Key:   "spec.domain",
+			Value: model.TestDomainEntityRef.String(),
+		},
+	}
+	refs, _, err := store.ListSystems(ctx, filters, Ordering{}, Pagination{})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{system1.EntityRef()}, refs)
+}
+
+// ---
+
+func TestCreateLocationAndReadLocation(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	l, err := store.CreateLocation(ctx, model.TestFullLocation)
+	assert.NoError(t, err)
+	stored := l.Entity
+	l = model.TestFullLocation
+	l.Entity = withStoreFields(l.Entity, stored)
+
+	r, err := store.ReadLocation(ctx, model.TestFullLocation.EntityRef())
+	assert.NoError(t, err)
+
+	assert.Equal(t, l, r)
+}
+
+func TestUpdateLocation(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	l, err := store.CreateLocation(ctx, model.TestFullLocation)
+	assert.NoError(t, err)
+
+	// metadata updates tested for component
+	l.Spec.Type = model.LocationTypeGit
+	l.Spec.Target = ""
+	l.Spec.Targets = []string{
+		"/checkout/with space/catalog-info.yaml",
+	}
+
+	u, err := store.UpdateLocation(ctx, l)
+	assert.NoError(t, err)
+	l.Version++
+	l.Metadata.Etag = "2"
+	l.Metadata.UpdatedAt = u.Metadata.UpdatedAt
+	assert.Equal(t, l, u)
+
+	r, err := store.ReadLocation(ctx, model.TestFullLocation.EntityRef())
+	assert.NoError(t, err)
+	assert.Equal(t, l, r)
+}
+
+func TestDeleteLocation(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	l, err := store.CreateLocation(ctx, model.TestFullLocation)
+	assert.NoError(t, err)
+	id := l.ID
+
+	d, err := store.DeleteLocation(ctx, model.TestFullLocation.EntityRef(), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, l, d)
+
+	_, err = store.readEntity(l.EntityRef())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	rows, err := store.db.Queryx(locationSelectStatement, id)
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+	rows.Close()
+
+	_, err = store.PurgeEntities(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	rows, err = store.db.Queryx(locationSelectStatement, id)
+	defer rows.Close()
+	assert.NoError(t, err)
+	assert.False(t, rows.Next())
+}
+
+// ---
+
+func TestCreateTemplateAndReadTemplate(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	tm, err := store.CreateTemplate(ctx, model.TestFullTemplate)
+	assert.NoError(t, err)
+	stored := tm.Entity
+	tm = model.TestFullTemplate
+	tm.Entity = withStoreFields(tm.Entity, stored)
+
+	r, err := store.ReadTemplate(ctx, model.TestFullTemplate.EntityRef())
+	assert.NoError(t, err)
+
+	assert.Equal(t, tm, r)
+}
+
+func TestUpdateTemplate(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	tm, err := store.CreateTemplate(ctx, model.TestFullTemplate)
+	assert.NoError(t, err)
+
+	// metadata updates tested for component
+	tm.Spec.Type = model.ComponentTypeWebsite
+	tm.Spec.Owner = model.TestOwner2EntityRef
+	tm.Spec.Parameters = map[string]any{
+		"required": []any{"name"},
+	}
+	tm.Spec.Steps = []map[string]any{
+		{
+			"id":     "log",
+			"action": "debug:log",
+		},
+	}
+	tm.Spec.Output = nil
+
+	u, err := store.UpdateTemplate(ctx, tm)
+	assert.NoError(t, err)
+	tm.Version++
+	tm.Metadata.Etag = "2"
+	tm.Metadata.UpdatedAt = u.Metadata.UpdatedAt
+	assert.Equal(t, tm, u)
+
+	r, err := store.ReadTemplate(ctx, model.TestFullTemplate.EntityRef())
+	assert.NoError(t, err)
+	assert.Equal(t, tm, r)
+}
+
+func TestDeleteTemplate(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	tm, err := store.CreateTemplate(ctx, model.TestFullTemplate)
+	assert.NoError(t, err)
+	id := tm.ID
+
+	d, err := store.DeleteTemplate(ctx, model.TestFullTemplate.EntityRef(), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, tm, d)
+
+	_, err = store.readEntity(tm.EntityRef())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	rows, err := store.db.Queryx(templateSelectStatement, id)
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+	rows.Close()
+
+	_, err = store.PurgeEntities(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	rows, err = store.db.Queryx(templateSelectStatement, id)
+	defer rows.Close()
+	assert.NoError(t, err)
+	assert.False(t, rows.Next())
+}
+
+func TestListTemplates(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	template1 := model.TestFullTemplate
+	template1.Metadata.Name = "template1"
+	template2 := model.TestFullTemplate
+	template2.Metadata.Name = "template2"
+	template2.Spec.Type = model.ComponentTypeWebsite
+	template3 := model.TestFullTemplate
+	template3.Metadata.Name = "template3"
+	template3.Spec.Owner = model.TestOwner2EntityRef
+
+	for _, tm := range []model.Template{template1, template2, template3} {
+		_, err := store.CreateTemplate(ctx, tm)
+		assert.NoError(t, err)
+	}
+
+	filters := []Filter{
+		{
+			Key:   "spec.type",
+			Value: model.ComponentTypeService,
+		},
+		{
+			Key:   "spec.owner",
+			Value: model.TestOwnerEntityRef.String(),
+		},
+	}
+	refs, _, err := store.ListTemplates(ctx, filters, Ordering{}, Pagination{})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{template1.EntityRef()}, refs)
+}
+
+// ---
+
+func TestListAPIsUsersGroups(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	names := []string{"entity1", "entity2", "entity3"}
+	for _, name := range names {
+		a := model.TestFullAPI
+		a.Metadata.Name = name
+		_, err := store.CreateAPI(ctx, a)
+		require.NoError(t, err)
+		u := model.TestFullUser
+		u.Metadata.Name = name
+		_, err = store.CreateUser(ctx, u)
+		require.NoError(t, err)
+		g := model.TestFullGroup
+		g.Metadata.Name = name
+		_, err = store.CreateGroup(ctx, g)
+		require.NoError(t, err)
+	}
+
+	type listFunc func(context.Context, []Filter, Ordering, Pagination) ([]model.EntityRef, Pagination, error)
+	listFuncs := map[string]listFunc{
+		model.KindAPI:   store.ListAPIs,
+		model.KindUser:  store.ListUsers,
+		model.KindGroup: store.ListGroups,
+	}
+	for kind, list := range listFuncs {
+		t.Run(kind, func(t *testing.T) {
+			refs, pagination, err := list(ctx, nil, Ordering{OrderBy: OrderByName, Descending: true}, Pagination{Limit: 2})
+			assert.NoError(t, err)
+			assert.Equal(t, []model.EntityRef{
+				{Kind: kind, Namespace: "my-namespace", Name: "entity3"},
+				{Kind: kind, Namespace: "my-namespace", Name: "entity2"},
+			}, refs)
+			assert.Equal(t, Pagination{Limit: 2, Offset: 2}, pagination)
+
+			refs, _, err = list(ctx, []Filter{{Key: "entity.name", Value: "entity1"}}, Ordering{}, Pagination{})
+			assert.NoError(t, err)
+			assert.Equal(t, []model.EntityRef{
+				{Kind: kind, Namespace: "my-namespace", Name: "entity1"},
+			}, refs)
+		})
+	}
+}
+
+// ---
+
+func TestListEntities(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	component := model.TestFullComponent
+	component.Metadata.Namespace = "default"
+	_, err := store.CreateComponent(ctx, component)
+	require.NoError(t, err)
+	_, err = store.CreateAPI(ctx, model.TestFullAPI)
+	require.NoError(t, err)
+	_, err = store.CreateGroup(ctx, model.TestFullGroup)
+	require.NoError(t, err)
+	_, err = store.CreateSystem(ctx, model.TestFullSystem)
+	require.NoError(t, err)
+
+	type testCase struct {
+		kinds              []string
+		filters            []Filter
+		pagination         Pagination
+		expectedEntityRefs []model.EntityRef
+		description        string
+	}
+	tcs := []testCase{
+		{
+			expectedEntityRefs: []model.EntityRef{
+				model.TestFullAPI.EntityRef(),
+				component.EntityRef(),
+				model.TestFullGroup.EntityRef(),
+				model.TestFullSystem.EntityRef(),
+			},
+			description: "all kinds",
+		},
+		{
+			kinds: []string{model.KindGroup},
+			expectedEntityRefs: []model.EntityRef{
+				model.TestFullGroup.EntityRef(),
+			},
+			description: "single kind",
+		},
+		{
+			kinds: []string{model.KindSystem, model.KindAPI, model.KindUser},
+			expectedEntityRefs: []model.EntityRef{
+				model.TestFullAPI.EntityRef(),
+				model.TestFullSystem.EntityRef(),
+			},
+			description: "multiple kinds",
+		},
+		{
+			filters: []Filter{
+				{
+					Key:   "entity.namespace",
+					Value: "my-namespace",
+				},
+			},
+			expectedEntityRefs: []model.EntityRef{
+				model.TestFullAPI.EntityRef(),
+				model.TestFullGroup.EntityRef(),
+				model.TestFullSystem.EntityRef(),
+			},
+			description: "filter on namespace",
+		},
+		{
+			pagination: Pagination{
+				Limit:  2,
+				Offset: 1,
+			},
+			expectedEntityRefs: []model.EntityRef{
+				component.EntityRef(),
+				model.TestFullGroup.EntityRef(),
+			},
+			description: "page",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			refs, pagination, err := store.ListEntities(ctx, tc.kinds, tc.filters, Ordering{OrderBy: OrderByKind}, tc.pagination)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedEntityRefs, refs)
+			assert.Equal(t, tc.pagination.Offset+len(refs), pagination.Offset)
+		})
+	}
+}
+
+// ---
+
+func TestListComponents_LabelFilters(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	labelSets := map[string]map[string]string{
+		"backend-prod": {"tier": "backend", "env": "prod", "team": "a"},
+		"backend-dev":  {"tier": "backend", "env": "dev", "team": "b"},
+		"frontend":     {"tier": "frontend", "team": "c"},
+		"unlabeled":    nil,
+	}
+	for name, labels := range labelSets {
+		c := model.TestFullComponent
+		c.Metadata.Name = name
+		c.Metadata.Labels = labels
+		_, err := store.CreateComponent(ctx, c)
+		require.NoError(t, err)
+	}
+	refsOf := func(names ...string) []model.EntityRef {
+		refs := []model.EntityRef{}
+		for _, name := range names {
+			refs = append(refs, model.EntityRef{Kind: model.KindComponent, Namespace: "my-namespace", Name: name})
+		}
+		return refs
+	}
+
+	type testCase struct {
+		filters            []Filter
+		expectedEntityRefs []model.EntityRef
+		description        string
+	}
+	tcs := []testCase{
+		{
+			filters: []Filter{
+				{Key: "metadata.labels.tier", Value: "backend"},
+			},
+			expectedEntityRefs: refsOf("backend-prod", "backend-dev"),
+			description:        "equals",
+		},
+		{
+			filters: []Filter{
+				{Key: "metadata.labels.env", Value: "dev", Operator: FilterNotEquals},
+			},
+			expectedEntityRefs: refsOf("backend-prod", "frontend", "unlabeled"),
+			description:        "not equals includes unlabeled",
+		},
+		{
+			filters: []Filter{
+				{Key: "metadata.labels.team", Operator: FilterIn, Values: []string{"a", "c"}},
+			},
+			expectedEntityRefs: refsOf("backend-prod", "frontend"),
+			description:        "in",
+		},
+		{
+			filters: []Filter{
+				{Key: "metadata.labels.team", Operator: FilterNotIn, Values: []string{"a", "c"}},
+			},
+			expectedEntityRefs: refsOf("backend-dev", "unlabeled"),
+			description:        "notin",
+		},
+		{
+			filters: []Filter{
+				{Key: "metadata.labels.env", Operator: FilterExists},
+			},
+			expectedEntityRefs: refsOf("backend-prod", "backend-dev"),
+			description:        "exists",
+		},
+		{
+			filters: []Filter{
+				{Key: "metadata.labels.env", Operator: FilterNotExists},
+			},
+			expectedEntityRefs: refsOf("frontend", "unlabeled"),
+			description:        "not exists",
+		},
+		{
+			filters: []Filter{
+				{Key: "metadata.labels.tier", Value: "backend"},
+				{Key: "metadata.labels.env", Value: "dev", Operator: FilterNotEquals},
+				{Key: "entity.namespace", Value: "my-namespace"},
+			},
+			expectedEntityRefs: refsOf("backend-prod"),
+			description:        "combined with column filter",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			refs, _, err := store.ListComponents(ctx, tc.filters, Ordering{}, Pagination{})
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tc.expectedEntityRefs, refs)
+		})
+	}
+}
+
+func TestListComponents_FieldFilters(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+
+	payments := model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "payments"}
+	type component struct {
+		name         string
+		lifecycle    string
+		owner        model.EntityRef
+		tags         []string
+		annotations  map[string]string
+		dependsOn    []model.EntityRef
+		dependencyOf []model.EntityRef
+	}
+	components := []component{
+		{
+			name:        "payments-api",
+			lifecycle:   model.ComponentLifecycleProduction,
+			owner:       payments,
+			tags:        []string{"java", "payments"},
+			annotations: map[string]string{"backstage.io/techdocs-ref": "dir:."},
+			dependsOn:   []model.EntityRef{model.TestResource1EntityRef, model.TestResource2EntityRef},
+		},
+		{
+			name:      "payments-worker",
+			lifecycle: model.ComponentLifecycleExperimental,
+			owner:     payments,
+			tags:      []string{"go"},
+		},
+		{
+			name:        "search",
+			lifecycle:   model.ComponentLifecycleProduction,
+			owner:       model.TestOwnerEntityRef,
+			tags:        []string{"java_legacy"},
+			annotations: map[string]string{"backstage.io/techdocs-ref": "url:https://example.com"},
+			dependsOn:   []model.EntityRef{model.TestResource2EntityRef},
+		},
+	}
+	for _, co := range components {
+		c := model.TestFullComponent
+		c.Metadata.Name = co.name
+		c.Metadata.Tags = co.tags
+		c.Metadata.Annotations = co.annotations
+		c.Spec.Lifecycle = co.lifecycle
+		c.Spec.Owner = co.owner
+		c.Spec.DependsOn = co.dependsOn
+		c.Spec.DependencyOf = co.dependencyOf
+		_, err := store.CreateComponent(ctx, c)
+		require.NoError(t, err)
+	}
+	refsOf := func(names ...string) []model.EntityRef {
+		refs := []model.EntityRef{}
+		for _, name := range names {
+			refs = append(refs, model.EntityRef{Kind: model.KindComponent, Namespace: "my-namespace", Name: name})
+		}
+		return refs
+	}
+
+	type testCase struct {
+		filters            []Filter
+		expectedEntityRefs []model.EntityRef
+		description        string
+	}
+	tcs := []testCase{
+		{
+			filters: []Filter{
+				{Key: "spec.lifecycle", Value: model.ComponentLifecycleProduction},
+				{Key: "spec.owner", Value: payments.String()},
+			},
+			expectedEntityRefs: refsOf("payments-api"),
+			description:        "production services owned by a group",
+		},
+		{
+			filters: []Filter{
+				{Key: "spec.lifecycle", Operator: FilterIn, Values: []string{model.ComponentLifecycleProduction, model.ComponentLifecycleDeprecated}},
+			},
+			expectedEntityRefs: refsOf("payments-api", "search"),
+			description:        "spec field in",
+		},
+		{
+			filters: []Filter{
+				{Key: "metadata.name", Value: "payments-", Operator: FilterPrefix},
+			},
+			expectedEntityRefs: refsOf("payments-api", "payments-worker"),
+			description:        "name prefix",
+		},
+		{
+			filters: []Filter{
+				{Key: "metadata.tags", Value: "java"},
+			},
+			expectedEntityRefs: refsOf("payments-api"),
+			description:        "tag equals does not match a longer tag",
+		},
+		{
+			filters: []Filter{
+				{Key: "metadata.tags", Value: "java", Operator: FilterPrefix},
+			},
+			expectedEntityRefs: refsOf("payments-api", "search"),
+			description:        "tag prefix",
+		},
+		{
+			filters: []Filter{
+				{Key: "metadata.tags", Value: "java_", Operator: FilterPrefix},
+			},
+			expectedEntityRefs: refsOf("search"),
+			description:        "tag prefix escapes wildcards",
+		},
+		{
+			filters: []Filter{
+				{Key: "metadata.tags", Operator: FilterNotIn, Values: []string{"go", "payments"}},
+			},
+			expectedEntityRefs: refsOf("search"),
+			description:        "tag notin",
+		},
+		{
+			filters: []Filter{
+				{Key: "metadata.annotations.backstage.io/techdocs-ref", Operator: FilterExists},
+			},
+			expectedEntityRefs: refsOf("payments-api", "search"),
+			description:        "annotation exists",
+		},
+		{
+			filters: []Filter{
+				{Key: "metadata.annotations.backstage.io/techdocs-ref", Value: "url:", Operator: FilterPrefix},
+			},
+			expectedEntityRefs: refsOf("search"),
+			description:        "annotation prefix",
+		},
+		{
+			filters: []Filter{
+				{Key: "spec.dependsOn", Value: model.TestResource2EntityRef.String()},
+			},
+			expectedEntityRefs: refsOf("payments-api", "search"),
+			description:        "entity ref list contains",
+		},
+		{
+			filters: []Filter{
+				{Key: "spec.dependsOn", Operator: FilterNotExists},
+			},
+			expectedEntityRefs: refsOf("payments-worker"),
+			description:        "entity ref list empty",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			refs, _, err := store.ListComponents(ctx, tc.filters, Ordering{}, Pagination{})
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tc.expectedEntityRefs, refs)
+		})
+	}
+
+	refs, _, err := store.ListEntities(ctx, []string{model.KindComponent}, []Filter{{Key: "spec.owner", Value: payments.String()}}, Ordering{}, Pagination{})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, refsOf("payments-api", "payments-worker"), refs)
+}
+
+func TestValidateFilters(t *testing.T) {
+	type testCase struct {
+		kinds       []string
+		filters     []Filter
+		valid       bool
+		description string
+	}
+	tcs := []testCase{
+		{
+			kinds:       []string{model.KindComponent},
+			filters:     []Filter{{Key: "spec.lifecycle", Value: "production"}, {Key: "metadata.tags", Operator: FilterExists}},
+			valid:       true,
+			description: "spec and metadata fields for one kind",
+		},
+		{
+			kinds:       []string{model.KindComponent, model.KindAPI},
+			filters:     []Filter{{Key: "metadata.name", Value: "x", Operator: FilterPrefix}, {Key: "metadata.labels.tier", Value: "backend"}},
+			valid:       true,
+			description: "metadata fields for several kinds",
+		},
+		{
+			kinds:       []string{model.KindComponent, model.KindAPI},
+			filters:     []Filter{{Key: "spec.owner", Value: "group:default/payments"}},
+			description: "spec field for several kinds",
+		},
+		{
+			kinds:       []string{model.KindComponent},
+			filters:     []Filter{{Key: "spec.owner", Operator: FilterIn, Values: []string{"group:default/payments", "group:"}}},
+			description: "invalid entity ref",
+		},
+		{
+			kinds:       []string{model.KindUser},
+			filters:     []Filter{{Key: "spec.lifecycle", Value: "production"}},
+			description: "spec field not available for kind",
+		},
+		{
+			kinds:       []string{model.KindComponent},
+			filters:     []Filter{{Key: "component.owner", Value: "group:default/payments"}},
+			description: "column name",
+		},
+		{
+			kinds:       []string{model.KindComponent},
+			filters:     []Filter{{Key: "spec.type", Operator: FilterIn}},
+			description: "in without values",
+		},
+		{
+			kinds:       []string{model.KindComponent},
+			filters:     []Filter{{Key: "spec.type", Operator: FilterOperator("like")}},
+			description: "unknown operator",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			err := ValidateFilters(tc.kinds, tc.filters)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+// ---
+
+func TestSearchEntities(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+	if exists, err := searchIndexExists(ctx, store.db); err != nil || !exists {
+		t.Skip("full-text search is not available; run tests with -tags sqlite_fts5")
+	}
+
+	c1 := model.TestFullComponent
+	c1.Metadata.Name = "payments-api"
+	c1.Metadata.Title = "Payments API"
+	c1.Metadata.Description = "Takes card payments and issues refunds"
+	c1.Metadata.Tags = []string{"java"}
+	c1.Metadata.Annotations = nil
+	c1, err := store.CreateComponent(ctx, c1)
+	require.NoError(t, err)
+
+	c2 := model.TestFullComponent
+	c2.Metadata.Name = "ledger"
+	c2.Metadata.Title = "Ledger"
+	c2.Metadata.Description = "Records every transaction"
+	c2.Metadata.Tags = []string{"go", "payments"}
+	c2.Metadata.Annotations = map[string]string{"runbook": "https://wiki.example.com/refunds"}
+	c2, err = store.CreateComponent(ctx, c2)
+	require.NoError(t, err)
+
+	refsOf := func(matches []SearchMatch) []model.EntityRef {
+		refs := []model.EntityRef{}
+		for _, match := range matches {
+			refs = append(refs, match.EntityRef)
+		}
+		return refs
+	}
+
+	matches, _, err := store.SearchEntities(ctx, "payments", Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{c1.EntityRef(), c2.EntityRef()}, refsOf(matches))
+	assert.Contains(t, matches[0].Snippet, "<mark>")
+
+	matches, _, err = store.SearchEntities(ctx, "refund", Pagination{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []model.EntityRef{c1.EntityRef(), c2.EntityRef()}, refsOf(matches))
+
+	matches, _, err = store.SearchEntities(ctx, "card refund", Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{c1.EntityRef()}, refsOf(matches))
+
+	matches, nextPagination, err := store.SearchEntities(ctx, "refund", Pagination{Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, Pagination{Limit: 1, Offset: 1}, nextPagination)
+
+	matches, _, err = store.SearchEntities(ctx, `"AND (`, Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{c1.EntityRef()}, refsOf(matches))
+
+	c2.Metadata.Annotations = nil
+	_, err = store.UpdateComponent(ctx, c2)
+	require.NoError(t, err)
+	matches, _, err = store.SearchEntities(ctx, "refund", Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{c1.EntityRef()}, refsOf(matches))
+
+	_, err = store.DeleteComponent(ctx, c1.EntityRef(), 0)
+	require.NoError(t, err)
+	matches, _, err = store.SearchEntities(ctx, "payments", Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{c2.EntityRef()}, refsOf(matches))
+}
+
+// ---
+
+func TestSqliteDSN(t *testing.T) {
+	type testCase struct {
+		connString  string
+		expected    string
+		description string
+	}
+	tcs := []testCase{
+		{
+			connString:  "file::memory:",
+			expected:    "file::memory:?_fk=on",
+			description: "in memory",
+		},
+		{
+			connString:  "/var/lib/rewind/rewind.db",
+			expected:    "/var/lib/rewind/rewind.db?_fk=on&_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate",
+			description: "path",
+		},
+		{
+			connString:  "file:rewind.db?cache=shared&_timeout=100",
+			expected:    "file:rewind.db?cache=shared&_timeout=100&_fk=on&_journal_mode=WAL&_txlock=immediate",
+			description: "DSN with parameters",
+		},
+		{
+			connString:  "file:rewind.db?_foreign_keys=off&_journal=DELETE&_busy_timeout=1&_txlock=deferred",
+			expected:    "file:rewind.db?_foreign_keys=off&_journal=DELETE&_busy_timeout=1&_txlock=deferred",
+			description: "DSN overriding every default",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, sqliteDSN(tc.connString))
+		})
+	}
+}
+
+func TestNewSqliteStore_OnDisk(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "rewind.db")
+
+	// Several processes may start against a new database at once.
+	const starters = 4
+	stores := make([]*sqliteStore, starters)
+	errs := make([]error, starters)
+	var wg sync.WaitGroup
+	for i := range starters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stores[i], errs[i] = NewSqliteStore(path)
+		}()
+	}
+	wg.Wait()
+	for i := range starters {
+		require.NoError(t, errs[i])
+	}
+
+	var journalMode string
+	require.NoError(t, stores[0].db.Get(&journalMode, "PRAGMA journal_mode"))
+	assert.Equal(t, "wal", journalMode)
+
+	_, err := stores[0].CreateComponent(context.Background(), model.TestFullComponent)
+	require.NoError(t, err)
+	for _, store := range stores {
+		require.NoError(t, store.db.Close())
+	}
+
+	store, err := NewSqliteStore(path)
+	require.NoError(t, err)
+	defer store.db.Close()
+	c, err := store.ReadComponent(ctx, model.TestFullComponent.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, model.TestFullComponent.Spec, c.Spec)
+}
+
+func TestNewSqliteStore_InMemoryConcurrent(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+	defer store.db.Close()
+	assert.Equal(t, 1, store.db.Stats().MaxOpenConnections)
+
+	// Requests, ingestion and purging all use the store at once, and must
+	// all see the one in-memory database.
+	const workers = 16
+	const rounds = 10
+	start := make(chan struct{})
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for j := range rounds {
+				c := model.TestFullComponent
+				c.Metadata.Name = fmt.Sprintf("component%d-%d", i, j)
+				if _, errs[i] = store.CreateComponent(ctx, c); errs[i] != nil {
+					return
+				}
+				if _, _, errs[i] = store.ListComponents(ctx, nil, Ordering{}, Pagination{}); errs[i] != nil {
+					return
+				}
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	for i := range workers {
+		require.NoError(t, errs[i])
+	}
+
+	refs, _, err := store.ListComponents(ctx, nil, Ordering{}, Pagination{})
+	require.NoError(t, err)
+	assert.Len(t, refs, workers*rounds)
+}
+
+func TestNewSqliteStore_FillsRelations(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "rewind.db")
+
+	store, err := NewSqliteStore(path)
+	require.NoError(t, err)
+	_, err = store.CreateComponent(ctx, model.TestFullComponent)
+	require.NoError(t, err)
+	expected, err := store.ListRelations(ctx, model.TestFullComponent.EntityRef())
+	require.NoError(t, err)
+	require.NotEmpty(t, expected)
+
+	// A database from before relations were kept has entities but no
+	// relations.
+	_, err = store.db.Exec("DELETE FROM relation")
+	require.NoError(t, err)
+	require.NoError(t, store.db.Close())
+
+	store, err = NewSqliteStore(path)
+	require.NoError(t, err)
+	defer store.db.Close()
+	relations, err := store.ListRelations(ctx, model.TestFullComponent.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, expected, relations)
+}
+
+func TestListDanglingRefs_ManyRelations(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t)
+	team := model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "team"}
+	entity := func(ref model.EntityRef) model.Entity {
+		return model.Entity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       ref.Kind,
+			Metadata:   model.Metadata{Namespace: ref.Namespace, Name: ref.Name},
+		}
+	}
+
+	_, err := store.CreateGroup(ctx, model.Group{Entity: entity(team), Spec: model.GroupSpec{Type: "team"}})
+	require.NoError(t, err)
+	// Every component depends on a database, and only the even ones exist.
+	const n = 500
+	var expected []model.DanglingRef
+	for i := range n {
+		database := model.EntityRef{Kind: model.KindResource, Namespace: "default", Name: fmt.Sprintf("db%03d", i)}
+		if i%2 == 0 {
+			_, err := store.CreateResource(ctx, model.Resource{
+				Entity: entity(database),
+				Spec:   model.ResourceSpec{Type: "database", Owner: team},
+			})
+			require.NoError(t, err)
+		}
+		component := model.EntityRef{Kind: model.KindComponent, Namespace: "default", Name: fmt.Sprintf("component%03d", i)}
+		_, err := store.CreateComponent(ctx, model.Component{
+			Entity: entity(component),
+			Spec: model.ComponentSpec{
+				Type:      model.ComponentTypeService,
+				Lifecycle: model.ComponentLifecycleProduction,
+				Owner:     team,
+				DependsOn: []model.EntityRef{database},
+			},
+		})
+		require.NoError(t, err)
+		if i%2 == 1 {
+			expected = append(expected, model.DanglingRef{EntityRef: component, Owner: team, Field: "spec.dependsOn", TargetRef: database})
+		}
+	}
+
+	refs, err := store.ListDanglingRefs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, expected, refs)
+
+	// Each target is looked up by the entity ref index rather than by a
+	// scan of every entity.
+	rows, err := store.db.Query("EXPLAIN QUERY PLAN " + relationDanglingStatement)
+	require.NoError(t, err)
+	defer rows.Close()
+	var plan []string
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		require.NoError(t, rows.Scan(&id, &parent, &notUsed, &detail))
+		plan = append(plan, detail)
+	}
+	require.NoError(t, rows.Err())
+	assert.True(t, slices.ContainsFunc(plan, func(detail string) bool {
+		return strings.HasPrefix(detail, "SEARCH target USING") && strings.Contains(detail, "entity_ref_idx")
+	}), "plan: %v", plan)
+}
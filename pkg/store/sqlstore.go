@@ -0,0 +1,1947 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// sqlStore implements Store over a SQL database. The statements here are
+// written with ? placeholders and rebound for the database in use, and the
+// dialect handles everything else that differs between databases.
+type sqlStore struct {
+	db      *sqlx.DB
+	dialect dialect
+}
+
+// for testing
+func (s sqlStore) readEntity(ref model.EntityRef) (model.Entity, error) {
+	ctx := context.Background()
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Entity{}, fmt.Errorf("failed to begin transaction for read: %w", err)
+	}
+	defer tx.Commit()
+
+	entity, err := readEntity(ctx, s.dialect, ref, tx)
+	if err != nil {
+		return model.Entity{}, err
+	}
+
+	return entity, nil
+}
+
+var (
+	componentInsertStatement = `INSERT INTO component (entity_id, type, lifecycle, owner, system, subcomponent_of, provides_apis, consumes_apis, depends_on, dependency_of) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	componentSelectStatement = `SELECT type, lifecycle, owner, system, subcomponent_of, provides_apis, consumes_apis, depends_on, dependency_of FROM component WHERE entity_id = ?`
+	componentUpdateStatement = `UPDATE component SET (type, lifecycle, owner, system, subcomponent_of, provides_apis, consumes_apis, depends_on, dependency_of) = (?, ?, ?, ?, ?, ?, ?, ?, ?) WHERE entity_id = ?`
+
+	componentListStatementPrefix = `SELECT entity.kind, entity.namespace, entity.name FROM entity INNER JOIN component ON entity.id = component.entity_id`
+
+	apiInsertStatement = `INSERT INTO api (entity_id, type, lifecycle, owner, system, definition) VALUES (?, ?, ?, ?, ?, ?)`
+	apiSelectStatement = `SELECT type, lifecycle, owner, system, definition FROM api WHERE entity_id = ?`
+	apiUpdateStatement = `UPDATE api SET (type, lifecycle, owner, system, definition) = (?, ?, ?, ?, ?) WHERE entity_id = ?`
+
+	apiListStatementPrefix = `SELECT entity.kind, entity.namespace, entity.name FROM entity INNER JOIN api ON entity.id = api.entity_id`
+
+	userInsertStatement = `INSERT INTO "user" (entity_id, display_name, email, picture, member_of) VALUES (?, ?, ?, ?, ?)`
+	userSelectStatement = `SELECT display_name, email, picture, member_of FROM "user" WHERE entity_id = ?`
+	userUpdateStatement = `UPDATE "user" SET (display_name, email, picture, member_of) = (?, ?, ?, ?) WHERE entity_id = ?`
+
+	userListStatementPrefix = `SELECT entity.kind, entity.namespace, entity.name FROM entity INNER JOIN "user" ON entity.id = "user".entity_id`
+
+	groupInsertStatement = `INSERT INTO grp (entity_id, type, display_name, email, picture, parent, children, members) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	groupSelectStatement = `SELECT type, display_name, email, picture, parent, children, members FROM grp WHERE entity_id = ?`
+	groupUpdateStatement = `UPDATE grp SET (type, display_name, email, picture, parent, children, members) = (?, ?, ?, ?, ?, ?, ?) WHERE entity_id = ?`
+
+	groupListStatementPrefix = `SELECT entity.kind, entity.namespace, entity.name FROM entity INNER JOIN grp ON entity.id = grp.entity_id`
+
+	systemInsertStatement = `INSERT INTO system (entity_id, owner, domain, type) VALUES (?, ?, ?, ?)`
+	systemSelectStatement = `SELECT owner, domain, type FROM system WHERE entity_id = ?`
+	systemUpdateStatement = `UPDATE system SET (owner, domain, type) = (?, ?, ?) WHERE entity_id = ?`
+
+	systemListStatementPrefix = `SELECT entity.kind, entity.namespace, entity.name FROM entity INNER JOIN system ON entity.id = system.entity_id`
+
+	resourceInsertStatement = `INSERT INTO resource (entity_id, type, owner, system, depends_on, dependency_of) VALUES (?, ?, ?, ?, ?, ?)`
+	resourceSelectStatement = `SELECT type, owner, system, depends_on, dependency_of FROM resource WHERE entity_id = ?`
+	resourceUpdateStatement = `UPDATE resource SET (type, owner, system, depends_on, dependency_of) = (?, ?, ?, ?, ?) WHERE entity_id = ?`
+
+	resourceListStatementPrefix = `SELECT entity.kind, entity.namespace, entity.name FROM entity INNER JOIN resource ON entity.id = resource.entity_id`
+
+	domainInsertStatement = `INSERT INTO domain (entity_id, owner, subdomain_of, type) VALUES (?, ?, ?, ?)`
+	domainSelectStatement = `SELECT owner, subdomain_of, type FROM domain WHERE entity_id = ?`
+	domainUpdateStatement = `UPDATE domain SET (owner, subdomain_of, type) = (?, ?, ?) WHERE entity_id = ?`
+
+	domainListStatementPrefix = `SELECT entity.kind, entity.namespace, entity.name FROM entity INNER JOIN domain ON entity.id = domain.entity_id`
+
+	locationInsertStatement = `INSERT INTO location (entity_id, type, target, targets) VALUES (?, ?, ?, ?)`
+	locationSelectStatement = `SELECT type, target, targets FROM location WHERE entity_id = ?`
+	locationUpdateStatement = `UPDATE location SET (type, target, targets) = (?, ?, ?) WHERE entity_id = ?`
+
+	locationListStatementPrefix = `SELECT entity.kind, entity.namespace, entity.name FROM entity INNER JOIN location ON entity.id = location.entity_id`
+
+	templateInsertStatement = `INSERT INTO template (entity_id, type, owner, parameters, steps, output) VALUES (?, ?, ?, ?, ?, ?)`
+	templateSelectStatement = `SELECT type, owner, parameters, steps, output FROM template WHERE entity_id = ?`
+	templateUpdateStatement = `UPDATE template SET (type, owner, parameters, steps, output) = (?, ?, ?, ?, ?) WHERE entity_id = ?`
+
+	templateListStatementPrefix = `SELECT entity.kind, entity.namespace, entity.name FROM entity INNER JOIN template ON entity.id = template.entity_id`
+)
+
+// kindListStatementPrefixes are the list statement prefixes that join each
+// kind's own table, so that spec fields can be filtered on.
+var kindListStatementPrefixes = map[string]string{
+	model.KindComponent: componentListStatementPrefix,
+	model.KindAPI:       apiListStatementPrefix,
+	model.KindUser:      userListStatementPrefix,
+	model.KindGroup:     groupListStatementPrefix,
+	model.KindSystem:    systemListStatementPrefix,
+	model.KindResource:  resourceListStatementPrefix,
+	model.KindDomain:    domainListStatementPrefix,
+	model.KindLocation:  locationListStatementPrefix,
+	model.KindTemplate:  templateListStatementPrefix,
+}
+
+func (s sqlStore) ListEntities(ctx context.Context, kinds []string, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	listStatementPrefix := entityListStatementPrefix
+	if len(kinds) == 1 {
+		if prefix, ok := kindListStatementPrefixes[kinds[0]]; ok {
+			listStatementPrefix = prefix
+		}
+	}
+	return listEntities(ctx, s.dialect, s.db, listStatementPrefix, kinds, filters, ordering, pagination)
+}
+
+func (s sqlStore) SearchEntities(ctx context.Context, query string, pagination Pagination) ([]SearchMatch, Pagination, error) {
+	return s.dialect.searchEntities(ctx, s.db, query, pagination)
+}
+
+func (s sqlStore) ResolveUID(ctx context.Context, uid string) (model.EntityRef, error) {
+	return resolveUID(ctx, s.db, uid)
+}
+
+func (s sqlStore) ListRevisions(ctx context.Context, ref model.EntityRef, pagination Pagination) ([]model.Revision, Pagination, error) {
+	return listRevisions(ctx, s.db, ref, pagination)
+}
+
+func (s sqlStore) ReadRevision(ctx context.Context, ref model.EntityRef, version int64) (model.Revision, error) {
+	return readRevision(ctx, s.db, ref, version)
+}
+
+func (s sqlStore) RestoreEntity(ctx context.Context, ref model.EntityRef) (err error) {
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for restore: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := restoreEntity(ctx, s.dialect, ref, tx)
+	if err != nil {
+		return err
+	}
+	value, err := s.readKind(ctx, entity, tx)
+	if err != nil {
+		return err
+	}
+
+	if err = writeRelations(ctx, tx, entity, value); err != nil {
+		return err
+	}
+	if err = recordRevision(ctx, tx, model.OperationRestore, entity, value); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction for restore: %w", err)
+	}
+	return nil
+}
+
+// readKind reads the rest of an entity of whatever kind it is, once its
+// entity has been read.
+func (s sqlStore) readKind(ctx context.Context, entity model.Entity, tx *sqlx.Tx) (any, error) {
+	switch entity.Kind {
+	case model.KindComponent:
+		return s.readComponent(ctx, entity, tx)
+	case model.KindAPI:
+		return s.readAPI(ctx, entity, tx)
+	case model.KindUser:
+		return s.readUser(ctx, entity, tx)
+	case model.KindGroup:
+		return s.readGroup(ctx, entity, tx)
+	case model.KindSystem:
+		return s.readSystem(ctx, entity, tx)
+	case model.KindResource:
+		return s.readResource(ctx, entity, tx)
+	case model.KindDomain:
+		return s.readDomain(ctx, entity, tx)
+	case model.KindLocation:
+		return s.readLocation(ctx, entity, tx)
+	case model.KindTemplate:
+		return s.readTemplate(ctx, entity, tx)
+	default:
+		return nil, fmt.Errorf("%w: unsupported kind %s", ErrInvalid, entity.Kind)
+	}
+}
+
+func (s sqlStore) ListDeletedEntities(ctx context.Context, kinds []string, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return listDeletedEntities(ctx, s.db, kinds, pagination)
+}
+
+func (s sqlStore) PurgeEntities(ctx context.Context, before time.Time) (int64, error) {
+	return purgeEntities(ctx, s.db, before)
+}
+
+func (s sqlStore) CreateComponent(ctx context.Context, c model.Component) (rc model.Component, err error) {
+	if err = validateEntity(c.Entity, model.KindComponent); err != nil {
+		return model.Component{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Component{}, fmt.Errorf("failed to begin transaction for create: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := createEntity(ctx, s.dialect, c.Entity, tx)
+	if err != nil {
+		return model.Component{}, err
+	}
+
+	rc = c
+	rc.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(componentInsertStatement),
+		entity.ID,
+		c.Spec.Type,
+		c.Spec.Lifecycle,
+		c.Spec.Owner,
+		c.Spec.System,
+		c.Spec.SubcomponentOf,
+		s.entityRefsValue(c.Spec.ProvidesAPIs),
+		s.entityRefsValue(c.Spec.ConsumesAPIs),
+		s.entityRefsValue(c.Spec.DependsOn),
+		s.entityRefsValue(c.Spec.DependencyOf),
+	)
+	if err != nil {
+		return model.Component{}, fmt.Errorf("failed to create component: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, rc.Entity, rc); err != nil {
+		return model.Component{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationCreate, rc.Entity, rc); err != nil {
+		return model.Component{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.Component{}, fmt.Errorf("failed to commit transaction for create: %w", err)
+	}
+	return rc, nil
+}
+
+func (s sqlStore) ReadComponent(ctx context.Context, ref model.EntityRef) (c model.Component, err error) {
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Component{}, fmt.Errorf("failed to begin transaction for read: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := readEntity(ctx, s.dialect, ref, tx)
+	if err != nil {
+		return model.Component{}, err
+	}
+
+	c, err = s.readComponent(ctx, entity, tx)
+	if err != nil {
+		return model.Component{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return model.Component{}, fmt.Errorf("failed to commit transaction for read: %w", err)
+	}
+	return c, nil
+}
+
+// readComponent reads the rest of a component whose entity has been read.
+func (s sqlStore) readComponent(ctx context.Context, entity model.Entity, tx *sqlx.Tx) (c model.Component, err error) {
+	c = model.Component{
+		Entity: entity,
+	}
+
+	rows, err := tx.QueryxContext(ctx, tx.Rebind(componentSelectStatement), entity.ID)
+	if err != nil {
+		return model.Component{}, fmt.Errorf("failed to query for component: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var componentType string
+		var lifecycle string
+		var owner model.EntityRef
+		var system model.EntityRef
+		var subcomponentOf model.EntityRef
+		providesAPIs := s.newEntityRefs()
+		consumesAPIs := s.newEntityRefs()
+		dependsOn := s.newEntityRefs()
+		dependencyOf := s.newEntityRefs()
+
+		err = rows.Scan(&componentType, &lifecycle, &owner, &system, &subcomponentOf, providesAPIs, consumesAPIs, dependsOn, dependencyOf)
+		if err != nil {
+			return model.Component{}, fmt.Errorf("failed to scan columns for component: %w", err)
+		}
+		c.Spec = model.ComponentSpec{
+			Type:           componentType,
+			Lifecycle:      lifecycle,
+			Owner:          owner,
+			System:         system,
+			SubcomponentOf: subcomponentOf,
+			ProvidesAPIs:   providesAPIs.Items(),
+			ConsumesAPIs:   consumesAPIs.Items(),
+			DependsOn:      dependsOn.Items(),
+			DependencyOf:   dependencyOf.Items(),
+		}
+	}
+
+	return c, nil
+}
+
+func (s sqlStore) UpdateComponent(ctx context.Context, c model.Component) (rc model.Component, err error) {
+	if err = validateEntity(c.Entity, model.KindComponent); err != nil {
+		return model.Component{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Component{}, fmt.Errorf("failed to begin transaction for update: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := updateEntity(ctx, s.dialect, c.Entity, tx)
+	if err != nil {
+		return model.Component{}, err
+	}
+
+	rc = c
+	rc.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(componentUpdateStatement),
+		c.Spec.Type,
+		c.Spec.Lifecycle,
+		c.Spec.Owner,
+		c.Spec.System,
+		c.Spec.SubcomponentOf,
+		s.entityRefsValue(c.Spec.ProvidesAPIs),
+		s.entityRefsValue(c.Spec.ConsumesAPIs),
+		s.entityRefsValue(c.Spec.DependsOn),
+		s.entityRefsValue(c.Spec.DependencyOf),
+		entity.ID,
+	)
+	if err != nil {
+		return model.Component{}, fmt.Errorf("failed to update component: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, rc.Entity, rc); err != nil {
+		return model.Component{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationUpdate, rc.Entity, rc); err != nil {
+		return model.Component{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.Component{}, fmt.Errorf("failed to commit transaction for update: %w", err)
+	}
+	return rc, nil
+}
+
+func (s sqlStore) DeleteComponent(ctx context.Context, ref model.EntityRef, version int64) (model.Component, error) {
+	component, err := s.ReadComponent(ctx, ref)
+	if err != nil {
+		return model.Component{}, err
+	}
+
+	err = deleteEntity(ctx, s.dialect, component.Entity, version, s.db)
+	if err != nil {
+		return model.Component{}, fmt.Errorf("failed to delete component: %w", err)
+	}
+
+	return component, nil
+}
+
+func (s sqlStore) ListComponents(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return listEntities(ctx, s.dialect, s.db, componentListStatementPrefix, []string{model.KindComponent}, filters, ordering, pagination)
+}
+
+// ---
+
+func (s sqlStore) CreateAPI(ctx context.Context, a model.API) (ra model.API, err error) {
+	if err = validateEntity(a.Entity, model.KindAPI); err != nil {
+		return model.API{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.API{}, fmt.Errorf("failed to begin transaction for create: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := createEntity(ctx, s.dialect, a.Entity, tx)
+	if err != nil {
+		return model.API{}, err
+	}
+
+	ra = a
+	ra.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(apiInsertStatement),
+		entity.ID,
+		a.Spec.Type,
+		a.Spec.Lifecycle,
+		a.Spec.Owner,
+		a.Spec.System,
+		a.Spec.Definition,
+	)
+	if err != nil {
+		return model.API{}, fmt.Errorf("failed to create API: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, ra.Entity, ra); err != nil {
+		return model.API{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationCreate, ra.Entity, ra); err != nil {
+		return model.API{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.API{}, fmt.Errorf("failed to commit transaction for create: %w", err)
+	}
+	return ra, nil
+}
+
+func (s sqlStore) ReadAPI(ctx context.Context, ref model.EntityRef) (a model.API, err error) {
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.API{}, fmt.Errorf("failed to begin transaction for read: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := readEntity(ctx, s.dialect, ref, tx)
+	if err != nil {
+		return model.API{}, err
+	}
+
+	a, err = s.readAPI(ctx, entity, tx)
+	if err != nil {
+		return model.API{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return model.API{}, fmt.Errorf("failed to commit transaction for read: %w", err)
+	}
+	return a, nil
+}
+
+// readAPI reads the rest of an API whose entity has been read.
+func (s sqlStore) readAPI(ctx context.Context, entity model.Entity, tx *sqlx.Tx) (a model.API, err error) {
+	a = model.API{
+		Entity: entity,
+	}
+
+	rows, err := tx.QueryxContext(ctx, tx.Rebind(apiSelectStatement), entity.ID)
+	if err != nil {
+		return model.API{}, fmt.Errorf("failed to query for API: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var apiType string
+		var lifecycle string
+		var owner model.EntityRef
+		var system model.EntityRef
+		var definition string
+		err = rows.Scan(&apiType, &lifecycle, &owner, &system, &definition)
+		if err != nil {
+			return model.API{}, fmt.Errorf("failed to scan columns for API: %w", err)
+		}
+		a.Spec = model.APISpec{
+			Type:       apiType,
+			Lifecycle:  lifecycle,
+			Owner:      owner,
+			System:     system,
+			Definition: definition,
+		}
+	}
+
+	return a, nil
+}
+
+func (s sqlStore) UpdateAPI(ctx context.Context, a model.API) (ra model.API, err error) {
+	if err = validateEntity(a.Entity, model.KindAPI); err != nil {
+		return model.API{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.API{}, fmt.Errorf("failed to begin transaction for update: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := updateEntity(ctx, s.dialect, a.Entity, tx)
+	if err != nil {
+		return model.API{}, err
+	}
+
+	ra = a
+	ra.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(apiUpdateStatement),
+		a.Spec.Type,
+		a.Spec.Lifecycle,
+		a.Spec.Owner,
+		a.Spec.System,
+		a.Spec.Definition,
+		entity.ID,
+	)
+	if err != nil {
+		return model.API{}, fmt.Errorf("failed to update API: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, ra.Entity, ra); err != nil {
+		return model.API{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationUpdate, ra.Entity, ra); err != nil {
+		return model.API{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.API{}, fmt.Errorf("failed to commit transaction for update: %w", err)
+	}
+	return ra, nil
+}
+
+func (s sqlStore) DeleteAPI(ctx context.Context, ref model.EntityRef, version int64) (model.API, error) {
+	api, err := s.ReadAPI(ctx, ref)
+	if err != nil {
+		return model.API{}, err
+	}
+
+	err = deleteEntity(ctx, s.dialect, api.Entity, version, s.db)
+	if err != nil {
+		return model.API{}, fmt.Errorf("failed to delete API: %w", err)
+	}
+
+	return api, nil
+}
+
+func (s sqlStore) ListAPIs(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return listEntities(ctx, s.dialect, s.db, apiListStatementPrefix, []string{model.KindAPI}, filters, ordering, pagination)
+}
+
+// ---
+
+func (s sqlStore) CreateUser(ctx context.Context, u model.User) (ru model.User, err error) {
+	if err = validateEntity(u.Entity, model.KindUser); err != nil {
+		return model.User{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.User{}, fmt.Errorf("failed to begin transaction for create: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := createEntity(ctx, s.dialect, u.Entity, tx)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	ru = u
+	ru.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(userInsertStatement),
+		entity.ID,
+		u.Spec.Profile.DisplayName,
+		u.Spec.Profile.Email,
+		u.Spec.Profile.Picture,
+		s.entityRefsValue(u.Spec.MemberOf),
+	)
+	if err != nil {
+		return model.User{}, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, ru.Entity, ru); err != nil {
+		return model.User{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationCreate, ru.Entity, ru); err != nil {
+		return model.User{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.User{}, fmt.Errorf("failed to commit transaction for create: %w", err)
+	}
+	return ru, nil
+}
+
+func (s sqlStore) ReadUser(ctx context.Context, ref model.EntityRef) (u model.User, err error) {
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.User{}, fmt.Errorf("failed to begin transaction for read: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := readEntity(ctx, s.dialect, ref, tx)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	u, err = s.readUser(ctx, entity, tx)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return model.User{}, fmt.Errorf("failed to commit transaction for read: %w", err)
+	}
+	return u, nil
+}
+
+// readUser reads the rest of an user whose entity has been read.
+func (s sqlStore) readUser(ctx context.Context, entity model.Entity, tx *sqlx.Tx) (u model.User, err error) {
+	u = model.User{
+		Entity: entity,
+	}
+
+	rows, err := tx.QueryxContext(ctx, tx.Rebind(userSelectStatement), entity.ID)
+	if err != nil {
+		return model.User{}, fmt.Errorf("failed to query for user: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var displayName string
+		var email string
+		var picture string
+		memberOf := s.newEntityRefs()
+		err = rows.Scan(&displayName, &email, &picture, memberOf)
+		if err != nil {
+			return model.User{}, fmt.Errorf("failed to scan columns for user: %w", err)
+		}
+		u.Spec = model.UserSpec{
+			Profile: model.UserProfile{
+				DisplayName: displayName,
+				Email:       email,
+				Picture:     picture,
+			},
+			MemberOf: memberOf.Items(),
+		}
+	}
+
+	return u, nil
+}
+
+func (s sqlStore) UpdateUser(ctx context.Context, u model.User) (ru model.User, err error) {
+	if err = validateEntity(u.Entity, model.KindUser); err != nil {
+		return model.User{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.User{}, fmt.Errorf("failed to begin transaction for update: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := updateEntity(ctx, s.dialect, u.Entity, tx)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	ru = u
+	ru.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(userUpdateStatement),
+		u.Spec.Profile.DisplayName,
+		u.Spec.Profile.Email,
+		u.Spec.Profile.Picture,
+		s.entityRefsValue(u.Spec.MemberOf),
+		entity.ID,
+	)
+	if err != nil {
+		return model.User{}, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, ru.Entity, ru); err != nil {
+		return model.User{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationUpdate, ru.Entity, ru); err != nil {
+		return model.User{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.User{}, fmt.Errorf("failed to commit transaction for update: %w", err)
+	}
+	return ru, nil
+}
+
+func (s sqlStore) DeleteUser(ctx context.Context, ref model.EntityRef, version int64) (model.User, error) {
+	user, err := s.ReadUser(ctx, ref)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	err = deleteEntity(ctx, s.dialect, user.Entity, version, s.db)
+	if err != nil {
+		return model.User{}, fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	return user, nil
+}
+
+func (s sqlStore) ListUsers(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return listEntities(ctx, s.dialect, s.db, userListStatementPrefix, []string{model.KindUser}, filters, ordering, pagination)
+}
+
+// ---
+
+func (s sqlStore) CreateGroup(ctx context.Context, g model.Group) (rg model.Group, err error) {
+	if err = validateEntity(g.Entity, model.KindGroup); err != nil {
+		return model.Group{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Group{}, fmt.Errorf("failed to begin transaction for create: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := createEntity(ctx, s.dialect, g.Entity, tx)
+	if err != nil {
+		return model.Group{}, err
+	}
+
+	rg = g
+	rg.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(groupInsertStatement),
+		entity.ID,
+		g.Spec.Type,
+		g.Spec.Profile.DisplayName,
+		g.Spec.Profile.Email,
+		g.Spec.Profile.Picture,
+		g.Spec.Parent,
+		s.entityRefsValue(g.Spec.Children),
+		s.entityRefsValue(g.Spec.Members),
+	)
+	if err != nil {
+		return model.Group{}, fmt.Errorf("failed to create group: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, rg.Entity, rg); err != nil {
+		return model.Group{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationCreate, rg.Entity, rg); err != nil {
+		return model.Group{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.Group{}, fmt.Errorf("failed to commit transaction for create: %w", err)
+	}
+	return rg, nil
+}
+
+func (s sqlStore) ReadGroup(ctx context.Context, ref model.EntityRef) (g model.Group, err error) {
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Group{}, fmt.Errorf("failed to begin transaction for read: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := readEntity(ctx, s.dialect, ref, tx)
+	if err != nil {
+		return model.Group{}, err
+	}
+
+	g, err = s.readGroup(ctx, entity, tx)
+	if err != nil {
+		return model.Group{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return model.Group{}, fmt.Errorf("failed to commit transaction for read: %w", err)
+	}
+	return g, nil
+}
+
+// readGroup reads the rest of a group whose entity has been read.
+func (s sqlStore) readGroup(ctx context.Context, entity model.Entity, tx *sqlx.Tx) (g model.Group, err error) {
+	g = model.Group{
+		Entity: entity,
+	}
+
+	rows, err := tx.QueryxContext(ctx, tx.Rebind(groupSelectStatement), entity.ID)
+	if err != nil {
+		return model.Group{}, fmt.Errorf("failed to query for group: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var groupType string
+		var displayName string
+		var email string
+		var picture string
+		var parent model.EntityRef
+		children := s.newEntityRefs()
+		members := s.newEntityRefs()
+		err = rows.Scan(&groupType, &displayName, &email, &picture, &parent, children, members)
+		if err != nil {
+			return model.Group{}, fmt.Errorf("failed to scan columns for group: %w", err)
+		}
+		g.Spec = model.GroupSpec{
+			Type: groupType,
+			Profile: model.GroupProfile{
+				DisplayName: displayName,
+				Email:       email,
+				Picture:     picture,
+			},
+			Parent:   parent,
+			Children: children.Items(),
+			Members:  members.Items(),
+		}
+	}
+
+	return g, nil
+}
+
+func (s sqlStore) UpdateGroup(ctx context.Context, g model.Group) (rg model.Group, err error) {
+	if err = validateEntity(g.Entity, model.KindGroup); err != nil {
+		return model.Group{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Group{}, fmt.Errorf("failed to begin transaction for update: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := updateEntity(ctx, s.dialect, g.Entity, tx)
+	if err != nil {
+		return model.Group{}, err
+	}
+
+	rg = g
+	rg.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(groupUpdateStatement),
+		g.Spec.Type,
+		g.Spec.Profile.DisplayName,
+		g.Spec.Profile.Email,
+		g.Spec.Profile.Picture,
+		g.Spec.Parent,
+		s.entityRefsValue(g.Spec.Children),
+		s.entityRefsValue(g.Spec.Members),
+		entity.ID,
+	)
+	if err != nil {
+		return model.Group{}, fmt.Errorf("failed to update group: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, rg.Entity, rg); err != nil {
+		return model.Group{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationUpdate, rg.Entity, rg); err != nil {
+		return model.Group{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.Group{}, fmt.Errorf("failed to commit transaction for update: %w", err)
+	}
+	return rg, nil
+}
+
+func (s sqlStore) DeleteGroup(ctx context.Context, ref model.EntityRef, version int64) (model.Group, error) {
+	group, err := s.ReadGroup(ctx, ref)
+	if err != nil {
+		return model.Group{}, err
+	}
+
+	err = deleteEntity(ctx, s.dialect, group.Entity, version, s.db)
+	if err != nil {
+		return model.Group{}, fmt.Errorf("failed to delete group: %w", err)
+	}
+
+	return group, nil
+}
+
+func (s sqlStore) ListGroups(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return listEntities(ctx, s.dialect, s.db, groupListStatementPrefix, []string{model.KindGroup}, filters, ordering, pagination)
+}
+
+// ---
+
+func (s sqlStore) CreateSystem(ctx context.Context, sy model.System) (rs model.System, err error) {
+	if err = validateEntity(sy.Entity, model.KindSystem); err != nil {
+		return model.System{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.System{}, fmt.Errorf("failed to begin transaction for create: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := createEntity(ctx, s.dialect, sy.Entity, tx)
+	if err != nil {
+		return model.System{}, err
+	}
+
+	rs = sy
+	rs.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(systemInsertStatement),
+		entity.ID,
+		sy.Spec.Owner,
+		sy.Spec.Domain,
+		nullString(sy.Spec.Type),
+	)
+	if err != nil {
+		return model.System{}, fmt.Errorf("failed to create system: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, rs.Entity, rs); err != nil {
+		return model.System{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationCreate, rs.Entity, rs); err != nil {
+		return model.System{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.System{}, fmt.Errorf("failed to commit transaction for create: %w", err)
+	}
+	return rs, nil
+}
+
+func (s sqlStore) ReadSystem(ctx context.Context, ref model.EntityRef) (sy model.System, err error) {
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.System{}, fmt.Errorf("failed to begin transaction for read: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := readEntity(ctx, s.dialect, ref, tx)
+	if err != nil {
+		return model.System{}, err
+	}
+
+	sy, err = s.readSystem(ctx, entity, tx)
+	if err != nil {
+		return model.System{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return model.System{}, fmt.Errorf("failed to commit transaction for read: %w", err)
+	}
+	return sy, nil
+}
+
+// readSystem reads the rest of a system whose entity has been read.
+func (s sqlStore) readSystem(ctx context.Context, entity model.Entity, tx *sqlx.Tx) (sy model.System, err error) {
+	sy = model.System{
+		Entity: entity,
+	}
+
+	rows, err := tx.QueryxContext(ctx, tx.Rebind(systemSelectStatement), entity.ID)
+	if err != nil {
+		return model.System{}, fmt.Errorf("failed to query for system: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var owner model.EntityRef
+		var domain model.EntityRef
+		var systemType sql.NullString
+		err = rows.Scan(&owner, &domain, &systemType)
+		if err != nil {
+			return model.System{}, fmt.Errorf("failed to scan columns for system: %w", err)
+		}
+		sy.Spec = model.SystemSpec{
+			Owner:  owner,
+			Domain: domain,
+			Type:   fromNullString(systemType),
+		}
+	}
+
+	return sy, nil
+}
+
+func (s sqlStore) UpdateSystem(ctx context.Context, sy model.System) (rs model.System, err error) {
+	if err = validateEntity(sy.Entity, model.KindSystem); err != nil {
+		return model.System{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.System{}, fmt.Errorf("failed to begin transaction for update: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := updateEntity(ctx, s.dialect, sy.Entity, tx)
+	if err != nil {
+		return model.System{}, err
+	}
+
+	rs = sy
+	rs.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(systemUpdateStatement),
+		sy.Spec.Owner,
+		sy.Spec.Domain,
+		nullString(sy.Spec.Type),
+		entity.ID,
+	)
+	if err != nil {
+		return model.System{}, fmt.Errorf("failed to update system: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, rs.Entity, rs); err != nil {
+		return model.System{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationUpdate, rs.Entity, rs); err != nil {
+		return model.System{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.System{}, fmt.Errorf("failed to commit transaction for update: %w", err)
+	}
+	return rs, nil
+}
+
+func (s sqlStore) DeleteSystem(ctx context.Context, ref model.EntityRef, version int64) (model.System, error) {
+	system, err := s.ReadSystem(ctx, ref)
+	if err != nil {
+		return model.System{}, err
+	}
+
+	err = deleteEntity(ctx, s.dialect, system.Entity, version, s.db)
+	if err != nil {
+		return model.System{}, fmt.Errorf("failed to delete system: %w", err)
+	}
+
+	return system, nil
+}
+
+func (s sqlStore) ListSystems(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return listEntities(ctx, s.dialect, s.db, systemListStatementPrefix, []string{model.KindSystem}, filters, ordering, pagination)
+}
+
+// ---
+
+func (s sqlStore) CreateResource(ctx context.Context, r model.Resource) (rr model.Resource, err error) {
+	if err = validateEntity(r.Entity, model.KindResource); err != nil {
+		return model.Resource{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Resource{}, fmt.Errorf("failed to begin transaction for create: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := createEntity(ctx, s.dialect, r.Entity, tx)
+	if err != nil {
+		return model.Resource{}, err
+	}
+
+	rr = r
+	rr.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(resourceInsertStatement),
+		entity.ID,
+		r.Spec.Type,
+		r.Spec.Owner,
+		r.Spec.System,
+		s.entityRefsValue(r.Spec.DependsOn),
+		s.entityRefsValue(r.Spec.DependencyOf),
+	)
+	if err != nil {
+		return model.Resource{}, fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, rr.Entity, rr); err != nil {
+		return model.Resource{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationCreate, rr.Entity, rr); err != nil {
+		return model.Resource{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.Resource{}, fmt.Errorf("failed to commit transaction for create: %w", err)
+	}
+	return rr, nil
+}
+
+func (s sqlStore) ReadResource(ctx context.Context, ref model.EntityRef) (r model.Resource, err error) {
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Resource{}, fmt.Errorf("failed to begin transaction for read: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := readEntity(ctx, s.dialect, ref, tx)
+	if err != nil {
+		return model.Resource{}, err
+	}
+
+	r, err = s.readResource(ctx, entity, tx)
+	if err != nil {
+		return model.Resource{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return model.Resource{}, fmt.Errorf("failed to commit transaction for read: %w", err)
+	}
+	return r, nil
+}
+
+// readResource reads the rest of a resource whose entity has been read.
+func (s sqlStore) readResource(ctx context.Context, entity model.Entity, tx *sqlx.Tx) (r model.Resource, err error) {
+	r = model.Resource{
+		Entity: entity,
+	}
+
+	rows, err := tx.QueryxContext(ctx, tx.Rebind(resourceSelectStatement), entity.ID)
+	if err != nil {
+		return model.Resource{}, fmt.Errorf("failed to query for resource: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var resourceType string
+		var owner model.EntityRef
+		var system model.EntityRef
+		dependsOn := s.newEntityRefs()
+		dependencyOf := s.newEntityRefs()
+		err = rows.Scan(&resourceType, &owner, &system, dependsOn, dependencyOf)
+		if err != nil {
+			return model.Resource{}, fmt.Errorf("failed to scan columns for resource: %w", err)
+		}
+		r.Spec = model.ResourceSpec{
+			Type:         resourceType,
+			Owner:        owner,
+			System:       system,
+			DependsOn:    dependsOn.Items(),
+			DependencyOf: dependencyOf.Items(),
+		}
+	}
+
+	return r, nil
+}
+
+func (s sqlStore) UpdateResource(ctx context.Context, r model.Resource) (rr model.Resource, err error) {
+	if err = validateEntity(r.Entity, model.KindResource); err != nil {
+		return model.Resource{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Resource{}, fmt.Errorf("failed to begin transaction for update: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := updateEntity(ctx, s.dialect, r.Entity, tx)
+	if err != nil {
+		return model.Resource{}, err
+	}
+
+	rr = r
+	rr.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(resourceUpdateStatement),
+		r.Spec.Type,
+		r.Spec.Owner,
+		r.Spec.System,
+		s.entityRefsValue(r.Spec.DependsOn),
+		s.entityRefsValue(r.Spec.DependencyOf),
+		entity.ID,
+	)
+	if err != nil {
+		return model.Resource{}, fmt.Errorf("failed to update resource: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, rr.Entity, rr); err != nil {
+		return model.Resource{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationUpdate, rr.Entity, rr); err != nil {
+		return model.Resource{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.Resource{}, fmt.Errorf("failed to commit transaction for update: %w", err)
+	}
+	return rr, nil
+}
+
+func (s sqlStore) DeleteResource(ctx context.Context, ref model.EntityRef, version int64) (model.Resource, error) {
+	resource, err := s.ReadResource(ctx, ref)
+	if err != nil {
+		return model.Resource{}, err
+	}
+
+	err = deleteEntity(ctx, s.dialect, resource.Entity, version, s.db)
+	if err != nil {
+		return model.Resource{}, fmt.Errorf("failed to delete resource: %w", err)
+	}
+
+	return resource, nil
+}
+
+func (s sqlStore) ListResources(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return listEntities(ctx, s.dialect, s.db, resourceListStatementPrefix, []string{model.KindResource}, filters, ordering, pagination)
+}
+
+// ---
+
+func (s sqlStore) CreateDomain(ctx context.Context, d model.Domain) (rd model.Domain, err error) {
+	if err = validateEntity(d.Entity, model.KindDomain); err != nil {
+		return model.Domain{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Domain{}, fmt.Errorf("failed to begin transaction for create: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := createEntity(ctx, s.dialect, d.Entity, tx)
+	if err != nil {
+		return model.Domain{}, err
+	}
+
+	rd = d
+	rd.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(domainInsertStatement),
+		entity.ID,
+		d.Spec.Owner,
+		d.Spec.SubdomainOf,
+		nullString(d.Spec.Type),
+	)
+	if err != nil {
+		return model.Domain{}, fmt.Errorf("failed to create domain: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, rd.Entity, rd); err != nil {
+		return model.Domain{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationCreate, rd.Entity, rd); err != nil {
+		return model.Domain{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.Domain{}, fmt.Errorf("failed to commit transaction for create: %w", err)
+	}
+	return rd, nil
+}
+
+func (s sqlStore) ReadDomain(ctx context.Context, ref model.EntityRef) (d model.Domain, err error) {
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Domain{}, fmt.Errorf("failed to begin transaction for read: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := readEntity(ctx, s.dialect, ref, tx)
+	if err != nil {
+		return model.Domain{}, err
+	}
+
+	d, err = s.readDomain(ctx, entity, tx)
+	if err != nil {
+		return model.Domain{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return model.Domain{}, fmt.Errorf("failed to commit transaction for read: %w", err)
+	}
+	return d, nil
+}
+
+// readDomain reads the rest of a domain whose entity has been read.
+func (s sqlStore) readDomain(ctx context.Context, entity model.Entity, tx *sqlx.Tx) (d model.Domain, err error) {
+	d = model.Domain{
+		Entity: entity,
+	}
+
+	rows, err := tx.QueryxContext(ctx, tx.Rebind(domainSelectStatement), entity.ID)
+	if err != nil {
+		return model.Domain{}, fmt.Errorf("failed to query for domain: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var owner model.EntityRef
+		var subdomainOf model.EntityRef
+		var domainType sql.NullString
+		err = rows.Scan(&owner, &subdomainOf, &domainType)
+		if err != nil {
+			return model.Domain{}, fmt.Errorf("failed to scan columns for domain: %w", err)
+		}
+		d.Spec = model.DomainSpec{
+			Owner:       owner,
+			SubdomainOf: subdomainOf,
+			Type:        fromNullString(domainType),
+		}
+	}
+
+	return d, nil
+}
+
+func (s sqlStore) UpdateDomain(ctx context.Context, d model.Domain) (rd model.Domain, err error) {
+	if err = validateEntity(d.Entity, model.KindDomain); err != nil {
+		return model.Domain{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Domain{}, fmt.Errorf("failed to begin transaction for update: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := updateEntity(ctx, s.dialect, d.Entity, tx)
+	if err != nil {
+		return model.Domain{}, err
+	}
+
+	rd = d
+	rd.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(domainUpdateStatement),
+		d.Spec.Owner,
+		d.Spec.SubdomainOf,
+		nullString(d.Spec.Type),
+		entity.ID,
+	)
+	if err != nil {
+		return model.Domain{}, fmt.Errorf("failed to update domain: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, rd.Entity, rd); err != nil {
+		return model.Domain{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationUpdate, rd.Entity, rd); err != nil {
+		return model.Domain{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.Domain{}, fmt.Errorf("failed to commit transaction for update: %w", err)
+	}
+	return rd, nil
+}
+
+func (s sqlStore) DeleteDomain(ctx context.Context, ref model.EntityRef, version int64) (model.Domain, error) {
+	domain, err := s.ReadDomain(ctx, ref)
+	if err != nil {
+		return model.Domain{}, err
+	}
+
+	err = deleteEntity(ctx, s.dialect, domain.Entity, version, s.db)
+	if err != nil {
+		return model.Domain{}, fmt.Errorf("failed to delete domain: %w", err)
+	}
+
+	return domain, nil
+}
+
+func (s sqlStore) ListDomains(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return listEntities(ctx, s.dialect, s.db, domainListStatementPrefix, []string{model.KindDomain}, filters, ordering, pagination)
+}
+
+// ---
+
+func (s sqlStore) CreateLocation(ctx context.Context, l model.Location) (rl model.Location, err error) {
+	if err = validateEntity(l.Entity, model.KindLocation); err != nil {
+		return model.Location{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Location{}, fmt.Errorf("failed to begin transaction for create: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := createEntity(ctx, s.dialect, l.Entity, tx)
+	if err != nil {
+		return model.Location{}, err
+	}
+
+	rl = l
+	rl.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(locationInsertStatement),
+		entity.ID,
+		nullString(l.Spec.Type),
+		nullString(l.Spec.Target),
+		s.dialect.listValue(l.Spec.Targets, "\n"),
+	)
+	if err != nil {
+		return model.Location{}, fmt.Errorf("failed to create location: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, rl.Entity, rl); err != nil {
+		return model.Location{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationCreate, rl.Entity, rl); err != nil {
+		return model.Location{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.Location{}, fmt.Errorf("failed to commit transaction for create: %w", err)
+	}
+	return rl, nil
+}
+
+func (s sqlStore) ReadLocation(ctx context.Context, ref model.EntityRef) (l model.Location, err error) {
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Location{}, fmt.Errorf("failed to begin transaction for read: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := readEntity(ctx, s.dialect, ref, tx)
+	if err != nil {
+		return model.Location{}, err
+	}
+
+	l, err = s.readLocation(ctx, entity, tx)
+	if err != nil {
+		return model.Location{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return model.Location{}, fmt.Errorf("failed to commit transaction for read: %w", err)
+	}
+	return l, nil
+}
+
+// readLocation reads the rest of a location whose entity has been read.
+func (s sqlStore) readLocation(ctx context.Context, entity model.Entity, tx *sqlx.Tx) (l model.Location, err error) {
+	l = model.Location{
+		Entity: entity,
+	}
+
+	rows, err := tx.QueryxContext(ctx, tx.Rebind(locationSelectStatement), entity.ID)
+	if err != nil {
+		return model.Location{}, fmt.Errorf("failed to query for location: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var locationType sql.NullString
+		var target sql.NullString
+		targets := s.dialect.newList("\n")
+		err = rows.Scan(&locationType, &target, targets)
+		if err != nil {
+			return model.Location{}, fmt.Errorf("failed to scan columns for location: %w", err)
+		}
+		l.Spec = model.LocationSpec{
+			Type:   fromNullString(locationType),
+			Target: fromNullString(target),
+		}
+		if items := targets.Items(); len(items) > 0 {
+			l.Spec.Targets = items
+		}
+	}
+
+	return l, nil
+}
+
+func (s sqlStore) UpdateLocation(ctx context.Context, l model.Location) (rl model.Location, err error) {
+	if err = validateEntity(l.Entity, model.KindLocation); err != nil {
+		return model.Location{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Location{}, fmt.Errorf("failed to begin transaction for update: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := updateEntity(ctx, s.dialect, l.Entity, tx)
+	if err != nil {
+		return model.Location{}, err
+	}
+
+	rl = l
+	rl.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(locationUpdateStatement),
+		nullString(l.Spec.Type),
+		nullString(l.Spec.Target),
+		s.dialect.listValue(l.Spec.Targets, "\n"),
+		entity.ID,
+	)
+	if err != nil {
+		return model.Location{}, fmt.Errorf("failed to update location: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, rl.Entity, rl); err != nil {
+		return model.Location{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationUpdate, rl.Entity, rl); err != nil {
+		return model.Location{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.Location{}, fmt.Errorf("failed to commit transaction for update: %w", err)
+	}
+	return rl, nil
+}
+
+func (s sqlStore) DeleteLocation(ctx context.Context, ref model.EntityRef, version int64) (model.Location, error) {
+	location, err := s.ReadLocation(ctx, ref)
+	if err != nil {
+		return model.Location{}, err
+	}
+
+	err = deleteEntity(ctx, s.dialect, location.Entity, version, s.db)
+	if err != nil {
+		return model.Location{}, fmt.Errorf("failed to delete location: %w", err)
+	}
+
+	return location, nil
+}
+
+func (s sqlStore) ListLocations(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return listEntities(ctx, s.dialect, s.db, locationListStatementPrefix, []string{model.KindLocation}, filters, ordering, pagination)
+}
+
+// ---
+
+func templateYAMLColumns(t model.Template) (parameters sql.NullString, steps sql.NullString, output sql.NullString, err error) {
+	if parameters, err = yamlString(t.Spec.Parameters); err != nil {
+		return parameters, steps, output, fmt.Errorf("%w: failed to marshal template parameters: %s", ErrInvalid, err)
+	}
+	if len(t.Spec.Steps) > 0 {
+		if steps, err = yamlString(t.Spec.Steps); err != nil {
+			return parameters, steps, output, fmt.Errorf("%w: failed to marshal template steps: %s", ErrInvalid, err)
+		}
+	}
+	if len(t.Spec.Output) > 0 {
+		if output, err = yamlString(t.Spec.Output); err != nil {
+			return parameters, steps, output, fmt.Errorf("%w: failed to marshal template output: %s", ErrInvalid, err)
+		}
+	}
+	return parameters, steps, output, nil
+}
+
+func (s sqlStore) CreateTemplate(ctx context.Context, t model.Template) (rt model.Template, err error) {
+	if err = validateEntity(t.Entity, model.KindTemplate); err != nil {
+		return model.Template{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Template{}, fmt.Errorf("failed to begin transaction for create: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	parameters, steps, output, err := templateYAMLColumns(t)
+	if err != nil {
+		return model.Template{}, err
+	}
+
+	entity, err := createEntity(ctx, s.dialect, t.Entity, tx)
+	if err != nil {
+		return model.Template{}, err
+	}
+
+	rt = t
+	rt.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(templateInsertStatement),
+		entity.ID,
+		t.Spec.Type,
+		t.Spec.Owner,
+		parameters,
+		steps,
+		output,
+	)
+	if err != nil {
+		return model.Template{}, fmt.Errorf("failed to create template: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, rt.Entity, rt); err != nil {
+		return model.Template{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationCreate, rt.Entity, rt); err != nil {
+		return model.Template{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.Template{}, fmt.Errorf("failed to commit transaction for create: %w", err)
+	}
+	return rt, nil
+}
+
+func (s sqlStore) ReadTemplate(ctx context.Context, ref model.EntityRef) (t model.Template, err error) {
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Template{}, fmt.Errorf("failed to begin transaction for read: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	entity, err := readEntity(ctx, s.dialect, ref, tx)
+	if err != nil {
+		return model.Template{}, err
+	}
+
+	t, err = s.readTemplate(ctx, entity, tx)
+	if err != nil {
+		return model.Template{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return model.Template{}, fmt.Errorf("failed to commit transaction for read: %w", err)
+	}
+	return t, nil
+}
+
+// readTemplate reads the rest of a template whose entity has been read.
+func (s sqlStore) readTemplate(ctx context.Context, entity model.Entity, tx *sqlx.Tx) (t model.Template, err error) {
+	t = model.Template{
+		Entity: entity,
+	}
+
+	rows, err := tx.QueryxContext(ctx, tx.Rebind(templateSelectStatement), entity.ID)
+	if err != nil {
+		return model.Template{}, fmt.Errorf("failed to query for template: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var templateType string
+		var owner model.EntityRef
+		var parameters sql.NullString
+		var steps sql.NullString
+		var output sql.NullString
+		err = rows.Scan(&templateType, &owner, &parameters, &steps, &output)
+		if err != nil {
+			return model.Template{}, fmt.Errorf("failed to scan columns for template: %w", err)
+		}
+		t.Spec = model.TemplateSpec{
+			Type:  templateType,
+			Owner: owner,
+		}
+		if err = fromYAMLString(parameters, &t.Spec.Parameters); err != nil {
+			return model.Template{}, fmt.Errorf("failed to unmarshal template parameters: %w", err)
+		}
+		if err = fromYAMLString(steps, &t.Spec.Steps); err != nil {
+			return model.Template{}, fmt.Errorf("failed to unmarshal template steps: %w", err)
+		}
+		if err = fromYAMLString(output, &t.Spec.Output); err != nil {
+			return model.Template{}, fmt.Errorf("failed to unmarshal template output: %w", err)
+		}
+	}
+
+	return t, nil
+}
+
+func (s sqlStore) UpdateTemplate(ctx context.Context, t model.Template) (rt model.Template, err error) {
+	if err = validateEntity(t.Entity, model.KindTemplate); err != nil {
+		return model.Template{}, err
+	}
+
+	var tx *sqlx.Tx
+	tx, err = s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Template{}, fmt.Errorf("failed to begin transaction for update: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rerr := tx.Rollback()
+			if rerr != nil {
+				err = fmt.Errorf("failed to rollback transaction (%s): %w", rerr, err)
+			}
+		}
+	}()
+
+	parameters, steps, output, err := templateYAMLColumns(t)
+	if err != nil {
+		return model.Template{}, err
+	}
+
+	entity, err := updateEntity(ctx, s.dialect, t.Entity, tx)
+	if err != nil {
+		return model.Template{}, err
+	}
+
+	rt = t
+	rt.Entity = entity
+
+	_, err = tx.ExecContext(
+		ctx,
+		tx.Rebind(templateUpdateStatement),
+		t.Spec.Type,
+		t.Spec.Owner,
+		parameters,
+		steps,
+		output,
+		entity.ID,
+	)
+	if err != nil {
+		return model.Template{}, fmt.Errorf("failed to update template: %w", err)
+	}
+
+	if err = writeRelations(ctx, tx, rt.Entity, rt); err != nil {
+		return model.Template{}, err
+	}
+	if err = recordRevision(ctx, tx, model.OperationUpdate, rt.Entity, rt); err != nil {
+		return model.Template{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return model.Template{}, fmt.Errorf("failed to commit transaction for update: %w", err)
+	}
+	return rt, nil
+}
+
+func (s sqlStore) DeleteTemplate(ctx context.Context, ref model.EntityRef, version int64) (model.Template, error) {
+	template, err := s.ReadTemplate(ctx, ref)
+	if err != nil {
+		return model.Template{}, err
+	}
+
+	err = deleteEntity(ctx, s.dialect, template.Entity, version, s.db)
+	if err != nil {
+		return model.Template{}, fmt.Errorf("failed to delete template: %w", err)
+	}
+
+	return template, nil
+}
+
+func (s sqlStore) ListTemplates(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return listEntities(ctx, s.dialect, s.db, templateListStatementPrefix, []string{model.KindTemplate}, filters, ordering, pagination)
+}
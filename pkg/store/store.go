@@ -0,0 +1,236 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bhavanki/rewind/pkg/model"
+)
+
+//go:generate moq -out store_mock.go . Store
+
+// Store keeps the entities of a catalog. Every implementation must pass the
+// conformance suite in the storetest package, and returns the context's error
+// once the context of an operation is done.
+type Store interface {
+	ListEntities(ctx context.Context, kinds []string, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+	SearchEntities(ctx context.Context, query string, pagination Pagination) ([]SearchMatch, Pagination, error)
+	// ResolveUID finds the current ref of the live entity with a UID.
+	ResolveUID(ctx context.Context, uid string) (model.EntityRef, error)
+
+	// ListRevisions lists the history of the entity with a ref, or of the
+	// entity that last had it, newest first. Each change is recorded with
+	// the actor named in its context by WithActor.
+	ListRevisions(ctx context.Context, ref model.EntityRef, pagination Pagination) ([]model.Revision, Pagination, error)
+	ReadRevision(ctx context.Context, ref model.EntityRef, version int64) (model.Revision, error)
+
+	// RestoreEntity brings back, as a new version, the entity most recently
+	// deleted with a ref. Deleted entities are kept as tombstones, which
+	// other operations do not see, until PurgeEntities removes those older
+	// than a time.
+	RestoreEntity(ctx context.Context, ref model.EntityRef) error
+	ListDeletedEntities(ctx context.Context, kinds []string, pagination Pagination) ([]model.EntityRef, Pagination, error)
+	PurgeEntities(ctx context.Context, before time.Time) (int64, error)
+
+	// ListRelations lists the relations that the spec of an entity makes,
+	// and the inverses of those that other specs make to it.
+	ListRelations(ctx context.Context, ref model.EntityRef) ([]model.Relation, error)
+	// ListReferrers lists the live entities whose specs refer to a ref,
+	// whether or not an entity has it.
+	ListReferrers(ctx context.Context, ref model.EntityRef) ([]model.Referrer, error)
+	// ListDanglingRefs lists the refs in live specs that name no live entity.
+	ListDanglingRefs(ctx context.Context) ([]model.DanglingRef, error)
+	// ListSpecRefs lists the refs in the given spec fields of live entities,
+	// or in all of them if no fields are given.
+	ListSpecRefs(ctx context.Context, fields []string) ([]model.EntitySpecRef, error)
+	// ListExistingRefs returns those of a set of refs that name live
+	// entities, looking them all up at once.
+	ListExistingRefs(ctx context.Context, refs []model.EntityRef) ([]model.EntityRef, error)
+
+	// Updates of entities with a non-zero version, and deletes with a
+	// non-zero version argument, fail with ErrVersionMismatch unless the
+	// stored entity is at that version.
+	CreateComponent(ctx context.Context, c model.Component) (model.Component, error)
+	ReadComponent(ctx context.Context, ref model.EntityRef) (model.Component, error)
+	UpdateComponent(ctx context.Context, c model.Component) (model.Component, error)
+	DeleteComponent(ctx context.Context, ref model.EntityRef, version int64) (model.Component, error)
+	ListComponents(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	CreateAPI(ctx context.Context, a model.API) (model.API, error)
+	ReadAPI(ctx context.Context, ref model.EntityRef) (model.API, error)
+	UpdateAPI(ctx context.Context, a model.API) (model.API, error)
+	DeleteAPI(ctx context.Context, ref model.EntityRef, version int64) (model.API, error)
+	ListAPIs(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	CreateUser(ctx context.Context, u model.User) (model.User, error)
+	ReadUser(ctx context.Context, ref model.EntityRef) (model.User, error)
+	UpdateUser(ctx context.Context, u model.User) (model.User, error)
+	DeleteUser(ctx context.Context, ref model.EntityRef, version int64) (model.User, error)
+	ListUsers(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	CreateGroup(ctx context.Context, g model.Group) (model.Group, error)
+	ReadGroup(ctx context.Context, ref model.EntityRef) (model.Group, error)
+	UpdateGroup(ctx context.Context, g model.Group) (model.Group, error)
+	DeleteGroup(ctx context.Context, ref model.EntityRef, version int64) (model.Group, error)
+	ListGroups(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	CreateSystem(ctx context.Context, s model.System) (model.System, error)
+	ReadSystem(ctx context.Context, ref model.EntityRef) (model.System, error)
+	UpdateSystem(ctx context.Context, s model.System) (model.System, error)
+	DeleteSystem(ctx context.Context, ref model.EntityRef, version int64) (model.System, error)
+	ListSystems(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	CreateResource(ctx context.Context, r model.Resource) (model.Resource, error)
+	ReadResource(ctx context.Context, ref model.EntityRef) (model.Resource, error)
+	UpdateResource(ctx context.Context, r model.Resource) (model.Resource, error)
+	DeleteResource(ctx context.Context, ref model.EntityRef, version int64) (model.Resource, error)
+	ListResources(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	CreateDomain(ctx context.Context, d model.Domain) (model.Domain, error)
+	ReadDomain(ctx context.Context, ref model.EntityRef) (model.Domain, error)
+	UpdateDomain(ctx context.Context, d model.Domain) (model.Domain, error)
+	DeleteDomain(ctx context.Context, ref model.EntityRef, version int64) (model.Domain, error)
+	ListDomains(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	CreateLocation(ctx context.Context, l model.Location) (model.Location, error)
+	ReadLocation(ctx context.Context, ref model.EntityRef) (model.Location, error)
+	UpdateLocation(ctx context.Context, l model.Location) (model.Location, error)
+	DeleteLocation(ctx context.Context, ref model.EntityRef, version int64) (model.Location, error)
+	ListLocations(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	CreateTemplate(ctx context.Context, t model.Template) (model.Template, error)
+	ReadTemplate(ctx context.Context, ref model.EntityRef) (model.Template, error)
+	UpdateTemplate(ctx context.Context, t model.Template) (model.Template, error)
+	DeleteTemplate(ctx context.Context, ref model.EntityRef, version int64) (model.Template, error)
+	ListTemplates(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+}
+
+// FilterOperator is the comparison that a filter makes. The zero value
+// compares for equality.
+type FilterOperator string
+
+const (
+	FilterEquals    = FilterOperator("")
+	FilterNotEquals = FilterOperator("!=")
+	FilterIn        = FilterOperator("in")
+	FilterNotIn     = FilterOperator("notin")
+	FilterExists    = FilterOperator("exists")
+	FilterNotExists = FilterOperator("!exists")
+	FilterPrefix    = FilterOperator("prefix")
+)
+
+const (
+	// LabelFilterKeyPrefix prefixes the keys of filters on metadata labels,
+	// e.g., "metadata.labels.tier".
+	LabelFilterKeyPrefix = "metadata.labels."
+	// AnnotationFilterKeyPrefix prefixes the keys of filters on metadata
+	// annotations, e.g., "metadata.annotations.backstage.io/techdocs-ref".
+	AnnotationFilterKeyPrefix = "metadata.annotations."
+)
+
+// Filter restricts the entities returned by a list operation. Key is a field
+// path, such as "metadata.name", "metadata.tags" or "spec.owner"; see
+// ValidateFilters for the paths available for each kind. Value is compared
+// for FilterEquals, FilterNotEquals and FilterPrefix, and Values for FilterIn
+// and FilterNotIn. For fields that hold several values, such as tags or
+// dependsOn, a filter matches when any one of the values matches, and a
+// negated filter matches when none of them do.
+type Filter struct {
+	Key      string
+	Value    string
+	Operator FilterOperator
+	Values   []string
+}
+
+type OrderingField string
+
+const (
+	OrderByKind      = OrderingField("entity.kind")
+	OrderByNamespace = OrderingField("entity.namespace")
+	OrderByName      = OrderingField("entity.name")
+)
+
+type Ordering struct {
+	OrderBy    OrderingField
+	Descending bool
+}
+
+type Pagination struct {
+	Limit  int
+	Offset int
+}
+
+// Every Store implementation reports these conditions with errors that wrap
+// one of the following, so that callers can tell them apart with errors.Is.
+var (
+	// ErrNotFound means that the entity does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists means that an entity with the same ref already
+	// exists.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrConflict means that a change conflicts with another entity, such as
+	// renaming an entity to the ref of one that exists.
+	ErrConflict = errors.New("conflict")
+	// ErrInvalid means that an entity, or a filter, cannot be stored or
+	// used as given.
+	ErrInvalid = errors.New("invalid")
+	// ErrVersionMismatch means that an update or delete was made against a
+	// version of an entity that is no longer current.
+	ErrVersionMismatch = errors.New("version mismatch")
+	// ErrTimeout means that an operation ran longer than the store allows;
+	// see WithTimeout.
+	ErrTimeout = errors.New("timed out")
+)
+
+// validateEntity checks that an entity is of the kind being stored and has a
+// name and namespace.
+func validateEntity(e model.Entity, kind string) error {
+	if e.Kind != kind {
+		return fmt.Errorf("%w: expected kind %s, got %q", ErrInvalid, kind, e.Kind)
+	}
+	if e.Metadata.Name == "" {
+		return fmt.Errorf("%w: %s has no name", ErrInvalid, kind)
+	}
+	if e.Metadata.Namespace == "" {
+		return fmt.Errorf("%w: %s %s has no namespace", ErrInvalid, kind, e.Metadata.Name)
+	}
+	return nil
+}
+
+// newUID generates a random (version 4) UUID for a new entity.
+func newUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate UID: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// versionEtag returns the etag for a version of an entity.
+func versionEtag(version int64) string {
+	return strconv.FormatInt(version, 10)
+}
+
+// storeTime returns the current time as stored for entities: in UTC, and to
+// the microsecond, which is as fine as the databases keep.
+func storeTime() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// ErrSearchUnavailable is returned by SearchEntities when the store was built
+// without full-text search support.
+var ErrSearchUnavailable = errors.New("full-text search is not available")
+
+// SearchMatch is an entity that matches a full-text search, along with an
+// excerpt of the matching text. The excerpt is escaped HTML, in which
+// matching words are wrapped in <mark> tags.
+type SearchMatch struct {
+	EntityRef model.EntityRef
+	Snippet   string
+}
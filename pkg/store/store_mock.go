@@ -0,0 +1,3131 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package store
+
+import (
+	"context"
+	"github.com/bhavanki/rewind/pkg/model"
+	"sync"
+	"time"
+)
+
+// Ensure, that StoreMock does implement Store.
+// If this is not the case, regenerate this file with moq.
+var _ Store = &StoreMock{}
+
+// StoreMock is a mock implementation of Store.
+//
+//	func TestSomethingThatUsesStore(t *testing.T) {
+//
+//		// make and configure a mocked Store
+//		mockedStore := &StoreMock{
+//			CreateAPIFunc: func(ctx context.Context, a model.API) (model.API, error) {
+//				panic("mock out the CreateAPI method")
+//			},
+//			CreateComponentFunc: func(ctx context.Context, c model.Component) (model.Component, error) {
+//				panic("mock out the CreateComponent method")
+//			},
+//			CreateDomainFunc: func(ctx context.Context, d model.Domain) (model.Domain, error) {
+//				panic("mock out the CreateDomain method")
+//			},
+//			CreateGroupFunc: func(ctx context.Context, g model.Group) (model.Group, error) {
+//				panic("mock out the CreateGroup method")
+//			},
+//			CreateLocationFunc: func(ctx context.Context, l model.Location) (model.Location, error) {
+//				panic("mock out the CreateLocation method")
+//			},
+//			CreateResourceFunc: func(ctx context.Context, r model.Resource) (model.Resource, error) {
+//				panic("mock out the CreateResource method")
+//			},
+//			CreateSystemFunc: func(ctx context.Context, s model.System) (model.System, error) {
+//				panic("mock out the CreateSystem method")
+//			},
+//			CreateTemplateFunc: func(ctx context.Context, t model.Template) (model.Template, error) {
+//				panic("mock out the CreateTemplate method")
+//			},
+//			CreateUserFunc: func(ctx context.Context, u model.User) (model.User, error) {
+//				panic("mock out the CreateUser method")
+//			},
+//			DeleteAPIFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.API, error) {
+//				panic("mock out the DeleteAPI method")
+//			},
+//			DeleteComponentFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Component, error) {
+//				panic("mock out the DeleteComponent method")
+//			},
+//			DeleteDomainFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Domain, error) {
+//				panic("mock out the DeleteDomain method")
+//			},
+//			DeleteGroupFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Group, error) {
+//				panic("mock out the DeleteGroup method")
+//			},
+//			DeleteLocationFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Location, error) {
+//				panic("mock out the DeleteLocation method")
+//			},
+//			DeleteResourceFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Resource, error) {
+//				panic("mock out the DeleteResource method")
+//			},
+//			DeleteSystemFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.System, error) {
+//				panic("mock out the DeleteSystem method")
+//			},
+//			DeleteTemplateFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Template, error) {
+//				panic("mock out the DeleteTemplate method")
+//			},
+//			DeleteUserFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.User, error) {
+//				panic("mock out the DeleteUser method")
+//			},
+//			ListAPIsFunc: func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+//				panic("mock out the ListAPIs method")
+//			},
+//			ListComponentsFunc: func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+//				panic("mock out the ListComponents method")
+//			},
+//			ListDanglingRefsFunc: func(ctx context.Context) ([]model.DanglingRef, error) {
+//				panic("mock out the ListDanglingRefs method")
+//			},
+//			ListDeletedEntitiesFunc: func(ctx context.Context, kinds []string, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+//				panic("mock out the ListDeletedEntities method")
+//			},
+//			ListDomainsFunc: func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+//				panic("mock out the ListDomains method")
+//			},
+//			ListEntitiesFunc: func(ctx context.Context, kinds []string, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+//				panic("mock out the ListEntities method")
+//			},
+//			ListExistingRefsFunc: func(ctx context.Context, refs []model.EntityRef) ([]model.EntityRef, error) {
+//				panic("mock out the ListExistingRefs method")
+//			},
+//			ListGroupsFunc: func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+//				panic("mock out the ListGroups method")
+//			},
+//			ListLocationsFunc: func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+//				panic("mock out the ListLocations method")
+//			},
+//			ListReferrersFunc: func(ctx context.Context, ref model.EntityRef) ([]model.Referrer, error) {
+//				panic("mock out the ListReferrers method")
+//			},
+//			ListRelationsFunc: func(ctx context.Context, ref model.EntityRef) ([]model.Relation, error) {
+//				panic("mock out the ListRelations method")
+//			},
+//			ListResourcesFunc: func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+//				panic("mock out the ListResources method")
+//			},
+//			ListRevisionsFunc: func(ctx context.Context, ref model.EntityRef, pagination Pagination) ([]model.Revision, Pagination, error) {
+//				panic("mock out the ListRevisions method")
+//			},
+//			ListSpecRefsFunc: func(ctx context.Context, fields []string) ([]model.EntitySpecRef, error) {
+//				panic("mock out the ListSpecRefs method")
+//			},
+//			ListSystemsFunc: func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+//				panic("mock out the ListSystems method")
+//			},
+//			ListTemplatesFunc: func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+//				panic("mock out the ListTemplates method")
+//			},
+//			ListUsersFunc: func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+//				panic("mock out the ListUsers method")
+//			},
+//			PurgeEntitiesFunc: func(ctx context.Context, before time.Time) (int64, error) {
+//				panic("mock out the PurgeEntities method")
+//			},
+//			ReadAPIFunc: func(ctx context.Context, ref model.EntityRef) (model.API, error) {
+//				panic("mock out the ReadAPI method")
+//			},
+//			ReadComponentFunc: func(ctx context.Context, ref model.EntityRef) (model.Component, error) {
+//				panic("mock out the ReadComponent method")
+//			},
+//			ReadDomainFunc: func(ctx context.Context, ref model.EntityRef) (model.Domain, error) {
+//				panic("mock out the ReadDomain method")
+//			},
+//			ReadGroupFunc: func(ctx context.Context, ref model.EntityRef) (model.Group, error) {
+//				panic("mock out the ReadGroup method")
+//			},
+//			ReadLocationFunc: func(ctx context.Context, ref model.EntityRef) (model.Location, error) {
+//				panic("mock out the ReadLocation method")
+//			},
+//			ReadResourceFunc: func(ctx context.Context, ref model.EntityRef) (model.Resource, error) {
+//				panic("mock out the ReadResource method")
+//			},
+//			ReadRevisionFunc: func(ctx context.Context, ref model.EntityRef, version int64) (model.Revision, error) {
+//				panic("mock out the ReadRevision method")
+//			},
+//			ReadSystemFunc: func(ctx context.Context, ref model.EntityRef) (model.System, error) {
+//				panic("mock out the ReadSystem method")
+//			},
+//			ReadTemplateFunc: func(ctx context.Context, ref model.EntityRef) (model.Template, error) {
+//				panic("mock out the ReadTemplate method")
+//			},
+//			ReadUserFunc: func(ctx context.Context, ref model.EntityRef) (model.User, error) {
+//				panic("mock out the ReadUser method")
+//			},
+//			ResolveUIDFunc: func(ctx context.Context, uid string) (model.EntityRef, error) {
+//				panic("mock out the ResolveUID method")
+//			},
+//			RestoreEntityFunc: func(ctx context.Context, ref model.EntityRef) error {
+//				panic("mock out the RestoreEntity method")
+//			},
+//			SearchEntitiesFunc: func(ctx context.Context, query string, pagination Pagination) ([]SearchMatch, Pagination, error) {
+//				panic("mock out the SearchEntities method")
+//			},
+//			UpdateAPIFunc: func(ctx context.Context, a model.API) (model.API, error) {
+//				panic("mock out the UpdateAPI method")
+//			},
+//			UpdateComponentFunc: func(ctx context.Context, c model.Component) (model.Component, error) {
+//				panic("mock out the UpdateComponent method")
+//			},
+//			UpdateDomainFunc: func(ctx context.Context, d model.Domain) (model.Domain, error) {
+//				panic("mock out the UpdateDomain method")
+//			},
+//			UpdateGroupFunc: func(ctx context.Context, g model.Group) (model.Group, error) {
+//				panic("mock out the UpdateGroup method")
+//			},
+//			UpdateLocationFunc: func(ctx context.Context, l model.Location) (model.Location, error) {
+//				panic("mock out the UpdateLocation method")
+//			},
+//			UpdateResourceFunc: func(ctx context.Context, r model.Resource) (model.Resource, error) {
+//				panic("mock out the UpdateResource method")
+//			},
+//			UpdateSystemFunc: func(ctx context.Context, s model.System) (model.System, error) {
+//				panic("mock out the UpdateSystem method")
+//			},
+//			UpdateTemplateFunc: func(ctx context.Context, t model.Template) (model.Template, error) {
+//				panic("mock out the UpdateTemplate method")
+//			},
+//			UpdateUserFunc: func(ctx context.Context, u model.User) (model.User, error) {
+//				panic("mock out the UpdateUser method")
+//			},
+//		}
+//
+//		// use mockedStore in code that requires Store
+//		// and then make assertions.
+//
+//	}
+type StoreMock struct {
+	// CreateAPIFunc mocks the CreateAPI method.
+	CreateAPIFunc func(ctx context.Context, a model.API) (model.API, error)
+
+	// CreateComponentFunc mocks the CreateComponent method.
+	CreateComponentFunc func(ctx context.Context, c model.Component) (model.Component, error)
+
+	// CreateDomainFunc mocks the CreateDomain method.
+	CreateDomainFunc func(ctx context.Context, d model.Domain) (model.Domain, error)
+
+	// CreateGroupFunc mocks the CreateGroup method.
+	CreateGroupFunc func(ctx context.Context, g model.Group) (model.Group, error)
+
+	// CreateLocationFunc mocks the CreateLocation method.
+	CreateLocationFunc func(ctx context.Context, l model.Location) (model.Location, error)
+
+	// CreateResourceFunc mocks the CreateResource method.
+	CreateResourceFunc func(ctx context.Context, r model.Resource) (model.Resource, error)
+
+	// CreateSystemFunc mocks the CreateSystem method.
+	CreateSystemFunc func(ctx context.Context, s model.System) (model.System, error)
+
+	// CreateTemplateFunc mocks the CreateTemplate method.
+	CreateTemplateFunc func(ctx context.Context, t model.Template) (model.Template, error)
+
+	// CreateUserFunc mocks the CreateUser method.
+	CreateUserFunc func(ctx context.Context, u model.User) (model.User, error)
+
+	// DeleteAPIFunc mocks the DeleteAPI method.
+	DeleteAPIFunc func(ctx context.Context, ref model.EntityRef, version int64) (model.API, error)
+
+	// DeleteComponentFunc mocks the DeleteComponent method.
+	DeleteComponentFunc func(ctx context.Context, ref model.EntityRef, version int64) (model.Component, error)
+
+	// DeleteDomainFunc mocks the DeleteDomain method.
+	DeleteDomainFunc func(ctx context.Context, ref model.EntityRef, version int64) (model.Domain, error)
+
+	// DeleteGroupFunc mocks the DeleteGroup method.
+	DeleteGroupFunc func(ctx context.Context, ref model.EntityRef, version int64) (model.Group, error)
+
+	// DeleteLocationFunc mocks the DeleteLocation method.
+	DeleteLocationFunc func(ctx context.Context, ref model.EntityRef, version int64) (model.Location, error)
+
+	// DeleteResourceFunc mocks the DeleteResource method.
+	DeleteResourceFunc func(ctx context.Context, ref model.EntityRef, version int64) (model.Resource, error)
+
+	// DeleteSystemFunc mocks the DeleteSystem method.
+	DeleteSystemFunc func(ctx context.Context, ref model.EntityRef, version int64) (model.System, error)
+
+	// DeleteTemplateFunc mocks the DeleteTemplate method.
+	DeleteTemplateFunc func(ctx context.Context, ref model.EntityRef, version int64) (model.Template, error)
+
+	// DeleteUserFunc mocks the DeleteUser method.
+	DeleteUserFunc func(ctx context.Context, ref model.EntityRef, version int64) (model.User, error)
+
+	// ListAPIsFunc mocks the ListAPIs method.
+	ListAPIsFunc func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	// ListComponentsFunc mocks the ListComponents method.
+	ListComponentsFunc func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	// ListDanglingRefsFunc mocks the ListDanglingRefs method.
+	ListDanglingRefsFunc func(ctx context.Context) ([]model.DanglingRef, error)
+
+	// ListDeletedEntitiesFunc mocks the ListDeletedEntities method.
+	ListDeletedEntitiesFunc func(ctx context.Context, kinds []string, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	// ListDomainsFunc mocks the ListDomains method.
+	ListDomainsFunc func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	// ListEntitiesFunc mocks the ListEntities method.
+	ListEntitiesFunc func(ctx context.Context, kinds []string, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	// ListExistingRefsFunc mocks the ListExistingRefs method.
+	ListExistingRefsFunc func(ctx context.Context, refs []model.EntityRef) ([]model.EntityRef, error)
+
+	// ListGroupsFunc mocks the ListGroups method.
+	ListGroupsFunc func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	// ListLocationsFunc mocks the ListLocations method.
+	ListLocationsFunc func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	// ListReferrersFunc mocks the ListReferrers method.
+	ListReferrersFunc func(ctx context.Context, ref model.EntityRef) ([]model.Referrer, error)
+
+	// ListRelationsFunc mocks the ListRelations method.
+	ListRelationsFunc func(ctx context.Context, ref model.EntityRef) ([]model.Relation, error)
+
+	// ListResourcesFunc mocks the ListResources method.
+	ListResourcesFunc func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	// ListRevisionsFunc mocks the ListRevisions method.
+	ListRevisionsFunc func(ctx context.Context, ref model.EntityRef, pagination Pagination) ([]model.Revision, Pagination, error)
+
+	// ListSpecRefsFunc mocks the ListSpecRefs method.
+	ListSpecRefsFunc func(ctx context.Context, fields []string) ([]model.EntitySpecRef, error)
+
+	// ListSystemsFunc mocks the ListSystems method.
+	ListSystemsFunc func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	// ListTemplatesFunc mocks the ListTemplates method.
+	ListTemplatesFunc func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	// ListUsersFunc mocks the ListUsers method.
+	ListUsersFunc func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error)
+
+	// PurgeEntitiesFunc mocks the PurgeEntities method.
+	PurgeEntitiesFunc func(ctx context.Context, before time.Time) (int64, error)
+
+	// ReadAPIFunc mocks the ReadAPI method.
+	ReadAPIFunc func(ctx context.Context, ref model.EntityRef) (model.API, error)
+
+	// ReadComponentFunc mocks the ReadComponent method.
+	ReadComponentFunc func(ctx context.Context, ref model.EntityRef) (model.Component, error)
+
+	// ReadDomainFunc mocks the ReadDomain method.
+	ReadDomainFunc func(ctx context.Context, ref model.EntityRef) (model.Domain, error)
+
+	// ReadGroupFunc mocks the ReadGroup method.
+	ReadGroupFunc func(ctx context.Context, ref model.EntityRef) (model.Group, error)
+
+	// ReadLocationFunc mocks the ReadLocation method.
+	ReadLocationFunc func(ctx context.Context, ref model.EntityRef) (model.Location, error)
+
+	// ReadResourceFunc mocks the ReadResource method.
+	ReadResourceFunc func(ctx context.Context, ref model.EntityRef) (model.Resource, error)
+
+	// ReadRevisionFunc mocks the ReadRevision method.
+	ReadRevisionFunc func(ctx context.Context, ref model.EntityRef, version int64) (model.Revision, error)
+
+	// ReadSystemFunc mocks the ReadSystem method.
+	ReadSystemFunc func(ctx context.Context, ref model.EntityRef) (model.System, error)
+
+	// ReadTemplateFunc mocks the ReadTemplate method.
+	ReadTemplateFunc func(ctx context.Context, ref model.EntityRef) (model.Template, error)
+
+	// ReadUserFunc mocks the ReadUser method.
+	ReadUserFunc func(ctx context.Context, ref model.EntityRef) (model.User, error)
+
+	// ResolveUIDFunc mocks the ResolveUID method.
+	ResolveUIDFunc func(ctx context.Context, uid string) (model.EntityRef, error)
+
+	// RestoreEntityFunc mocks the RestoreEntity method.
+	RestoreEntityFunc func(ctx context.Context, ref model.EntityRef) error
+
+	// SearchEntitiesFunc mocks the SearchEntities method.
+	SearchEntitiesFunc func(ctx context.Context, query string, pagination Pagination) ([]SearchMatch, Pagination, error)
+
+	// UpdateAPIFunc mocks the UpdateAPI method.
+	UpdateAPIFunc func(ctx context.Context, a model.API) (model.API, error)
+
+	// UpdateComponentFunc mocks the UpdateComponent method.
+	UpdateComponentFunc func(ctx context.Context, c model.Component) (model.Component, error)
+
+	// UpdateDomainFunc mocks the UpdateDomain method.
+	UpdateDomainFunc func(ctx context.Context, d model.Domain) (model.Domain, error)
+
+	// UpdateGroupFunc mocks the UpdateGroup method.
+	UpdateGroupFunc func(ctx context.Context, g model.Group) (model.Group, error)
+
+	// UpdateLocationFunc mocks the UpdateLocation method.
+	UpdateLocationFunc func(ctx context.Context, l model.Location) (model.Location, error)
+
+	// UpdateResourceFunc mocks the UpdateResource method.
+	UpdateResourceFunc func(ctx context.Context, r model.Resource) (model.Resource, error)
+
+	// UpdateSystemFunc mocks the UpdateSystem method.
+	UpdateSystemFunc func(ctx context.Context, s model.System) (model.System, error)
+
+	// UpdateTemplateFunc mocks the UpdateTemplate method.
+	UpdateTemplateFunc func(ctx context.Context, t model.Template) (model.Template, error)
+
+	// UpdateUserFunc mocks the UpdateUser method.
+	UpdateUserFunc func(ctx context.Context, u model.User) (model.User, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// CreateAPI holds details about calls to the CreateAPI method.
+		CreateAPI []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// A is the a argument value.
+			A model.API
+		}
+		// CreateComponent holds details about calls to the CreateComponent method.
+		CreateComponent []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// C is the c argument value.
+			C model.Component
+		}
+		// CreateDomain holds details about calls to the CreateDomain method.
+		CreateDomain []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// D is the d argument value.
+			D model.Domain
+		}
+		// CreateGroup holds details about calls to the CreateGroup method.
+		CreateGroup []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// G is the g argument value.
+			G model.Group
+		}
+		// CreateLocation holds details about calls to the CreateLocation method.
+		CreateLocation []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// L is the l argument value.
+			L model.Location
+		}
+		// CreateResource holds details about calls to the CreateResource method.
+		CreateResource []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// R is the r argument value.
+			R model.Resource
+		}
+		// CreateSystem holds details about calls to the CreateSystem method.
+		CreateSystem []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// S is the s argument value.
+			S model.System
+		}
+		// CreateTemplate holds details about calls to the CreateTemplate method.
+		CreateTemplate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// T is the t argument value.
+			T model.Template
+		}
+		// CreateUser holds details about calls to the CreateUser method.
+		CreateUser []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// U is the u argument value.
+			U model.User
+		}
+		// DeleteAPI holds details about calls to the DeleteAPI method.
+		DeleteAPI []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+			// Version is the version argument value.
+			Version int64
+		}
+		// DeleteComponent holds details about calls to the DeleteComponent method.
+		DeleteComponent []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+			// Version is the version argument value.
+			Version int64
+		}
+		// DeleteDomain holds details about calls to the DeleteDomain method.
+		DeleteDomain []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+			// Version is the version argument value.
+			Version int64
+		}
+		// DeleteGroup holds details about calls to the DeleteGroup method.
+		DeleteGroup []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+			// Version is the version argument value.
+			Version int64
+		}
+		// DeleteLocation holds details about calls to the DeleteLocation method.
+		DeleteLocation []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+			// Version is the version argument value.
+			Version int64
+		}
+		// DeleteResource holds details about calls to the DeleteResource method.
+		DeleteResource []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+			// Version is the version argument value.
+			Version int64
+		}
+		// DeleteSystem holds details about calls to the DeleteSystem method.
+		DeleteSystem []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+			// Version is the version argument value.
+			Version int64
+		}
+		// DeleteTemplate holds details about calls to the DeleteTemplate method.
+		DeleteTemplate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+			// Version is the version argument value.
+			Version int64
+		}
+		// DeleteUser holds details about calls to the DeleteUser method.
+		DeleteUser []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+			// Version is the version argument value.
+			Version int64
+		}
+		// ListAPIs holds details about calls to the ListAPIs method.
+		ListAPIs []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filters is the filters argument value.
+			Filters []Filter
+			// Ordering is the ordering argument value.
+			Ordering Ordering
+			// Pagination is the pagination argument value.
+			Pagination Pagination
+		}
+		// ListComponents holds details about calls to the ListComponents method.
+		ListComponents []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filters is the filters argument value.
+			Filters []Filter
+			// Ordering is the ordering argument value.
+			Ordering Ordering
+			// Pagination is the pagination argument value.
+			Pagination Pagination
+		}
+		// ListDanglingRefs holds details about calls to the ListDanglingRefs method.
+		ListDanglingRefs []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// ListDeletedEntities holds details about calls to the ListDeletedEntities method.
+		ListDeletedEntities []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Kinds is the kinds argument value.
+			Kinds []string
+			// Pagination is the pagination argument value.
+			Pagination Pagination
+		}
+		// ListDomains holds details about calls to the ListDomains method.
+		ListDomains []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filters is the filters argument value.
+			Filters []Filter
+			// Ordering is the ordering argument value.
+			Ordering Ordering
+			// Pagination is the pagination argument value.
+			Pagination Pagination
+		}
+		// ListEntities holds details about calls to the ListEntities method.
+		ListEntities []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Kinds is the kinds argument value.
+			Kinds []string
+			// Filters is the filters argument value.
+			Filters []Filter
+			// Ordering is the ordering argument value.
+			Ordering Ordering
+			// Pagination is the pagination argument value.
+			Pagination Pagination
+		}
+		// ListExistingRefs holds details about calls to the ListExistingRefs method.
+		ListExistingRefs []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Refs is the refs argument value.
+			Refs []model.EntityRef
+		}
+		// ListGroups holds details about calls to the ListGroups method.
+		ListGroups []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filters is the filters argument value.
+			Filters []Filter
+			// Ordering is the ordering argument value.
+			Ordering Ordering
+			// Pagination is the pagination argument value.
+			Pagination Pagination
+		}
+		// ListLocations holds details about calls to the ListLocations method.
+		ListLocations []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filters is the filters argument value.
+			Filters []Filter
+			// Ordering is the ordering argument value.
+			Ordering Ordering
+			// Pagination is the pagination argument value.
+			Pagination Pagination
+		}
+		// ListReferrers holds details about calls to the ListReferrers method.
+		ListReferrers []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+		}
+		// ListRelations holds details about calls to the ListRelations method.
+		ListRelations []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+		}
+		// ListResources holds details about calls to the ListResources method.
+		ListResources []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filters is the filters argument value.
+			Filters []Filter
+			// Ordering is the ordering argument value.
+			Ordering Ordering
+			// Pagination is the pagination argument value.
+			Pagination Pagination
+		}
+		// ListRevisions holds details about calls to the ListRevisions method.
+		ListRevisions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+			// Pagination is the pagination argument value.
+			Pagination Pagination
+		}
+		// ListSpecRefs holds details about calls to the ListSpecRefs method.
+		ListSpecRefs []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Fields is the fields argument value.
+			Fields []string
+		}
+		// ListSystems holds details about calls to the ListSystems method.
+		ListSystems []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filters is the filters argument value.
+			Filters []Filter
+			// Ordering is the ordering argument value.
+			Ordering Ordering
+			// Pagination is the pagination argument value.
+			Pagination Pagination
+		}
+		// ListTemplates holds details about calls to the ListTemplates method.
+		ListTemplates []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filters is the filters argument value.
+			Filters []Filter
+			// Ordering is the ordering argument value.
+			Ordering Ordering
+			// Pagination is the pagination argument value.
+			Pagination Pagination
+		}
+		// ListUsers holds details about calls to the ListUsers method.
+		ListUsers []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filters is the filters argument value.
+			Filters []Filter
+			// Ordering is the ordering argument value.
+			Ordering Ordering
+			// Pagination is the pagination argument value.
+			Pagination Pagination
+		}
+		// PurgeEntities holds details about calls to the PurgeEntities method.
+		PurgeEntities []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Before is the before argument value.
+			Before time.Time
+		}
+		// ReadAPI holds details about calls to the ReadAPI method.
+		ReadAPI []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+		}
+		// ReadComponent holds details about calls to the ReadComponent method.
+		ReadComponent []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+		}
+		// ReadDomain holds details about calls to the ReadDomain method.
+		ReadDomain []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+		}
+		// ReadGroup holds details about calls to the ReadGroup method.
+		ReadGroup []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+		}
+		// ReadLocation holds details about calls to the ReadLocation method.
+		ReadLocation []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+		}
+		// ReadResource holds details about calls to the ReadResource method.
+		ReadResource []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+		}
+		// ReadRevision holds details about calls to the ReadRevision method.
+		ReadRevision []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+			// Version is the version argument value.
+			Version int64
+		}
+		// ReadSystem holds details about calls to the ReadSystem method.
+		ReadSystem []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+		}
+		// ReadTemplate holds details about calls to the ReadTemplate method.
+		ReadTemplate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+		}
+		// ReadUser holds details about calls to the ReadUser method.
+		ReadUser []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+		}
+		// ResolveUID holds details about calls to the ResolveUID method.
+		ResolveUID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// UID is the uid argument value.
+			UID string
+		}
+		// RestoreEntity holds details about calls to the RestoreEntity method.
+		RestoreEntity []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ref is the ref argument value.
+			Ref model.EntityRef
+		}
+		// SearchEntities holds details about calls to the SearchEntities method.
+		SearchEntities []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Query is the query argument value.
+			Query string
+			// Pagination is the pagination argument value.
+			Pagination Pagination
+		}
+		// UpdateAPI holds details about calls to the UpdateAPI method.
+		UpdateAPI []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// A is the a argument value.
+			A model.API
+		}
+		// UpdateComponent holds details about calls to the UpdateComponent method.
+		UpdateComponent []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// C is the c argument value.
+			C model.Component
+		}
+		// UpdateDomain holds details about calls to the UpdateDomain method.
+		UpdateDomain []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// D is the d argument value.
+			D model.Domain
+		}
+		// UpdateGroup holds details about calls to the UpdateGroup method.
+		UpdateGroup []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// G is the g argument value.
+			G model.Group
+		}
+		// UpdateLocation holds details about calls to the UpdateLocation method.
+		UpdateLocation []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// L is the l argument value.
+			L model.Location
+		}
+		// UpdateResource holds details about calls to the UpdateResource method.
+		UpdateResource []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// R is the r argument value.
+			R model.Resource
+		}
+		// UpdateSystem holds details about calls to the UpdateSystem method.
+		UpdateSystem []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// S is the s argument value.
+			S model.System
+		}
+		// UpdateTemplate holds details about calls to the UpdateTemplate method.
+		UpdateTemplate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// T is the t argument value.
+			T model.Template
+		}
+		// UpdateUser holds details about calls to the UpdateUser method.
+		UpdateUser []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// U is the u argument value.
+			U model.User
+		}
+	}
+	lockCreateAPI           sync.RWMutex
+	lockCreateComponent     sync.RWMutex
+	lockCreateDomain        sync.RWMutex
+	lockCreateGroup         sync.RWMutex
+	lockCreateLocation      sync.RWMutex
+	lockCreateResource      sync.RWMutex
+	lockCreateSystem        sync.RWMutex
+	lockCreateTemplate      sync.RWMutex
+	lockCreateUser          sync.RWMutex
+	lockDeleteAPI           sync.RWMutex
+	lockDeleteComponent     sync.RWMutex
+	lockDeleteDomain        sync.RWMutex
+	lockDeleteGroup         sync.RWMutex
+	lockDeleteLocation      sync.RWMutex
+	lockDeleteResource      sync.RWMutex
+	lockDeleteSystem        sync.RWMutex
+	lockDeleteTemplate      sync.RWMutex
+	lockDeleteUser          sync.RWMutex
+	lockListAPIs            sync.RWMutex
+	lockListComponents      sync.RWMutex
+	lockListDanglingRefs    sync.RWMutex
+	lockListDeletedEntities sync.RWMutex
+	lockListDomains         sync.RWMutex
+	lockListEntities        sync.RWMutex
+	lockListExistingRefs    sync.RWMutex
+	lockListGroups          sync.RWMutex
+	lockListLocations       sync.RWMutex
+	lockListReferrers       sync.RWMutex
+	lockListRelations       sync.RWMutex
+	lockListResources       sync.RWMutex
+	lockListRevisions       sync.RWMutex
+	lockListSpecRefs        sync.RWMutex
+	lockListSystems         sync.RWMutex
+	lockListTemplates       sync.RWMutex
+	lockListUsers           sync.RWMutex
+	lockPurgeEntities       sync.RWMutex
+	lockReadAPI             sync.RWMutex
+	lockReadComponent       sync.RWMutex
+	lockReadDomain          sync.RWMutex
+	lockReadGroup           sync.RWMutex
+	lockReadLocation        sync.RWMutex
+	lockReadResource        sync.RWMutex
+	lockReadRevision        sync.RWMutex
+	lockReadSystem          sync.RWMutex
+	lockReadTemplate        sync.RWMutex
+	lockReadUser            sync.RWMutex
+	lockResolveUID          sync.RWMutex
+	lockRestoreEntity       sync.RWMutex
+	lockSearchEntities      sync.RWMutex
+	lockUpdateAPI           sync.RWMutex
+	lockUpdateComponent     sync.RWMutex
+	lockUpdateDomain        sync.RWMutex
+	lockUpdateGroup         sync.RWMutex
+	lockUpdateLocation      sync.RWMutex
+	lockUpdateResource      sync.RWMutex
+	lockUpdateSystem        sync.RWMutex
+	lockUpdateTemplate      sync.RWMutex
+	lockUpdateUser          sync.RWMutex
+}
+
+// CreateAPI calls CreateAPIFunc.
+func (mock *StoreMock) CreateAPI(ctx context.Context, a model.API) (model.API, error) {
+	if mock.CreateAPIFunc == nil {
+		panic("StoreMock.CreateAPIFunc: method is nil but Store.CreateAPI was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		A   model.API
+	}{
+		Ctx: ctx,
+		A:   a,
+	}
+	mock.lockCreateAPI.Lock()
+	mock.calls.CreateAPI = append(mock.calls.CreateAPI, callInfo)
+	mock.lockCreateAPI.Unlock()
+	return mock.CreateAPIFunc(ctx, a)
+}
+
+// CreateAPICalls gets all the calls that were made to CreateAPI.
+// Check the length with:
+//
+//	len(mockedStore.CreateAPICalls())
+func (mock *StoreMock) CreateAPICalls() []struct {
+	Ctx context.Context
+	A   model.API
+} {
+	var calls []struct {
+		Ctx context.Context
+		A   model.API
+	}
+	mock.lockCreateAPI.RLock()
+	calls = mock.calls.CreateAPI
+	mock.lockCreateAPI.RUnlock()
+	return calls
+}
+
+// CreateComponent calls CreateComponentFunc.
+func (mock *StoreMock) CreateComponent(ctx context.Context, c model.Component) (model.Component, error) {
+	if mock.CreateComponentFunc == nil {
+		panic("StoreMock.CreateComponentFunc: method is nil but Store.CreateComponent was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		C   model.Component
+	}{
+		Ctx: ctx,
+		C:   c,
+	}
+	mock.lockCreateComponent.Lock()
+	mock.calls.CreateComponent = append(mock.calls.CreateComponent, callInfo)
+	mock.lockCreateComponent.Unlock()
+	return mock.CreateComponentFunc(ctx, c)
+}
+
+// CreateComponentCalls gets all the calls that were made to CreateComponent.
+// Check the length with:
+//
+//	len(mockedStore.CreateComponentCalls())
+func (mock *StoreMock) CreateComponentCalls() []struct {
+	Ctx context.Context
+	C   model.Component
+} {
+	var calls []struct {
+		Ctx context.Context
+		C   model.Component
+	}
+	mock.lockCreateComponent.RLock()
+	calls = mock.calls.CreateComponent
+	mock.lockCreateComponent.RUnlock()
+	return calls
+}
+
+// CreateDomain calls CreateDomainFunc.
+func (mock *StoreMock) CreateDomain(ctx context.Context, d model.Domain) (model.Domain, error) {
+	if mock.CreateDomainFunc == nil {
+		panic("StoreMock.CreateDomainFunc: method is nil but Store.CreateDomain was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		D   model.Domain
+	}{
+		Ctx: ctx,
+		D:   d,
+	}
+	mock.lockCreateDomain.Lock()
+	mock.calls.CreateDomain = append(mock.calls.CreateDomain, callInfo)
+	mock.lockCreateDomain.Unlock()
+	return mock.CreateDomainFunc(ctx, d)
+}
+
+// CreateDomainCalls gets all the calls that were made to CreateDomain.
+// Check the length with:
+//
+//	len(mockedStore.CreateDomainCalls())
+func (mock *StoreMock) CreateDomainCalls() []struct {
+	Ctx context.Context
+	D   model.Domain
+} {
+	var calls []struct {
+		Ctx context.Context
+		D   model.Domain
+	}
+	mock.lockCreateDomain.RLock()
+	calls = mock.calls.CreateDomain
+	mock.lockCreateDomain.RUnlock()
+	return calls
+}
+
+// CreateGroup calls CreateGroupFunc.
+func (mock *StoreMock) CreateGroup(ctx context.Context, g model.Group) (model.Group, error) {
+	if mock.CreateGroupFunc == nil {
+		panic("StoreMock.CreateGroupFunc: method is nil but Store.CreateGroup was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		G   model.Group
+	}{
+		Ctx: ctx,
+		G:   g,
+	}
+	mock.lockCreateGroup.Lock()
+	mock.calls.CreateGroup = append(mock.calls.CreateGroup, callInfo)
+	mock.lockCreateGroup.Unlock()
+	return mock.CreateGroupFunc(ctx, g)
+}
+
+// CreateGroupCalls gets all the calls that were made to CreateGroup.
+// Check the length with:
+//
+//	len(mockedStore.CreateGroupCalls())
+func (mock *StoreMock) CreateGroupCalls() []struct {
+	Ctx context.Context
+	G   model.Group
+} {
+	var calls []struct {
+		Ctx context.Context
+		G   model.Group
+	}
+	mock.lockCreateGroup.RLock()
+	calls = mock.calls.CreateGroup
+	mock.lockCreateGroup.RUnlock()
+	return calls
+}
+
+// CreateLocation calls CreateLocationFunc.
+func (mock *StoreMock) CreateLocation(ctx context.Context, l model.Location) (model.Location, error) {
+	if mock.CreateLocationFunc == nil {
+		panic("StoreMock.CreateLocationFunc: method is nil but Store.CreateLocation was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		L   model.Location
+	}{
+		Ctx: ctx,
+		L:   l,
+	}
+	mock.lockCreateLocation.Lock()
+	mock.calls.CreateLocation = append(mock.calls.CreateLocation, callInfo)
+	mock.lockCreateLocation.Unlock()
+	return mock.CreateLocationFunc(ctx, l)
+}
+
+// CreateLocationCalls gets all the calls that were made to CreateLocation.
+// Check the length with:
+//
+//	len(mockedStore.CreateLocationCalls())
+func (mock *StoreMock) CreateLocationCalls() []struct {
+	Ctx context.Context
+	L   model.Location
+} {
+	var calls []struct {
+		Ctx context.Context
+		L   model.Location
+	}
+	mock.lockCreateLocation.RLock()
+	calls = mock.calls.CreateLocation
+	mock.lockCreateLocation.RUnlock()
+	return calls
+}
+
+// CreateResource calls CreateResourceFunc.
+func (mock *StoreMock) CreateResource(ctx context.Context, r model.Resource) (model.Resource, error) {
+	if mock.CreateResourceFunc == nil {
+		panic("StoreMock.CreateResourceFunc: method is nil but Store.CreateResource was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		R   model.Resource
+	}{
+		Ctx: ctx,
+		R:   r,
+	}
+	mock.lockCreateResource.Lock()
+	mock.calls.CreateResource = append(mock.calls.CreateResource, callInfo)
+	mock.lockCreateResource.Unlock()
+	return mock.CreateResourceFunc(ctx, r)
+}
+
+// CreateResourceCalls gets all the calls that were made to CreateResource.
+// Check the length with:
+//
+//	len(mockedStore.CreateResourceCalls())
+func (mock *StoreMock) CreateResourceCalls() []struct {
+	Ctx context.Context
+	R   model.Resource
+} {
+	var calls []struct {
+		Ctx context.Context
+		R   model.Resource
+	}
+	mock.lockCreateResource.RLock()
+	calls = mock.calls.CreateResource
+	mock.lockCreateResource.RUnlock()
+	return calls
+}
+
+// CreateSystem calls CreateSystemFunc.
+func (mock *StoreMock) CreateSystem(ctx context.Context, s model.System) (model.System, error) {
+	if mock.CreateSystemFunc == nil {
+		panic("StoreMock.CreateSystemFunc: method is nil but Store.CreateSystem was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		S   model.System
+	}{
+		Ctx: ctx,
+		S:   s,
+	}
+	mock.lockCreateSystem.Lock()
+	mock.calls.CreateSystem = append(mock.calls.CreateSystem, callInfo)
+	mock.lockCreateSystem.Unlock()
+	return mock.CreateSystemFunc(ctx, s)
+}
+
+// CreateSystemCalls gets all the calls that were made to CreateSystem.
+// Check the length with:
+//
+//	len(mockedStore.CreateSystemCalls())
+func (mock *StoreMock) CreateSystemCalls() []struct {
+	Ctx context.Context
+	S   model.System
+} {
+	var calls []struct {
+		Ctx context.Context
+		S   model.System
+	}
+	mock.lockCreateSystem.RLock()
+	calls = mock.calls.CreateSystem
+	mock.lockCreateSystem.RUnlock()
+	return calls
+}
+
+// CreateTemplate calls CreateTemplateFunc.
+func (mock *StoreMock) CreateTemplate(ctx context.Context, t model.Template) (model.Template, error) {
+	if mock.CreateTemplateFunc == nil {
+		panic("StoreMock.CreateTemplateFunc: method is nil but Store.CreateTemplate was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		T   model.Template
+	}{
+		Ctx: ctx,
+		T:   t,
+	}
+	mock.lockCreateTemplate.Lock()
+	mock.calls.CreateTemplate = append(mock.calls.CreateTemplate, callInfo)
+	mock.lockCreateTemplate.Unlock()
+	return mock.CreateTemplateFunc(ctx, t)
+}
+
+// CreateTemplateCalls gets all the calls that were made to CreateTemplate.
+// Check the length with:
+//
+//	len(mockedStore.CreateTemplateCalls())
+func (mock *StoreMock) CreateTemplateCalls() []struct {
+	Ctx context.Context
+	T   model.Template
+} {
+	var calls []struct {
+		Ctx context.Context
+		T   model.Template
+	}
+	mock.lockCreateTemplate.RLock()
+	calls = mock.calls.CreateTemplate
+	mock.lockCreateTemplate.RUnlock()
+	return calls
+}
+
+// CreateUser calls CreateUserFunc.
+func (mock *StoreMock) CreateUser(ctx context.Context, u model.User) (model.User, error) {
+	if mock.CreateUserFunc == nil {
+		panic("StoreMock.CreateUserFunc: method is nil but Store.CreateUser was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		U   model.User
+	}{
+		Ctx: ctx,
+		U:   u,
+	}
+	mock.lockCreateUser.Lock()
+	mock.calls.CreateUser = append(mock.calls.CreateUser, callInfo)
+	mock.lockCreateUser.Unlock()
+	return mock.CreateUserFunc(ctx, u)
+}
+
+// CreateUserCalls gets all the calls that were made to CreateUser.
+// Check the length with:
+//
+//	len(mockedStore.CreateUserCalls())
+func (mock *StoreMock) CreateUserCalls() []struct {
+	Ctx context.Context
+	U   model.User
+} {
+	var calls []struct {
+		Ctx context.Context
+		U   model.User
+	}
+	mock.lockCreateUser.RLock()
+	calls = mock.calls.CreateUser
+	mock.lockCreateUser.RUnlock()
+	return calls
+}
+
+// DeleteAPI calls DeleteAPIFunc.
+func (mock *StoreMock) DeleteAPI(ctx context.Context, ref model.EntityRef, version int64) (model.API, error) {
+	if mock.DeleteAPIFunc == nil {
+		panic("StoreMock.DeleteAPIFunc: method is nil but Store.DeleteAPI was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}{
+		Ctx:     ctx,
+		Ref:     ref,
+		Version: version,
+	}
+	mock.lockDeleteAPI.Lock()
+	mock.calls.DeleteAPI = append(mock.calls.DeleteAPI, callInfo)
+	mock.lockDeleteAPI.Unlock()
+	return mock.DeleteAPIFunc(ctx, ref, version)
+}
+
+// DeleteAPICalls gets all the calls that were made to DeleteAPI.
+// Check the length with:
+//
+//	len(mockedStore.DeleteAPICalls())
+func (mock *StoreMock) DeleteAPICalls() []struct {
+	Ctx     context.Context
+	Ref     model.EntityRef
+	Version int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}
+	mock.lockDeleteAPI.RLock()
+	calls = mock.calls.DeleteAPI
+	mock.lockDeleteAPI.RUnlock()
+	return calls
+}
+
+// DeleteComponent calls DeleteComponentFunc.
+func (mock *StoreMock) DeleteComponent(ctx context.Context, ref model.EntityRef, version int64) (model.Component, error) {
+	if mock.DeleteComponentFunc == nil {
+		panic("StoreMock.DeleteComponentFunc: method is nil but Store.DeleteComponent was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}{
+		Ctx:     ctx,
+		Ref:     ref,
+		Version: version,
+	}
+	mock.lockDeleteComponent.Lock()
+	mock.calls.DeleteComponent = append(mock.calls.DeleteComponent, callInfo)
+	mock.lockDeleteComponent.Unlock()
+	return mock.DeleteComponentFunc(ctx, ref, version)
+}
+
+// DeleteComponentCalls gets all the calls that were made to DeleteComponent.
+// Check the length with:
+//
+//	len(mockedStore.DeleteComponentCalls())
+func (mock *StoreMock) DeleteComponentCalls() []struct {
+	Ctx     context.Context
+	Ref     model.EntityRef
+	Version int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}
+	mock.lockDeleteComponent.RLock()
+	calls = mock.calls.DeleteComponent
+	mock.lockDeleteComponent.RUnlock()
+	return calls
+}
+
+// DeleteDomain calls DeleteDomainFunc.
+func (mock *StoreMock) DeleteDomain(ctx context.Context, ref model.EntityRef, version int64) (model.Domain, error) {
+	if mock.DeleteDomainFunc == nil {
+		panic("StoreMock.DeleteDomainFunc: method is nil but Store.DeleteDomain was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}{
+		Ctx:     ctx,
+		Ref:     ref,
+		Version: version,
+	}
+	mock.lockDeleteDomain.Lock()
+	mock.calls.DeleteDomain = append(mock.calls.DeleteDomain, callInfo)
+	mock.lockDeleteDomain.Unlock()
+	return mock.DeleteDomainFunc(ctx, ref, version)
+}
+
+// DeleteDomainCalls gets all the calls that were made to DeleteDomain.
+// Check the length with:
+//
+//	len(mockedStore.DeleteDomainCalls())
+func (mock *StoreMock) DeleteDomainCalls() []struct {
+	Ctx     context.Context
+	Ref     model.EntityRef
+	Version int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}
+	mock.lockDeleteDomain.RLock()
+	calls = mock.calls.DeleteDomain
+	mock.lockDeleteDomain.RUnlock()
+	return calls
+}
+
+// DeleteGroup calls DeleteGroupFunc.
+func (mock *StoreMock) DeleteGroup(ctx context.Context, ref model.EntityRef, version int64) (model.Group, error) {
+	if mock.DeleteGroupFunc == nil {
+		panic("StoreMock.DeleteGroupFunc: method is nil but Store.DeleteGroup was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}{
+		Ctx:     ctx,
+		Ref:     ref,
+		Version: version,
+	}
+	mock.lockDeleteGroup.Lock()
+	mock.calls.DeleteGroup = append(mock.calls.DeleteGroup, callInfo)
+	mock.lockDeleteGroup.Unlock()
+	return mock.DeleteGroupFunc(ctx, ref, version)
+}
+
+// DeleteGroupCalls gets all the calls that were made to DeleteGroup.
+// Check the length with:
+//
+//	len(mockedStore.DeleteGroupCalls())
+func (mock *StoreMock) DeleteGroupCalls() []struct {
+	Ctx     context.Context
+	Ref     model.EntityRef
+	Version int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}
+	mock.lockDeleteGroup.RLock()
+	calls = mock.calls.DeleteGroup
+	mock.lockDeleteGroup.RUnlock()
+	return calls
+}
+
+// DeleteLocation calls DeleteLocationFunc.
+func (mock *StoreMock) DeleteLocation(ctx context.Context, ref model.EntityRef, version int64) (model.Location, error) {
+	if mock.DeleteLocationFunc == nil {
+		panic("StoreMock.DeleteLocationFunc: method is nil but Store.DeleteLocation was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}{
+		Ctx:     ctx,
+		Ref:     ref,
+		Version: version,
+	}
+	mock.lockDeleteLocation.Lock()
+	mock.calls.DeleteLocation = append(mock.calls.DeleteLocation, callInfo)
+	mock.lockDeleteLocation.Unlock()
+	return mock.DeleteLocationFunc(ctx, ref, version)
+}
+
+// DeleteLocationCalls gets all the calls that were made to DeleteLocation.
+// Check the length with:
+//
+//	len(mockedStore.DeleteLocationCalls())
+func (mock *StoreMock) DeleteLocationCalls() []struct {
+	Ctx     context.Context
+	Ref     model.EntityRef
+	Version int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}
+	mock.lockDeleteLocation.RLock()
+	calls = mock.calls.DeleteLocation
+	mock.lockDeleteLocation.RUnlock()
+	return calls
+}
+
+// DeleteResource calls DeleteResourceFunc.
+func (mock *StoreMock) DeleteResource(ctx context.Context, ref model.EntityRef, version int64) (model.Resource, error) {
+	if mock.DeleteResourceFunc == nil {
+		panic("StoreMock.DeleteResourceFunc: method is nil but Store.DeleteResource was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}{
+		Ctx:     ctx,
+		Ref:     ref,
+		Version: version,
+	}
+	mock.lockDeleteResource.Lock()
+	mock.calls.DeleteResource = append(mock.calls.DeleteResource, callInfo)
+	mock.lockDeleteResource.Unlock()
+	return mock.DeleteResourceFunc(ctx, ref, version)
+}
+
+// DeleteResourceCalls gets all the calls that were made to DeleteResource.
+// Check the length with:
+//
+//	len(mockedStore.DeleteResourceCalls())
+func (mock *StoreMock) DeleteResourceCalls() []struct {
+	Ctx     context.Context
+	Ref     model.EntityRef
+	Version int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}
+	mock.lockDeleteResource.RLock()
+	calls = mock.calls.DeleteResource
+	mock.lockDeleteResource.RUnlock()
+	return calls
+}
+
+// DeleteSystem calls DeleteSystemFunc.
+func (mock *StoreMock) DeleteSystem(ctx context.Context, ref model.EntityRef, version int64) (model.System, error) {
+	if mock.DeleteSystemFunc == nil {
+		panic("StoreMock.DeleteSystemFunc: method is nil but Store.DeleteSystem was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}{
+		Ctx:     ctx,
+		Ref:     ref,
+		Version: version,
+	}
+	mock.lockDeleteSystem.Lock()
+	mock.calls.DeleteSystem = append(mock.calls.DeleteSystem, callInfo)
+	mock.lockDeleteSystem.Unlock()
+	return mock.DeleteSystemFunc(ctx, ref, version)
+}
+
+// DeleteSystemCalls gets all the calls that were made to DeleteSystem.
+// Check the length with:
+//
+//	len(mockedStore.DeleteSystemCalls())
+func (mock *StoreMock) DeleteSystemCalls() []struct {
+	Ctx     context.Context
+	Ref     model.EntityRef
+	Version int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}
+	mock.lockDeleteSystem.RLock()
+	calls = mock.calls.DeleteSystem
+	mock.lockDeleteSystem.RUnlock()
+	return calls
+}
+
+// DeleteTemplate calls DeleteTemplateFunc.
+func (mock *StoreMock) DeleteTemplate(ctx context.Context, ref model.EntityRef, version int64) (model.Template, error) {
+	if mock.DeleteTemplateFunc == nil {
+		panic("StoreMock.DeleteTemplateFunc: method is nil but Store.DeleteTemplate was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}{
+		Ctx:     ctx,
+		Ref:     ref,
+		Version: version,
+	}
+	mock.lockDeleteTemplate.Lock()
+	mock.calls.DeleteTemplate = append(mock.calls.DeleteTemplate, callInfo)
+	mock.lockDeleteTemplate.Unlock()
+	return mock.DeleteTemplateFunc(ctx, ref, version)
+}
+
+// DeleteTemplateCalls gets all the calls that were made to DeleteTemplate.
+// Check the length with:
+//
+//	len(mockedStore.DeleteTemplateCalls())
+func (mock *StoreMock) DeleteTemplateCalls() []struct {
+	Ctx     context.Context
+	Ref     model.EntityRef
+	Version int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}
+	mock.lockDeleteTemplate.RLock()
+	calls = mock.calls.DeleteTemplate
+	mock.lockDeleteTemplate.RUnlock()
+	return calls
+}
+
+// DeleteUser calls DeleteUserFunc.
+func (mock *StoreMock) DeleteUser(ctx context.Context, ref model.EntityRef, version int64) (model.User, error) {
+	if mock.DeleteUserFunc == nil {
+		panic("StoreMock.DeleteUserFunc: method is nil but Store.DeleteUser was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}{
+		Ctx:     ctx,
+		Ref:     ref,
+		Version: version,
+	}
+	mock.lockDeleteUser.Lock()
+	mock.calls.DeleteUser = append(mock.calls.DeleteUser, callInfo)
+	mock.lockDeleteUser.Unlock()
+	return mock.DeleteUserFunc(ctx, ref, version)
+}
+
+// DeleteUserCalls gets all the calls that were made to DeleteUser.
+// Check the length with:
+//
+//	len(mockedStore.DeleteUserCalls())
+func (mock *StoreMock) DeleteUserCalls() []struct {
+	Ctx     context.Context
+	Ref     model.EntityRef
+	Version int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}
+	mock.lockDeleteUser.RLock()
+	calls = mock.calls.DeleteUser
+	mock.lockDeleteUser.RUnlock()
+	return calls
+}
+
+// ListAPIs calls ListAPIsFunc.
+func (mock *StoreMock) ListAPIs(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	if mock.ListAPIsFunc == nil {
+		panic("StoreMock.ListAPIsFunc: method is nil but Store.ListAPIs was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}{
+		Ctx:        ctx,
+		Filters:    filters,
+		Ordering:   ordering,
+		Pagination: pagination,
+	}
+	mock.lockListAPIs.Lock()
+	mock.calls.ListAPIs = append(mock.calls.ListAPIs, callInfo)
+	mock.lockListAPIs.Unlock()
+	return mock.ListAPIsFunc(ctx, filters, ordering, pagination)
+}
+
+// ListAPIsCalls gets all the calls that were made to ListAPIs.
+// Check the length with:
+//
+//	len(mockedStore.ListAPIsCalls())
+func (mock *StoreMock) ListAPIsCalls() []struct {
+	Ctx        context.Context
+	Filters    []Filter
+	Ordering   Ordering
+	Pagination Pagination
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}
+	mock.lockListAPIs.RLock()
+	calls = mock.calls.ListAPIs
+	mock.lockListAPIs.RUnlock()
+	return calls
+}
+
+// ListComponents calls ListComponentsFunc.
+func (mock *StoreMock) ListComponents(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	if mock.ListComponentsFunc == nil {
+		panic("StoreMock.ListComponentsFunc: method is nil but Store.ListComponents was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}{
+		Ctx:        ctx,
+		Filters:    filters,
+		Ordering:   ordering,
+		Pagination: pagination,
+	}
+	mock.lockListComponents.Lock()
+	mock.calls.ListComponents = append(mock.calls.ListComponents, callInfo)
+	mock.lockListComponents.Unlock()
+	return mock.ListComponentsFunc(ctx, filters, ordering, pagination)
+}
+
+// ListComponentsCalls gets all the calls that were made to ListComponents.
+// Check the length with:
+//
+//	len(mockedStore.ListComponentsCalls())
+func (mock *StoreMock) ListComponentsCalls() []struct {
+	Ctx        context.Context
+	Filters    []Filter
+	Ordering   Ordering
+	Pagination Pagination
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}
+	mock.lockListComponents.RLock()
+	calls = mock.calls.ListComponents
+	mock.lockListComponents.RUnlock()
+	return calls
+}
+
+// ListDanglingRefs calls ListDanglingRefsFunc.
+func (mock *StoreMock) ListDanglingRefs(ctx context.Context) ([]model.DanglingRef, error) {
+	if mock.ListDanglingRefsFunc == nil {
+		panic("StoreMock.ListDanglingRefsFunc: method is nil but Store.ListDanglingRefs was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockListDanglingRefs.Lock()
+	mock.calls.ListDanglingRefs = append(mock.calls.ListDanglingRefs, callInfo)
+	mock.lockListDanglingRefs.Unlock()
+	return mock.ListDanglingRefsFunc(ctx)
+}
+
+// ListDanglingRefsCalls gets all the calls that were made to ListDanglingRefs.
+// Check the length with:
+//
+//	len(mockedStore.ListDanglingRefsCalls())
+func (mock *StoreMock) ListDanglingRefsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockListDanglingRefs.RLock()
+	calls = mock.calls.ListDanglingRefs
+	mock.lockListDanglingRefs.RUnlock()
+	return calls
+}
+
+// ListDeletedEntities calls ListDeletedEntitiesFunc.
+func (mock *StoreMock) ListDeletedEntities(ctx context.Context, kinds []string, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	if mock.ListDeletedEntitiesFunc == nil {
+		panic("StoreMock.ListDeletedEntitiesFunc: method is nil but Store.ListDeletedEntities was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Kinds      []string
+		Pagination Pagination
+	}{
+		Ctx:        ctx,
+		Kinds:      kinds,
+		Pagination: pagination,
+	}
+	mock.lockListDeletedEntities.Lock()
+	mock.calls.ListDeletedEntities = append(mock.calls.ListDeletedEntities, callInfo)
+	mock.lockListDeletedEntities.Unlock()
+	return mock.ListDeletedEntitiesFunc(ctx, kinds, pagination)
+}
+
+// ListDeletedEntitiesCalls gets all the calls that were made to ListDeletedEntities.
+// Check the length with:
+//
+//	len(mockedStore.ListDeletedEntitiesCalls())
+func (mock *StoreMock) ListDeletedEntitiesCalls() []struct {
+	Ctx        context.Context
+	Kinds      []string
+	Pagination Pagination
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Kinds      []string
+		Pagination Pagination
+	}
+	mock.lockListDeletedEntities.RLock()
+	calls = mock.calls.ListDeletedEntities
+	mock.lockListDeletedEntities.RUnlock()
+	return calls
+}
+
+// ListDomains calls ListDomainsFunc.
+func (mock *StoreMock) ListDomains(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	if mock.ListDomainsFunc == nil {
+		panic("StoreMock.ListDomainsFunc: method is nil but Store.ListDomains was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}{
+		Ctx:        ctx,
+		Filters:    filters,
+		Ordering:   ordering,
+		Pagination: pagination,
+	}
+	mock.lockListDomains.Lock()
+	mock.calls.ListDomains = append(mock.calls.ListDomains, callInfo)
+	mock.lockListDomains.Unlock()
+	return mock.ListDomainsFunc(ctx, filters, ordering, pagination)
+}
+
+// ListDomainsCalls gets all the calls that were made to ListDomains.
+// Check the length with:
+//
+//	len(mockedStore.ListDomainsCalls())
+func (mock *StoreMock) ListDomainsCalls() []struct {
+	Ctx        context.Context
+	Filters    []Filter
+	Ordering   Ordering
+	Pagination Pagination
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}
+	mock.lockListDomains.RLock()
+	calls = mock.calls.ListDomains
+	mock.lockListDomains.RUnlock()
+	return calls
+}
+
+// ListEntities calls ListEntitiesFunc.
+func (mock *StoreMock) ListEntities(ctx context.Context, kinds []string, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	if mock.ListEntitiesFunc == nil {
+		panic("StoreMock.ListEntitiesFunc: method is nil but Store.ListEntities was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Kinds      []string
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}{
+		Ctx:        ctx,
+		Kinds:      kinds,
+		Filters:    filters,
+		Ordering:   ordering,
+		Pagination: pagination,
+	}
+	mock.lockListEntities.Lock()
+	mock.calls.ListEntities = append(mock.calls.ListEntities, callInfo)
+	mock.lockListEntities.Unlock()
+	return mock.ListEntitiesFunc(ctx, kinds, filters, ordering, pagination)
+}
+
+// ListEntitiesCalls gets all the calls that were made to ListEntities.
+// Check the length with:
+//
+//	len(mockedStore.ListEntitiesCalls())
+func (mock *StoreMock) ListEntitiesCalls() []struct {
+	Ctx        context.Context
+	Kinds      []string
+	Filters    []Filter
+	Ordering   Ordering
+	Pagination Pagination
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Kinds      []string
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}
+	mock.lockListEntities.RLock()
+	calls = mock.calls.ListEntities
+	mock.lockListEntities.RUnlock()
+	return calls
+}
+
+// ListExistingRefs calls ListExistingRefsFunc.
+func (mock *StoreMock) ListExistingRefs(ctx context.Context, refs []model.EntityRef) ([]model.EntityRef, error) {
+	if mock.ListExistingRefsFunc == nil {
+		panic("StoreMock.ListExistingRefsFunc: method is nil but Store.ListExistingRefs was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Refs []model.EntityRef
+	}{
+		Ctx:  ctx,
+		Refs: refs,
+	}
+	mock.lockListExistingRefs.Lock()
+	mock.calls.ListExistingRefs = append(mock.calls.ListExistingRefs, callInfo)
+	mock.lockListExistingRefs.Unlock()
+	return mock.ListExistingRefsFunc(ctx, refs)
+}
+
+// ListExistingRefsCalls gets all the calls that were made to ListExistingRefs.
+// Check the length with:
+//
+//	len(mockedStore.ListExistingRefsCalls())
+func (mock *StoreMock) ListExistingRefsCalls() []struct {
+	Ctx  context.Context
+	Refs []model.EntityRef
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Refs []model.EntityRef
+	}
+	mock.lockListExistingRefs.RLock()
+	calls = mock.calls.ListExistingRefs
+	mock.lockListExistingRefs.RUnlock()
+	return calls
+}
+
+// ListGroups calls ListGroupsFunc.
+func (mock *StoreMock) ListGroups(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	if mock.ListGroupsFunc == nil {
+		panic("StoreMock.ListGroupsFunc: method is nil but Store.ListGroups was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}{
+		Ctx:        ctx,
+		Filters:    filters,
+		Ordering:   ordering,
+		Pagination: pagination,
+	}
+	mock.lockListGroups.Lock()
+	mock.calls.ListGroups = append(mock.calls.ListGroups, callInfo)
+	mock.lockListGroups.Unlock()
+	return mock.ListGroupsFunc(ctx, filters, ordering, pagination)
+}
+
+// ListGroupsCalls gets all the calls that were made to ListGroups.
+// Check the length with:
+//
+//	len(mockedStore.ListGroupsCalls())
+func (mock *StoreMock) ListGroupsCalls() []struct {
+	Ctx        context.Context
+	Filters    []Filter
+	Ordering   Ordering
+	Pagination Pagination
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}
+	mock.lockListGroups.RLock()
+	calls = mock.calls.ListGroups
+	mock.lockListGroups.RUnlock()
+	return calls
+}
+
+// ListLocations calls ListLocationsFunc.
+func (mock *StoreMock) ListLocations(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	if mock.ListLocationsFunc == nil {
+		panic("StoreMock.ListLocationsFunc: method is nil but Store.ListLocations was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}{
+		Ctx:        ctx,
+		Filters:    filters,
+		Ordering:   ordering,
+		Pagination: pagination,
+	}
+	mock.lockListLocations.Lock()
+	mock.calls.ListLocations = append(mock.calls.ListLocations, callInfo)
+	mock.lockListLocations.Unlock()
+	return mock.ListLocationsFunc(ctx, filters, ordering, pagination)
+}
+
+// ListLocationsCalls gets all the calls that were made to ListLocations.
+// Check the length with:
+//
+//	len(mockedStore.ListLocationsCalls())
+func (mock *StoreMock) ListLocationsCalls() []struct {
+	Ctx        context.Context
+	Filters    []Filter
+	Ordering   Ordering
+	Pagination Pagination
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}
+	mock.lockListLocations.RLock()
+	calls = mock.calls.ListLocations
+	mock.lockListLocations.RUnlock()
+	return calls
+}
+
+// ListReferrers calls ListReferrersFunc.
+func (mock *StoreMock) ListReferrers(ctx context.Context, ref model.EntityRef) ([]model.Referrer, error) {
+	if mock.ListReferrersFunc == nil {
+		panic("StoreMock.ListReferrersFunc: method is nil but Store.ListReferrers was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}{
+		Ctx: ctx,
+		Ref: ref,
+	}
+	mock.lockListReferrers.Lock()
+	mock.calls.ListReferrers = append(mock.calls.ListReferrers, callInfo)
+	mock.lockListReferrers.Unlock()
+	return mock.ListReferrersFunc(ctx, ref)
+}
+
+// ListReferrersCalls gets all the calls that were made to ListReferrers.
+// Check the length with:
+//
+//	len(mockedStore.ListReferrersCalls())
+func (mock *StoreMock) ListReferrersCalls() []struct {
+	Ctx context.Context
+	Ref model.EntityRef
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}
+	mock.lockListReferrers.RLock()
+	calls = mock.calls.ListReferrers
+	mock.lockListReferrers.RUnlock()
+	return calls
+}
+
+// ListRelations calls ListRelationsFunc.
+func (mock *StoreMock) ListRelations(ctx context.Context, ref model.EntityRef) ([]model.Relation, error) {
+	if mock.ListRelationsFunc == nil {
+		panic("StoreMock.ListRelationsFunc: method is nil but Store.ListRelations was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}{
+		Ctx: ctx,
+		Ref: ref,
+	}
+	mock.lockListRelations.Lock()
+	mock.calls.ListRelations = append(mock.calls.ListRelations, callInfo)
+	mock.lockListRelations.Unlock()
+	return mock.ListRelationsFunc(ctx, ref)
+}
+
+// ListRelationsCalls gets all the calls that were made to ListRelations.
+// Check the length with:
+//
+//	len(mockedStore.ListRelationsCalls())
+func (mock *StoreMock) ListRelationsCalls() []struct {
+	Ctx context.Context
+	Ref model.EntityRef
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}
+	mock.lockListRelations.RLock()
+	calls = mock.calls.ListRelations
+	mock.lockListRelations.RUnlock()
+	return calls
+}
+
+// ListResources calls ListResourcesFunc.
+func (mock *StoreMock) ListResources(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	if mock.ListResourcesFunc == nil {
+		panic("StoreMock.ListResourcesFunc: method is nil but Store.ListResources was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}{
+		Ctx:        ctx,
+		Filters:    filters,
+		Ordering:   ordering,
+		Pagination: pagination,
+	}
+	mock.lockListResources.Lock()
+	mock.calls.ListResources = append(mock.calls.ListResources, callInfo)
+	mock.lockListResources.Unlock()
+	return mock.ListResourcesFunc(ctx, filters, ordering, pagination)
+}
+
+// ListResourcesCalls gets all the calls that were made to ListResources.
+// Check the length with:
+//
+//	len(mockedStore.ListResourcesCalls())
+func (mock *StoreMock) ListResourcesCalls() []struct {
+	Ctx        context.Context
+	Filters    []Filter
+	Ordering   Ordering
+	Pagination Pagination
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}
+	mock.lockListResources.RLock()
+	calls = mock.calls.ListResources
+	mock.lockListResources.RUnlock()
+	return calls
+}
+
+// ListRevisions calls ListRevisionsFunc.
+func (mock *StoreMock) ListRevisions(ctx context.Context, ref model.EntityRef, pagination Pagination) ([]model.Revision, Pagination, error) {
+	if mock.ListRevisionsFunc == nil {
+		panic("StoreMock.ListRevisionsFunc: method is nil but Store.ListRevisions was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Ref        model.EntityRef
+		Pagination Pagination
+	}{
+		Ctx:        ctx,
+		Ref:        ref,
+		Pagination: pagination,
+	}
+	mock.lockListRevisions.Lock()
+	mock.calls.ListRevisions = append(mock.calls.ListRevisions, callInfo)
+	mock.lockListRevisions.Unlock()
+	return mock.ListRevisionsFunc(ctx, ref, pagination)
+}
+
+// ListRevisionsCalls gets all the calls that were made to ListRevisions.
+// Check the length with:
+//
+//	len(mockedStore.ListRevisionsCalls())
+func (mock *StoreMock) ListRevisionsCalls() []struct {
+	Ctx        context.Context
+	Ref        model.EntityRef
+	Pagination Pagination
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Ref        model.EntityRef
+		Pagination Pagination
+	}
+	mock.lockListRevisions.RLock()
+	calls = mock.calls.ListRevisions
+	mock.lockListRevisions.RUnlock()
+	return calls
+}
+
+// ListSpecRefs calls ListSpecRefsFunc.
+func (mock *StoreMock) ListSpecRefs(ctx context.Context, fields []string) ([]model.EntitySpecRef, error) {
+	if mock.ListSpecRefsFunc == nil {
+		panic("StoreMock.ListSpecRefsFunc: method is nil but Store.ListSpecRefs was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Fields []string
+	}{
+		Ctx:    ctx,
+		Fields: fields,
+	}
+	mock.lockListSpecRefs.Lock()
+	mock.calls.ListSpecRefs = append(mock.calls.ListSpecRefs, callInfo)
+	mock.lockListSpecRefs.Unlock()
+	return mock.ListSpecRefsFunc(ctx, fields)
+}
+
+// ListSpecRefsCalls gets all the calls that were made to ListSpecRefs.
+// Check the length with:
+//
+//	len(mockedStore.ListSpecRefsCalls())
+func (mock *StoreMock) ListSpecRefsCalls() []struct {
+	Ctx    context.Context
+	Fields []string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Fields []string
+	}
+	mock.lockListSpecRefs.RLock()
+	calls = mock.calls.ListSpecRefs
+	mock.lockListSpecRefs.RUnlock()
+	return calls
+}
+
+// ListSystems calls ListSystemsFunc.
+func (mock *StoreMock) ListSystems(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	if mock.ListSystemsFunc == nil {
+		panic("StoreMock.ListSystemsFunc: method is nil but Store.ListSystems was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}{
+		Ctx:        ctx,
+		Filters:    filters,
+		Ordering:   ordering,
+		Pagination: pagination,
+	}
+	mock.lockListSystems.Lock()
+	mock.calls.ListSystems = append(mock.calls.ListSystems, callInfo)
+	mock.lockListSystems.Unlock()
+	return mock.ListSystemsFunc(ctx, filters, ordering, pagination)
+}
+
+// ListSystemsCalls gets all the calls that were made to ListSystems.
+// Check the length with:
+//
+//	len(mockedStore.ListSystemsCalls())
+func (mock *StoreMock) ListSystemsCalls() []struct {
+	Ctx        context.Context
+	Filters    []Filter
+	Ordering   Ordering
+	Pagination Pagination
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}
+	mock.lockListSystems.RLock()
+	calls = mock.calls.ListSystems
+	mock.lockListSystems.RUnlock()
+	return calls
+}
+
+// ListTemplates calls ListTemplatesFunc.
+func (mock *StoreMock) ListTemplates(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	if mock.ListTemplatesFunc == nil {
+		panic("StoreMock.ListTemplatesFunc: method is nil but Store.ListTemplates was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}{
+		Ctx:        ctx,
+		Filters:    filters,
+		Ordering:   ordering,
+		Pagination: pagination,
+	}
+	mock.lockListTemplates.Lock()
+	mock.calls.ListTemplates = append(mock.calls.ListTemplates, callInfo)
+	mock.lockListTemplates.Unlock()
+	return mock.ListTemplatesFunc(ctx, filters, ordering, pagination)
+}
+
+// ListTemplatesCalls gets all the calls that were made to ListTemplates.
+// Check the length with:
+//
+//	len(mockedStore.ListTemplatesCalls())
+func (mock *StoreMock) ListTemplatesCalls() []struct {
+	Ctx        context.Context
+	Filters    []Filter
+	Ordering   Ordering
+	Pagination Pagination
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}
+	mock.lockListTemplates.RLock()
+	calls = mock.calls.ListTemplates
+	mock.lockListTemplates.RUnlock()
+	return calls
+}
+
+// ListUsers calls ListUsersFunc.
+func (mock *StoreMock) ListUsers(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	if mock.ListUsersFunc == nil {
+		panic("StoreMock.ListUsersFunc: method is nil but Store.ListUsers was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}{
+		Ctx:        ctx,
+		Filters:    filters,
+		Ordering:   ordering,
+		Pagination: pagination,
+	}
+	mock.lockListUsers.Lock()
+	mock.calls.ListUsers = append(mock.calls.ListUsers, callInfo)
+	mock.lockListUsers.Unlock()
+	return mock.ListUsersFunc(ctx, filters, ordering, pagination)
+}
+
+// ListUsersCalls gets all the calls that were made to ListUsers.
+// Check the length with:
+//
+//	len(mockedStore.ListUsersCalls())
+func (mock *StoreMock) ListUsersCalls() []struct {
+	Ctx        context.Context
+	Filters    []Filter
+	Ordering   Ordering
+	Pagination Pagination
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Filters    []Filter
+		Ordering   Ordering
+		Pagination Pagination
+	}
+	mock.lockListUsers.RLock()
+	calls = mock.calls.ListUsers
+	mock.lockListUsers.RUnlock()
+	return calls
+}
+
+// PurgeEntities calls PurgeEntitiesFunc.
+func (mock *StoreMock) PurgeEntities(ctx context.Context, before time.Time) (int64, error) {
+	if mock.PurgeEntitiesFunc == nil {
+		panic("StoreMock.PurgeEntitiesFunc: method is nil but Store.PurgeEntities was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Before time.Time
+	}{
+		Ctx:    ctx,
+		Before: before,
+	}
+	mock.lockPurgeEntities.Lock()
+	mock.calls.PurgeEntities = append(mock.calls.PurgeEntities, callInfo)
+	mock.lockPurgeEntities.Unlock()
+	return mock.PurgeEntitiesFunc(ctx, before)
+}
+
+// PurgeEntitiesCalls gets all the calls that were made to PurgeEntities.
+// Check the length with:
+//
+//	len(mockedStore.PurgeEntitiesCalls())
+func (mock *StoreMock) PurgeEntitiesCalls() []struct {
+	Ctx    context.Context
+	Before time.Time
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Before time.Time
+	}
+	mock.lockPurgeEntities.RLock()
+	calls = mock.calls.PurgeEntities
+	mock.lockPurgeEntities.RUnlock()
+	return calls
+}
+
+// ReadAPI calls ReadAPIFunc.
+func (mock *StoreMock) ReadAPI(ctx context.Context, ref model.EntityRef) (model.API, error) {
+	if mock.ReadAPIFunc == nil {
+		panic("StoreMock.ReadAPIFunc: method is nil but Store.ReadAPI was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}{
+		Ctx: ctx,
+		Ref: ref,
+	}
+	mock.lockReadAPI.Lock()
+	mock.calls.ReadAPI = append(mock.calls.ReadAPI, callInfo)
+	mock.lockReadAPI.Unlock()
+	return mock.ReadAPIFunc(ctx, ref)
+}
+
+// ReadAPICalls gets all the calls that were made to ReadAPI.
+// Check the length with:
+//
+//	len(mockedStore.ReadAPICalls())
+func (mock *StoreMock) ReadAPICalls() []struct {
+	Ctx context.Context
+	Ref model.EntityRef
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}
+	mock.lockReadAPI.RLock()
+	calls = mock.calls.ReadAPI
+	mock.lockReadAPI.RUnlock()
+	return calls
+}
+
+// ReadComponent calls ReadComponentFunc.
+func (mock *StoreMock) ReadComponent(ctx context.Context, ref model.EntityRef) (model.Component, error) {
+	if mock.ReadComponentFunc == nil {
+		panic("StoreMock.ReadComponentFunc: method is nil but Store.ReadComponent was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}{
+		Ctx: ctx,
+		Ref: ref,
+	}
+	mock.lockReadComponent.Lock()
+	mock.calls.ReadComponent = append(mock.calls.ReadComponent, callInfo)
+	mock.lockReadComponent.Unlock()
+	return mock.ReadComponentFunc(ctx, ref)
+}
+
+// ReadComponentCalls gets all the calls that were made to ReadComponent.
+// Check the length with:
+//
+//	len(mockedStore.ReadComponentCalls())
+func (mock *StoreMock) ReadComponentCalls() []struct {
+	Ctx context.Context
+	Ref model.EntityRef
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}
+	mock.lockReadComponent.RLock()
+	calls = mock.calls.ReadComponent
+	mock.lockReadComponent.RUnlock()
+	return calls
+}
+
+// ReadDomain calls ReadDomainFunc.
+func (mock *StoreMock) ReadDomain(ctx context.Context, ref model.EntityRef) (model.Domain, error) {
+	if mock.ReadDomainFunc == nil {
+		panic("StoreMock.ReadDomainFunc: method is nil but Store.ReadDomain was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}{
+		Ctx: ctx,
+		Ref: ref,
+	}
+	mock.lockReadDomain.Lock()
+	mock.calls.ReadDomain = append(mock.calls.ReadDomain, callInfo)
+	mock.lockReadDomain.Unlock()
+	return mock.ReadDomainFunc(ctx, ref)
+}
+
+// ReadDomainCalls gets all the calls that were made to ReadDomain.
+// Check the length with:
+//
+//	len(mockedStore.ReadDomainCalls())
+func (mock *StoreMock) ReadDomainCalls() []struct {
+	Ctx context.Context
+	Ref model.EntityRef
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}
+	mock.lockReadDomain.RLock()
+	calls = mock.calls.ReadDomain
+	mock.lockReadDomain.RUnlock()
+	return calls
+}
+
+// ReadGroup calls ReadGroupFunc.
+func (mock *StoreMock) ReadGroup(ctx context.Context, ref model.EntityRef) (model.Group, error) {
+	if mock.ReadGroupFunc == nil {
+		panic("StoreMock.ReadGroupFunc: method is nil but Store.ReadGroup was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}{
+		Ctx: ctx,
+		Ref: ref,
+	}
+	mock.lockReadGroup.Lock()
+	mock.calls.ReadGroup = append(mock.calls.ReadGroup, callInfo)
+	mock.lockReadGroup.Unlock()
+	return mock.ReadGroupFunc(ctx, ref)
+}
+
+// ReadGroupCalls gets all the calls that were made to ReadGroup.
+// Check the length with:
+//
+//	len(mockedStore.ReadGroupCalls())
+func (mock *StoreMock) ReadGroupCalls() []struct {
+	Ctx context.Context
+	Ref model.EntityRef
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}
+	mock.lockReadGroup.RLock()
+	calls = mock.calls.ReadGroup
+	mock.lockReadGroup.RUnlock()
+	return calls
+}
+
+// ReadLocation calls ReadLocationFunc.
+func (mock *StoreMock) ReadLocation(ctx context.Context, ref model.EntityRef) (model.Location, error) {
+	if mock.ReadLocationFunc == nil {
+		panic("StoreMock.ReadLocationFunc: method is nil but Store.ReadLocation was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}{
+		Ctx: ctx,
+		Ref: ref,
+	}
+	mock.lockReadLocation.Lock()
+	mock.calls.ReadLocation = append(mock.calls.ReadLocation, callInfo)
+	mock.lockReadLocation.Unlock()
+	return mock.ReadLocationFunc(ctx, ref)
+}
+
+// ReadLocationCalls gets all the calls that were made to ReadLocation.
+// Check the length with:
+//
+//	len(mockedStore.ReadLocationCalls())
+func (mock *StoreMock) ReadLocationCalls() []struct {
+	Ctx context.Context
+	Ref model.EntityRef
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}
+	mock.lockReadLocation.RLock()
+	calls = mock.calls.ReadLocation
+	mock.lockReadLocation.RUnlock()
+	return calls
+}
+
+// ReadResource calls ReadResourceFunc.
+func (mock *StoreMock) ReadResource(ctx context.Context, ref model.EntityRef) (model.Resource, error) {
+	if mock.ReadResourceFunc == nil {
+		panic("StoreMock.ReadResourceFunc: method is nil but Store.ReadResource was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}{
+		Ctx: ctx,
+		Ref: ref,
+	}
+	mock.lockReadResource.Lock()
+	mock.calls.ReadResource = append(mock.calls.ReadResource, callInfo)
+	mock.lockReadResource.Unlock()
+	return mock.ReadResourceFunc(ctx, ref)
+}
+
+// ReadResourceCalls gets all the calls that were made to ReadResource.
+// Check the length with:
+//
+//	len(mockedStore.ReadResourceCalls())
+func (mock *StoreMock) ReadResourceCalls() []struct {
+	Ctx context.Context
+	Ref model.EntityRef
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}
+	mock.lockReadResource.RLock()
+	calls = mock.calls.ReadResource
+	mock.lockReadResource.RUnlock()
+	return calls
+}
+
+// ReadRevision calls ReadRevisionFunc.
+func (mock *StoreMock) ReadRevision(ctx context.Context, ref model.EntityRef, version int64) (model.Revision, error) {
+	if mock.ReadRevisionFunc == nil {
+		panic("StoreMock.ReadRevisionFunc: method is nil but Store.ReadRevision was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}{
+		Ctx:     ctx,
+		Ref:     ref,
+		Version: version,
+	}
+	mock.lockReadRevision.Lock()
+	mock.calls.ReadRevision = append(mock.calls.ReadRevision, callInfo)
+	mock.lockReadRevision.Unlock()
+	return mock.ReadRevisionFunc(ctx, ref, version)
+}
+
+// ReadRevisionCalls gets all the calls that were made to ReadRevision.
+// Check the length with:
+//
+//	len(mockedStore.ReadRevisionCalls())
+func (mock *StoreMock) ReadRevisionCalls() []struct {
+	Ctx     context.Context
+	Ref     model.EntityRef
+	Version int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Ref     model.EntityRef
+		Version int64
+	}
+	mock.lockReadRevision.RLock()
+	calls = mock.calls.ReadRevision
+	mock.lockReadRevision.RUnlock()
+	return calls
+}
+
+// ReadSystem calls ReadSystemFunc.
+func (mock *StoreMock) ReadSystem(ctx context.Context, ref model.EntityRef) (model.System, error) {
+	if mock.ReadSystemFunc == nil {
+		panic("StoreMock.ReadSystemFunc: method is nil but Store.ReadSystem was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}{
+		Ctx: ctx,
+		Ref: ref,
+	}
+	mock.lockReadSystem.Lock()
+	mock.calls.ReadSystem = append(mock.calls.ReadSystem, callInfo)
+	mock.lockReadSystem.Unlock()
+	return mock.ReadSystemFunc(ctx, ref)
+}
+
+// ReadSystemCalls gets all the calls that were made to ReadSystem.
+// Check the length with:
+//
+//	len(mockedStore.ReadSystemCalls())
+func (mock *StoreMock) ReadSystemCalls() []struct {
+	Ctx context.Context
+	Ref model.EntityRef
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}
+	mock.lockReadSystem.RLock()
+	calls = mock.calls.ReadSystem
+	mock.lockReadSystem.RUnlock()
+	return calls
+}
+
+// ReadTemplate calls ReadTemplateFunc.
+func (mock *StoreMock) ReadTemplate(ctx context.Context, ref model.EntityRef) (model.Template, error) {
+	if mock.ReadTemplateFunc == nil {
+		panic("StoreMock.ReadTemplateFunc: method is nil but Store.ReadTemplate was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}{
+		Ctx: ctx,
+		Ref: ref,
+	}
+	mock.lockReadTemplate.Lock()
+	mock.calls.ReadTemplate = append(mock.calls.ReadTemplate, callInfo)
+	mock.lockReadTemplate.Unlock()
+	return mock.ReadTemplateFunc(ctx, ref)
+}
+
+// ReadTemplateCalls gets all the calls that were made to ReadTemplate.
+// Check the length with:
+//
+//	len(mockedStore.ReadTemplateCalls())
+func (mock *StoreMock) ReadTemplateCalls() []struct {
+	Ctx context.Context
+	Ref model.EntityRef
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}
+	mock.lockReadTemplate.RLock()
+	calls = mock.calls.ReadTemplate
+	mock.lockReadTemplate.RUnlock()
+	return calls
+}
+
+// ReadUser calls ReadUserFunc.
+func (mock *StoreMock) ReadUser(ctx context.Context, ref model.EntityRef) (model.User, error) {
+	if mock.ReadUserFunc == nil {
+		panic("StoreMock.ReadUserFunc: method is nil but Store.ReadUser was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}{
+		Ctx: ctx,
+		Ref: ref,
+	}
+	mock.lockReadUser.Lock()
+	mock.calls.ReadUser = append(mock.calls.ReadUser, callInfo)
+	mock.lockReadUser.Unlock()
+	return mock.ReadUserFunc(ctx, ref)
+}
+
+// ReadUserCalls gets all the calls that were made to ReadUser.
+// Check the length with:
+//
+//	len(mockedStore.ReadUserCalls())
+func (mock *StoreMock) ReadUserCalls() []struct {
+	Ctx context.Context
+	Ref model.EntityRef
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}
+	mock.lockReadUser.RLock()
+	calls = mock.calls.ReadUser
+	mock.lockReadUser.RUnlock()
+	return calls
+}
+
+// ResolveUID calls ResolveUIDFunc.
+func (mock *StoreMock) ResolveUID(ctx context.Context, uid string) (model.EntityRef, error) {
+	if mock.ResolveUIDFunc == nil {
+		panic("StoreMock.ResolveUIDFunc: method is nil but Store.ResolveUID was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		UID string
+	}{
+		Ctx: ctx,
+		UID: uid,
+	}
+	mock.lockResolveUID.Lock()
+	mock.calls.ResolveUID = append(mock.calls.ResolveUID, callInfo)
+	mock.lockResolveUID.Unlock()
+	return mock.ResolveUIDFunc(ctx, uid)
+}
+
+// ResolveUIDCalls gets all the calls that were made to ResolveUID.
+// Check the length with:
+//
+//	len(mockedStore.ResolveUIDCalls())
+func (mock *StoreMock) ResolveUIDCalls() []struct {
+	Ctx context.Context
+	UID string
+} {
+	var calls []struct {
+		Ctx context.Context
+		UID string
+	}
+	mock.lockResolveUID.RLock()
+	calls = mock.calls.ResolveUID
+	mock.lockResolveUID.RUnlock()
+	return calls
+}
+
+// RestoreEntity calls RestoreEntityFunc.
+func (mock *StoreMock) RestoreEntity(ctx context.Context, ref model.EntityRef) error {
+	if mock.RestoreEntityFunc == nil {
+		panic("StoreMock.RestoreEntityFunc: method is nil but Store.RestoreEntity was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}{
+		Ctx: ctx,
+		Ref: ref,
+	}
+	mock.lockRestoreEntity.Lock()
+	mock.calls.RestoreEntity = append(mock.calls.RestoreEntity, callInfo)
+	mock.lockRestoreEntity.Unlock()
+	return mock.RestoreEntityFunc(ctx, ref)
+}
+
+// RestoreEntityCalls gets all the calls that were made to RestoreEntity.
+// Check the length with:
+//
+//	len(mockedStore.RestoreEntityCalls())
+func (mock *StoreMock) RestoreEntityCalls() []struct {
+	Ctx context.Context
+	Ref model.EntityRef
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ref model.EntityRef
+	}
+	mock.lockRestoreEntity.RLock()
+	calls = mock.calls.RestoreEntity
+	mock.lockRestoreEntity.RUnlock()
+	return calls
+}
+
+// SearchEntities calls SearchEntitiesFunc.
+func (mock *StoreMock) SearchEntities(ctx context.Context, query string, pagination Pagination) ([]SearchMatch, Pagination, error) {
+	if mock.SearchEntitiesFunc == nil {
+		panic("StoreMock.SearchEntitiesFunc: method is nil but Store.SearchEntities was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Query      string
+		Pagination Pagination
+	}{
+		Ctx:        ctx,
+		Query:      query,
+		Pagination: pagination,
+	}
+	mock.lockSearchEntities.Lock()
+	mock.calls.SearchEntities = append(mock.calls.SearchEntities, callInfo)
+	mock.lockSearchEntities.Unlock()
+	return mock.SearchEntitiesFunc(ctx, query, pagination)
+}
+
+// SearchEntitiesCalls gets all the calls that were made to SearchEntities.
+// Check the length with:
+//
+//	len(mockedStore.SearchEntitiesCalls())
+func (mock *StoreMock) SearchEntitiesCalls() []struct {
+	Ctx        context.Context
+	Query      string
+	Pagination Pagination
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Query      string
+		Pagination Pagination
+	}
+	mock.lockSearchEntities.RLock()
+	calls = mock.calls.SearchEntities
+	mock.lockSearchEntities.RUnlock()
+	return calls
+}
+
+// UpdateAPI calls UpdateAPIFunc.
+func (mock *StoreMock) UpdateAPI(ctx context.Context, a model.API) (model.API, error) {
+	if mock.UpdateAPIFunc == nil {
+		panic("StoreMock.UpdateAPIFunc: method is nil but Store.UpdateAPI was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		A   model.API
+	}{
+		Ctx: ctx,
+		A:   a,
+	}
+	mock.lockUpdateAPI.Lock()
+	mock.calls.UpdateAPI = append(mock.calls.UpdateAPI, callInfo)
+	mock.lockUpdateAPI.Unlock()
+	return mock.UpdateAPIFunc(ctx, a)
+}
+
+// UpdateAPICalls gets all the calls that were made to UpdateAPI.
+// Check the length with:
+//
+//	len(mockedStore.UpdateAPICalls())
+func (mock *StoreMock) UpdateAPICalls() []struct {
+	Ctx context.Context
+	A   model.API
+} {
+	var calls []struct {
+		Ctx context.Context
+		A   model.API
+	}
+	mock.lockUpdateAPI.RLock()
+	calls = mock.calls.UpdateAPI
+	mock.lockUpdateAPI.RUnlock()
+	return calls
+}
+
+// UpdateComponent calls UpdateComponentFunc.
+func (mock *StoreMock) UpdateComponent(ctx context.Context, c model.Component) (model.Component, error) {
+	if mock.UpdateComponentFunc == nil {
+		panic("StoreMock.UpdateComponentFunc: method is nil but Store.UpdateComponent was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		C   model.Component
+	}{
+		Ctx: ctx,
+		C:   c,
+	}
+	mock.lockUpdateComponent.Lock()
+	mock.calls.UpdateComponent = append(mock.calls.UpdateComponent, callInfo)
+	mock.lockUpdateComponent.Unlock()
+	return mock.UpdateComponentFunc(ctx, c)
+}
+
+// UpdateComponentCalls gets all the calls that were made to UpdateComponent.
+// Check the length with:
+//
+//	len(mockedStore.UpdateComponentCalls())
+func (mock *StoreMock) UpdateComponentCalls() []struct {
+	Ctx context.Context
+	C   model.Component
+} {
+	var calls []struct {
+		Ctx context.Context
+		C   model.Component
+	}
+	mock.lockUpdateComponent.RLock()
+	calls = mock.calls.UpdateComponent
+	mock.lockUpdateComponent.RUnlock()
+	return calls
+}
+
+// UpdateDomain calls UpdateDomainFunc.
+func (mock *StoreMock) UpdateDomain(ctx context.Context, d model.Domain) (model.Domain, error) {
+	if mock.UpdateDomainFunc == nil {
+		panic("StoreMock.UpdateDomainFunc: method is nil but Store.UpdateDomain was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		D   model.Domain
+	}{
+		Ctx: ctx,
+		D:   d,
+	}
+	mock.lockUpdateDomain.Lock()
+	mock.calls.UpdateDomain = append(mock.calls.UpdateDomain, callInfo)
+	mock.lockUpdateDomain.Unlock()
+	return mock.UpdateDomainFunc(ctx, d)
+}
+
+// UpdateDomainCalls gets all the calls that were made to UpdateDomain.
+// Check the length with:
+//
+//	len(mockedStore.UpdateDomainCalls())
+func (mock *StoreMock) UpdateDomainCalls() []struct {
+	Ctx context.Context
+	D   model.Domain
+} {
+	var calls []struct {
+		Ctx context.Context
+		D   model.Domain
+	}
+	mock.lockUpdateDomain.RLock()
+	calls = mock.calls.UpdateDomain
+	mock.lockUpdateDomain.RUnlock()
+	return calls
+}
+
+// UpdateGroup calls UpdateGroupFunc.
+func (mock *StoreMock) UpdateGroup(ctx context.Context, g model.Group) (model.Group, error) {
+	if mock.UpdateGroupFunc == nil {
+		panic("StoreMock.UpdateGroupFunc: method is nil but Store.UpdateGroup was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		G   model.Group
+	}{
+		Ctx: ctx,
+		G:   g,
+	}
+	mock.lockUpdateGroup.Lock()
+	mock.calls.UpdateGroup = append(mock.calls.UpdateGroup, callInfo)
+	mock.lockUpdateGroup.Unlock()
+	return mock.UpdateGroupFunc(ctx, g)
+}
+
+// UpdateGroupCalls gets all the calls that were made to UpdateGroup.
+// Check the length with:
+//
+//	len(mockedStore.UpdateGroupCalls())
+func (mock *StoreMock) UpdateGroupCalls() []struct {
+	Ctx context.Context
+	G   model.Group
+} {
+	var calls []struct {
+		Ctx context.Context
+		G   model.Group
+	}
+	mock.lockUpdateGroup.RLock()
+	calls = mock.calls.UpdateGroup
+	mock.lockUpdateGroup.RUnlock()
+	return calls
+}
+
+// UpdateLocation calls UpdateLocationFunc.
+func (mock *StoreMock) UpdateLocation(ctx context.Context, l model.Location) (model.Location, error) {
+	if mock.UpdateLocationFunc == nil {
+		panic("StoreMock.UpdateLocationFunc: method is nil but Store.UpdateLocation was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		L   model.Location
+	}{
+		Ctx: ctx,
+		L:   l,
+	}
+	mock.lockUpdateLocation.Lock()
+	mock.calls.UpdateLocation = append(mock.calls.UpdateLocation, callInfo)
+	mock.lockUpdateLocation.Unlock()
+	return mock.UpdateLocationFunc(ctx, l)
+}
+
+// UpdateLocationCalls gets all the calls that were made to UpdateLocation.
+// Check the length with:
+//
+//	len(mockedStore.UpdateLocationCalls())
+func (mock *StoreMock) UpdateLocationCalls() []struct {
+	Ctx context.Context
+	L   model.Location
+} {
+	var calls []struct {
+		Ctx context.Context
+		L   model.Location
+	}
+	mock.lockUpdateLocation.RLock()
+	calls = mock.calls.UpdateLocation
+	mock.lockUpdateLocation.RUnlock()
+	return calls
+}
+
+// UpdateResource calls UpdateResourceFunc.
+func (mock *StoreMock) UpdateResource(ctx context.Context, r model.Resource) (model.Resource, error) {
+	if mock.UpdateResourceFunc == nil {
+		panic("StoreMock.UpdateResourceFunc: method is nil but Store.UpdateResource was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		R   model.Resource
+	}{
+		Ctx: ctx,
+		R:   r,
+	}
+	mock.lockUpdateResource.Lock()
+	mock.calls.UpdateResource = append(mock.calls.UpdateResource, callInfo)
+	mock.lockUpdateResource.Unlock()
+	return mock.UpdateResourceFunc(ctx, r)
+}
+
+// UpdateResourceCalls gets all the calls that were made to UpdateResource.
+// Check the length with:
+//
+//	len(mockedStore.UpdateResourceCalls())
+func (mock *StoreMock) UpdateResourceCalls() []struct {
+	Ctx context.Context
+	R   model.Resource
+} {
+	var calls []struct {
+		Ctx context.Context
+		R   model.Resource
+	}
+	mock.lockUpdateResource.RLock()
+	calls = mock.calls.UpdateResource
+	mock.lockUpdateResource.RUnlock()
+	return calls
+}
+
+// UpdateSystem calls UpdateSystemFunc.
+func (mock *StoreMock) UpdateSystem(ctx context.Context, s model.System) (model.System, error) {
+	if mock.UpdateSystemFunc == nil {
+		panic("StoreMock.UpdateSystemFunc: method is nil but Store.UpdateSystem was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		S   model.System
+	}{
+		Ctx: ctx,
+		S:   s,
+	}
+	mock.lockUpdateSystem.Lock()
+	mock.calls.UpdateSystem = append(mock.calls.UpdateSystem, callInfo)
+	mock.lockUpdateSystem.Unlock()
+	return mock.UpdateSystemFunc(ctx, s)
+}
+
+// UpdateSystemCalls gets all the calls that were made to UpdateSystem.
+// Check the length with:
+//
+//	len(mockedStore.UpdateSystemCalls())
+func (mock *StoreMock) UpdateSystemCalls() []struct {
+	Ctx context.Context
+	S   model.System
+} {
+	var calls []struct {
+		Ctx context.Context
+		S   model.System
+	}
+	mock.lockUpdateSystem.RLock()
+	calls = mock.calls.UpdateSystem
+	mock.lockUpdateSystem.RUnlock()
+	return calls
+}
+
+// UpdateTemplate calls UpdateTemplateFunc.
+func (mock *StoreMock) UpdateTemplate(ctx context.Context, t model.Template) (model.Template, error) {
+	if mock.UpdateTemplateFunc == nil {
+		panic("StoreMock.UpdateTemplateFunc: method is nil but Store.UpdateTemplate was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		T   model.Template
+	}{
+		Ctx: ctx,
+		T:   t,
+	}
+	mock.lockUpdateTemplate.Lock()
+	mock.calls.UpdateTemplate = append(mock.calls.UpdateTemplate, callInfo)
+	mock.lockUpdateTemplate.Unlock()
+	return mock.UpdateTemplateFunc(ctx, t)
+}
+
+// UpdateTemplateCalls gets all the calls that were made to UpdateTemplate.
+// Check the length with:
+//
+//	len(mockedStore.UpdateTemplateCalls())
+func (mock *StoreMock) UpdateTemplateCalls() []struct {
+	Ctx context.Context
+	T   model.Template
+} {
+	var calls []struct {
+		Ctx context.Context
+		T   model.Template
+	}
+	mock.lockUpdateTemplate.RLock()
+	calls = mock.calls.UpdateTemplate
+	mock.lockUpdateTemplate.RUnlock()
+	return calls
+}
+
+// UpdateUser calls UpdateUserFunc.
+func (mock *StoreMock) UpdateUser(ctx context.Context, u model.User) (model.User, error) {
+	if mock.UpdateUserFunc == nil {
+		panic("StoreMock.UpdateUserFunc: method is nil but Store.UpdateUser was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		U   model.User
+	}{
+		Ctx: ctx,
+		U:   u,
+	}
+	mock.lockUpdateUser.Lock()
+	mock.calls.UpdateUser = append(mock.calls.UpdateUser, callInfo)
+	mock.lockUpdateUser.Unlock()
+	return mock.UpdateUserFunc(ctx, u)
+}
+
+// UpdateUserCalls gets all the calls that were made to UpdateUser.
+// Check the length with:
+//
+//	len(mockedStore.UpdateUserCalls())
+func (mock *StoreMock) UpdateUserCalls() []struct {
+	Ctx context.Context
+	U   model.User
+} {
+	var calls []struct {
+		Ctx context.Context
+		U   model.User
+	}
+	mock.lockUpdateUser.RLock()
+	calls = mock.calls.UpdateUser
+	mock.lockUpdateUser.RUnlock()
+	return calls
+}
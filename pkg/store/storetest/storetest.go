@@ -0,0 +1,1576 @@
+// Package storetest is a conformance suite for implementations of
+// store.Store. Every store implementation runs it from its own tests, so that
+// they all behave the same way; implementations outside this module can run
+// it too.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/bhavanki/rewind/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// NewStore makes a new, empty store for a test. It should skip the test if
+// the store cannot be made in the current environment.
+type NewStore func(t *testing.T) store.Store
+
+// Run runs the conformance suite against the stores that newStore makes.
+// Each subtest gets a store of its own.
+func Run(t *testing.T, newStore NewStore) {
+	t.Run("CRUD", func(t *testing.T) {
+		testCRUD(t, newStore)
+	})
+	t.Run("UpdateMetadata", func(t *testing.T) {
+		testUpdateMetadata(t, newStore(t))
+	})
+	t.Run("DeleteCascades", func(t *testing.T) {
+		testDeleteCascades(t, newStore(t))
+	})
+	t.Run("ListFilters", func(t *testing.T) {
+		testListFilters(t, newStore(t))
+	})
+	t.Run("ListOrdering", func(t *testing.T) {
+		testListOrdering(t, newStore(t))
+	})
+	t.Run("ListPagination", func(t *testing.T) {
+		testListPagination(t, newStore(t))
+	})
+	t.Run("ListEntities", func(t *testing.T) {
+		testListEntities(t, newStore(t))
+	})
+	t.Run("SearchEntities", func(t *testing.T) {
+		testSearchEntities(t, newStore(t))
+	})
+	t.Run("Errors", func(t *testing.T) {
+		testErrors(t, newStore(t))
+	})
+	t.Run("Versions", func(t *testing.T) {
+		testVersions(t, newStore(t))
+	})
+	t.Run("UIDs", func(t *testing.T) {
+		testUIDs(t, newStore(t))
+	})
+	t.Run("History", func(t *testing.T) {
+		testHistory(t, newStore(t))
+	})
+	t.Run("SoftDelete", func(t *testing.T) {
+		testSoftDelete(t, newStore(t))
+	})
+	t.Run("Relations", func(t *testing.T) {
+		testRelations(t, newStore(t))
+	})
+	t.Run("Referrers", func(t *testing.T) {
+		testReferrers(t, newStore(t))
+	})
+	t.Run("DanglingRefs", func(t *testing.T) {
+		testDanglingRefs(t, newStore(t))
+	})
+	t.Run("SpecRefs", func(t *testing.T) {
+		testSpecRefs(t, newStore(t))
+	})
+	t.Run("ExistingRefs", func(t *testing.T) {
+		testExistingRefs(t, newStore(t))
+	})
+	t.Run("Canceled", func(t *testing.T) {
+		testCanceled(t, newStore(t))
+	})
+}
+
+// ---
+
+// crudCase exercises the operations of a single kind.
+type crudCase[T any] struct {
+	full     T
+	entityOf func(*T) *model.Entity
+	// modify changes the spec of an entity for an update.
+	modify  func(*T)
+	create  func(context.Context, T) (T, error)
+	read    func(context.Context, model.EntityRef) (T, error)
+	update  func(context.Context, T) (T, error)
+	delete  func(context.Context, model.EntityRef, int64) (T, error)
+	list    func(context.Context, []store.Filter, store.Ordering, store.Pagination) ([]model.EntityRef, store.Pagination, error)
+	restore func(context.Context, model.EntityRef) error
+}
+
+func (c crudCase[T]) run(t *testing.T) {
+	ctx := context.Background()
+	full := c.full
+	ref := c.entityOf(&full).EntityRef()
+
+	created, err := c.create(ctx, full)
+	require.NoError(t, err)
+	id := c.entityOf(&created).ID
+	assert.NotZero(t, id)
+	metadata := c.entityOf(&created).Metadata
+	assert.NotEmpty(t, metadata.UID)
+	assert.Equal(t, "1", metadata.Etag)
+	assert.NotZero(t, metadata.CreatedAt)
+	assert.Equal(t, metadata.CreatedAt, metadata.UpdatedAt)
+	expected := full
+	setStoreFields(c.entityOf(&expected), c.entityOf(&created))
+	assert.Equal(t, expected, created)
+
+	read, err := c.read(ctx, ref)
+	require.NoError(t, err)
+	assert.Equal(t, expected, read)
+
+	refs, _, err := c.list(ctx, nil, store.Ordering{}, store.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{ref}, refs)
+
+	_, err = c.create(ctx, full)
+	assert.ErrorIs(t, err, store.ErrAlreadyExists, "creating a duplicate")
+
+	c.modify(&expected)
+	c.entityOf(&expected).Metadata.Title = "my-new-title"
+	updated, err := c.update(ctx, expected)
+	require.NoError(t, err)
+	c.entityOf(&expected).Version = 2
+	c.entityOf(&expected).Metadata.Etag = "2"
+	assert.False(t, c.entityOf(&updated).Metadata.UpdatedAt.Before(metadata.CreatedAt))
+	c.entityOf(&expected).Metadata.UpdatedAt = c.entityOf(&updated).Metadata.UpdatedAt
+	assert.Equal(t, expected, updated)
+	read, err = c.read(ctx, ref)
+	require.NoError(t, err)
+	assert.Equal(t, expected, read)
+
+	byRef := expected
+	c.entityOf(&byRef).ID = 0
+	c.entityOf(&byRef).Metadata.Description = "updated by ref"
+	updated, err = c.update(ctx, byRef)
+	require.NoError(t, err, "updating without an ID")
+	assert.Equal(t, id, c.entityOf(&updated).ID)
+	assert.Equal(t, int64(3), c.entityOf(&updated).Version)
+	read, err = c.read(ctx, ref)
+	require.NoError(t, err)
+	assert.Equal(t, "updated by ref", c.entityOf(&read).Metadata.Description)
+	expected = read
+
+	deleted, err := c.delete(ctx, ref, 0)
+	require.NoError(t, err)
+	assert.Equal(t, expected, deleted)
+	_, err = c.read(ctx, ref)
+	assert.ErrorIs(t, err, store.ErrNotFound, "reading after delete")
+	_, err = c.update(ctx, expected)
+	assert.ErrorIs(t, err, store.ErrNotFound, "updating after delete")
+	refs, _, err = c.list(ctx, nil, store.Ordering{}, store.Pagination{})
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+
+	recreated, err := c.create(ctx, full)
+	require.NoError(t, err, "creating again after delete")
+	assert.NotEqual(t, metadata.UID, c.entityOf(&recreated).Metadata.UID, "UID after recreating")
+	expected = full
+	setStoreFields(c.entityOf(&expected), c.entityOf(&recreated))
+	assert.Equal(t, expected, recreated)
+
+	_, err = c.delete(ctx, ref, 0)
+	require.NoError(t, err)
+	require.NoError(t, c.restore(ctx, ref), "restoring after delete")
+	restored, err := c.read(ctx, ref)
+	require.NoError(t, err)
+	c.entityOf(&expected).Version = 2
+	c.entityOf(&expected).Metadata.Etag = "2"
+	assert.False(t, c.entityOf(&restored).Metadata.UpdatedAt.Before(c.entityOf(&recreated).Metadata.UpdatedAt))
+	c.entityOf(&expected).Metadata.UpdatedAt = c.entityOf(&restored).Metadata.UpdatedAt
+	assert.Equal(t, expected, restored)
+}
+
+// setStoreFields copies the fields that the store sets from a stored entity.
+func setStoreFields(e, stored *model.Entity) {
+	e.ID = stored.ID
+	e.Version = stored.Version
+	e.Metadata.UID = stored.Metadata.UID
+	e.Metadata.Etag = stored.Metadata.Etag
+	e.Metadata.CreatedAt = stored.Metadata.CreatedAt
+	e.Metadata.UpdatedAt = stored.Metadata.UpdatedAt
+}
+
+func testCRUD(t *testing.T, newStore NewStore) {
+	t.Run("Component", func(t *testing.T) {
+		st := newStore(t)
+		crudCase[model.Component]{
+			full:     model.TestFullComponent,
+			entityOf: func(c *model.Component) *model.Entity { return &c.Entity },
+			modify: func(c *model.Component) {
+				c.Spec.Lifecycle = model.ComponentLifecycleDeprecated
+				c.Spec.Owner = model.TestOwner2EntityRef
+				c.Spec.ProvidesAPIs = []model.EntityRef{model.TestAPI1EntityRef, model.TestAPI2EntityRef}
+				c.Spec.DependsOn = nil
+			},
+			create:  st.CreateComponent,
+			read:    st.ReadComponent,
+			update:  st.UpdateComponent,
+			delete:  st.DeleteComponent,
+			list:    st.ListComponents,
+			restore: st.RestoreEntity,
+		}.run(t)
+	})
+	t.Run("API", func(t *testing.T) {
+		st := newStore(t)
+		crudCase[model.API]{
+			full:     model.TestFullAPI,
+			entityOf: func(a *model.API) *model.Entity { return &a.Entity },
+			modify: func(a *model.API) {
+				a.Spec.Type = model.APITypeGRPC
+				a.Spec.System = model.TestSystem2EntityRef
+				a.Spec.Definition = "new-definition"
+			},
+			create:  st.CreateAPI,
+			read:    st.ReadAPI,
+			update:  st.UpdateAPI,
+			delete:  st.DeleteAPI,
+			list:    st.ListAPIs,
+			restore: st.RestoreEntity,
+		}.run(t)
+	})
+	t.Run("User", func(t *testing.T) {
+		st := newStore(t)
+		crudCase[model.User]{
+			full:     model.TestFullUser,
+			entityOf: func(u *model.User) *model.Entity { return &u.Entity },
+			modify: func(u *model.User) {
+				u.Spec.Profile.Email = "new-email"
+				u.Spec.MemberOf = []model.EntityRef{model.TestGroupEntityRef, model.TestGroup2EntityRef}
+			},
+			create:  st.CreateUser,
+			read:    st.ReadUser,
+			update:  st.UpdateUser,
+			delete:  st.DeleteUser,
+			list:    st.ListUsers,
+			restore: st.RestoreEntity,
+		}.run(t)
+	})
+	t.Run("Group", func(t *testing.T) {
+		st := newStore(t)
+		crudCase[model.Group]{
+			full:     model.TestFullGroup,
+			entityOf: func(g *model.Group) *model.Entity { return &g.Entity },
+			modify: func(g *model.Group) {
+				g.Spec.Parent = model.TestGroup2EntityRef
+				g.Spec.Members = nil
+			},
+			create:  st.CreateGroup,
+			read:    st.ReadGroup,
+			update:  st.UpdateGroup,
+			delete:  st.DeleteGroup,
+			list:    st.ListGroups,
+			restore: st.RestoreEntity,
+		}.run(t)
+	})
+	t.Run("System", func(t *testing.T) {
+		st := newStore(t)
+		crudCase[model.System]{
+			full:     model.TestFullSystem,
+			entityOf: func(s *model.System) *model.Entity { return &s.Entity },
+			modify: func(s *model.System) {
+				s.Spec.Domain = model.TestDomain2EntityRef
+			},
+			create:  st.CreateSystem,
+			read:    st.ReadSystem,
+			update:  st.UpdateSystem,
+			delete:  st.DeleteSystem,
+			list:    st.ListSystems,
+			restore: st.RestoreEntity,
+		}.run(t)
+	})
+	t.Run("Resource", func(t *testing.T) {
+		st := newStore(t)
+		crudCase[model.Resource]{
+			full:     model.TestFullResource,
+			entityOf: func(r *model.Resource) *model.Entity { return &r.Entity },
+			modify: func(r *model.Resource) {
+				r.Spec.Type = "queue"
+				r.Spec.DependsOn = []model.EntityRef{model.TestResource2EntityRef}
+				r.Spec.DependencyOf = nil
+			},
+			create:  st.CreateResource,
+			read:    st.ReadResource,
+			update:  st.UpdateResource,
+			delete:  st.DeleteResource,
+			list:    st.ListResources,
+			restore: st.RestoreEntity,
+		}.run(t)
+	})
+	t.Run("Domain", func(t *testing.T) {
+		st := newStore(t)
+		crudCase[model.Domain]{
+			full:     model.TestFullDomain,
+			entityOf: func(d *model.Domain) *model.Entity { return &d.Entity },
+			modify: func(d *model.Domain) {
+				d.Spec.Owner = model.TestOwner2EntityRef
+				d.Spec.SubdomainOf = model.EntityRef{}
+			},
+			create:  st.CreateDomain,
+			read:    st.ReadDomain,
+			update:  st.UpdateDomain,
+			delete:  st.DeleteDomain,
+			list:    st.ListDomains,
+			restore: st.RestoreEntity,
+		}.run(t)
+	})
+	t.Run("Location", func(t *testing.T) {
+		st := newStore(t)
+		crudCase[model.Location]{
+			full:     model.TestFullLocation,
+			entityOf: func(l *model.Location) *model.Entity { return &l.Entity },
+			modify: func(l *model.Location) {
+				l.Spec.Target = ""
+				l.Spec.Targets = []string{"./systems/catalog-info.yaml"}
+			},
+			create:  st.CreateLocation,
+			read:    st.ReadLocation,
+			update:  st.UpdateLocation,
+			delete:  st.DeleteLocation,
+			list:    st.ListLocations,
+			restore: st.RestoreEntity,
+		}.run(t)
+	})
+	t.Run("Template", func(t *testing.T) {
+		st := newStore(t)
+		crudCase[model.Template]{
+			full:     model.TestFullTemplate,
+			entityOf: func(t *model.Template) *model.Entity { return &t.Entity },
+			modify: func(t *model.Template) {
+				t.Spec.Type = model.ComponentTypeWebsite
+				t.Spec.Output = nil
+			},
+			create:  st.CreateTemplate,
+			read:    st.ReadTemplate,
+			update:  st.UpdateTemplate,
+			delete:  st.DeleteTemplate,
+			list:    st.ListTemplates,
+			restore: st.RestoreEntity,
+		}.run(t)
+	})
+}
+
+// ---
+
+func testUpdateMetadata(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	c, err := st.CreateComponent(ctx, model.TestFullComponent)
+	require.NoError(t, err)
+
+	c.Metadata.Description = "my-new-description"
+	c.Metadata.Labels = map[string]string{
+		"key1": "value1",
+		"key2": "value2a",
+		"key4": "value4",
+	}
+	c.Metadata.Annotations = map[string]string{
+		"keyb": "valueb1",
+		"keyd": "valued",
+	}
+	c.Metadata.Tags = []string{"tag3", "tag4"}
+	c.Metadata.Links = []model.Link{
+		{
+			URL:   "http://example.com/url2",
+			Title: "link2a",
+			Icon:  "icon2",
+		},
+		{
+			URL:   "http://example.com/url3",
+			Title: "link3",
+		},
+		{
+			URL:   "http://example.com/url1",
+			Title: "link1",
+			Icon:  "icon1",
+			Type:  "linktype1",
+		},
+	}
+	c, err = st.UpdateComponent(ctx, c)
+	require.NoError(t, err)
+	r, err := st.ReadComponent(ctx, c.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, c, r)
+
+	c.Metadata.Labels = map[string]string{}
+	c.Metadata.Annotations = map[string]string{}
+	c.Metadata.Tags = nil
+	c.Metadata.Links = []model.Link{}
+	c, err = st.UpdateComponent(ctx, c)
+	require.NoError(t, err)
+	r, err = st.ReadComponent(ctx, c.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, c, r)
+
+	refs, _, err := st.ListComponents(ctx, []store.Filter{{Key: "metadata.labels.key1", Operator: store.FilterExists}}, store.Ordering{}, store.Pagination{})
+	require.NoError(t, err)
+	assert.Empty(t, refs, "removed labels no longer match")
+}
+
+func testDeleteCascades(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	c, err := st.CreateComponent(ctx, model.TestFullComponent)
+	require.NoError(t, err)
+	_, err = st.DeleteComponent(ctx, c.EntityRef(), 0)
+	require.NoError(t, err)
+
+	for _, filter := range []store.Filter{
+		{Key: "metadata.labels.key1", Operator: store.FilterExists},
+		{Key: "metadata.annotations.keya", Operator: store.FilterExists},
+		{Key: "metadata.tags", Value: "tag1"},
+	} {
+		refs, _, err := st.ListEntities(ctx, nil, []store.Filter{filter}, store.Ordering{}, store.Pagination{})
+		require.NoError(t, err)
+		assert.Empty(t, refs, "filter on %s", filter.Key)
+	}
+
+	bare := model.TestFullComponent
+	bare.Metadata.Labels = map[string]string{}
+	bare.Metadata.Annotations = map[string]string{}
+	bare.Metadata.Tags = nil
+	bare.Metadata.Links = []model.Link{}
+	bare, err = st.CreateComponent(ctx, bare)
+	require.NoError(t, err)
+	r, err := st.ReadComponent(ctx, bare.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, bare, r, "no metadata left over from the deleted entity")
+}
+
+// ---
+
+func testListFilters(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	payments := model.EntityRef{Kind: model.KindGroup, Namespace: model.TestFullComponent.Metadata.Namespace, Name: "payments"}
+	type component struct {
+		name        string
+		lifecycle   string
+		owner       model.EntityRef
+		labels      map[string]string
+		annotations map[string]string
+		tags        []string
+		dependsOn   []model.EntityRef
+	}
+	components := []component{
+		{
+			name:        "payments-api",
+			lifecycle:   model.ComponentLifecycleProduction,
+			owner:       payments,
+			labels:      map[string]string{"tier": "backend", "team": "payments"},
+			annotations: map[string]string{"backstage.io/techdocs-ref": "dir:."},
+			tags:        []string{"java", "payments"},
+			dependsOn:   []model.EntityRef{model.TestResource1EntityRef, model.TestResource2EntityRef},
+		},
+		{
+			name:      "payments-worker",
+			lifecycle: model.ComponentLifecycleExperimental,
+			owner:     model.EntityRef{Name: payments.Name},
+			labels:    map[string]string{"tier": "worker", "team": "payments"},
+			tags:      []string{"go"},
+		},
+		{
+			name:        "search",
+			lifecycle:   model.ComponentLifecycleProduction,
+			owner:       model.TestOwnerEntityRef,
+			labels:      map[string]string{"tier": "frontend"},
+			annotations: map[string]string{"backstage.io/techdocs-ref": "url:https://example.com"},
+			tags:        []string{"java_legacy"},
+			dependsOn:   []model.EntityRef{model.TestResource2EntityRef},
+		},
+	}
+	for _, co := range components {
+		c := model.TestFullComponent
+		c.Metadata.Name = co.name
+		c.Metadata.Labels = co.labels
+		c.Metadata.Annotations = co.annotations
+		c.Metadata.Tags = co.tags
+		c.Spec.Lifecycle = co.lifecycle
+		c.Spec.Owner = co.owner
+		c.Spec.DependsOn = co.dependsOn
+		c.Spec.DependencyOf = nil
+		_, err := st.CreateComponent(ctx, c)
+		require.NoError(t, err)
+	}
+	_, err := st.CreateAPI(ctx, model.TestFullAPI)
+	require.NoError(t, err)
+
+	type testCase struct {
+		filters       []store.Filter
+		expectedNames []string
+		description   string
+	}
+	tcs := []testCase{
+		{
+			filters:       []store.Filter{{Key: "metadata.labels.tier", Value: "backend"}},
+			expectedNames: []string{"payments-api"},
+			description:   "label equals",
+		},
+		{
+			filters:       []store.Filter{{Key: "metadata.labels.team", Operator: store.FilterNotEquals, Value: "payments"}},
+			expectedNames: []string{"search"},
+			description:   "label not equals matches entities without the label",
+		},
+		{
+			filters:       []store.Filter{{Key: "metadata.labels.tier", Operator: store.FilterIn, Values: []string{"frontend", "worker"}}},
+			expectedNames: []string{"payments-worker", "search"},
+			description:   "label in",
+		},
+		{
+			filters:       []store.Filter{{Key: "metadata.labels.tier", Operator: store.FilterNotIn, Values: []string{"frontend", "worker"}}},
+			expectedNames: []string{"payments-api"},
+			description:   "label notin",
+		},
+		{
+			filters:       []store.Filter{{Key: "metadata.labels.team", Operator: store.FilterNotExists}},
+			expectedNames: []string{"search"},
+			description:   "label does not exist",
+		},
+		{
+			filters:       []store.Filter{{Key: "metadata.labels.tier", Operator: store.FilterPrefix, Value: "w"}},
+			expectedNames: []string{"payments-worker"},
+			description:   "label prefix",
+		},
+		{
+			filters:       []store.Filter{{Key: "metadata.annotations.backstage.io/techdocs-ref", Operator: store.FilterExists}},
+			expectedNames: []string{"payments-api", "search"},
+			description:   "annotation exists",
+		},
+		{
+			filters:       []store.Filter{{Key: "metadata.annotations.backstage.io/techdocs-ref", Operator: store.FilterPrefix, Value: "url:"}},
+			expectedNames: []string{"search"},
+			description:   "annotation prefix",
+		},
+		{
+			filters:       []store.Filter{{Key: "metadata.name", Operator: store.FilterPrefix, Value: "payments-"}},
+			expectedNames: []string{"payments-api", "payments-worker"},
+			description:   "name prefix",
+		},
+		{
+			filters:       []store.Filter{{Key: "metadata.tags", Value: "java"}},
+			expectedNames: []string{"payments-api"},
+			description:   "tag equals does not match a longer tag",
+		},
+		{
+			filters:       []store.Filter{{Key: "metadata.tags", Operator: store.FilterPrefix, Value: "java_"}},
+			expectedNames: []string{"search"},
+			description:   "tag prefix takes wildcards literally",
+		},
+		{
+			filters:       []store.Filter{{Key: "metadata.tags", Operator: store.FilterNotIn, Values: []string{"go", "payments"}}},
+			expectedNames: []string{"search"},
+			description:   "tag notin",
+		},
+		{
+			filters: []store.Filter{
+				{Key: "spec.lifecycle", Value: model.ComponentLifecycleProduction},
+				{Key: "spec.owner", Value: payments.String()},
+			},
+			expectedNames: []string{"payments-api"},
+			description:   "several filters",
+		},
+		{
+			filters:       []store.Filter{{Key: "spec.owner", Value: payments.String()}},
+			expectedNames: []string{"payments-api", "payments-worker"},
+			description:   "entity ref matches a ref written in short form",
+		},
+		{
+			filters:       []store.Filter{{Key: "spec.owner", Value: payments.Name}},
+			expectedNames: []string{"payments-api", "payments-worker"},
+			description:   "entity ref in short form is qualified",
+		},
+		{
+			filters:       []store.Filter{{Key: "spec.owner", Value: "group:default/payments"}},
+			expectedNames: []string{},
+			description:   "entity ref in another namespace",
+		},
+		{
+			filters:       []store.Filter{{Key: "spec.owner", Operator: store.FilterNotIn, Values: []string{payments.String()}}},
+			expectedNames: []string{"search"},
+			description:   "entity ref notin",
+		},
+		{
+			filters:       []store.Filter{{Key: "spec.owner", Operator: store.FilterPrefix, Value: "group:"}},
+			expectedNames: []string{"payments-api", "payments-worker"},
+			description:   "entity ref prefix matches the qualified ref",
+		},
+		{
+			filters:       []store.Filter{{Key: "spec.dependsOn", Value: model.TestResource2EntityRef.String()}},
+			expectedNames: []string{"payments-api", "search"},
+			description:   "entity ref list contains",
+		},
+		{
+			filters:       []store.Filter{{Key: "spec.dependsOn", Operator: store.FilterNotEquals, Value: model.TestResource1EntityRef.String()}},
+			expectedNames: []string{"payments-worker", "search"},
+			description:   "entity ref list does not contain",
+		},
+		{
+			filters:       []store.Filter{{Key: "spec.dependsOn", Operator: store.FilterNotExists}},
+			expectedNames: []string{"payments-worker"},
+			description:   "entity ref list empty",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			refs, _, err := st.ListComponents(ctx, tc.filters, store.Ordering{OrderBy: store.OrderByName}, store.Pagination{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedNames, names(refs))
+		})
+	}
+
+	refs, _, err := st.ListEntities(ctx, nil, []store.Filter{{Key: "metadata.tags", Value: "java"}}, store.Ordering{OrderBy: store.OrderByKind}, store.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{
+		{Kind: model.KindComponent, Namespace: "my-namespace", Name: "payments-api"},
+	}, refs, "filters apply across kinds")
+}
+
+func testListOrdering(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	for _, name := range []string{"b", "c", "a"} {
+		c := model.TestFullComponent
+		c.Metadata.Name = name
+		_, err := st.CreateComponent(ctx, c)
+		require.NoError(t, err)
+	}
+
+	refs, _, err := st.ListComponents(ctx, nil, store.Ordering{OrderBy: store.OrderByName}, store.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, names(refs))
+
+	refs, _, err = st.ListComponents(ctx, nil, store.Ordering{OrderBy: store.OrderByName, Descending: true}, store.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "b", "a"}, names(refs))
+}
+
+func testListPagination(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	for i := range 5 {
+		c := model.TestFullComponent
+		c.Metadata.Name = fmt.Sprintf("component-%d", i)
+		_, err := st.CreateComponent(ctx, c)
+		require.NoError(t, err)
+	}
+	ordering := store.Ordering{OrderBy: store.OrderByName}
+
+	type testCase struct {
+		pagination         store.Pagination
+		expectedNames      []string
+		expectedPagination store.Pagination
+		description        string
+	}
+	tcs := []testCase{
+		{
+			expectedNames:      []string{"component-0", "component-1", "component-2", "component-3", "component-4"},
+			expectedPagination: store.Pagination{Offset: 5},
+			description:        "no limit",
+		},
+		{
+			pagination:         store.Pagination{Limit: 2},
+			expectedNames:      []string{"component-0", "component-1"},
+			expectedPagination: store.Pagination{Limit: 2, Offset: 2},
+			description:        "first page",
+		},
+		{
+			pagination:         store.Pagination{Limit: 2, Offset: 4},
+			expectedNames:      []string{"component-4"},
+			expectedPagination: store.Pagination{Limit: 2, Offset: 5},
+			description:        "short last page",
+		},
+		{
+			pagination:         store.Pagination{Limit: 10},
+			expectedNames:      []string{"component-0", "component-1", "component-2", "component-3", "component-4"},
+			expectedPagination: store.Pagination{Limit: 10, Offset: 5},
+			description:        "limit beyond the end",
+		},
+		{
+			pagination:         store.Pagination{Limit: 2, Offset: 5},
+			expectedNames:      []string{},
+			expectedPagination: store.Pagination{Limit: 2, Offset: 5},
+			description:        "offset at the end",
+		},
+		{
+			pagination:         store.Pagination{Limit: 2, Offset: 50},
+			expectedNames:      []string{},
+			expectedPagination: store.Pagination{Limit: 2, Offset: 50},
+			description:        "offset beyond the end",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			refs, pagination, err := st.ListComponents(ctx, nil, ordering, tc.pagination)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedNames, names(refs))
+			assert.Equal(t, tc.expectedPagination, pagination)
+		})
+	}
+
+	var all []string
+	pagination := store.Pagination{Limit: 2}
+	for {
+		refs, next, err := st.ListComponents(ctx, nil, ordering, pagination)
+		require.NoError(t, err)
+		all = append(all, names(refs)...)
+		if len(refs) < pagination.Limit {
+			break
+		}
+		pagination = next
+	}
+	assert.Equal(t, []string{"component-0", "component-1", "component-2", "component-3", "component-4"}, all, "paging through every entity")
+}
+
+func testListEntities(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	component := model.TestFullComponent
+	component.Metadata.Namespace = "default"
+	_, err := st.CreateComponent(ctx, component)
+	require.NoError(t, err)
+	_, err = st.CreateAPI(ctx, model.TestFullAPI)
+	require.NoError(t, err)
+	_, err = st.CreateGroup(ctx, model.TestFullGroup)
+	require.NoError(t, err)
+	_, err = st.CreateSystem(ctx, model.TestFullSystem)
+	require.NoError(t, err)
+
+	type testCase struct {
+		kinds              []string
+		filters            []store.Filter
+		ordering           store.Ordering
+		expectedEntityRefs []model.EntityRef
+		description        string
+	}
+	tcs := []testCase{
+		{
+			expectedEntityRefs: []model.EntityRef{
+				model.TestFullAPI.EntityRef(),
+				component.EntityRef(),
+				model.TestFullGroup.EntityRef(),
+				model.TestFullSystem.EntityRef(),
+			},
+			description: "all kinds",
+		},
+		{
+			ordering: store.Ordering{OrderBy: store.OrderByKind, Descending: true},
+			expectedEntityRefs: []model.EntityRef{
+				model.TestFullSystem.EntityRef(),
+				model.TestFullGroup.EntityRef(),
+				component.EntityRef(),
+				model.TestFullAPI.EntityRef(),
+			},
+			description: "descending",
+		},
+		{
+			kinds: []string{model.KindGroup},
+			expectedEntityRefs: []model.EntityRef{
+				model.TestFullGroup.EntityRef(),
+			},
+			description: "single kind",
+		},
+		{
+			kinds: []string{model.KindSystem, model.KindAPI, model.KindUser},
+			expectedEntityRefs: []model.EntityRef{
+				model.TestFullAPI.EntityRef(),
+				model.TestFullSystem.EntityRef(),
+			},
+			description: "multiple kinds",
+		},
+		{
+			filters: []store.Filter{{Key: "metadata.namespace", Value: "my-namespace"}},
+			expectedEntityRefs: []model.EntityRef{
+				model.TestFullAPI.EntityRef(),
+				model.TestFullGroup.EntityRef(),
+				model.TestFullSystem.EntityRef(),
+			},
+			description: "filter on namespace",
+		},
+		{
+			kinds:   []string{model.KindGroup},
+			filters: []store.Filter{{Key: "spec.type", Value: "team"}},
+			expectedEntityRefs: []model.EntityRef{
+				model.TestFullGroup.EntityRef(),
+			},
+			description: "spec field of a single kind",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.description, func(t *testing.T) {
+			ordering := tc.ordering
+			if ordering.OrderBy == "" {
+				ordering.OrderBy = store.OrderByKind
+			}
+			refs, _, err := st.ListEntities(ctx, tc.kinds, tc.filters, ordering, store.Pagination{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedEntityRefs, refs)
+		})
+	}
+}
+
+// ---
+
+func testSearchEntities(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	_, _, err := st.SearchEntities(ctx, "anything", store.Pagination{})
+	if errors.Is(err, store.ErrSearchUnavailable) {
+		t.Skip("full-text search is not available")
+	}
+	require.NoError(t, err)
+
+	c1 := model.TestFullComponent
+	c1.Metadata.Name = "payments-api"
+	c1.Metadata.Title = "Payments API"
+	c1.Metadata.Description = "Takes card payments and issues refunds"
+	c1.Metadata.Annotations = nil
+	c1, err = st.CreateComponent(ctx, c1)
+	require.NoError(t, err)
+	c2 := model.TestFullComponent
+	c2.Metadata.Name = "ledger"
+	c2.Metadata.Title = "Ledger"
+	c2.Metadata.Description = "Records every transaction"
+	c2.Metadata.Tags = []string{"go", "payments"}
+	c2.Metadata.Annotations = map[string]string{"runbook": "https://wiki.example.com/refunds"}
+	c2, err = st.CreateComponent(ctx, c2)
+	require.NoError(t, err)
+
+	refsOf := func(matches []store.SearchMatch) []model.EntityRef {
+		refs := []model.EntityRef{}
+		for _, match := range matches {
+			refs = append(refs, match.EntityRef)
+		}
+		return refs
+	}
+
+	matches, _, err := st.SearchEntities(ctx, "payments", store.Pagination{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []model.EntityRef{c1.EntityRef(), c2.EntityRef()}, refsOf(matches))
+	for _, match := range matches {
+		assert.Contains(t, match.Snippet, "<mark>")
+	}
+
+	c3 := model.TestFullComponent
+	c3.Metadata.Name = "invoicing"
+	c3.Metadata.Title = "Invoicing"
+	c3.Metadata.Description = "Renders <b>invoices</b> & receipts"
+	c3.Metadata.Annotations = nil
+	_, err = st.CreateComponent(ctx, c3)
+	require.NoError(t, err)
+	matches, _, err = st.SearchEntities(ctx, "invoices", store.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Contains(t, matches[0].Snippet, "&lt;b&gt;<mark>invoices</mark>&lt;/b&gt; &amp; receipts", "snippets are escaped")
+
+	matches, _, err = st.SearchEntities(ctx, "card refund", store.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{c1.EntityRef()}, refsOf(matches), "every word must match")
+
+	matches, next, err := st.SearchEntities(ctx, "refund", store.Pagination{Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, store.Pagination{Limit: 1, Offset: 1}, next)
+
+	c2.Metadata.Annotations = nil
+	_, err = st.UpdateComponent(ctx, c2)
+	require.NoError(t, err)
+	matches, _, err = st.SearchEntities(ctx, "refund", store.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{c1.EntityRef()}, refsOf(matches), "updates are searchable")
+
+	_, err = st.DeleteComponent(ctx, c1.EntityRef(), 0)
+	require.NoError(t, err)
+	matches, _, err = st.SearchEntities(ctx, "payments", store.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{c2.EntityRef()}, refsOf(matches), "deleted entities are not found")
+}
+
+// ---
+
+func testErrors(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	c, err := st.CreateComponent(ctx, model.TestFullComponent)
+	require.NoError(t, err)
+	other := model.TestFullComponent
+	other.Metadata.Name = "other"
+	other, err = st.CreateComponent(ctx, other)
+	require.NoError(t, err)
+	missing := model.EntityRef{Kind: model.KindComponent, Namespace: "default", Name: "missing"}
+
+	_, err = st.ReadComponent(ctx, missing)
+	assert.ErrorIs(t, err, store.ErrNotFound, "reading a missing entity")
+	_, err = st.DeleteComponent(ctx, missing, 0)
+	assert.ErrorIs(t, err, store.ErrNotFound, "deleting a missing entity")
+	notStored := model.TestFullComponent
+	notStored.Metadata.Name = missing.Name
+	_, err = st.UpdateComponent(ctx, notStored)
+	assert.ErrorIs(t, err, store.ErrNotFound, "updating a missing entity")
+	notStored.ID = other.ID + 1000
+	_, err = st.UpdateComponent(ctx, notStored)
+	assert.ErrorIs(t, err, store.ErrNotFound, "updating a missing ID")
+
+	_, err = st.CreateComponent(ctx, model.TestFullComponent)
+	assert.ErrorIs(t, err, store.ErrAlreadyExists, "creating a duplicate")
+	renamed := other
+	renamed.Metadata.Name = c.Metadata.Name
+	_, err = st.UpdateComponent(ctx, renamed)
+	assert.ErrorIs(t, err, store.ErrConflict, "renaming onto another entity")
+
+	unnamed := model.TestFullComponent
+	unnamed.Metadata.Name = ""
+	_, err = st.CreateComponent(ctx, unnamed)
+	assert.ErrorIs(t, err, store.ErrInvalid, "creating an entity without a name")
+	noNamespace := model.TestFullComponent
+	noNamespace.Metadata.Namespace = ""
+	_, err = st.CreateComponent(ctx, noNamespace)
+	assert.ErrorIs(t, err, store.ErrInvalid, "creating an entity without a namespace")
+	_, err = st.CreateAPI(ctx, model.API{Entity: model.TestFullComponent.Entity})
+	assert.ErrorIs(t, err, store.ErrInvalid, "creating an entity of the wrong kind")
+	wrongKind := c
+	wrongKind.Kind = model.KindAPI
+	_, err = st.UpdateComponent(ctx, wrongKind)
+	assert.ErrorIs(t, err, store.ErrInvalid, "updating an entity of the wrong kind")
+
+	_, _, err = st.ListComponents(ctx, []store.Filter{{Key: "spec.nope", Value: "x"}}, store.Ordering{}, store.Pagination{})
+	assert.ErrorIs(t, err, store.ErrInvalid, "filtering on an unknown field")
+	_, _, err = st.ListEntities(ctx, nil, []store.Filter{{Key: "spec.type", Value: "x"}}, store.Ordering{}, store.Pagination{})
+	assert.ErrorIs(t, err, store.ErrInvalid, "filtering on a spec field across kinds")
+	_, _, err = st.ListComponents(ctx, []store.Filter{{Key: "metadata.tags", Operator: store.FilterIn}}, store.Ordering{}, store.Pagination{})
+	assert.ErrorIs(t, err, store.ErrInvalid, "in filter without values")
+	_, _, err = st.ListComponents(ctx, []store.Filter{{Key: "metadata.labels.", Operator: store.FilterExists}}, store.Ordering{}, store.Pagination{})
+	assert.ErrorIs(t, err, store.ErrInvalid, "label filter without a key")
+
+	r, err := st.ReadComponent(ctx, c.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, c, r, "failed operations leave the store unchanged")
+	r, err = st.ReadComponent(ctx, other.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, other, r, "failed operations leave the store unchanged")
+}
+
+func names(refs []model.EntityRef) []string {
+	names := []string{}
+	for _, ref := range refs {
+		names = append(names, ref.Name)
+	}
+	return names
+}
+
+// ---
+
+func testVersions(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	c, err := st.CreateComponent(ctx, model.TestFullComponent)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c.Version)
+
+	first := c
+	first.Metadata.Title = "first"
+	first, err = st.UpdateComponent(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), first.Version)
+
+	second := c
+	second.Metadata.Title = "second"
+	_, err = st.UpdateComponent(ctx, second)
+	assert.ErrorIs(t, err, store.ErrVersionMismatch, "updating a stale version")
+	second.ID = 0
+	_, err = st.UpdateComponent(ctx, second)
+	assert.ErrorIs(t, err, store.ErrVersionMismatch, "updating a stale version by ref")
+	_, err = st.DeleteComponent(ctx, c.EntityRef(), c.Version)
+	assert.ErrorIs(t, err, store.ErrVersionMismatch, "deleting a stale version")
+	read, err := st.ReadComponent(ctx, c.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, first, read, "stale changes are not stored")
+
+	second.Version = 0
+	second, err = st.UpdateComponent(ctx, second)
+	require.NoError(t, err, "updating without a version")
+	assert.Equal(t, int64(3), second.Version)
+
+	deleted, err := st.DeleteComponent(ctx, c.EntityRef(), second.Version)
+	require.NoError(t, err, "deleting the current version")
+	assert.Equal(t, second, deleted)
+	_, err = st.DeleteComponent(ctx, c.EntityRef(), second.Version)
+	assert.ErrorIs(t, err, store.ErrNotFound, "deleting again")
+}
+
+// ---
+
+func testUIDs(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	given := model.TestFullComponent
+	given.Metadata.UID = "given-uid"
+	given.Metadata.Etag = "given-etag"
+	given.Metadata.CreatedAt = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c, err := st.CreateComponent(ctx, given)
+	require.NoError(t, err)
+	assert.NotEqual(t, "given-uid", c.Metadata.UID, "the store sets the UID")
+	assert.Equal(t, "1", c.Metadata.Etag, "the store sets the etag")
+	assert.True(t, c.Metadata.CreatedAt.After(given.Metadata.CreatedAt), "the store sets the creation time")
+	a, err := st.CreateAPI(ctx, model.TestFullAPI)
+	require.NoError(t, err)
+	assert.NotEqual(t, c.Metadata.UID, a.Metadata.UID)
+
+	ref, err := st.ResolveUID(ctx, c.Metadata.UID)
+	require.NoError(t, err)
+	assert.Equal(t, c.EntityRef(), ref)
+
+	renamed := c
+	renamed.Metadata.Name = "renamed"
+	renamed.Metadata.UID = "other-uid"
+	renamed.Metadata.CreatedAt = time.Time{}
+	renamed, err = st.UpdateComponent(ctx, renamed)
+	require.NoError(t, err)
+	assert.Equal(t, c.Metadata.UID, renamed.Metadata.UID, "the UID is kept across a rename")
+	assert.Equal(t, c.Metadata.CreatedAt, renamed.Metadata.CreatedAt, "the creation time is kept")
+	assert.False(t, renamed.Metadata.UpdatedAt.Before(c.Metadata.UpdatedAt))
+	read, err := st.ReadComponent(ctx, renamed.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, renamed, read)
+
+	ref, err = st.ResolveUID(ctx, c.Metadata.UID)
+	require.NoError(t, err)
+	assert.Equal(t, renamed.EntityRef(), ref, "resolving after a rename")
+
+	_, err = st.DeleteComponent(ctx, renamed.EntityRef(), 0)
+	require.NoError(t, err)
+	_, err = st.ResolveUID(ctx, c.Metadata.UID)
+	assert.ErrorIs(t, err, store.ErrNotFound, "resolving after delete")
+	_, err = st.ResolveUID(ctx, "no-such-uid")
+	assert.ErrorIs(t, err, store.ErrNotFound, "resolving an unknown UID")
+}
+
+// ---
+
+func testHistory(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	ref := model.TestFullComponent.EntityRef()
+
+	created, err := st.CreateComponent(store.WithActor(ctx, "alice"), model.TestFullComponent)
+	require.NoError(t, err)
+	changed := created
+	changed.Metadata.Title = "my-new-title"
+	updated, err := st.UpdateComponent(store.WithActor(ctx, "bob"), changed)
+	require.NoError(t, err)
+	_, err = st.DeleteComponent(ctx, ref, 0)
+	require.NoError(t, err)
+
+	revisions, next, err := st.ListRevisions(ctx, ref, store.Pagination{})
+	require.NoError(t, err, "listing the history of a deleted entity")
+	require.Len(t, revisions, 3)
+	assert.Equal(t, 3, next.Offset)
+	deleteRevision, updateRevision, createRevision := revisions[0], revisions[1], revisions[2]
+
+	for _, r := range revisions {
+		assert.Equal(t, ref, r.EntityRef)
+		assert.Equal(t, created.Metadata.UID, r.UID)
+	}
+
+	assert.Equal(t, model.OperationCreate, createRevision.Operation)
+	assert.Equal(t, int64(1), createRevision.Version)
+	assert.Equal(t, "alice", createRevision.Actor)
+	assert.Equal(t, created.Metadata.CreatedAt, createRevision.Timestamp)
+	assert.Empty(t, createRevision.Previous)
+	assertRevisionYAML(t, created, createRevision.Current)
+
+	assert.Equal(t, model.OperationUpdate, updateRevision.Operation)
+	assert.Equal(t, int64(2), updateRevision.Version)
+	assert.Equal(t, "bob", updateRevision.Actor)
+	assert.Equal(t, updated.Metadata.UpdatedAt, updateRevision.Timestamp)
+	assert.Equal(t, createRevision.Current, updateRevision.Previous)
+	assertRevisionYAML(t, updated, updateRevision.Current)
+
+	assert.Equal(t, model.OperationDelete, deleteRevision.Operation)
+	assert.Equal(t, int64(2), deleteRevision.Version)
+	assert.Empty(t, deleteRevision.Actor)
+	assert.False(t, deleteRevision.Timestamp.Before(updateRevision.Timestamp))
+	assert.Equal(t, updateRevision.Current, deleteRevision.Previous)
+	assert.Empty(t, deleteRevision.Current)
+
+	page, next, err := st.ListRevisions(ctx, ref, store.Pagination{Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []model.Revision{updateRevision}, page)
+	assert.Equal(t, store.Pagination{Limit: 1, Offset: 2}, next)
+
+	revision, err := st.ReadRevision(ctx, ref, 1)
+	require.NoError(t, err)
+	assert.Equal(t, createRevision, revision)
+	revision, err = st.ReadRevision(ctx, ref, 2)
+	require.NoError(t, err)
+	assert.Equal(t, updateRevision, revision, "reading the version that was deleted")
+	_, err = st.ReadRevision(ctx, ref, 3)
+	assert.ErrorIs(t, err, store.ErrNotFound, "reading a version that never existed")
+
+	recreated, err := st.CreateComponent(ctx, model.TestFullComponent)
+	require.NoError(t, err)
+	renamed := recreated
+	renamed.Metadata.Name = "renamed"
+	renamed, err = st.UpdateComponent(ctx, renamed)
+	require.NoError(t, err)
+	revisions, _, err = st.ListRevisions(ctx, renamed.EntityRef(), store.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, revisions, 2, "history follows an entity across a rename")
+	assert.Equal(t, renamed.EntityRef(), revisions[0].EntityRef)
+	assert.Equal(t, ref, revisions[1].EntityRef)
+	assert.Equal(t, recreated.Metadata.UID, revisions[1].UID)
+
+	_, _, err = st.ListRevisions(ctx, model.TestComponent2EntityRef, store.Pagination{})
+	assert.ErrorIs(t, err, store.ErrNotFound, "listing the history of an unknown entity")
+}
+
+// assertRevisionYAML checks that the YAML in a revision is that of a stored
+// component.
+func assertRevisionYAML(t *testing.T, expected model.Component, actual string) {
+	t.Helper()
+	var c model.Component
+	require.NoError(t, yaml.Unmarshal([]byte(actual), &c))
+	expected.ID = 0
+	expected.Version = 0
+	assert.Equal(t, expected, c)
+}
+
+// ---
+
+func testSoftDelete(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	ref := model.TestFullComponent.EntityRef()
+	apiRef := model.TestFullAPI.EntityRef()
+
+	created, err := st.CreateComponent(ctx, model.TestFullComponent)
+	require.NoError(t, err)
+	_, err = st.CreateAPI(ctx, model.TestFullAPI)
+	require.NoError(t, err)
+	_, err = st.DeleteComponent(ctx, ref, 0)
+	require.NoError(t, err)
+
+	_, err = st.ReadComponent(ctx, ref)
+	assert.ErrorIs(t, err, store.ErrNotFound, "reading a deleted entity")
+	refs, _, err := st.ListEntities(ctx, nil, nil, store.Ordering{}, store.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{apiRef}, refs, "listing hides deleted entities")
+	refs, _, err = st.ListDeletedEntities(ctx, nil, store.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{ref}, refs)
+	refs, _, err = st.ListDeletedEntities(ctx, []string{model.KindAPI}, store.Pagination{})
+	require.NoError(t, err)
+	assert.Empty(t, refs, "listing deleted entities of another kind")
+
+	err = st.RestoreEntity(ctx, model.TestComponent2EntityRef)
+	assert.ErrorIs(t, err, store.ErrNotFound, "restoring an entity that was never deleted")
+
+	recreated, err := st.CreateComponent(ctx, model.TestFullComponent)
+	require.NoError(t, err, "creating again while a tombstone exists")
+	err = st.RestoreEntity(ctx, ref)
+	assert.ErrorIs(t, err, store.ErrConflict, "restoring over a live entity")
+
+	_, err = st.DeleteComponent(ctx, ref, 0)
+	require.NoError(t, err)
+	refs, _, err = st.ListDeletedEntities(ctx, nil, store.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{ref}, refs, "a ref is listed once however often it was deleted")
+
+	err = st.RestoreEntity(store.WithActor(ctx, "alice"), ref)
+	require.NoError(t, err)
+	restored, err := st.ReadComponent(ctx, ref)
+	require.NoError(t, err)
+	assert.Equal(t, recreated.Metadata.UID, restored.Metadata.UID, "the latest deletion is restored")
+	assert.NotEqual(t, created.Metadata.UID, restored.Metadata.UID)
+	assert.Equal(t, int64(2), restored.Version)
+	uidRef, err := st.ResolveUID(ctx, restored.Metadata.UID)
+	require.NoError(t, err)
+	assert.Equal(t, ref, uidRef)
+	revisions, _, err := st.ListRevisions(ctx, ref, store.Pagination{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	assert.Equal(t, model.OperationRestore, revisions[0].Operation)
+	assert.Equal(t, int64(2), revisions[0].Version)
+	assert.Equal(t, "alice", revisions[0].Actor)
+	assert.Equal(t, restored.Metadata.UpdatedAt, revisions[0].Timestamp)
+	assert.Empty(t, revisions[0].Previous)
+	assertRevisionYAML(t, restored, revisions[0].Current)
+
+	_, err = st.DeleteAPI(ctx, apiRef, 0)
+	require.NoError(t, err)
+	refs, next, err := st.ListDeletedEntities(ctx, nil, store.Pagination{Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{ref}, refs, "the first deletion is still there")
+	assert.Equal(t, store.Pagination{Limit: 1, Offset: 2}, next)
+
+	n, err := st.PurgeEntities(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n, "purging before anything was deleted")
+	n, err = st.PurgeEntities(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	refs, _, err = st.ListDeletedEntities(ctx, nil, store.Pagination{})
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+	err = st.RestoreEntity(ctx, apiRef)
+	assert.ErrorIs(t, err, store.ErrNotFound, "restoring after a purge")
+	_, err = st.ReadComponent(ctx, ref)
+	assert.NoError(t, err, "purging leaves live entities alone")
+	revisions, _, err = st.ListRevisions(ctx, apiRef, store.Pagination{})
+	require.NoError(t, err, "history outlives a purge")
+	assert.Len(t, revisions, 2)
+}
+
+// ---
+
+func testRelations(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	team := model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "team"}
+	alice := model.EntityRef{Kind: model.KindUser, Namespace: "default", Name: "alice"}
+	db := model.EntityRef{Kind: model.KindResource, Namespace: "default", Name: "db"}
+	entity := func(ref model.EntityRef) model.Entity {
+		return model.Entity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       ref.Kind,
+			Metadata:   model.Metadata{Namespace: ref.Namespace, Name: ref.Name},
+		}
+	}
+
+	_, err := st.CreateGroup(ctx, model.Group{Entity: entity(team), Spec: model.GroupSpec{Type: "team"}})
+	require.NoError(t, err)
+	_, err = st.CreateUser(ctx, model.User{
+		Entity: entity(alice),
+		Spec:   model.UserSpec{MemberOf: []model.EntityRef{{Name: "team"}}},
+	})
+	require.NoError(t, err)
+	component := model.Component{
+		Entity: entity(model.TestComponentEntityRef),
+		Spec: model.ComponentSpec{
+			Type:         model.ComponentTypeService,
+			Lifecycle:    model.ComponentLifecycleProduction,
+			Owner:        model.EntityRef{Name: "team"},
+			ProvidesAPIs: []model.EntityRef{model.TestAPI1EntityRef},
+			DependsOn:    []model.EntityRef{db},
+		},
+	}
+	component.Relations = []model.Relation{{Type: model.RelationOwnedBy, TargetRef: alice}}
+	component, err = st.CreateComponent(ctx, component)
+	require.NoError(t, err)
+	assert.Empty(t, component.Relations, "given relations are ignored")
+	_, err = st.CreateAPI(ctx, model.API{
+		Entity: entity(model.TestAPI1EntityRef),
+		Spec: model.APISpec{
+			Type:      "openapi",
+			Lifecycle: "production",
+			Owner:     team,
+		},
+	})
+	require.NoError(t, err)
+
+	relations, err := st.ListRelations(ctx, model.TestComponentEntityRef)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Relation{
+		{Type: model.RelationDependsOn, TargetRef: db},
+		{Type: model.RelationOwnedBy, TargetRef: team},
+		{Type: model.RelationProvidesAPI, TargetRef: model.TestAPI1EntityRef},
+	}, relations, "relations from a spec, with unqualified refs qualified")
+
+	relations, err = st.ListRelations(ctx, team)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Relation{
+		{Type: model.RelationHasMember, TargetRef: alice},
+		{Type: model.RelationOwnerOf, TargetRef: model.TestAPI1EntityRef},
+		{Type: model.RelationOwnerOf, TargetRef: model.TestComponentEntityRef},
+	}, relations, "inverse relations from other specs")
+
+	relations, err = st.ListRelations(ctx, model.TestAPI1EntityRef)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Relation{
+		{Type: model.RelationAPIProvidedBy, TargetRef: model.TestComponentEntityRef},
+		{Type: model.RelationOwnedBy, TargetRef: team},
+	}, relations)
+
+	component.Spec.ProvidesAPIs = nil
+	component, err = st.UpdateComponent(ctx, component)
+	require.NoError(t, err)
+	relations, err = st.ListRelations(ctx, model.TestAPI1EntityRef)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Relation{
+		{Type: model.RelationOwnedBy, TargetRef: team},
+	}, relations, "relations after an update")
+
+	_, err = st.DeleteComponent(ctx, component.EntityRef(), 0)
+	require.NoError(t, err)
+	_, err = st.ListRelations(ctx, component.EntityRef())
+	assert.ErrorIs(t, err, store.ErrNotFound, "listing the relations of a deleted entity")
+	relations, err = st.ListRelations(ctx, team)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Relation{
+		{Type: model.RelationHasMember, TargetRef: alice},
+		{Type: model.RelationOwnerOf, TargetRef: model.TestAPI1EntityRef},
+	}, relations, "relations after a delete")
+
+	require.NoError(t, st.RestoreEntity(ctx, component.EntityRef()))
+	relations, err = st.ListRelations(ctx, team)
+	require.NoError(t, err)
+	assert.Contains(t, relations, model.Relation{Type: model.RelationOwnerOf, TargetRef: model.TestComponentEntityRef}, "relations after a restore")
+}
+
+func testReferrers(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	team := model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "team"}
+	alice := model.EntityRef{Kind: model.KindUser, Namespace: "default", Name: "alice"}
+	client := model.EntityRef{Kind: model.KindComponent, Namespace: "default", Name: "client"}
+	entity := func(ref model.EntityRef) model.Entity {
+		return model.Entity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       ref.Kind,
+			Metadata:   model.Metadata{Namespace: ref.Namespace, Name: ref.Name},
+		}
+	}
+
+	_, err := st.CreateGroup(ctx, model.Group{
+		Entity: entity(team),
+		Spec:   model.GroupSpec{Type: "team", Members: []model.EntityRef{{Name: "alice"}}},
+	})
+	require.NoError(t, err)
+	_, err = st.CreateAPI(ctx, model.API{
+		Entity: entity(model.TestAPI1EntityRef),
+		Spec:   model.APISpec{Type: "openapi", Lifecycle: "production", Owner: team},
+	})
+	require.NoError(t, err)
+	_, err = st.CreateComponent(ctx, model.Component{
+		Entity: entity(client),
+		Spec: model.ComponentSpec{
+			Type:         model.ComponentTypeService,
+			Lifecycle:    model.ComponentLifecycleProduction,
+			Owner:        model.EntityRef{Name: "team"},
+			ConsumesAPIs: []model.EntityRef{model.TestAPI1EntityRef},
+			DependsOn:    []model.EntityRef{model.TestAPI1EntityRef},
+		},
+	})
+	require.NoError(t, err)
+
+	referrers, err := st.ListReferrers(ctx, model.TestAPI1EntityRef)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Referrer{
+		{EntityRef: client, Field: "spec.consumesApis"},
+		{EntityRef: client, Field: "spec.dependsOn"},
+	}, referrers, "one referrer for each field")
+
+	referrers, err = st.ListReferrers(ctx, team)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Referrer{
+		{EntityRef: model.TestAPI1EntityRef, Field: "spec.owner"},
+		{EntityRef: client, Field: "spec.owner"},
+	}, referrers)
+
+	referrers, err = st.ListReferrers(ctx, alice)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Referrer{
+		{EntityRef: team, Field: "spec.members"},
+	}, referrers, "referrers to a missing entity")
+
+	referrers, err = st.ListReferrers(ctx, client)
+	require.NoError(t, err)
+	assert.Empty(t, referrers)
+
+	_, err = st.DeleteComponent(ctx, client, 0)
+	require.NoError(t, err)
+	referrers, err = st.ListReferrers(ctx, model.TestAPI1EntityRef)
+	require.NoError(t, err)
+	assert.Empty(t, referrers, "referrers after a delete")
+}
+
+func testDanglingRefs(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	team := model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "team"}
+	alice := model.EntityRef{Kind: model.KindUser, Namespace: "default", Name: "alice"}
+	missing := model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "missing"}
+	entity := func(ref model.EntityRef) model.Entity {
+		return model.Entity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       ref.Kind,
+			Metadata:   model.Metadata{Namespace: ref.Namespace, Name: ref.Name},
+		}
+	}
+
+	refs, err := st.ListDanglingRefs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+
+	_, err = st.CreateGroup(ctx, model.Group{
+		Entity: entity(team),
+		Spec:   model.GroupSpec{Type: "team", Parent: missing, Members: []model.EntityRef{{Name: "alice"}}},
+	})
+	require.NoError(t, err)
+	_, err = st.CreateUser(ctx, model.User{
+		Entity: entity(alice),
+		Spec:   model.UserSpec{MemberOf: []model.EntityRef{team}},
+	})
+	require.NoError(t, err)
+	component, err := st.CreateComponent(ctx, model.Component{
+		Entity: entity(model.TestComponentEntityRef),
+		Spec: model.ComponentSpec{
+			Type:         model.ComponentTypeService,
+			Lifecycle:    model.ComponentLifecycleProduction,
+			Owner:        model.EntityRef{Name: "team"},
+			ProvidesAPIs: []model.EntityRef{model.TestAPI1EntityRef},
+			DependsOn:    []model.EntityRef{{Name: "db"}},
+		},
+	})
+	require.NoError(t, err)
+
+	refs, err = st.ListDanglingRefs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []model.DanglingRef{
+		{EntityRef: model.TestComponentEntityRef, Owner: team, Field: "spec.dependsOn", TargetRef: model.EntityRef{Namespace: "default", Name: "db"}},
+		{EntityRef: model.TestComponentEntityRef, Owner: team, Field: "spec.providesApis", TargetRef: model.TestAPI1EntityRef},
+		{EntityRef: team, Field: "spec.parent", TargetRef: missing},
+	}, refs, "refs to missing entities and refs with no kind")
+
+	_, err = st.DeleteUser(ctx, alice, 0)
+	require.NoError(t, err)
+	_, err = st.DeleteComponent(ctx, component.EntityRef(), 0)
+	require.NoError(t, err)
+	refs, err = st.ListDanglingRefs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []model.DanglingRef{
+		{EntityRef: team, Field: "spec.members", TargetRef: alice},
+		{EntityRef: team, Field: "spec.parent", TargetRef: missing},
+	}, refs, "refs to and from deleted entities")
+}
+
+func testSpecRefs(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	parent := model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "parent"}
+	child := model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "child"}
+	alice := model.EntityRef{Kind: model.KindUser, Namespace: "default", Name: "alice"}
+	entity := func(ref model.EntityRef) model.Entity {
+		return model.Entity{
+			APIVersion: "backstage.io/v1alpha1",
+			Kind:       ref.Kind,
+			Metadata:   model.Metadata{Namespace: ref.Namespace, Name: ref.Name},
+		}
+	}
+
+	_, err := st.CreateGroup(ctx, model.Group{
+		Entity: entity(parent),
+		Spec:   model.GroupSpec{Type: "team", Children: []model.EntityRef{{Name: "child"}}, Members: []model.EntityRef{alice}},
+	})
+	require.NoError(t, err)
+	_, err = st.CreateGroup(ctx, model.Group{
+		Entity: entity(child),
+		Spec:   model.GroupSpec{Type: "team", Parent: parent},
+	})
+	require.NoError(t, err)
+
+	refs, err := st.ListSpecRefs(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntitySpecRef{
+		{EntityRef: child, SpecRef: model.SpecRef{Field: "spec.parent", Relation: model.RelationChildOf, Ref: parent}},
+		{EntityRef: parent, SpecRef: model.SpecRef{Field: "spec.children", Relation: model.RelationParentOf, Ref: child}},
+		{EntityRef: parent, SpecRef: model.SpecRef{Field: "spec.members", Relation: model.RelationHasMember, Ref: alice}},
+	}, refs, "all fields")
+
+	refs, err = st.ListSpecRefs(ctx, []string{"spec.children", "spec.members"})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntitySpecRef{
+		{EntityRef: parent, SpecRef: model.SpecRef{Field: "spec.children", Relation: model.RelationParentOf, Ref: child}},
+		{EntityRef: parent, SpecRef: model.SpecRef{Field: "spec.members", Relation: model.RelationHasMember, Ref: alice}},
+	}, refs, "some fields")
+
+	_, err = st.DeleteGroup(ctx, parent, 0)
+	require.NoError(t, err)
+	refs, err = st.ListSpecRefs(ctx, []string{"spec.children"})
+	require.NoError(t, err)
+	assert.Empty(t, refs, "refs from a deleted entity")
+}
+
+func testExistingRefs(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	team := model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "team"}
+	other := model.EntityRef{Kind: model.KindGroup, Namespace: "other", Name: "team"}
+	gone := model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: "gone"}
+	missing := model.EntityRef{Kind: model.KindUser, Namespace: "default", Name: "team"}
+	for _, ref := range []model.EntityRef{team, other, gone} {
+		_, err := st.CreateGroup(ctx, model.Group{
+			Entity: model.Entity{
+				APIVersion: "backstage.io/v1alpha1",
+				Kind:       ref.Kind,
+				Metadata:   model.Metadata{Namespace: ref.Namespace, Name: ref.Name},
+			},
+			Spec: model.GroupSpec{Type: "team"},
+		})
+		require.NoError(t, err)
+	}
+	_, err := st.DeleteGroup(ctx, gone, 0)
+	require.NoError(t, err)
+
+	refs, err := st.ListExistingRefs(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, refs, "no refs")
+
+	refs, err = st.ListExistingRefs(ctx, []model.EntityRef{other, missing, gone, team, {Name: "team"}, team})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{team, other}, refs)
+
+	// More refs than fit in one query are looked up in batches.
+	many := []model.EntityRef{team}
+	for i := range 1000 {
+		many = append(many, model.EntityRef{Kind: model.KindGroup, Namespace: "default", Name: fmt.Sprintf("missing%d", i)})
+	}
+	many = append(many, other)
+	refs, err = st.ListExistingRefs(ctx, many)
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{team, other}, refs, "many refs")
+}
+
+// ---
+
+func testCanceled(t *testing.T, st store.Store) {
+	c, err := st.CreateComponent(context.Background(), model.TestFullComponent)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	other := model.TestFullComponent
+	other.Metadata.Name = "other"
+	_, err = st.CreateComponent(ctx, other)
+	assert.ErrorIs(t, err, context.Canceled, "creating")
+	_, err = st.ReadComponent(ctx, c.EntityRef())
+	assert.ErrorIs(t, err, context.Canceled, "reading")
+	updated := c
+	updated.Metadata.Title = "my-new-title"
+	_, err = st.UpdateComponent(ctx, updated)
+	assert.ErrorIs(t, err, context.Canceled, "updating")
+	_, err = st.DeleteComponent(ctx, c.EntityRef(), 0)
+	assert.ErrorIs(t, err, context.Canceled, "deleting")
+	_, _, err = st.ListComponents(ctx, nil, store.Ordering{}, store.Pagination{})
+	assert.ErrorIs(t, err, context.Canceled, "listing")
+	_, _, err = st.ListEntities(ctx, nil, nil, store.Ordering{}, store.Pagination{})
+	assert.ErrorIs(t, err, context.Canceled, "listing all kinds")
+	_, err = st.ResolveUID(ctx, c.Metadata.UID)
+	assert.ErrorIs(t, err, context.Canceled, "resolving a UID")
+	_, _, err = st.ListRevisions(ctx, c.EntityRef(), store.Pagination{})
+	assert.ErrorIs(t, err, context.Canceled, "listing history")
+	_, err = st.ReadRevision(ctx, c.EntityRef(), 1)
+	assert.ErrorIs(t, err, context.Canceled, "reading a revision")
+	err = st.RestoreEntity(ctx, c.EntityRef())
+	assert.ErrorIs(t, err, context.Canceled, "restoring")
+	_, _, err = st.ListDeletedEntities(ctx, nil, store.Pagination{})
+	assert.ErrorIs(t, err, context.Canceled, "listing deleted entities")
+	_, err = st.PurgeEntities(ctx, time.Now())
+	assert.ErrorIs(t, err, context.Canceled, "purging")
+	_, err = st.ListRelations(ctx, c.EntityRef())
+	assert.ErrorIs(t, err, context.Canceled, "listing relations")
+	_, err = st.ListReferrers(ctx, c.EntityRef())
+	assert.ErrorIs(t, err, context.Canceled, "listing referrers")
+	_, err = st.ListDanglingRefs(ctx)
+	assert.ErrorIs(t, err, context.Canceled, "listing dangling refs")
+	_, err = st.ListSpecRefs(ctx, nil)
+	assert.ErrorIs(t, err, context.Canceled, "listing spec refs")
+	_, err = st.ListExistingRefs(ctx, []model.EntityRef{c.EntityRef()})
+	assert.ErrorIs(t, err, context.Canceled, "listing existing refs")
+
+	refs, _, err := st.ListComponents(context.Background(), nil, store.Ordering{}, store.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.EntityRef{c.EntityRef()}, refs, "nothing changed")
+	read, err := st.ReadComponent(context.Background(), c.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, c, read)
+}
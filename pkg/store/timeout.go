@@ -0,0 +1,441 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bhavanki/rewind/pkg/model"
+)
+
+// Timeouts are the deadlines for store operations. Reads include lists,
+// searches and reports; writes are every operation that changes entities.
+// A timeout of zero or less puts no deadline on its operations.
+type Timeouts struct {
+	Read  time.Duration
+	Write time.Duration
+}
+
+// timeoutStore puts a deadline on every operation of another store.
+type timeoutStore struct {
+	store    Store
+	timeouts Timeouts
+}
+
+var _ Store = &timeoutStore{}
+
+// WithTimeout wraps a store so that each operation is canceled if it runs
+// longer than timeout, failing with an error that wraps ErrTimeout. A timeout
+// of zero or less leaves the store as it is.
+func WithTimeout(st Store, timeout time.Duration) Store {
+	return WithTimeouts(st, Timeouts{Read: timeout, Write: timeout})
+}
+
+// WithTimeouts is WithTimeout with separate timeouts for reads and writes.
+func WithTimeouts(st Store, timeouts Timeouts) Store {
+	if timeouts.Read <= 0 && timeouts.Write <= 0 {
+		return st
+	}
+	return &timeoutStore{
+		store:    st,
+		timeouts: timeouts,
+	}
+}
+
+// withTimeout runs an operation under a deadline. Only errors caused by that
+// deadline are reported as timeouts; those caused by the caller's own context
+// are returned as they are.
+func withTimeout[T any](ctx context.Context, timeout time.Duration, op func(context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return op(ctx)
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, ErrTimeout)
+	defer cancel()
+	t, err := op(ctx)
+	return t, timeoutError(ctx, timeout, err)
+}
+
+// withTimeoutPage is withTimeout for operations that return a page of results.
+func withTimeoutPage[T any](ctx context.Context, timeout time.Duration, op func(context.Context) (T, Pagination, error)) (T, Pagination, error) {
+	if timeout <= 0 {
+		return op(ctx)
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, ErrTimeout)
+	defer cancel()
+	t, pagination, err := op(ctx)
+	return t, pagination, timeoutError(ctx, timeout, err)
+}
+
+func timeoutError(ctx context.Context, timeout time.Duration, err error) error {
+	if err != nil && context.Cause(ctx) == ErrTimeout {
+		return fmt.Errorf("%w after %s: %w", ErrTimeout, timeout, err)
+	}
+	return err
+}
+
+func (s *timeoutStore) ListEntities(ctx context.Context, kinds []string, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return withTimeoutPage(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.EntityRef, Pagination, error) {
+		return s.store.ListEntities(ctx, kinds, filters, ordering, pagination)
+	})
+}
+
+func (s *timeoutStore) ResolveUID(ctx context.Context, uid string) (model.EntityRef, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) (model.EntityRef, error) {
+		return s.store.ResolveUID(ctx, uid)
+	})
+}
+
+func (s *timeoutStore) ListRevisions(ctx context.Context, ref model.EntityRef, pagination Pagination) ([]model.Revision, Pagination, error) {
+	return withTimeoutPage(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.Revision, Pagination, error) {
+		return s.store.ListRevisions(ctx, ref, pagination)
+	})
+}
+
+func (s *timeoutStore) ReadRevision(ctx context.Context, ref model.EntityRef, version int64) (model.Revision, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) (model.Revision, error) {
+		return s.store.ReadRevision(ctx, ref, version)
+	})
+}
+
+func (s *timeoutStore) RestoreEntity(ctx context.Context, ref model.EntityRef) error {
+	_, err := withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, s.store.RestoreEntity(ctx, ref)
+	})
+	return err
+}
+
+func (s *timeoutStore) ListDeletedEntities(ctx context.Context, kinds []string, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return withTimeoutPage(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.EntityRef, Pagination, error) {
+		return s.store.ListDeletedEntities(ctx, kinds, pagination)
+	})
+}
+
+func (s *timeoutStore) PurgeEntities(ctx context.Context, before time.Time) (int64, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (int64, error) {
+		return s.store.PurgeEntities(ctx, before)
+	})
+}
+
+func (s *timeoutStore) ListRelations(ctx context.Context, ref model.EntityRef) ([]model.Relation, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.Relation, error) {
+		return s.store.ListRelations(ctx, ref)
+	})
+}
+
+func (s *timeoutStore) ListReferrers(ctx context.Context, ref model.EntityRef) ([]model.Referrer, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.Referrer, error) {
+		return s.store.ListReferrers(ctx, ref)
+	})
+}
+
+func (s *timeoutStore) ListDanglingRefs(ctx context.Context) ([]model.DanglingRef, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.DanglingRef, error) {
+		return s.store.ListDanglingRefs(ctx)
+	})
+}
+
+func (s *timeoutStore) ListSpecRefs(ctx context.Context, fields []string) ([]model.EntitySpecRef, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.EntitySpecRef, error) {
+		return s.store.ListSpecRefs(ctx, fields)
+	})
+}
+
+func (s *timeoutStore) ListExistingRefs(ctx context.Context, refs []model.EntityRef) ([]model.EntityRef, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.EntityRef, error) {
+		return s.store.ListExistingRefs(ctx, refs)
+	})
+}
+
+func (s *timeoutStore) SearchEntities(ctx context.Context, query string, pagination Pagination) ([]SearchMatch, Pagination, error) {
+	return withTimeoutPage(ctx, s.timeouts.Read, func(ctx context.Context) ([]SearchMatch, Pagination, error) {
+		return s.store.SearchEntities(ctx, query, pagination)
+	})
+}
+
+// ---
+
+func (s *timeoutStore) CreateComponent(ctx context.Context, c model.Component) (model.Component, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Component, error) {
+		return s.store.CreateComponent(ctx, c)
+	})
+}
+
+func (s *timeoutStore) ReadComponent(ctx context.Context, ref model.EntityRef) (model.Component, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) (model.Component, error) {
+		return s.store.ReadComponent(ctx, ref)
+	})
+}
+
+func (s *timeoutStore) UpdateComponent(ctx context.Context, c model.Component) (model.Component, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Component, error) {
+		return s.store.UpdateComponent(ctx, c)
+	})
+}
+
+func (s *timeoutStore) DeleteComponent(ctx context.Context, ref model.EntityRef, version int64) (model.Component, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Component, error) {
+		return s.store.DeleteComponent(ctx, ref, version)
+	})
+}
+
+func (s *timeoutStore) ListComponents(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return withTimeoutPage(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.EntityRef, Pagination, error) {
+		return s.store.ListComponents(ctx, filters, ordering, pagination)
+	})
+}
+
+// ---
+
+func (s *timeoutStore) CreateAPI(ctx context.Context, a model.API) (model.API, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.API, error) {
+		return s.store.CreateAPI(ctx, a)
+	})
+}
+
+func (s *timeoutStore) ReadAPI(ctx context.Context, ref model.EntityRef) (model.API, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) (model.API, error) {
+		return s.store.ReadAPI(ctx, ref)
+	})
+}
+
+func (s *timeoutStore) UpdateAPI(ctx context.Context, a model.API) (model.API, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.API, error) {
+		return s.store.UpdateAPI(ctx, a)
+	})
+}
+
+func (s *timeoutStore) DeleteAPI(ctx context.Context, ref model.EntityRef, version int64) (model.API, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.API, error) {
+		return s.store.DeleteAPI(ctx, ref, version)
+	})
+}
+
+func (s *timeoutStore) ListAPIs(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return withTimeoutPage(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.EntityRef, Pagination, error) {
+		return s.store.ListAPIs(ctx, filters, ordering, pagination)
+	})
+}
+
+// ---
+
+func (s *timeoutStore) CreateUser(ctx context.Context, u model.User) (model.User, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.User, error) {
+		return s.store.CreateUser(ctx, u)
+	})
+}
+
+func (s *timeoutStore) ReadUser(ctx context.Context, ref model.EntityRef) (model.User, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) (model.User, error) {
+		return s.store.ReadUser(ctx, ref)
+	})
+}
+
+func (s *timeoutStore) UpdateUser(ctx context.Context, u model.User) (model.User, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.User, error) {
+		return s.store.UpdateUser(ctx, u)
+	})
+}
+
+func (s *timeoutStore) DeleteUser(ctx context.Context, ref model.EntityRef, version int64) (model.User, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.User, error) {
+		return s.store.DeleteUser(ctx, ref, version)
+	})
+}
+
+func (s *timeoutStore) ListUsers(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return withTimeoutPage(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.EntityRef, Pagination, error) {
+		return s.store.ListUsers(ctx, filters, ordering, pagination)
+	})
+}
+
+// ---
+
+func (s *timeoutStore) CreateGroup(ctx context.Context, g model.Group) (model.Group, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Group, error) {
+		return s.store.CreateGroup(ctx, g)
+	})
+}
+
+func (s *timeoutStore) ReadGroup(ctx context.Context, ref model.EntityRef) (model.Group, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) (model.Group, error) {
+		return s.store.ReadGroup(ctx, ref)
+	})
+}
+
+func (s *timeoutStore) UpdateGroup(ctx context.Context, g model.Group) (model.Group, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Group, error) {
+		return s.store.UpdateGroup(ctx, g)
+	})
+}
+
+func (s *timeoutStore) DeleteGroup(ctx context.Context, ref model.EntityRef, version int64) (model.Group, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Group, error) {
+		return s.store.DeleteGroup(ctx, ref, version)
+	})
+}
+
+func (s *timeoutStore) ListGroups(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return withTimeoutPage(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.EntityRef, Pagination, error) {
+		return s.store.ListGroups(ctx, filters, ordering, pagination)
+	})
+}
+
+// ---
+
+func (s *timeoutStore) CreateSystem(ctx context.Context, sy model.System) (model.System, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.System, error) {
+		return s.store.CreateSystem(ctx, sy)
+	})
+}
+
+func (s *timeoutStore) ReadSystem(ctx context.Context, ref model.EntityRef) (model.System, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) (model.System, error) {
+		return s.store.ReadSystem(ctx, ref)
+	})
+}
+
+func (s *timeoutStore) UpdateSystem(ctx context.Context, sy model.System) (model.System, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.System, error) {
+		return s.store.UpdateSystem(ctx, sy)
+	})
+}
+
+func (s *timeoutStore) DeleteSystem(ctx context.Context, ref model.EntityRef, version int64) (model.System, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.System, error) {
+		return s.store.DeleteSystem(ctx, ref, version)
+	})
+}
+
+func (s *timeoutStore) ListSystems(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return withTimeoutPage(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.EntityRef, Pagination, error) {
+		return s.store.ListSystems(ctx, filters, ordering, pagination)
+	})
+}
+
+// ---
+
+func (s *timeoutStore) CreateResource(ctx context.Context, r model.Resource) (model.Resource, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Resource, error) {
+		return s.store.CreateResource(ctx, r)
+	})
+}
+
+func (s *timeoutStore) ReadResource(ctx context.Context, ref model.EntityRef) (model.Resource, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) (model.Resource, error) {
+		return s.store.ReadResource(ctx, ref)
+	})
+}
+
+func (s *timeoutStore) UpdateResource(ctx context.Context, r model.Resource) (model.Resource, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Resource, error) {
+		return s.store.UpdateResource(ctx, r)
+	})
+}
+
+func (s *timeoutStore) DeleteResource(ctx context.Context, ref model.EntityRef, version int64) (model.Resource, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Resource, error) {
+		return s.store.DeleteResource(ctx, ref, version)
+	})
+}
+
+func (s *timeoutStore) ListResources(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return withTimeoutPage(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.EntityRef, Pagination, error) {
+		return s.store.ListResources(ctx, filters, ordering, pagination)
+	})
+}
+
+// ---
+
+func (s *timeoutStore) CreateDomain(ctx context.Context, d model.Domain) (model.Domain, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Domain, error) {
+		return s.store.CreateDomain(ctx, d)
+	})
+}
+
+func (s *timeoutStore) ReadDomain(ctx context.Context, ref model.EntityRef) (model.Domain, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) (model.Domain, error) {
+		return s.store.ReadDomain(ctx, ref)
+	})
+}
+
+func (s *timeoutStore) UpdateDomain(ctx context.Context, d model.Domain) (model.Domain, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Domain, error) {
+		return s.store.UpdateDomain(ctx, d)
+	})
+}
+
+func (s *timeoutStore) DeleteDomain(ctx context.Context, ref model.EntityRef, version int64) (model.Domain, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Domain, error) {
+		return s.store.DeleteDomain(ctx, ref, version)
+	})
+}
+
+func (s *timeoutStore) ListDomains(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return withTimeoutPage(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.EntityRef, Pagination, error) {
+		return s.store.ListDomains(ctx, filters, ordering, pagination)
+	})
+}
+
+// ---
+
+func (s *timeoutStore) CreateLocation(ctx context.Context, l model.Location) (model.Location, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Location, error) {
+		return s.store.CreateLocation(ctx, l)
+	})
+}
+
+func (s *timeoutStore) ReadLocation(ctx context.Context, ref model.EntityRef) (model.Location, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) (model.Location, error) {
+		return s.store.ReadLocation(ctx, ref)
+	})
+}
+
+func (s *timeoutStore) UpdateLocation(ctx context.Context, l model.Location) (model.Location, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Location, error) {
+		return s.store.UpdateLocation(ctx, l)
+	})
+}
+
+func (s *timeoutStore) DeleteLocation(ctx context.Context, ref model.EntityRef, version int64) (model.Location, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Location, error) {
+		return s.store.DeleteLocation(ctx, ref, version)
+	})
+}
+
+func (s *timeoutStore) ListLocations(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return withTimeoutPage(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.EntityRef, Pagination, error) {
+		return s.store.ListLocations(ctx, filters, ordering, pagination)
+	})
+}
+
+// ---
+
+func (s *timeoutStore) CreateTemplate(ctx context.Context, t model.Template) (model.Template, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Template, error) {
+		return s.store.CreateTemplate(ctx, t)
+	})
+}
+
+func (s *timeoutStore) ReadTemplate(ctx context.Context, ref model.EntityRef) (model.Template, error) {
+	return withTimeout(ctx, s.timeouts.Read, func(ctx context.Context) (model.Template, error) {
+		return s.store.ReadTemplate(ctx, ref)
+	})
+}
+
+func (s *timeoutStore) UpdateTemplate(ctx context.Context, t model.Template) (model.Template, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Template, error) {
+		return s.store.UpdateTemplate(ctx, t)
+	})
+}
+
+func (s *timeoutStore) DeleteTemplate(ctx context.Context, ref model.EntityRef, version int64) (model.Template, error) {
+	return withTimeout(ctx, s.timeouts.Write, func(ctx context.Context) (model.Template, error) {
+		return s.store.DeleteTemplate(ctx, ref, version)
+	})
+}
+
+func (s *timeoutStore) ListTemplates(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+	return withTimeoutPage(ctx, s.timeouts.Read, func(ctx context.Context) ([]model.EntityRef, Pagination, error) {
+		return s.store.ListTemplates(ctx, filters, ordering, pagination)
+	})
+}
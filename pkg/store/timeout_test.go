@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bhavanki/rewind/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingStore makes a store whose reads and lists wait until their context
+// is done.
+func blockingStore() *StoreMock {
+	return &StoreMock{
+		ReadComponentFunc: func(ctx context.Context, ref model.EntityRef) (model.Component, error) {
+			<-ctx.Done()
+			return model.Component{}, ctx.Err()
+		},
+		ListComponentsFunc: func(ctx context.Context, filters []Filter, ordering Ordering, pagination Pagination) ([]model.EntityRef, Pagination, error) {
+			<-ctx.Done()
+			return nil, Pagination{}, ctx.Err()
+		},
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	st := WithTimeout(blockingStore(), 10*time.Millisecond)
+
+	_, err := st.ReadComponent(context.Background(), model.TestFullComponent.EntityRef())
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, _, err = st.ListComponents(context.Background(), nil, Ordering{}, Pagination{})
+	assert.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestWithTimeout_CallerCanceled(t *testing.T) {
+	st := WithTimeout(blockingStore(), time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := st.ReadComponent(ctx, model.TestFullComponent.EntityRef())
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrTimeout)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = st.ReadComponent(ctx, model.TestFullComponent.EntityRef())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, ErrTimeout, "the caller's deadline is not the store's")
+}
+
+func TestWithTimeout_PassesThrough(t *testing.T) {
+	mock := &StoreMock{
+		ReadComponentFunc: func(ctx context.Context, ref model.EntityRef) (model.Component, error) {
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			if ref.Name == "missing" {
+				return model.Component{}, ErrNotFound
+			}
+			return model.TestFullComponent, nil
+		},
+	}
+	st := WithTimeout(mock, time.Minute)
+
+	c, err := st.ReadComponent(context.Background(), model.TestFullComponent.EntityRef())
+	require.NoError(t, err)
+	assert.Equal(t, model.TestFullComponent, c)
+
+	_, err = st.ReadComponent(context.Background(), model.EntityRef{Kind: model.KindComponent, Namespace: "default", Name: "missing"})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrTimeout)
+}
+
+func TestWithTimeout_None(t *testing.T) {
+	mock := &StoreMock{}
+	assert.Same(t, mock, WithTimeout(mock, 0))
+	assert.Same(t, mock, WithTimeouts(mock, Timeouts{}))
+}
+
+func TestWithTimeouts(t *testing.T) {
+	// slowStore makes a store whose reads and writes take 50ms, unless their
+	// context is done first.
+	slowStore := func() *StoreMock {
+		wait := func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(50 * time.Millisecond):
+				return nil
+			}
+		}
+		return &StoreMock{
+			ReadComponentFunc: func(ctx context.Context, ref model.EntityRef) (model.Component, error) {
+				return model.TestFullComponent, wait(ctx)
+			},
+			CreateComponentFunc: func(ctx context.Context, c model.Component) (model.Component, error) {
+				return c, wait(ctx)
+			},
+		}
+	}
+	ref := model.TestFullComponent.EntityRef()
+
+	st := WithTimeouts(slowStore(), Timeouts{Read: 10 * time.Millisecond, Write: time.Minute})
+	_, err := st.ReadComponent(context.Background(), ref)
+	assert.ErrorIs(t, err, ErrTimeout, "a slow read hits the read timeout")
+	assert.ErrorContains(t, err, "after 10ms")
+	_, err = st.CreateComponent(context.Background(), model.TestFullComponent)
+	assert.NoError(t, err, "a slow write is within the write timeout")
+
+	st = WithTimeouts(slowStore(), Timeouts{Read: time.Minute, Write: 10 * time.Millisecond})
+	_, err = st.ReadComponent(context.Background(), ref)
+	assert.NoError(t, err, "a slow read is within the read timeout")
+	_, err = st.CreateComponent(context.Background(), model.TestFullComponent)
+	assert.ErrorIs(t, err, ErrTimeout, "a slow write hits the write timeout")
+	assert.ErrorContains(t, err, "after 10ms")
+
+	st = WithTimeouts(slowStore(), Timeouts{Write: 10 * time.Millisecond})
+	_, err = st.ReadComponent(context.Background(), ref)
+	assert.NoError(t, err, "reads have no deadline without a read timeout")
+}